@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"loan_tracker_api/domain"
+	"net/http"
+	"strconv"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// CollateralController struct to hold the usecase
+type CollateralController struct {
+	CollateralUsecase domain.CollateralUsecase
+}
+
+// NewCollateralController function to create a new CollateralController
+func NewCollateralController(cuse domain.CollateralUsecase) *CollateralController {
+	return &CollateralController{
+		CollateralUsecase: cuse,
+	}
+}
+
+// RegisterCollateral function to handle the RegisterCollateral endpoint
+func (colc *CollateralController) RegisterCollateral(c *gin.Context) {
+	userid := c.GetString("userid")
+	var collateral domain.Collateral
+
+	if err := c.ShouldBindJSON(&collateral); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := colc.CollateralUsecase.RegisterCollateral(requestContext(c), &collateral, userid)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Collateral registered", "collateral": collateral})
+}
+
+// ListCollateral function to handle the ListCollateral endpoint
+func (colc *CollateralController) ListCollateral(c *gin.Context) {
+	pgnum, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || pgnum < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+
+	collateral, err := colc.CollateralUsecase.ListCollateral(requestContext(c), c.Query("owner_id"), pgnum)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"collateral": collateral})
+}
+
+// GetCollateral function to handle the GetCollateral endpoint
+func (colc *CollateralController) GetCollateral(c *gin.Context) {
+	collateral, err := colc.CollateralUsecase.GetCollateral(requestContext(c), c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"collateral": collateral})
+}
+
+// AppraiseCollateral function to handle the AppraiseCollateral endpoint
+func (colc *CollateralController) AppraiseCollateral(c *gin.Context) {
+	userid := c.GetString("userid")
+	var appraisal domain.Appraisal
+
+	if err := c.ShouldBindJSON(&appraisal); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	collateral, err := colc.CollateralUsecase.AppraiseCollateral(requestContext(c), c.Param("id"), appraisal, userid)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collateral appraised", "collateral": collateral})
+}
+
+// LinkCollateral function to handle the LinkCollateral endpoint
+func (colc *CollateralController) LinkCollateral(c *gin.Context) {
+	collateral, err := colc.CollateralUsecase.LinkCollateral(requestContext(c), c.Param("loan_id"), c.Param("id"), c.GetString("userid"))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collateral linked to the loan", "collateral": collateral})
+}
+
+// UnlinkCollateral function to handle the UnlinkCollateral endpoint
+func (colc *CollateralController) UnlinkCollateral(c *gin.Context) {
+	collateral, err := colc.CollateralUsecase.UnlinkCollateral(requestContext(c), c.Param("loan_id"), c.Param("id"), c.GetString("userid"))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collateral unlinked from the loan", "collateral": collateral})
+}
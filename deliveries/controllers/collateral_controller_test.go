@@ -0,0 +1,108 @@
+package controllers_test
+
+import (
+	"errors"
+	"loan_tracker_api/deliveries/controllers"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CollateralControllerTestSuite struct {
+	suite.Suite
+	controller  *controllers.CollateralController
+	mockUsecase *mocks.CollateralUsecase
+	Recorder    *httptest.ResponseRecorder
+	mockContext *gin.Context
+}
+
+func (suite *CollateralControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.mockUsecase = new(mocks.CollateralUsecase)
+	suite.controller = controllers.NewCollateralController(suite.mockUsecase)
+	// Prepare the recorder and context
+	suite.Recorder = httptest.NewRecorder()
+	suite.mockContext, _ = gin.CreateTestContext(suite.Recorder)
+}
+
+func (suite *CollateralControllerTestSuite) TestRegisterCollateral() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("RegisterCollateral", mock.Anything, mock.MatchedBy(func(c *domain.Collateral) bool {
+		return c.Type == domain.CollateralProperty && c.AppraisedValue == 250000 && len(c.Documents) == 1
+	}), "testadminid").Return(nil).Once()
+
+	// Prepare the request
+	body := `{"owner_id":"66b0f0c2a1b2c3d4e5f60718","type":"property","description":"Two bedroom apartment",
+		"appraised_value":250000,"appraisal_date":"2026-09-01T00:00:00Z","documents":[{"kind":"title_deed","reference":"TD-4471"}]}`
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/collateral", strings.NewReader(body))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+	suite.mockContext.Set("userid", "testadminid")
+
+	// Call the controller function
+	suite.controller.RegisterCollateral(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusCreated, suite.Recorder.Code)
+	suite.mockUsecase.AssertExpectations(suite.T())
+}
+
+func (suite *CollateralControllerTestSuite) TestListCollateralInvalidPage() {
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/admin/collateral?page=zero", nil)
+
+	// Call the controller function
+	suite.controller.ListCollateral(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusBadRequest, suite.Recorder.Code)
+	suite.mockUsecase.AssertNotCalled(suite.T(), "ListCollateral", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *CollateralControllerTestSuite) TestAppraiseCollateral() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("AppraiseCollateral", mock.Anything, "testcollateralid", mock.MatchedBy(func(a domain.Appraisal) bool {
+		return a.AppraisedValue == 230000
+	}), "testadminid").Return(domain.Collateral{AppraisedValue: 230000}, nil).Once()
+
+	// Prepare the request
+	body := `{"description":"Two bedroom apartment","appraised_value":230000,"appraisal_date":"2026-10-01T00:00:00Z"}`
+	suite.mockContext.Request = httptest.NewRequest("PUT", "/admin/collateral/testcollateralid/appraisal", strings.NewReader(body))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+	suite.mockContext.Params = gin.Params{{Key: "id", Value: "testcollateralid"}}
+	suite.mockContext.Set("userid", "testadminid")
+
+	// Call the controller function
+	suite.controller.AppraiseCollateral(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), `"appraised_value":230000`)
+}
+
+func (suite *CollateralControllerTestSuite) TestLinkCollateralError() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("LinkCollateral", mock.Anything, "testloanid", "testcollateralid", "testadminid").Return(domain.Collateral{}, errors.New("Collateral can only be linked to open loans")).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/loans/testloanid/collateral/testcollateralid", nil)
+	suite.mockContext.Params = gin.Params{{Key: "loan_id", Value: "testloanid"}, {Key: "id", Value: "testcollateralid"}}
+	suite.mockContext.Set("userid", "testadminid")
+
+	// Call the controller function
+	suite.controller.LinkCollateral(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusInternalServerError, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), "open loans")
+}
+
+func TestCollateralControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(CollateralControllerTestSuite))
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"loan_tracker_api/domain"
+	"net/http"
+
+	gin "github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CommentController struct to hold the usecase
+type CommentController struct {
+	CommentUsecase domain.CommentUsecase
+}
+
+// NewCommentController function to create a new CommentController
+func NewCommentController(cuse domain.CommentUsecase) *CommentController {
+	return &CommentController{
+		CommentUsecase: cuse,
+	}
+}
+
+// AddComment function to handle the AddComment endpoint
+func (cc *CommentController) AddComment(c *gin.Context) {
+	userid := c.GetString("userid")
+	var comment domain.Comment
+
+	if err := c.ShouldBindJSON(&comment); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := cc.CommentUsecase.AddComment(requestContext(c), c.Param("loan_id"), &comment, userid)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Comment posted", "comment": comment})
+}
+
+// LoanComments function to handle the LoanComments endpoint
+func (cc *CommentController) LoanComments(c *gin.Context) {
+	comments, err := cc.CommentUsecase.LoanComments(requestContext(c), c.Param("loan_id"), c.GetString("userid"))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"comments": comments})
+}
+
+// EditComment function to handle the EditComment endpoint
+func (cc *CommentController) EditComment(c *gin.Context) {
+	userid := c.GetString("userid")
+
+	var edit struct {
+		Body     string               `json:"body"`
+		Mentions []primitive.ObjectID `json:"mentions"`
+	}
+
+	if err := c.ShouldBindJSON(&edit); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := cc.CommentUsecase.EditComment(requestContext(c), c.Param("loan_id"), c.Param("comment_id"), edit.Body, edit.Mentions, userid)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment edited", "comment": comment})
+}
@@ -0,0 +1,110 @@
+package controllers_test
+
+import (
+	"errors"
+	"loan_tracker_api/deliveries/controllers"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CommentControllerTestSuite struct {
+	suite.Suite
+	controller  *controllers.CommentController
+	mockUsecase *mocks.CommentUsecase
+	Recorder    *httptest.ResponseRecorder
+	mockContext *gin.Context
+}
+
+func (suite *CommentControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.mockUsecase = new(mocks.CommentUsecase)
+	suite.controller = controllers.NewCommentController(suite.mockUsecase)
+	// Prepare the recorder and context
+	suite.Recorder = httptest.NewRecorder()
+	suite.mockContext, _ = gin.CreateTestContext(suite.Recorder)
+}
+
+func (suite *CommentControllerTestSuite) TestAddComment() {
+	mentioned, _ := primitive.ObjectIDFromHex("66b0f0c2a1b2c3d4e5f60718")
+
+	// Set up the mock expectation
+	suite.mockUsecase.On("AddComment", mock.Anything, "testloanid", mock.MatchedBy(func(c *domain.Comment) bool {
+		return c.Body == "Please double check the payslips" && c.Visibility == domain.CommentInternal && len(c.Mentions) == 1 && c.Mentions[0] == mentioned
+	}), "testadminid").Return(nil).Once()
+
+	// Prepare the request
+	body := `{"body":"Please double check the payslips","visibility":"internal","mentions":["66b0f0c2a1b2c3d4e5f60718"]}`
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/loans/testloanid/comments", strings.NewReader(body))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+	suite.mockContext.Params = gin.Params{{Key: "loan_id", Value: "testloanid"}}
+	suite.mockContext.Set("userid", "testadminid")
+
+	// Call the controller function
+	suite.controller.AddComment(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusCreated, suite.Recorder.Code)
+	suite.mockUsecase.AssertExpectations(suite.T())
+}
+
+func (suite *CommentControllerTestSuite) TestAddCommentInvalidMention() {
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/loans/testloanid/comments", strings.NewReader(`{"body":"Hi","mentions":["someone"]}`))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+	suite.mockContext.Params = gin.Params{{Key: "loan_id", Value: "testloanid"}}
+
+	// Call the controller function
+	suite.controller.AddComment(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusUnprocessableEntity, suite.Recorder.Code)
+	suite.mockUsecase.AssertNotCalled(suite.T(), "AddComment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *CommentControllerTestSuite) TestLoanComments() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("LoanComments", mock.Anything, "testloanid", "testuserid").Return([]domain.Comment{{Body: "Your application is with an underwriter", Visibility: domain.CommentBorrower}}, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/loan/testloanid/comments", nil)
+	suite.mockContext.Params = gin.Params{{Key: "loan_id", Value: "testloanid"}}
+	suite.mockContext.Set("userid", "testuserid")
+
+	// Call the controller function
+	suite.controller.LoanComments(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), "with an underwriter")
+}
+
+func (suite *CommentControllerTestSuite) TestEditCommentError() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("EditComment", mock.Anything, "testloanid", "testcommentid", "Updated", []primitive.ObjectID(nil), "testuserid").Return(domain.Comment{}, errors.New("Only the author can edit a comment")).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("PUT", "/loan/testloanid/comments/testcommentid", strings.NewReader(`{"body":"Updated"}`))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+	suite.mockContext.Params = gin.Params{{Key: "loan_id", Value: "testloanid"}, {Key: "comment_id", Value: "testcommentid"}}
+	suite.mockContext.Set("userid", "testuserid")
+
+	// Call the controller function
+	suite.controller.EditComment(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusInternalServerError, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), "Only the author")
+}
+
+func TestCommentControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(CommentControllerTestSuite))
+}
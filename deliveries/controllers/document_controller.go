@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"loan_tracker_api/domain"
+	"net/http"
+	"strconv"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// room left in an upload request for the multipart headers and the other form fields, in bytes
+const uploadOverhead = 64 << 10
+
+// DocumentController struct to hold the usecase and the largest document that can be uploaded, in bytes
+type DocumentController struct {
+	DocumentUsecase domain.DocumentUsecase
+	MaxSize         int64
+}
+
+// NewDocumentController function to create a new DocumentController
+func NewDocumentController(duse domain.DocumentUsecase, maxSize int64) *DocumentController {
+	return &DocumentController{
+		DocumentUsecase: duse,
+		MaxSize:         maxSize,
+	}
+}
+
+// UploadDocument function to handle the UploadDocument endpoint
+func (dc *DocumentController) UploadDocument(c *gin.Context) {
+	userid := c.GetString("userid")
+	loanID := c.Param("loan_id")
+
+	// the form is read before the usecase sees the size, an oversized body is cut off rather than buffered
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, dc.MaxSize+uploadOverhead)
+
+	header, err := c.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Document is larger than %d bytes", dc.MaxSize)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "A file is required"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Error reading file"})
+		return
+	}
+	defer file.Close()
+
+	document, err := dc.DocumentUsecase.UploadDocument(requestContext(c), loanID, domain.DocumentUpload{
+		Kind:     c.PostForm("kind"),
+		Filename: header.Filename,
+		Size:     header.Size,
+		Content:  file,
+	}, userid)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Document uploaded", "document": document})
+}
+
+// LoanDocuments function to handle the LoanDocuments endpoint
+func (dc *DocumentController) LoanDocuments(c *gin.Context) {
+	documents, err := dc.DocumentUsecase.LoanDocuments(requestContext(c), c.Param("loan_id"), c.GetString("userid"))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"documents": documents})
+}
+
+// DocumentLink function to handle the DocumentLink endpoint
+func (dc *DocumentController) DocumentLink(c *gin.Context) {
+	link, err := dc.DocumentUsecase.DocumentLink(requestContext(c), c.Param("loan_id"), c.Param("document_id"), c.GetString("userid"))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"link": link})
+}
+
+// DownloadDocument function to handle the DownloadDocument endpoint
+func (dc *DocumentController) DownloadDocument(c *gin.Context) {
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil || c.Query("signature") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid download link"})
+		return
+	}
+
+	document, content, err := dc.DocumentUsecase.OpenDocument(requestContext(c), c.Param("document_id"), expires, c.Query("signature"))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, document.Size, document.ContentType, content, map[string]string{
+		"Content-Disposition": "attachment; filename=" + strconv.Quote(document.Filename),
+		"Cache-Control":       "private, no-store",
+	})
+}
@@ -0,0 +1,156 @@
+package controllers_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"loan_tracker_api/deliveries/controllers"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type DocumentControllerTestSuite struct {
+	suite.Suite
+	controller  *controllers.DocumentController
+	mockUsecase *mocks.DocumentUsecase
+	Recorder    *httptest.ResponseRecorder
+	mockContext *gin.Context
+}
+
+func (suite *DocumentControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.mockUsecase = new(mocks.DocumentUsecase)
+	suite.controller = controllers.NewDocumentController(suite.mockUsecase, 1<<20)
+	// Prepare the recorder and context
+	suite.Recorder = httptest.NewRecorder()
+	suite.mockContext, _ = gin.CreateTestContext(suite.Recorder)
+}
+
+func (suite *DocumentControllerTestSuite) TestUploadDocument() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("UploadDocument", mock.Anything, "testloanid", mock.MatchedBy(func(upload domain.DocumentUpload) bool {
+		return upload.Kind == domain.DocumentPayslip && upload.Filename == "march.pdf" && upload.Size == 9
+	}), "testuserid").Return(domain.Document{Kind: domain.DocumentPayslip, Filename: "march.pdf", Checksum: "abc"}, nil).Once()
+
+	// Prepare the request
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("kind", domain.DocumentPayslip)
+	part, _ := writer.CreateFormFile("file", "march.pdf")
+	part.Write([]byte("%PDF-1.4\n"))
+	writer.Close()
+
+	suite.mockContext.Request = httptest.NewRequest("POST", "/loan/testloanid/documents", body)
+	suite.mockContext.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	suite.mockContext.Params = gin.Params{{Key: "loan_id", Value: "testloanid"}}
+	suite.mockContext.Set("userid", "testuserid")
+
+	// Call the controller function
+	suite.controller.UploadDocument(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusCreated, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), `"checksum":"abc"`)
+	suite.mockUsecase.AssertExpectations(suite.T())
+}
+
+func (suite *DocumentControllerTestSuite) TestUploadDocumentTooLarge() {
+	// Prepare the request
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("kind", domain.DocumentPayslip)
+	part, _ := writer.CreateFormFile("file", "march.pdf")
+	part.Write(bytes.Repeat([]byte("a"), 2<<20))
+	writer.Close()
+
+	suite.mockContext.Request = httptest.NewRequest("POST", "/loan/testloanid/documents", body)
+	suite.mockContext.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	suite.mockContext.Params = gin.Params{{Key: "loan_id", Value: "testloanid"}}
+
+	// Call the controller function
+	suite.controller.UploadDocument(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusRequestEntityTooLarge, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), "Document is larger than 1048576 bytes")
+	suite.mockUsecase.AssertNotCalled(suite.T(), "UploadDocument", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *DocumentControllerTestSuite) TestUploadDocumentWithoutFile() {
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/loan/testloanid/documents", strings.NewReader("kind=payslip"))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	suite.mockContext.Params = gin.Params{{Key: "loan_id", Value: "testloanid"}}
+
+	// Call the controller function
+	suite.controller.UploadDocument(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusUnprocessableEntity, suite.Recorder.Code)
+	suite.mockUsecase.AssertNotCalled(suite.T(), "UploadDocument", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *DocumentControllerTestSuite) TestDocumentLink() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("DocumentLink", mock.Anything, "testloanid", "testdocumentid", "testuserid").Return(domain.DocumentLink{URL: "/documents/testdocumentid?expires=1&signature=abc"}, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/loan/testloanid/documents/testdocumentid/link", nil)
+	suite.mockContext.Params = gin.Params{{Key: "loan_id", Value: "testloanid"}, {Key: "document_id", Value: "testdocumentid"}}
+	suite.mockContext.Set("userid", "testuserid")
+
+	// Call the controller function
+	suite.controller.DocumentLink(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), "signature=abc")
+}
+
+func (suite *DocumentControllerTestSuite) TestDownloadDocument() {
+	// Set up the mock expectation
+	document := domain.Document{Filename: "march.pdf", ContentType: "application/pdf", Size: 9}
+	suite.mockUsecase.On("OpenDocument", mock.Anything, "testdocumentid", int64(1700000000), "abc").Return(document, io.NopCloser(strings.NewReader("%PDF-1.4\n")), nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/documents/testdocumentid?expires=1700000000&signature=abc", nil)
+	suite.mockContext.Params = gin.Params{{Key: "document_id", Value: "testdocumentid"}}
+
+	// Call the controller function
+	suite.controller.DownloadDocument(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.Equal("application/pdf", suite.Recorder.Header().Get("Content-Type"))
+	suite.Equal(`attachment; filename="march.pdf"`, suite.Recorder.Header().Get("Content-Disposition"))
+	suite.Equal("%PDF-1.4\n", suite.Recorder.Body.String())
+}
+
+func (suite *DocumentControllerTestSuite) TestDownloadDocumentInvalidLink() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("OpenDocument", mock.Anything, "testdocumentid", int64(1700000000), "abc").Return(domain.Document{}, nil, errors.New("Invalid download link")).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/documents/testdocumentid?expires=1700000000&signature=abc", nil)
+	suite.mockContext.Params = gin.Params{{Key: "document_id", Value: "testdocumentid"}}
+
+	// Call the controller function
+	suite.controller.DownloadDocument(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusInternalServerError, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), "Invalid download link")
+}
+
+func TestDocumentControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(DocumentControllerTestSuite))
+}
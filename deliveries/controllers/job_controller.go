@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"loan_tracker_api/domain"
+	"net/http"
+	"strconv"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// JobController struct to hold the usecase
+type JobController struct {
+	SchedulerUsecase domain.SchedulerUsecase
+}
+
+// NewJobController function to create a new JobController
+func NewJobController(suse domain.SchedulerUsecase) *JobController {
+	return &JobController{
+		SchedulerUsecase: suse,
+	}
+}
+
+// Jobs function to handle the Jobs endpoint
+func (jc *JobController) Jobs(c *gin.Context) {
+	pgnum, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+
+	runs, err := jc.SchedulerUsecase.JobRuns(requestContext(c), c.Query("job"), pgnum)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jobs": jc.SchedulerUsecase.Jobs(requestContext(c)), "runs": runs})
+}
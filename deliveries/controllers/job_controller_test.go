@@ -0,0 +1,68 @@
+package controllers_test
+
+import (
+	"errors"
+	"loan_tracker_api/deliveries/controllers"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type JobControllerTestSuite struct {
+	suite.Suite
+	controller  *controllers.JobController
+	mockUsecase *mocks.SchedulerUsecase
+	Recorder    *httptest.ResponseRecorder
+	mockContext *gin.Context
+}
+
+func (suite *JobControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.mockUsecase = new(mocks.SchedulerUsecase)
+	suite.controller = controllers.NewJobController(suite.mockUsecase)
+	// Prepare the recorder and context
+	suite.Recorder = httptest.NewRecorder()
+	suite.mockContext, _ = gin.CreateTestContext(suite.Recorder)
+}
+
+func (suite *JobControllerTestSuite) TestJobs() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("JobRuns", mock.Anything, "payment_reminders", 2).Return([]domain.JobRun{{Job: "payment_reminders", Status: domain.JobSucceeded}}, nil).Once()
+	suite.mockUsecase.On("Jobs", mock.Anything).Return([]domain.ScheduledJob{{Name: "payment_reminders", Schedule: "0 8 * * *"}}).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/admin/jobs?job=payment_reminders&page=2", nil)
+
+	// Call the controller function
+	suite.controller.Jobs(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), `"schedule":"0 8 * * *"`)
+	suite.Contains(suite.Recorder.Body.String(), `"status":"succeeded"`)
+	suite.mockUsecase.AssertExpectations(suite.T())
+}
+
+func (suite *JobControllerTestSuite) TestJobsError() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("JobRuns", mock.Anything, "", 1).Return(nil, errors.New("Error fetching job runs")).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/admin/jobs", nil)
+
+	// Call the controller function
+	suite.controller.Jobs(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusInternalServerError, suite.Recorder.Code)
+}
+
+func TestJobControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(JobControllerTestSuite))
+}
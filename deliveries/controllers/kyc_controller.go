@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"loan_tracker_api/domain"
+	"net/http"
+	"strconv"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// KYCController struct to hold the usecase
+type KYCController struct {
+	KYCUsecase domain.KYCUsecase
+}
+
+// NewKYCController function to create a new KYCController
+func NewKYCController(kuse domain.KYCUsecase) *KYCController {
+	return &KYCController{
+		KYCUsecase: kuse,
+	}
+}
+
+// SubmitKYC function to handle the SubmitKYC endpoint
+func (kc *KYCController) SubmitKYC(c *gin.Context) {
+	userid := c.GetString("userid")
+	var profile domain.KYCProfile
+
+	if err := c.ShouldBindJSON(&profile); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := kc.KYCUsecase.SubmitKYC(requestContext(c), &profile, userid)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "KYC submitted for review", "kyc": profile})
+}
+
+// KYCProfile function to handle the KYCProfile endpoint
+func (kc *KYCController) KYCProfile(c *gin.Context) {
+	profile, err := kc.KYCUsecase.KYCProfile(requestContext(c), c.GetString("userid"))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"kyc": profile})
+}
+
+// KYCQueue function to handle the KYCQueue endpoint
+func (kc *KYCController) KYCQueue(c *gin.Context) {
+	pgnum, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || pgnum < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", domain.KYCPending, domain.KYCMoreInfo, domain.KYCApproved, domain.KYCRejected:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+
+	profiles, err := kc.KYCUsecase.KYCQueue(requestContext(c), status, pgnum)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"profiles": profiles})
+}
+
+// GetKYCProfile function to handle the GetKYCProfile endpoint
+func (kc *KYCController) GetKYCProfile(c *gin.Context) {
+	profile, err := kc.KYCUsecase.GetKYCProfile(requestContext(c), c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"kyc": profile})
+}
+
+// ReviewKYC function to handle the ReviewKYC endpoint
+func (kc *KYCController) ReviewKYC(c *gin.Context) {
+	userid := c.GetString("userid")
+
+	var review struct {
+		Decision string `json:"decision"`
+		Note     string `json:"note"`
+	}
+
+	if err := c.ShouldBindJSON(&review); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch review.Decision {
+	case domain.KYCDecisionApprove, domain.KYCDecisionReject, domain.KYCDecisionRequestInfo:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid decision"})
+		return
+	}
+
+	profile, err := kc.KYCUsecase.ReviewKYC(requestContext(c), c.Param("id"), review.Decision, review.Note, userid)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "KYC reviewed", "kyc": profile})
+}
@@ -0,0 +1,135 @@
+package controllers_test
+
+import (
+	"errors"
+	"loan_tracker_api/deliveries/controllers"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type KYCControllerTestSuite struct {
+	suite.Suite
+	controller  *controllers.KYCController
+	mockUsecase *mocks.KYCUsecase
+	Recorder    *httptest.ResponseRecorder
+	mockContext *gin.Context
+}
+
+func (suite *KYCControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.mockUsecase = new(mocks.KYCUsecase)
+	suite.controller = controllers.NewKYCController(suite.mockUsecase)
+	// Prepare the recorder and context
+	suite.Recorder = httptest.NewRecorder()
+	suite.mockContext, _ = gin.CreateTestContext(suite.Recorder)
+}
+
+func (suite *KYCControllerTestSuite) TestSubmitKYC() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("SubmitKYC", mock.Anything, mock.MatchedBy(func(p *domain.KYCProfile) bool {
+		return p.LegalName == "Jane Doe" && p.Address.City == "Addis Ababa" && len(p.Documents) == 1
+	}), "testuserid").Return(nil).Once()
+
+	// Prepare the request
+	body := `{"legal_name":"Jane Doe","date_of_birth":"1990-04-12T00:00:00Z","national_id":"ID-123456",
+		"address":{"line1":"1 Main Street","city":"Addis Ababa","country":"ET"},
+		"documents":[{"kind":"passport","reference":"EP1234567"}]}`
+	suite.mockContext.Request = httptest.NewRequest("POST", "/user/kyc", strings.NewReader(body))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+	suite.mockContext.Set("userid", "testuserid")
+
+	// Call the controller function
+	suite.controller.SubmitKYC(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusCreated, suite.Recorder.Code)
+	suite.mockUsecase.AssertExpectations(suite.T())
+}
+
+func (suite *KYCControllerTestSuite) TestSubmitKYCInvalidBody() {
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/user/kyc", strings.NewReader(`{"date_of_birth":"12/04/1990"}`))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+
+	// Call the controller function
+	suite.controller.SubmitKYC(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusUnprocessableEntity, suite.Recorder.Code)
+	suite.mockUsecase.AssertNotCalled(suite.T(), "SubmitKYC", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *KYCControllerTestSuite) TestKYCQueue() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("KYCQueue", mock.Anything, domain.KYCMoreInfo, 2).Return([]domain.KYCProfile{{LegalName: "Jane Doe", Status: domain.KYCMoreInfo}}, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/admin/kyc?status=more_info_requested&page=2", nil)
+
+	// Call the controller function
+	suite.controller.KYCQueue(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), `"legal_name":"Jane Doe"`)
+}
+
+func (suite *KYCControllerTestSuite) TestKYCQueueInvalidStatus() {
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/admin/kyc?status=unknown", nil)
+
+	// Call the controller function
+	suite.controller.KYCQueue(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusBadRequest, suite.Recorder.Code)
+	suite.mockUsecase.AssertNotCalled(suite.T(), "KYCQueue", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *KYCControllerTestSuite) TestReviewKYC() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("ReviewKYC", mock.Anything, "testkycid", domain.KYCDecisionRequestInfo, "Send a recent utility bill", "testadminid").Return(domain.KYCProfile{Status: domain.KYCMoreInfo}, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/kyc/testkycid/review", strings.NewReader(`{"decision":"request_info","note":"Send a recent utility bill"}`))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+	suite.mockContext.Params = gin.Params{{Key: "id", Value: "testkycid"}}
+	suite.mockContext.Set("userid", "testadminid")
+
+	// Call the controller function
+	suite.controller.ReviewKYC(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), `"status":"more_info_requested"`)
+}
+
+func (suite *KYCControllerTestSuite) TestReviewKYCError() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("ReviewKYC", mock.Anything, "testkycid", domain.KYCDecisionReject, "", "testadminid").Return(domain.KYCProfile{}, errors.New("A note is required to reject or request more information")).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/kyc/testkycid/review", strings.NewReader(`{"decision":"reject"}`))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+	suite.mockContext.Params = gin.Params{{Key: "id", Value: "testkycid"}}
+	suite.mockContext.Set("userid", "testadminid")
+
+	// Call the controller function
+	suite.controller.ReviewKYC(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusInternalServerError, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), "A note is required")
+}
+
+func TestKYCControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(KYCControllerTestSuite))
+}
@@ -1,12 +1,14 @@
 package controllers
 
 import (
-	"context"
 	"loan_tracker_api/domain"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	gin "github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // LoanController struct to hold the usecase
@@ -21,17 +23,49 @@ func NewLoanController(luse domain.LoanUsecase) *LoanController {
 	}
 }
 
+// loanApplication is the body of the apply endpoint, only what the borrower asks for.
+// The rest of the loan is set by the system and can't be sent along.
+type loanApplication struct {
+	Amount   float64 `json:"amount"`
+	Duration int     `json:"duration"`
+	Product  string  `json:"product"`
+	Parties  []struct {
+		Role           string             `json:"role"`
+		UserID         primitive.ObjectID `json:"user_id"`
+		Email          string             `json:"email"`
+		LiabilityShare float64            `json:"liability_share"`
+	} `json:"parties"`
+}
+
+func (application loanApplication) loan() domain.Loan {
+	loan := domain.Loan{
+		Amount:   application.Amount,
+		Duration: application.Duration,
+		Product:  application.Product,
+	}
+	for _, party := range application.Parties {
+		loan.Parties = append(loan.Parties, domain.LoanParty{
+			Role:           party.Role,
+			UserID:         party.UserID,
+			Email:          party.Email,
+			LiabilityShare: party.LiabilityShare,
+		})
+	}
+	return loan
+}
+
 // ApplyForLoan function to handle the ApplyForLoan endpoint
 func (lc *LoanController) ApplyForLoan(c *gin.Context) {
 	userid := c.GetString("userid")
-	var loan domain.Loan
+	var application loanApplication
 
-	if err := c.ShouldBindJSON(&loan); err != nil {
+	if err := c.ShouldBindJSON(&application); err != nil {
 		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
 		return
 	}
 
-	err := lc.LoanUsecase.ApplyForLoan(context.Background(), &loan, userid)
+	loan := application.loan()
+	err := lc.LoanUsecase.ApplyForLoan(requestContext(c), &loan, userid)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -46,7 +80,7 @@ func (lc *LoanController) LoanDetails(c *gin.Context) {
 	userid := c.GetString("userid")
 	loanID := c.Param("loan_id")
 
-	loan, err := lc.LoanUsecase.LoanDetails(context.Background(), loanID, userid)
+	loan, err := lc.LoanUsecase.LoanDetails(requestContext(c), loanID, userid)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -56,6 +90,46 @@ func (lc *LoanController) LoanDetails(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"loan": loan})
 }
 
+// UserLoans function to handle the UserLoans endpoint
+func (lc *LoanController) UserLoans(c *gin.Context) {
+	loans, err := lc.LoanUsecase.UserLoans(requestContext(c), c.GetString("userid"))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"loans": loans})
+}
+
+// RespondToLoan function to handle the RespondToLoan endpoint
+func (lc *LoanController) RespondToLoan(c *gin.Context) {
+	userid := c.GetString("userid")
+
+	var response struct {
+		Answer string `json:"answer"`
+	}
+
+	if err := c.ShouldBindJSON(&response); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	if response.Answer != "accept" && response.Answer != "decline" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid answer"})
+		return
+	}
+
+	loan, err := lc.LoanUsecase.RespondToLoan(requestContext(c), c.Param("loan_id"), c.Param("party_id"), response.Answer == "accept", userid)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Answer recorded", "loan": loan})
+}
+
 // ViewAllLoans function to handle the ViewAllLoans endpoint
 func (lc *LoanController) ViewAllLoans(c *gin.Context) {
 	pgnum, err := strconv.Atoi(c.Query("pgnum"))
@@ -66,7 +140,21 @@ func (lc *LoanController) ViewAllLoans(c *gin.Context) {
 	status := c.Query("status")
 	order := c.Query("order")
 
-	loans, _, err := lc.LoanUsecase.ViewAllLoans(context.Background(), pgnum, status, order)
+	dpd := domain.DPDRange{Min: 0, Max: -1}
+	if dpdMin := c.Query("dpd_min"); dpdMin != "" {
+		if dpd.Min, err = strconv.Atoi(dpdMin); err != nil || dpd.Min < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dpd_min parameter"})
+			return
+		}
+	}
+	if dpdMax := c.Query("dpd_max"); dpdMax != "" {
+		if dpd.Max, err = strconv.Atoi(dpdMax); err != nil || dpd.Max < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dpd_max parameter"})
+			return
+		}
+	}
+
+	loans, _, err := lc.LoanUsecase.ViewAllLoans(requestContext(c), pgnum, status, order, dpd)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -83,6 +171,7 @@ func (lc *LoanController) ApproveRejectLoan(c *gin.Context) {
 
 	var status struct {
 		Status string `json:"status"`
+		Reason string `json:"reason"`
 	}
 
 	if err := c.ShouldBindJSON(&status); err != nil {
@@ -95,7 +184,7 @@ func (lc *LoanController) ApproveRejectLoan(c *gin.Context) {
 		return
 	}
 
-	err := lc.LoanUsecase.ApproveRejectLoan(context.Background(), loanID, status.Status, userid)
+	err := lc.LoanUsecase.ApproveRejectLoan(requestContext(c), loanID, status.Status, status.Reason, userid)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -105,12 +194,242 @@ func (lc *LoanController) ApproveRejectLoan(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Loan status updated"})
 }
 
+// PayoffQuote function to handle the PayoffQuote endpoint
+func (lc *LoanController) PayoffQuote(c *gin.Context) {
+	userid := c.GetString("userid")
+	loanID := c.Param("loan_id")
+
+	date := time.Now()
+	if param := c.Query("date"); param != "" {
+		parsed, err := time.Parse("2006-01-02", param)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+			return
+		}
+		date = parsed
+	}
+
+	quote, err := lc.LoanUsecase.PayoffQuote(requestContext(c), loanID, date, userid)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quote": quote})
+}
+
+// MakePayment function to handle the MakePayment endpoint
+func (lc *LoanController) MakePayment(c *gin.Context) {
+	userid := c.GetString("userid")
+	loanID := c.Param("loan_id")
+
+	var payment struct {
+		Amount  float64 `json:"amount"`
+		QuoteID string  `json:"quote_id"`
+	}
+
+	if err := c.ShouldBindJSON(&payment); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	if payment.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment amount"})
+		return
+	}
+
+	receipt, err := lc.LoanUsecase.MakePayment(requestContext(c), loanID, payment.Amount, payment.QuoteID, userid)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Payment received", "payment": receipt})
+}
+
+// WaiveCharge function to handle the WaiveCharge endpoint
+func (lc *LoanController) WaiveCharge(c *gin.Context) {
+	userid := c.GetString("userid")
+	loanID := c.Param("loan_id")
+	chargeID := c.Param("charge_id")
+
+	var waiver struct {
+		Reason string `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&waiver); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	if strings.TrimSpace(waiver.Reason) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reason is required"})
+		return
+	}
+
+	err := lc.LoanUsecase.WaiveCharge(requestContext(c), loanID, chargeID, waiver.Reason, userid)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Charge waived"})
+}
+
+// RestructureLoan function to handle the RestructureLoan endpoint
+func (lc *LoanController) RestructureLoan(c *gin.Context) {
+	userid := c.GetString("userid")
+	loanID := c.Param("loan_id")
+
+	var terms domain.Restructure
+
+	if err := c.ShouldBindJSON(&terms); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	if strings.TrimSpace(terms.Reason) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reason is required"})
+		return
+	}
+
+	loan, err := lc.LoanUsecase.RestructureLoan(requestContext(c), loanID, terms, userid)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Loan restructured", "loan": loan})
+}
+
+// WriteOffLoan function to handle the WriteOffLoan endpoint
+func (lc *LoanController) WriteOffLoan(c *gin.Context) {
+	userid := c.GetString("userid")
+	loanID := c.Param("loan_id")
+
+	var writeOff struct {
+		Reason string `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&writeOff); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	if strings.TrimSpace(writeOff.Reason) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reason is required"})
+		return
+	}
+
+	err := lc.LoanUsecase.WriteOffLoan(requestContext(c), loanID, writeOff.Reason, userid)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Write-off awaiting approval"})
+}
+
+// DecideWriteOff function to handle the DecideWriteOff endpoint
+func (lc *LoanController) DecideWriteOff(c *gin.Context) {
+	userid := c.GetString("userid")
+	loanID := c.Param("loan_id")
+
+	var decision struct {
+		Decision string `json:"decision"`
+	}
+
+	if err := c.ShouldBindJSON(&decision); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	if decision.Decision != "approve" && decision.Decision != "decline" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid decision"})
+		return
+	}
+
+	err := lc.LoanUsecase.DecideWriteOff(requestContext(c), loanID, decision.Decision == "approve", userid)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	message := "Loan written off"
+	if decision.Decision == "decline" {
+		message = "Write-off declined"
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// PostRecovery function to handle the PostRecovery endpoint
+func (lc *LoanController) PostRecovery(c *gin.Context) {
+	userid := c.GetString("userid")
+	loanID := c.Param("loan_id")
+
+	var recovery struct {
+		Amount float64 `json:"amount"`
+		Note   string  `json:"note"`
+	}
+
+	if err := c.ShouldBindJSON(&recovery); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	if recovery.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recovery amount"})
+		return
+	}
+
+	posted, err := lc.LoanUsecase.PostRecovery(requestContext(c), loanID, recovery.Amount, recovery.Note, userid)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Recovery posted", "recovery": posted})
+}
+
+// WriteOffReport function to handle the WriteOffReport endpoint
+func (lc *LoanController) WriteOffReport(c *gin.Context) {
+	report, err := lc.LoanUsecase.WriteOffReport(requestContext(c))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+// AgingReport function to handle the AgingReport endpoint
+func (lc *LoanController) AgingReport(c *gin.Context) {
+	buckets, err := lc.LoanUsecase.AgingReport(requestContext(c))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"buckets": buckets})
+}
+
 // DeleteLoan function to handle the DeleteLoan endpoint
 func (lc *LoanController) DeleteLoan(c *gin.Context) {
 	userid := c.GetString("userid")
 	loanID := c.Param("loan_id")
 
-	err := lc.LoanUsecase.DeleteLoan(context.Background(), loanID, userid)
+	reason := c.Query("reason")
+
+	err := lc.LoanUsecase.DeleteLoan(requestContext(c), loanID, reason, userid)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -120,14 +439,17 @@ func (lc *LoanController) DeleteLoan(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Loan deleted"})
 }
 
-// ViewLogs function to handle the ViewLogs endpoint
-func (lc *LoanController) ViewLogs(c *gin.Context) {
-	logs, err := lc.LoanUsecase.ViewLogs(context.Background())
+// RestoreLoan function to handle the RestoreLoan endpoint
+func (lc *LoanController) RestoreLoan(c *gin.Context) {
+	userid := c.GetString("userid")
+	loanID := c.Param("loan_id")
+
+	err := lc.LoanUsecase.RestoreLoan(requestContext(c), loanID, userid)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"logs": logs})
+	c.JSON(http.StatusOK, gin.H{"message": "Loan restored"})
 }
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	gin "github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/mock"
@@ -54,6 +55,45 @@ func (suite *LoanControllerTestSuite) TestApplyForLoan() {
 	suite.Equal(http.StatusCreated, suite.Recorder.Code)
 }
 
+func (suite *LoanControllerTestSuite) TestApplyForLoanDropsSystemFields() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("ApplyForLoan", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+		return loan.Amount == 100000 && loan.Duration == 12 && loan.Product == "personal" &&
+			len(loan.Parties) == 1 && loan.Parties[0].Email == "guarantor@example.com" && loan.Parties[0].Status == "" &&
+			loan.Status == "" && loan.History == nil && loan.PayoffQuote == nil && loan.Rejection == nil && loan.WriteOff == nil &&
+			loan.Delinquency == "" && loan.DaysPastDue == 0 && loan.Schedule == nil && loan.Payments == nil && loan.Interest == 0
+	}), "testuserid").Return(nil).Once()
+
+	// Prepare the request body
+	requestBody := `{
+		"amount": 100000,
+		"duration": 12,
+		"product": "personal",
+		"parties": [{"role": "guarantor", "email": "guarantor@example.com", "status": "accepted"}],
+		"status": "approved",
+		"interest": 0.01,
+		"schedule_history": [{"version": 1, "reason": "Restructured by an admin"}],
+		"payoff_quote": {"total": 1},
+		"rejection": {"reason": "none"},
+		"write_off": {"amount": 100000},
+		"delinquency": "current",
+		"days_past_due": 0,
+		"payments": [{"amount": 100000}]
+	}`
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/loan/apply", strings.NewReader(requestBody))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+	suite.mockContext.Set("userid", "testuserid")
+
+	// Call the controller function
+	suite.controller.ApplyForLoan(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusCreated, suite.Recorder.Code)
+	suite.mockUsecase.AssertExpectations(suite.T())
+}
+
 func (suite *LoanControllerTestSuite) TestLoanDetails() {
 	// Define the expected loan data
 	expectedLoan := domain.Loan{
@@ -73,6 +113,53 @@ func (suite *LoanControllerTestSuite) TestLoanDetails() {
 	suite.Equal(http.StatusOK, suite.Recorder.Code)
 }
 
+func (suite *LoanControllerTestSuite) TestUserLoans() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("UserLoans", mock.Anything, "testuserid").Return([]domain.UserLoan{{Loan: domain.Loan{Amount: 1000}, Role: domain.PartyCoBorrower, LiabilityShare: 0.3}}, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/loan", nil)
+	suite.mockContext.Set("userid", "testuserid")
+
+	// Call the controller function
+	suite.controller.UserLoans(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), `"role":"co_borrower","liability_share":0.3`)
+}
+
+func (suite *LoanControllerTestSuite) TestRespondToLoan() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("RespondToLoan", mock.Anything, "testloanid", "testpartyid", false, "testuserid").Return(domain.Loan{Status: domain.LoanAwaitingAcceptance}, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/loan/testloanid/parties/testpartyid/respond", strings.NewReader(`{"answer": "decline"}`))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+	suite.mockContext.Params = gin.Params{{Key: "loan_id", Value: "testloanid"}, {Key: "party_id", Value: "testpartyid"}}
+	suite.mockContext.Set("userid", "testuserid")
+
+	// Call the controller function
+	suite.controller.RespondToLoan(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.mockUsecase.AssertExpectations(suite.T())
+}
+
+func (suite *LoanControllerTestSuite) TestRespondToLoanInvalidAnswer() {
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/loan/testloanid/parties/testpartyid/respond", strings.NewReader(`{"answer": "maybe"}`))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+
+	// Call the controller function
+	suite.controller.RespondToLoan(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusBadRequest, suite.Recorder.Code)
+	suite.mockUsecase.AssertNotCalled(suite.T(), "RespondToLoan", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func (suite *LoanControllerTestSuite) TestViewAllLoans() {
 	// Define the expected loans data
 	expectedLoans := []domain.Loan{
@@ -82,7 +169,7 @@ func (suite *LoanControllerTestSuite) TestViewAllLoans() {
 	}
 
 	// Set up the mock expectation
-	suite.mockUsecase.On("ViewAllLoans", mock.Anything, 1, "pending", "asc").Return(expectedLoans, 1, nil).Once()
+	suite.mockUsecase.On("ViewAllLoans", mock.Anything, 1, "pending", "asc", domain.DPDRange{Min: 0, Max: -1}).Return(expectedLoans, 1, nil).Once()
 
 	// Prepare the request
 	suite.mockContext.Request = httptest.NewRequest("GET", "/loans", nil)
@@ -95,9 +182,189 @@ func (suite *LoanControllerTestSuite) TestViewAllLoans() {
 	suite.Equal(http.StatusOK, suite.Recorder.Code)
 }
 
+func (suite *LoanControllerTestSuite) TestViewAllLoansAwaitingAcceptance() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("ViewAllLoans", mock.Anything, 1, domain.LoanAwaitingAcceptance, "", domain.DPDRange{Min: 0, Max: -1}).Return([]domain.Loan{}, 0, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/loans", nil)
+	suite.mockContext.Request.URL.RawQuery = "pgnum=1&status=awaiting_acceptance"
+
+	// Call the controller function
+	suite.controller.ViewAllLoans(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.mockUsecase.AssertExpectations(suite.T())
+}
+
+func (suite *LoanControllerTestSuite) TestViewAllLoansByDPD() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("ViewAllLoans", mock.Anything, 1, "", "", domain.DPDRange{Min: 31, Max: 60}).Return([]domain.Loan{}, 0, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/loans", nil)
+	suite.mockContext.Request.URL.RawQuery = "pgnum=1&dpd_min=31&dpd_max=60"
+
+	// Call the controller function
+	suite.controller.ViewAllLoans(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+}
+
+func (suite *LoanControllerTestSuite) TestViewAllLoansInvalidDPD() {
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/loans", nil)
+	suite.mockContext.Request.URL.RawQuery = "pgnum=1&dpd_min=abc"
+
+	// Call the controller function
+	suite.controller.ViewAllLoans(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusBadRequest, suite.Recorder.Code)
+}
+
+func (suite *LoanControllerTestSuite) TestRestructureLoan() {
+	// Set up the mock expectation
+	terms := domain.Restructure{ExtendMonths: 6, Reason: "job loss"}
+	suite.mockUsecase.On("RestructureLoan", mock.Anything, "testloanid", terms, "testuserid").Return(domain.Loan{Duration: 18}, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/loans/testloanid/restructure", strings.NewReader(`{"extend_months": 6, "reason": "job loss"}`))
+	suite.mockContext.Params = append(suite.mockContext.Params, gin.Param{Key: "loan_id", Value: "testloanid"})
+	suite.mockContext.Set("userid", "testuserid")
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+
+	// Call the controller function
+	suite.controller.RestructureLoan(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+}
+
+func (suite *LoanControllerTestSuite) TestRestructureLoanWithoutReason() {
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/loans/testloanid/restructure", strings.NewReader(`{"extend_months": 6}`))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+
+	// Call the controller function
+	suite.controller.RestructureLoan(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusBadRequest, suite.Recorder.Code)
+}
+
+func (suite *LoanControllerTestSuite) TestWriteOffLoan() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("WriteOffLoan", mock.Anything, "testloanid", "uncollectable", "testuserid").Return(nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/loans/testloanid/write-off", strings.NewReader(`{"reason": "uncollectable"}`))
+	suite.mockContext.Params = append(suite.mockContext.Params, gin.Param{Key: "loan_id", Value: "testloanid"})
+	suite.mockContext.Set("userid", "testuserid")
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+
+	// Call the controller function
+	suite.controller.WriteOffLoan(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusAccepted, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), "awaiting approval")
+}
+
+func (suite *LoanControllerTestSuite) TestWriteOffLoanWithoutReason() {
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/loans/testloanid/write-off", strings.NewReader(`{"reason": " "}`))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+
+	// Call the controller function
+	suite.controller.WriteOffLoan(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusBadRequest, suite.Recorder.Code)
+}
+
+func (suite *LoanControllerTestSuite) TestDecideWriteOff() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("DecideWriteOff", mock.Anything, "testloanid", true, "testapproverid").Return(nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/loans/testloanid/write-off/decision", strings.NewReader(`{"decision": "approve"}`))
+	suite.mockContext.Params = append(suite.mockContext.Params, gin.Param{Key: "loan_id", Value: "testloanid"})
+	suite.mockContext.Set("userid", "testapproverid")
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+
+	// Call the controller function
+	suite.controller.DecideWriteOff(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), "Loan written off")
+}
+
+func (suite *LoanControllerTestSuite) TestDecideWriteOffInvalidDecision() {
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/loans/testloanid/write-off/decision", strings.NewReader(`{"decision": "maybe"}`))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+
+	// Call the controller function
+	suite.controller.DecideWriteOff(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusBadRequest, suite.Recorder.Code)
+	suite.mockUsecase.AssertNotCalled(suite.T(), "DecideWriteOff", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *LoanControllerTestSuite) TestPostRecovery() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("PostRecovery", mock.Anything, "testloanid", 150.0, "collections", "testuserid").Return(domain.Recovery{Amount: 150}, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/loans/testloanid/recoveries", strings.NewReader(`{"amount": 150, "note": "collections"}`))
+	suite.mockContext.Params = append(suite.mockContext.Params, gin.Param{Key: "loan_id", Value: "testloanid"})
+	suite.mockContext.Set("userid", "testuserid")
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+
+	// Call the controller function
+	suite.controller.PostRecovery(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusCreated, suite.Recorder.Code)
+}
+
+func (suite *LoanControllerTestSuite) TestWriteOffReport() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("WriteOffReport", mock.Anything).Return(domain.WriteOffReport{Loans: 1, WrittenOff: 1000}, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/admin/reports/write-offs", nil)
+
+	// Call the controller function
+	suite.controller.WriteOffReport(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+}
+
+func (suite *LoanControllerTestSuite) TestAgingReport() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("AgingReport", mock.Anything).Return([]domain.AgingBucket{{Label: "1-30", Count: 2}}, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/admin/reports/aging", nil)
+
+	// Call the controller function
+	suite.controller.AgingReport(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), `"label":"1-30"`)
+}
+
 func (suite *LoanControllerTestSuite) TestApproveRejectLoan() {
 	// Set up the mock expectation
-	suite.mockUsecase.On("ApproveRejectLoan", mock.Anything, "testloanid", "approved", mock.Anything).Return(nil).Once()
+	suite.mockUsecase.On("ApproveRejectLoan", mock.Anything, "testloanid", "approved", "", mock.Anything).Return(nil).Once()
 
 	// Prepare the request
 	suite.mockContext.Request = httptest.NewRequest("PUT", "/loan/testloanid/approve", strings.NewReader(`{"status": "approved"}`))
@@ -112,12 +379,121 @@ func (suite *LoanControllerTestSuite) TestApproveRejectLoan() {
 	suite.Equal(http.StatusOK, suite.Recorder.Code)
 }
 
+func (suite *LoanControllerTestSuite) TestRejectLoanWithReason() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("ApproveRejectLoan", mock.Anything, "testloanid", "rejected", "Income could not be verified", "testadminid").Return(nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("PATCH", "/admin/loans/testloanid/status", strings.NewReader(`{"status": "rejected", "reason": "Income could not be verified"}`))
+	suite.mockContext.Params = append(suite.mockContext.Params, gin.Param{Key: "loan_id", Value: "testloanid"})
+	suite.mockContext.Set("userid", "testadminid")
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+
+	// Call the controller function
+	suite.controller.ApproveRejectLoan(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.mockUsecase.AssertExpectations(suite.T())
+}
+
+func (suite *LoanControllerTestSuite) TestPayoffQuote() {
+	// Set up the mock expectation
+	date := time.Date(2030, 1, 15, 0, 0, 0, 0, time.UTC)
+	suite.mockUsecase.On("PayoffQuote", mock.Anything, "testloanid", date, "testuserid").Return(domain.PayoffQuote{Total: 501.62}, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/loan/testloanid/payoff-quote?date=2030-01-15", nil)
+	suite.mockContext.Params = append(suite.mockContext.Params, gin.Param{Key: "loan_id", Value: "testloanid"})
+	suite.mockContext.Set("userid", "testuserid")
+
+	// Call the controller function
+	suite.controller.PayoffQuote(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), `"total":501.62`)
+}
+
+func (suite *LoanControllerTestSuite) TestPayoffQuoteInvalidDate() {
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/loan/testloanid/payoff-quote?date=15-01-2030", nil)
+
+	// Call the controller function
+	suite.controller.PayoffQuote(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusBadRequest, suite.Recorder.Code)
+}
+
+func (suite *LoanControllerTestSuite) TestMakePayment() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("MakePayment", mock.Anything, "testloanid", 250.0, "testquoteid", "testuserid").Return(domain.Payment{Amount: 250}, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/loan/testloanid/payments", strings.NewReader(`{"amount": 250, "quote_id": "testquoteid"}`))
+	suite.mockContext.Params = append(suite.mockContext.Params, gin.Param{Key: "loan_id", Value: "testloanid"})
+	suite.mockContext.Set("userid", "testuserid")
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+
+	// Call the controller function
+	suite.controller.MakePayment(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusCreated, suite.Recorder.Code)
+}
+
+func (suite *LoanControllerTestSuite) TestMakePaymentInvalidAmount() {
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/loan/testloanid/payments", strings.NewReader(`{"amount": -5}`))
+	suite.mockContext.Params = append(suite.mockContext.Params, gin.Param{Key: "loan_id", Value: "testloanid"})
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+
+	// Call the controller function
+	suite.controller.MakePayment(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusBadRequest, suite.Recorder.Code)
+	suite.mockUsecase.AssertNotCalled(suite.T(), "MakePayment", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *LoanControllerTestSuite) TestWaiveCharge() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("WaiveCharge", mock.Anything, "testloanid", "testchargeid", "hospitalized", "testuserid").Return(nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("PATCH", "/admin/loans/testloanid/charges/testchargeid/waive", strings.NewReader(`{"reason": "hospitalized"}`))
+	suite.mockContext.Params = append(suite.mockContext.Params,
+		gin.Param{Key: "loan_id", Value: "testloanid"},
+		gin.Param{Key: "charge_id", Value: "testchargeid"})
+	suite.mockContext.Set("userid", "testuserid")
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+
+	// Call the controller function
+	suite.controller.WaiveCharge(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+}
+
+func (suite *LoanControllerTestSuite) TestWaiveChargeWithoutReason() {
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("PATCH", "/admin/loans/testloanid/charges/testchargeid/waive", strings.NewReader(`{}`))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+
+	// Call the controller function
+	suite.controller.WaiveCharge(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusBadRequest, suite.Recorder.Code)
+}
+
 func (suite *LoanControllerTestSuite) TestDeleteLoan() {
 	// Set up the mock expectation
-	suite.mockUsecase.On("DeleteLoan", mock.Anything, "testloanid", mock.Anything).Return(nil).Once()
+	suite.mockUsecase.On("DeleteLoan", mock.Anything, "testloanid", "duplicate", mock.Anything).Return(nil).Once()
 
 	// Prepare the request
-	suite.mockContext.Request = httptest.NewRequest("DELETE", "/loan/testloanid", nil)
+	suite.mockContext.Request = httptest.NewRequest("DELETE", "/loan/testloanid?reason=duplicate", nil)
 	suite.mockContext.Params = append(suite.mockContext.Params, gin.Param{Key: "loan_id", Value: "testloanid"})
 	suite.mockContext.Set("userid", "testuserid")
 
@@ -128,22 +504,17 @@ func (suite *LoanControllerTestSuite) TestDeleteLoan() {
 	suite.Equal(http.StatusOK, suite.Recorder.Code)
 }
 
-func (suite *LoanControllerTestSuite) TestViewLogs() {
-	// Define the expected logs data
-	expectedLogs := []domain.Log{
-		{
-			ID: primitive.NewObjectID(),
-		},
-	}
-
+func (suite *LoanControllerTestSuite) TestRestoreLoan() {
 	// Set up the mock expectation
-	suite.mockUsecase.On("ViewLogs", mock.Anything).Return(expectedLogs, nil).Once()
+	suite.mockUsecase.On("RestoreLoan", mock.Anything, "testloanid", "testuserid").Return(nil).Once()
 
 	// Prepare the request
-	suite.mockContext.Request = httptest.NewRequest("GET", "/logs", nil)
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/loans/testloanid/restore", nil)
+	suite.mockContext.Params = append(suite.mockContext.Params, gin.Param{Key: "loan_id", Value: "testloanid"})
+	suite.mockContext.Set("userid", "testuserid")
 
 	// Call the controller function
-	suite.controller.ViewLogs(suite.mockContext)
+	suite.controller.RestoreLoan(suite.mockContext)
 
 	// Check the response
 	suite.Equal(http.StatusOK, suite.Recorder.Code)
@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"loan_tracker_api/domain"
+	"net/http"
+	"strconv"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// LogController struct to hold the usecase
+type LogController struct {
+	LogUsecase domain.LogUsecase
+}
+
+// NewLogController function to create a new LogController
+func NewLogController(luse domain.LogUsecase) *LogController {
+	return &LogController{
+		LogUsecase: luse,
+	}
+}
+
+// logFilter reads the audit log filter from the query string
+func logFilter(c *gin.Context) (domain.LogFilter, error) {
+	filter := domain.LogFilter{
+		ActorID:    c.Query("actor_id"),
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+	}
+
+	if page := c.Query("page"); page != "" {
+		pgnum, err := strconv.Atoi(page)
+		if err != nil || pgnum < 1 {
+			return filter, errors.New("Invalid page")
+		}
+		filter.Page = pgnum
+	}
+
+	// the time range is given as RFC 3339 timestamps, either end can be left open
+	bounds := []struct {
+		param string
+		value *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}}
+
+	for _, bound := range bounds {
+		value := c.Query(bound.param)
+		if value == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, errors.New("Invalid " + bound.param + " time, expected RFC 3339")
+		}
+		*bound.value = t
+	}
+
+	return filter, nil
+}
+
+// ViewLogs function to handle the ViewLogs endpoint
+func (lc *LogController) ViewLogs(c *gin.Context) {
+	filter, err := logFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	logs, err := lc.LogUsecase.ViewLogs(requestContext(c), filter)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"logs": logs})
+}
+
+// the columns of the CSV export
+var exportColumns = []string{
+	"id", "sequence", "created_at", "actor_id", "actor_role", "action", "target_type", "target_id",
+	"changes", "note", "request_id", "ip", "user_agent", "prev_hash", "hash",
+}
+
+// ExportLogs function to handle the ExportLogs endpoint, entries are streamed as they are read
+func (lc *LogController) ExportLogs(c *gin.Context) {
+	filter, err := logFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format := c.DefaultQuery("format", "ndjson")
+	if format != "ndjson" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, expected csv or ndjson"})
+		return
+	}
+
+	var write func(entry domain.Log) error
+	var flush func()
+
+	// nothing is sent before the first entry is read, so a rejected filter still gets a proper error
+	started := false
+	start := func() {
+		started = true
+
+		if format == "csv" {
+			c.Header("Content-Type", "text/csv")
+			c.Header("Content-Disposition", `attachment; filename="audit-log.csv"`)
+			c.Status(http.StatusOK)
+
+			writer := csv.NewWriter(c.Writer)
+			writer.Write(exportColumns)
+			write = func(entry domain.Log) error {
+				changes, _ := json.Marshal(entry.Changes)
+				return writer.Write([]string{
+					entry.ID.Hex(),
+					strconv.FormatInt(entry.Sequence, 10),
+					entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+					entry.ActorID.Hex(),
+					entry.ActorRole,
+					entry.Action,
+					entry.TargetType,
+					entry.TargetID.Hex(),
+					string(changes),
+					entry.Note,
+					entry.RequestID,
+					entry.IP,
+					entry.UserAgent,
+					entry.PrevHash,
+					entry.Hash,
+				})
+			}
+			flush = writer.Flush
+			return
+		}
+
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", `attachment; filename="audit-log.ndjson"`)
+		c.Status(http.StatusOK)
+
+		encoder := json.NewEncoder(c.Writer)
+		write = func(entry domain.Log) error {
+			return encoder.Encode(entry)
+		}
+		flush = func() {}
+	}
+
+	// push what has been written every so often so large exports reach the client as they go
+	written := 0
+	err = lc.LogUsecase.ExportLogs(requestContext(c), filter, func(entry domain.Log) error {
+		if !started {
+			start()
+		}
+
+		if err := write(entry); err != nil {
+			return err
+		}
+
+		written++
+		if written%500 == 0 {
+			flush()
+			c.Writer.Flush()
+		}
+		return nil
+	})
+
+	if err != nil && !started {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// the status is already sent, a failure can only cut the stream short
+	if err != nil {
+		flush()
+		c.Error(err)
+		return
+	}
+
+	if !started {
+		start()
+	}
+	flush()
+	c.Writer.Flush()
+}
+
+// VerifyLogs function to handle the VerifyLogs endpoint
+func (lc *LogController) VerifyLogs(c *gin.Context) {
+	verification, err := lc.LogUsecase.VerifyLogs(requestContext(c))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"verification": verification})
+}
@@ -0,0 +1,191 @@
+package controllers_test
+
+import (
+	"errors"
+	"loan_tracker_api/deliveries/controllers"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type LogControllerTestSuite struct {
+	suite.Suite
+	controller  *controllers.LogController
+	mockUsecase *mocks.LogUsecase
+	Recorder    *httptest.ResponseRecorder
+	mockContext *gin.Context
+}
+
+func (suite *LogControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.mockUsecase = new(mocks.LogUsecase)
+	suite.controller = controllers.NewLogController(suite.mockUsecase)
+	// Prepare the recorder and context
+	suite.Recorder = httptest.NewRecorder()
+	suite.mockContext, _ = gin.CreateTestContext(suite.Recorder)
+}
+
+func (suite *LogControllerTestSuite) TestViewLogs() {
+	// Define the expected logs data
+	expectedLogs := []domain.Log{
+		{
+			ID:     primitive.NewObjectID(),
+			Action: domain.ActionLoanApproved,
+		},
+	}
+
+	// Set up the mock expectation
+	suite.mockUsecase.On("ViewLogs", mock.Anything, domain.LogFilter{}).Return(expectedLogs, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/admin/logs", nil)
+
+	// Call the controller function
+	suite.controller.ViewLogs(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+}
+
+func (suite *LogControllerTestSuite) TestViewLogsWithFilters() {
+	// Set up the mock expectation
+	filter := domain.LogFilter{
+		ActorID:    "testactorid",
+		Action:     domain.ActionLoanApproved,
+		TargetType: domain.TargetLoan,
+		TargetID:   "testloanid",
+		From:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:         time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		Page:       2,
+	}
+	suite.mockUsecase.On("ViewLogs", mock.Anything, filter).Return([]domain.Log{}, nil).Once()
+
+	// Prepare the request
+	url := "/admin/logs?actor_id=testactorid&action=loan.approved&target_type=loan&target_id=testloanid" +
+		"&from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z&page=2"
+	suite.mockContext.Request = httptest.NewRequest("GET", url, nil)
+
+	// Call the controller function
+	suite.controller.ViewLogs(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.mockUsecase.AssertExpectations(suite.T())
+}
+
+func (suite *LogControllerTestSuite) TestViewLogsInvalidTime() {
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/admin/logs?from=yesterday", nil)
+
+	// Call the controller function
+	suite.controller.ViewLogs(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusBadRequest, suite.Recorder.Code)
+	suite.mockUsecase.AssertNotCalled(suite.T(), "ViewLogs", mock.Anything, mock.Anything)
+}
+
+func (suite *LogControllerTestSuite) TestVerifyLogs() {
+	// Set up the mock expectation
+	verification := domain.LogVerification{
+		Valid:      false,
+		Checked:    4,
+		BrokenLink: &domain.BrokenLink{Sequence: 5, Reason: "Entry content doesn't match its hash"},
+	}
+	suite.mockUsecase.On("VerifyLogs", mock.Anything).Return(verification, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/admin/logs/verify", nil)
+
+	// Call the controller function
+	suite.controller.VerifyLogs(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), `"sequence":5`)
+}
+
+func (suite *LogControllerTestSuite) TestExportLogsCSV() {
+	// Set up the mock expectation
+	entry := domain.Log{ID: primitive.NewObjectID(), Sequence: 7, Action: domain.ActionLoanPayment, Note: "first, second"}
+	suite.mockUsecase.On("ExportLogs", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		each := args.Get(2).(func(entry domain.Log) error)
+		each(entry)
+	}).Return(nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/admin/logs/export?format=csv&from=2024-01-01T00:00:00Z", nil)
+
+	// Call the controller function
+	suite.controller.ExportLogs(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.Equal("text/csv", suite.Recorder.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(suite.Recorder.Body.String()), "\n")
+	suite.Len(lines, 2)
+	suite.True(strings.HasPrefix(lines[0], "id,sequence,created_at"))
+	suite.Contains(lines[1], entry.ID.Hex()+",7,")
+	suite.Contains(lines[1], `"first, second"`)
+}
+
+func (suite *LogControllerTestSuite) TestExportLogsNDJSON() {
+	// Set up the mock expectation
+	entries := []domain.Log{{ID: primitive.NewObjectID(), Sequence: 1}, {ID: primitive.NewObjectID(), Sequence: 2}}
+	suite.mockUsecase.On("ExportLogs", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		each := args.Get(2).(func(entry domain.Log) error)
+		for _, entry := range entries {
+			each(entry)
+		}
+	}).Return(nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/admin/logs/export", nil)
+
+	// Call the controller function
+	suite.controller.ExportLogs(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.Equal("application/x-ndjson", suite.Recorder.Header().Get("Content-Type"))
+	suite.Len(strings.Split(strings.TrimSpace(suite.Recorder.Body.String()), "\n"), 2)
+}
+
+func (suite *LogControllerTestSuite) TestExportLogsRejectedFilter() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("ExportLogs", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("Invalid actor ID")).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/admin/logs/export?actor_id=nope", nil)
+
+	// Call the controller function
+	suite.controller.ExportLogs(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusInternalServerError, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), "Invalid actor ID")
+}
+
+func (suite *LogControllerTestSuite) TestExportLogsInvalidFormat() {
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/admin/logs/export?format=xml", nil)
+
+	// Call the controller function
+	suite.controller.ExportLogs(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusBadRequest, suite.Recorder.Code)
+}
+
+func TestLogControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(LogControllerTestSuite))
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"loan_tracker_api/domain"
+	"net/http"
+	"strconv"
+
+	gin "github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationController struct to hold the usecase
+type NotificationController struct {
+	NotificationUsecase domain.NotificationUsecase
+}
+
+// NewNotificationController function to create a new NotificationController
+func NewNotificationController(nuse domain.NotificationUsecase) *NotificationController {
+	return &NotificationController{
+		NotificationUsecase: nuse,
+	}
+}
+
+// Notifications function to handle the Notifications endpoint
+func (nc *NotificationController) Notifications(c *gin.Context) {
+	pgnum, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+
+	unreadOnly, err := strconv.ParseBool(c.DefaultQuery("unread", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unread filter"})
+		return
+	}
+
+	notifications, unread, err := nc.NotificationUsecase.Notifications(requestContext(c), c.GetString("userid"), unreadOnly, pgnum)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notifications": notifications, "unread": unread})
+}
+
+// MarkRead function to handle the MarkRead endpoint
+func (nc *NotificationController) MarkRead(c *gin.Context) {
+	if _, err := primitive.ObjectIDFromHex(c.Param("id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	err := nc.NotificationUsecase.MarkRead(requestContext(c), c.GetString("userid"), c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+// MarkAllRead function to handle the MarkAllRead endpoint
+func (nc *NotificationController) MarkAllRead(c *gin.Context) {
+	marked, err := nc.NotificationUsecase.MarkAllRead(requestContext(c), c.GetString("userid"))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notifications marked as read", "marked": marked})
+}
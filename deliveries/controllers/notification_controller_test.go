@@ -0,0 +1,111 @@
+package controllers_test
+
+import (
+	"errors"
+	"loan_tracker_api/deliveries/controllers"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type NotificationControllerTestSuite struct {
+	suite.Suite
+	controller  *controllers.NotificationController
+	mockUsecase *mocks.NotificationUsecase
+	Recorder    *httptest.ResponseRecorder
+	mockContext *gin.Context
+}
+
+func (suite *NotificationControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.mockUsecase = new(mocks.NotificationUsecase)
+	suite.controller = controllers.NewNotificationController(suite.mockUsecase)
+	// Prepare the recorder and context
+	suite.Recorder = httptest.NewRecorder()
+	suite.mockContext, _ = gin.CreateTestContext(suite.Recorder)
+	suite.mockContext.Set("userid", "testuserid")
+}
+
+func (suite *NotificationControllerTestSuite) TestNotificationsUnread() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("Notifications", mock.Anything, "testuserid", true, 2).Return([]domain.Notification{}, int64(4), nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/user/notifications?page=2&unread=true", nil)
+
+	// Call the controller function
+	suite.controller.Notifications(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), `"unread":4`)
+	suite.mockUsecase.AssertExpectations(suite.T())
+}
+
+func (suite *NotificationControllerTestSuite) TestNotificationsInvalidFilter() {
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/user/notifications?unread=maybe", nil)
+
+	// Call the controller function
+	suite.controller.Notifications(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusBadRequest, suite.Recorder.Code)
+	suite.mockUsecase.AssertNotCalled(suite.T(), "Notifications", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *NotificationControllerTestSuite) TestMarkRead() {
+	id := primitive.NewObjectID().Hex()
+
+	// Set up the mock expectation
+	suite.mockUsecase.On("MarkRead", mock.Anything, "testuserid", id).Return(nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/user/notifications/"+id+"/read", nil)
+	suite.mockContext.Params = gin.Params{{Key: "id", Value: id}}
+
+	// Call the controller function
+	suite.controller.MarkRead(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.mockUsecase.AssertExpectations(suite.T())
+}
+
+func (suite *NotificationControllerTestSuite) TestMarkReadInvalidID() {
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/user/notifications/invalid/read", nil)
+	suite.mockContext.Params = gin.Params{{Key: "id", Value: "invalid"}}
+
+	// Call the controller function
+	suite.controller.MarkRead(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusBadRequest, suite.Recorder.Code)
+	suite.mockUsecase.AssertNotCalled(suite.T(), "MarkRead", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *NotificationControllerTestSuite) TestMarkAllReadError() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("MarkAllRead", mock.Anything, "testuserid").Return(int64(0), errors.New("Error updating notifications")).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/user/notifications/read-all", nil)
+
+	// Call the controller function
+	suite.controller.MarkAllRead(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusInternalServerError, suite.Recorder.Code)
+}
+
+func TestNotificationControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(NotificationControllerTestSuite))
+}
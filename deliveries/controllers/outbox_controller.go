@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"loan_tracker_api/domain"
+	"net/http"
+	"strconv"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// OutboxController struct to hold the usecase
+type OutboxController struct {
+	OutboxUsecase domain.OutboxUsecase
+}
+
+// NewOutboxController function to create a new OutboxController
+func NewOutboxController(ouse domain.OutboxUsecase) *OutboxController {
+	return &OutboxController{
+		OutboxUsecase: ouse,
+	}
+}
+
+// DeadMessages function to handle the DeadMessages endpoint
+func (oc *OutboxController) DeadMessages(c *gin.Context) {
+	pgnum, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || pgnum < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+
+	messages, err := oc.OutboxUsecase.DeadMessages(requestContext(c), pgnum)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"messages": messages})
+}
+
+// RetryMessage function to handle the RetryMessage endpoint
+func (oc *OutboxController) RetryMessage(c *gin.Context) {
+	err := oc.OutboxUsecase.RetryMessage(requestContext(c), c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Message queued for delivery"})
+}
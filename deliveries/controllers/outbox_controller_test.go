@@ -0,0 +1,92 @@
+package controllers_test
+
+import (
+	"errors"
+	"loan_tracker_api/deliveries/controllers"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type OutboxControllerTestSuite struct {
+	suite.Suite
+	controller  *controllers.OutboxController
+	mockUsecase *mocks.OutboxUsecase
+	Recorder    *httptest.ResponseRecorder
+	mockContext *gin.Context
+}
+
+func (suite *OutboxControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.mockUsecase = new(mocks.OutboxUsecase)
+	suite.controller = controllers.NewOutboxController(suite.mockUsecase)
+	// Prepare the recorder and context
+	suite.Recorder = httptest.NewRecorder()
+	suite.mockContext, _ = gin.CreateTestContext(suite.Recorder)
+}
+
+func (suite *OutboxControllerTestSuite) TestDeadMessages() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("DeadMessages", mock.Anything, 2).Return([]domain.OutboxMessage{}, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/admin/outbox/dead?page=2", nil)
+
+	// Call the controller function
+	suite.controller.DeadMessages(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+}
+
+func (suite *OutboxControllerTestSuite) TestDeadMessagesInvalidPage() {
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/admin/outbox/dead?page=zero", nil)
+
+	// Call the controller function
+	suite.controller.DeadMessages(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusBadRequest, suite.Recorder.Code)
+	suite.mockUsecase.AssertNotCalled(suite.T(), "DeadMessages", mock.Anything, mock.Anything)
+}
+
+func (suite *OutboxControllerTestSuite) TestRetryMessage() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("RetryMessage", mock.Anything, "testmessageid").Return(nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/outbox/testmessageid/retry", nil)
+	suite.mockContext.Params = gin.Params{{Key: "id", Value: "testmessageid"}}
+
+	// Call the controller function
+	suite.controller.RetryMessage(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+}
+
+func (suite *OutboxControllerTestSuite) TestRetryMessageError() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("RetryMessage", mock.Anything, "testmessageid").Return(errors.New("Only dead messages can be retried")).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/outbox/testmessageid/retry", nil)
+	suite.mockContext.Params = gin.Params{{Key: "id", Value: "testmessageid"}}
+
+	// Call the controller function
+	suite.controller.RetryMessage(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusInternalServerError, suite.Recorder.Code)
+}
+
+func TestOutboxControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(OutboxControllerTestSuite))
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"loan_tracker_api/domain"
+	"net/http"
+	"strconv"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// QueueController struct to hold the usecase
+type QueueController struct {
+	JobQueueUsecase domain.JobQueueUsecase
+}
+
+// NewQueueController function to create a new QueueController
+func NewQueueController(quse domain.JobQueueUsecase) *QueueController {
+	return &QueueController{
+		JobQueueUsecase: quse,
+	}
+}
+
+// ListJobs function to handle the ListJobs endpoint
+func (qc *QueueController) ListJobs(c *gin.Context) {
+	pgnum, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || pgnum < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", domain.QueuePending, domain.QueueActive, domain.QueueCompleted, domain.QueueFailed, domain.QueueCancelled:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+
+	jobs, err := qc.JobQueueUsecase.ListJobs(requestContext(c), domain.JobFilter{Status: status, Type: c.Query("type"), Page: pgnum})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs})
+}
+
+// GetJob function to handle the GetJob endpoint
+func (qc *QueueController) GetJob(c *gin.Context) {
+	job, err := qc.JobQueueUsecase.GetJob(requestContext(c), c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"job": job})
+}
+
+// RetryJob function to handle the RetryJob endpoint
+func (qc *QueueController) RetryJob(c *gin.Context) {
+	err := qc.JobQueueUsecase.RetryJob(requestContext(c), c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Job queued again"})
+}
+
+// CancelJob function to handle the CancelJob endpoint
+func (qc *QueueController) CancelJob(c *gin.Context) {
+	err := qc.JobQueueUsecase.CancelJob(requestContext(c), c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Job cancelled"})
+}
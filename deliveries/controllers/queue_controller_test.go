@@ -0,0 +1,114 @@
+package controllers_test
+
+import (
+	"errors"
+	"loan_tracker_api/deliveries/controllers"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type QueueControllerTestSuite struct {
+	suite.Suite
+	controller  *controllers.QueueController
+	mockUsecase *mocks.JobQueueUsecase
+	Recorder    *httptest.ResponseRecorder
+	mockContext *gin.Context
+}
+
+func (suite *QueueControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.mockUsecase = new(mocks.JobQueueUsecase)
+	suite.controller = controllers.NewQueueController(suite.mockUsecase)
+	// Prepare the recorder and context
+	suite.Recorder = httptest.NewRecorder()
+	suite.mockContext, _ = gin.CreateTestContext(suite.Recorder)
+}
+
+func (suite *QueueControllerTestSuite) TestListJobs() {
+	// Set up the mock expectation
+	filter := domain.JobFilter{Status: domain.QueueFailed, Type: "loan_purge", Page: 2}
+	suite.mockUsecase.On("ListJobs", mock.Anything, filter).Return([]domain.QueuedJob{{Type: "loan_purge", Status: domain.QueueFailed, LastError: "Error purging loans"}}, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/admin/queue/jobs?status=failed&type=loan_purge&page=2", nil)
+
+	// Call the controller function
+	suite.controller.ListJobs(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), `"last_error":"Error purging loans"`)
+	suite.mockUsecase.AssertExpectations(suite.T())
+}
+
+func (suite *QueueControllerTestSuite) TestListJobsInvalidStatus() {
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/admin/queue/jobs?status=stuck", nil)
+
+	// Call the controller function
+	suite.controller.ListJobs(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusBadRequest, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), "Invalid status")
+	suite.mockUsecase.AssertNotCalled(suite.T(), "ListJobs", mock.Anything, mock.Anything)
+}
+
+func (suite *QueueControllerTestSuite) TestGetJob() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("GetJob", mock.Anything, "testjobid").Return(domain.QueuedJob{Type: "export", Failures: []domain.JobFailure{{Attempt: 1, Error: "Storage unavailable"}}}, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/admin/queue/jobs/testjobid", nil)
+	suite.mockContext.Params = gin.Params{{Key: "id", Value: "testjobid"}}
+
+	// Call the controller function
+	suite.controller.GetJob(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), `"error":"Storage unavailable"`)
+}
+
+func (suite *QueueControllerTestSuite) TestRetryJob() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("RetryJob", mock.Anything, "testjobid").Return(nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/queue/jobs/testjobid/retry", nil)
+	suite.mockContext.Params = gin.Params{{Key: "id", Value: "testjobid"}}
+
+	// Call the controller function
+	suite.controller.RetryJob(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.mockUsecase.AssertExpectations(suite.T())
+}
+
+func (suite *QueueControllerTestSuite) TestCancelJobError() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("CancelJob", mock.Anything, "testjobid").Return(errors.New("Only pending or failed jobs can be cancelled")).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/queue/jobs/testjobid/cancel", nil)
+	suite.mockContext.Params = gin.Params{{Key: "id", Value: "testjobid"}}
+
+	// Call the controller function
+	suite.controller.CancelJob(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusInternalServerError, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), "Only pending or failed jobs can be cancelled")
+}
+
+func TestQueueControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(QueueControllerTestSuite))
+}
@@ -0,0 +1,18 @@
+package controllers
+
+import (
+	"context"
+	"loan_tracker_api/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestContext returns the context handed to usecases, carrying who sent the request and where it came from
+func requestContext(c *gin.Context) context.Context {
+	return domain.WithRequestMeta(c.Request.Context(), domain.RequestMeta{
+		RequestID: c.GetString("requestid"),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		IsAdmin:   c.GetBool("isadmin"),
+	})
+}
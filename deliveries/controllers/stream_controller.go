@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"fmt"
+	"loan_tracker_api/domain"
+	"net/http"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StreamController struct to hold the event stream and how often idle streams are kept alive
+type StreamController struct {
+	EventStream domain.EventStream
+	Heartbeat   time.Duration
+}
+
+// NewStreamController function to create a new StreamController
+func NewStreamController(stream domain.EventStream, heartbeat time.Duration) *StreamController {
+	return &StreamController{
+		EventStream: stream,
+		Heartbeat:   heartbeat,
+	}
+}
+
+// UserEvents function to handle the UserEvents endpoint
+func (sc *StreamController) UserEvents(c *gin.Context) {
+	sc.stream(c, false)
+}
+
+// AdminEvents function to handle the AdminEvents endpoint
+func (sc *StreamController) AdminEvents(c *gin.Context) {
+	sc.stream(c, true)
+}
+
+// stream sends the events of the user, or of every user, as server-sent events until the client goes away
+func (sc *StreamController) stream(c *gin.Context, all bool) {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID != "" {
+		if _, err := primitive.ObjectIDFromHex(lastEventID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Last-Event-ID"})
+			return
+		}
+	}
+
+	subscription, err := sc.EventStream.Subscribe(requestContext(c), c.GetString("userid"), all, lastEventID)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer subscription.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// proxies must not hold events back
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	for _, event := range subscription.Replay {
+		writeStreamEvent(c, event)
+	}
+	// the client missed more events than were replayed and has to reload what it shows rather than rely on the stream.
+	// The event has no ID so it doesn't move where the client resumes from.
+	if subscription.Truncated {
+		fmt.Fprintf(c.Writer, "event: %s\ndata: {\"replayed\":%d}\n\n", domain.StreamTruncated, len(subscription.Replay))
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(sc.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-subscription.Events:
+			// the client fell behind, it reconnects and resumes from the last event it received
+			if !ok {
+				return
+			}
+			writeStreamEvent(c, event)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+// writeStreamEvent writes an event in the server-sent events format, its data is a single line of JSON
+func writeStreamEvent(c *gin.Context, event domain.StreamEvent) {
+	fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", event.ID.Hex(), event.Name, event.Data)
+}
@@ -0,0 +1,130 @@
+package controllers_test
+
+import (
+	"context"
+	"loan_tracker_api/deliveries/controllers"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type StreamControllerTestSuite struct {
+	suite.Suite
+	controller  *controllers.StreamController
+	mockStream  *mocks.EventStream
+	Recorder    *httptest.ResponseRecorder
+	mockContext *gin.Context
+}
+
+func (suite *StreamControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.mockStream = new(mocks.EventStream)
+	suite.controller = controllers.NewStreamController(suite.mockStream, 10*time.Millisecond)
+	// Prepare the recorder and context
+	suite.Recorder = httptest.NewRecorder()
+	suite.mockContext, _ = gin.CreateTestContext(suite.Recorder)
+	suite.mockContext.Set("userid", "testuserid")
+}
+
+func (suite *StreamControllerTestSuite) TestUserEventsResumes() {
+	last := primitive.NewObjectID().Hex()
+	missed := domain.StreamEvent{ID: primitive.NewObjectID(), Name: domain.EventLoanApproved, Data: `{"loan_id":"1"}`}
+	live := domain.StreamEvent{ID: primitive.NewObjectID(), Name: domain.EventPaymentReceived, Data: `{"amount":250}`}
+	events := make(chan domain.StreamEvent, 1)
+	events <- live
+	close(events)
+
+	// Set up the mock expectation
+	suite.mockStream.On("Subscribe", mock.Anything, "testuserid", false, last).Return(&domain.StreamSubscription{
+		Replay: []domain.StreamEvent{missed},
+		Events: events,
+		Close:  func() {},
+	}, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/user/events", nil)
+	suite.mockContext.Request.Header.Set("Last-Event-ID", last)
+
+	// Call the controller function
+	suite.controller.UserEvents(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.Equal("text/event-stream", suite.Recorder.Header().Get("Content-Type"))
+	suite.Equal("id: "+missed.ID.Hex()+"\nevent: loan.approved\ndata: {\"loan_id\":\"1\"}\n\n"+
+		"id: "+live.ID.Hex()+"\nevent: loan.payment_received\ndata: {\"amount\":250}\n\n", suite.Recorder.Body.String())
+}
+
+func (suite *StreamControllerTestSuite) TestUserEventsReplayTruncated() {
+	last := primitive.NewObjectID().Hex()
+	missed := domain.StreamEvent{ID: primitive.NewObjectID(), Name: domain.EventLoanApproved, Data: `{"loan_id":"1"}`}
+	events := make(chan domain.StreamEvent)
+	close(events)
+
+	// Set up the mock expectation
+	suite.mockStream.On("Subscribe", mock.Anything, "testuserid", false, last).Return(&domain.StreamSubscription{
+		Replay:    []domain.StreamEvent{missed},
+		Truncated: true,
+		Events:    events,
+		Close:     func() {},
+	}, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/user/events", nil)
+	suite.mockContext.Request.Header.Set("Last-Event-ID", last)
+
+	// Call the controller function
+	suite.controller.UserEvents(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.Equal("id: "+missed.ID.Hex()+"\nevent: loan.approved\ndata: {\"loan_id\":\"1\"}\n\n"+
+		"event: stream.truncated\ndata: {\"replayed\":1}\n\n", suite.Recorder.Body.String())
+}
+
+func (suite *StreamControllerTestSuite) TestAdminEventsHeartbeat() {
+	closed := false
+
+	// Set up the mock expectation
+	suite.mockStream.On("Subscribe", mock.Anything, "testuserid", true, "").Return(&domain.StreamSubscription{
+		Events: make(chan domain.StreamEvent),
+		Close:  func() { closed = true },
+	}, nil).Once()
+
+	// Prepare the request, the client goes away after a few heartbeats
+	ctx, cancel := context.WithTimeout(context.Background(), 35*time.Millisecond)
+	defer cancel()
+	suite.mockContext.Request = httptest.NewRequest("GET", "/admin/events", nil).WithContext(ctx)
+
+	// Call the controller function
+	suite.controller.AdminEvents(suite.mockContext)
+
+	// Check the response
+	suite.Contains(suite.Recorder.Body.String(), ": heartbeat\n\n")
+	suite.True(closed)
+}
+
+func (suite *StreamControllerTestSuite) TestUserEventsInvalidLastEventID() {
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("GET", "/user/events", nil)
+	suite.mockContext.Request.Header.Set("Last-Event-ID", "invalid")
+
+	// Call the controller function
+	suite.controller.UserEvents(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusBadRequest, suite.Recorder.Code)
+	suite.mockStream.AssertNotCalled(suite.T(), "Subscribe", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStreamControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(StreamControllerTestSuite))
+}
@@ -49,7 +49,7 @@ func (uc *UserController) RegisterUser(c *gin.Context) {
 	}
 	user.JoinedAt = time.Now()
 	user.IsAdmin = false
-	erro := uc.Userusecase.RegisterUser(c, &user)
+	erro := uc.Userusecase.RegisterUser(requestContext(c), &user)
 	if erro != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": erro.Error()})
 		return
@@ -66,7 +66,7 @@ func (uc *UserController) VerifyEmail(c *gin.Context) {
 		return
 	}
 
-	err := uc.Userusecase.VerifyUserEmail(c, token)
+	err := uc.Userusecase.VerifyUserEmail(requestContext(c), token)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -93,7 +93,7 @@ func (uc *UserController) LoginUser(c *gin.Context) {
 		c.JSON(400, gin.H{"error": "Invalid email address"})
 		return
 	}
-	refresh_token, access_token, erro := uc.Userusecase.LoginUser(c, user)
+	refresh_token, access_token, erro := uc.Userusecase.LoginUser(requestContext(c), user)
 	if erro != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": erro.Error()})
 		return
@@ -105,7 +105,7 @@ func (uc *UserController) LoginUser(c *gin.Context) {
 // TokenRefresh is a controller method to refresh a user's token
 func (uc *UserController) TokenRefresh(c *gin.Context) {
 	refreshToken := c.Query("refresh-token")
-	token, err := uc.Userusecase.TokenRefresh(c, refreshToken)
+	token, err := uc.Userusecase.TokenRefresh(requestContext(c), refreshToken)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -120,7 +120,7 @@ func (uc *UserController) UserProfile(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "User ID is required"})
 		return
 	}
-	user, err := uc.Userusecase.UserProfile(c, uid)
+	user, err := uc.Userusecase.UserProfile(requestContext(c), uid)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -143,7 +143,7 @@ func (uc *UserController) ForgotPassword(c *gin.Context) {
 		return
 	}
 
-	err := uc.Userusecase.ForgotPassword(c, info.Email)
+	err := uc.Userusecase.ForgotPassword(requestContext(c), info.Email)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -170,7 +170,7 @@ func (uc *UserController) ResetPassword(c *gin.Context) {
 		return
 	}
 
-	err := uc.Userusecase.ResetPassword(c, token, info.NewPassword)
+	err := uc.Userusecase.ResetPassword(requestContext(c), token, info.NewPassword)
 	if err != nil {
 		fmt.Printf("Error resetting password: %v\n", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -191,7 +191,7 @@ func (uc *UserController) UpdateUserDetails(c *gin.Context) {
 	}
 	user.ID = userID
 
-	erro := uc.Userusecase.UpdateUserDetails(c, &user)
+	erro := uc.Userusecase.UpdateUserDetails(requestContext(c), &user)
 	if erro != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": erro.Error()})
 		return
@@ -203,7 +203,7 @@ func (uc *UserController) UpdateUserDetails(c *gin.Context) {
 // LogoutUser is a controller method to logout a user
 func (uc *UserController) LogoutUser(c *gin.Context) {
 	uid := c.GetString("userid")
-	err := uc.Userusecase.LogoutUser(c, uid)
+	err := uc.Userusecase.LogoutUser(requestContext(c), uid)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -213,7 +213,7 @@ func (uc *UserController) LogoutUser(c *gin.Context) {
 
 // ViewAllUsers is a controller method to view all users
 func (uc *UserController) ViewAllUsers(c *gin.Context) {
-	users, err := uc.Userusecase.ViewAllUsers(c)
+	users, err := uc.Userusecase.ViewAllUsers(requestContext(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -224,10 +224,22 @@ func (uc *UserController) ViewAllUsers(c *gin.Context) {
 // DeleteUser is a controller method to delete a user
 func (uc *UserController) DeleteUser(c *gin.Context) {
 	uid := c.Param("id")
-	err := uc.Userusecase.DeleteUser(c, uid)
+	reason := c.Query("reason")
+	err := uc.Userusecase.DeleteUser(requestContext(c), uid, reason, c.GetString("userid"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
 }
+
+// RestoreUser is a controller method to restore a deleted user
+func (uc *UserController) RestoreUser(c *gin.Context) {
+	uid := c.Param("id")
+	err := uc.Userusecase.RestoreUser(requestContext(c), uid, c.GetString("userid"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "User restored successfully"})
+}
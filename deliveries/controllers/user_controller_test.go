@@ -201,7 +201,7 @@ func (suite *UserControllerTestSuite) TestViewAllUsers() {
 }
 
 func (suite *UserControllerTestSuite) TestDeleteUser() {
-	suite.mockUsecase.On("DeleteUser", mock.Anything, mock.Anything).Return(nil).Once()
+	suite.mockUsecase.On("DeleteUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
 
 	// Prepare the request
 	suite.mockContext.Request = httptest.NewRequest("DELETE", "/admin/user/test-user-id", nil)
@@ -214,6 +214,22 @@ func (suite *UserControllerTestSuite) TestDeleteUser() {
 	suite.Contains(suite.Recorder.Body.String(), "{\"message\":\"User deleted successfully\"}")
 }
 
+func (suite *UserControllerTestSuite) TestRestoreUser() {
+	suite.mockUsecase.On("RestoreUser", mock.Anything, "test-user-id", "test-admin-id").Return(nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/user/test-user-id/restore", nil)
+	suite.mockContext.Params = append(suite.mockContext.Params, gin.Param{Key: "id", Value: "test-user-id"})
+	suite.mockContext.Set("userid", "test-admin-id")
+
+	// Call the controller method
+	suite.controller.RestoreUser(suite.mockContext)
+
+	// Check the response
+	suite.Equal(200, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), "{\"message\":\"User restored successfully\"}")
+}
+
 func TestUserControllerTestSuite(t *testing.T) {
 	suite.Run(t, new(UserControllerTestSuite))
 }
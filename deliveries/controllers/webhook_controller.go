@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"loan_tracker_api/domain"
+	"net/http"
+	"strconv"
+
+	gin "github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WebhookController struct to hold the usecase
+type WebhookController struct {
+	WebhookUsecase domain.WebhookUsecase
+}
+
+// NewWebhookController function to create a new WebhookController
+func NewWebhookController(wuse domain.WebhookUsecase) *WebhookController {
+	return &WebhookController{
+		WebhookUsecase: wuse,
+	}
+}
+
+// webhookRequest is the body of the create and update webhook endpoints, webhooks are active unless told otherwise
+type webhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events" binding:"required"`
+	Secret string   `json:"secret"`
+	Active *bool    `json:"active"`
+}
+
+func (request webhookRequest) webhook() domain.Webhook {
+	webhook := domain.Webhook{
+		URL:    request.URL,
+		Events: request.Events,
+		Secret: request.Secret,
+		Active: true,
+	}
+	if request.Active != nil {
+		webhook.Active = *request.Active
+	}
+	return webhook
+}
+
+// CreateWebhook function to handle the CreateWebhook endpoint
+func (wc *WebhookController) CreateWebhook(c *gin.Context) {
+	var request webhookRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook := request.webhook()
+	webhook.CreatedBy, _ = primitive.ObjectIDFromHex(c.GetString("userid"))
+
+	err := wc.WebhookUsecase.CreateWebhook(requestContext(c), &webhook)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Webhook created", "webhook": webhook})
+}
+
+// ListWebhooks function to handle the ListWebhooks endpoint
+func (wc *WebhookController) ListWebhooks(c *gin.Context) {
+	webhooks, err := wc.WebhookUsecase.ListWebhooks(requestContext(c))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": webhooks})
+}
+
+// UpdateWebhook function to handle the UpdateWebhook endpoint
+func (wc *WebhookController) UpdateWebhook(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	var request webhookRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook := request.webhook()
+	webhook.ID = id
+
+	err = wc.WebhookUsecase.UpdateWebhook(requestContext(c), &webhook)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook updated", "webhook": webhook})
+}
+
+// DeleteWebhook function to handle the DeleteWebhook endpoint
+func (wc *WebhookController) DeleteWebhook(c *gin.Context) {
+	err := wc.WebhookUsecase.DeleteWebhook(requestContext(c), c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted"})
+}
+
+// TestWebhook function to handle the TestWebhook endpoint
+func (wc *WebhookController) TestWebhook(c *gin.Context) {
+	delivery, err := wc.WebhookUsecase.TestWebhook(requestContext(c), c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"delivery": delivery})
+}
+
+// Deliveries function to handle the Deliveries endpoint
+func (wc *WebhookController) Deliveries(c *gin.Context) {
+	pgnum, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || pgnum < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+
+	deliveries, err := wc.WebhookUsecase.Deliveries(requestContext(c), c.Param("id"), pgnum)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// Redeliver function to handle the Redeliver endpoint
+func (wc *WebhookController) Redeliver(c *gin.Context) {
+	err := wc.WebhookUsecase.Redeliver(requestContext(c), c.Param("id"), c.Param("delivery_id"))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Delivery queued for redelivery"})
+}
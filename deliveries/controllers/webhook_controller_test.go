@@ -0,0 +1,143 @@
+package controllers_test
+
+import (
+	"loan_tracker_api/deliveries/controllers"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gin "github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type WebhookControllerTestSuite struct {
+	suite.Suite
+	controller  *controllers.WebhookController
+	mockUsecase *mocks.WebhookUsecase
+	Recorder    *httptest.ResponseRecorder
+	mockContext *gin.Context
+}
+
+func (suite *WebhookControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.mockUsecase = new(mocks.WebhookUsecase)
+	suite.controller = controllers.NewWebhookController(suite.mockUsecase)
+	// Prepare the recorder and context
+	suite.Recorder = httptest.NewRecorder()
+	suite.mockContext, _ = gin.CreateTestContext(suite.Recorder)
+}
+
+func (suite *WebhookControllerTestSuite) TestCreateWebhook() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("CreateWebhook", mock.Anything, mock.MatchedBy(func(w *domain.Webhook) bool {
+		return w.URL == "https://example.com/hooks" && w.Active && len(w.Events) == 1
+	})).Return(nil).Once()
+
+	// Prepare the request
+	body := `{"url": "https://example.com/hooks", "events": ["loan.approved"]}`
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/webhooks", strings.NewReader(body))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+
+	// Call the controller function
+	suite.controller.CreateWebhook(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusCreated, suite.Recorder.Code)
+	suite.mockUsecase.AssertExpectations(suite.T())
+}
+
+func (suite *WebhookControllerTestSuite) TestCreatePausedWebhook() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("CreateWebhook", mock.Anything, mock.MatchedBy(func(w *domain.Webhook) bool {
+		return w.URL == "https://example.com/hooks" && !w.Active
+	})).Return(nil).Once()
+
+	// Prepare the request
+	body := `{"url": "https://example.com/hooks", "events": ["loan.approved"], "active": false}`
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/webhooks", strings.NewReader(body))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+
+	// Call the controller function
+	suite.controller.CreateWebhook(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusCreated, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), `"active":false`)
+	suite.mockUsecase.AssertExpectations(suite.T())
+}
+
+func (suite *WebhookControllerTestSuite) TestCreateWebhookMissingURL() {
+	// Prepare the request
+	body := `{"events": ["loan.approved"]}`
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/webhooks", strings.NewReader(body))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+
+	// Call the controller function
+	suite.controller.CreateWebhook(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusUnprocessableEntity, suite.Recorder.Code)
+	suite.mockUsecase.AssertNotCalled(suite.T(), "CreateWebhook", mock.Anything, mock.Anything)
+}
+
+func (suite *WebhookControllerTestSuite) TestUpdateWebhookPause() {
+	id := primitive.NewObjectID()
+
+	// Set up the mock expectation
+	suite.mockUsecase.On("UpdateWebhook", mock.Anything, mock.MatchedBy(func(w *domain.Webhook) bool {
+		return w.ID == id && !w.Active
+	})).Return(nil).Once()
+
+	// Prepare the request
+	body := `{"url": "https://example.com/hooks", "events": ["loan.approved"], "active": false}`
+	suite.mockContext.Request = httptest.NewRequest("PUT", "/admin/webhooks/"+id.Hex(), strings.NewReader(body))
+	suite.mockContext.Request.Header.Set("Content-Type", "application/json")
+	suite.mockContext.Params = gin.Params{{Key: "id", Value: id.Hex()}}
+
+	// Call the controller function
+	suite.controller.UpdateWebhook(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.mockUsecase.AssertExpectations(suite.T())
+}
+
+func (suite *WebhookControllerTestSuite) TestTestWebhook() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("TestWebhook", mock.Anything, "testwebhookid").Return(domain.WebhookDelivery{Status: domain.DeliveryDelivered}, nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/webhooks/testwebhookid/test", nil)
+	suite.mockContext.Params = gin.Params{{Key: "id", Value: "testwebhookid"}}
+
+	// Call the controller function
+	suite.controller.TestWebhook(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+	suite.Contains(suite.Recorder.Body.String(), `"status":"delivered"`)
+}
+
+func (suite *WebhookControllerTestSuite) TestRedeliver() {
+	// Set up the mock expectation
+	suite.mockUsecase.On("Redeliver", mock.Anything, "testwebhookid", "testdeliveryid").Return(nil).Once()
+
+	// Prepare the request
+	suite.mockContext.Request = httptest.NewRequest("POST", "/admin/webhooks/testwebhookid/deliveries/testdeliveryid/redeliver", nil)
+	suite.mockContext.Params = gin.Params{{Key: "id", Value: "testwebhookid"}, {Key: "delivery_id", Value: "testdeliveryid"}}
+
+	// Call the controller function
+	suite.controller.Redeliver(suite.mockContext)
+
+	// Check the response
+	suite.Equal(http.StatusOK, suite.Recorder.Code)
+}
+
+func TestWebhookControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookControllerTestSuite))
+}
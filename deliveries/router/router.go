@@ -1,6 +1,7 @@
 package router
 
 import (
+	"expvar"
 	"loan_tracker_api/deliveries/controllers"
 	"loan_tracker_api/infrastructure"
 
@@ -8,7 +9,9 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func SetRouter(router *gin.Engine, cu *controllers.UserController, client *mongo.Client, lc *controllers.LoanController) {
+func SetRouter(router *gin.Engine, cu *controllers.UserController, client *mongo.Client, lc *controllers.LoanController, logc *controllers.LogController, oc *controllers.OutboxController, wc *controllers.WebhookController, nc *controllers.NotificationController, sc *controllers.StreamController, jc *controllers.JobController, qc *controllers.QueueController, dc *controllers.DocumentController, kc *controllers.KYCController, colc *controllers.CollateralController, cc *controllers.CommentController) {
+
+	router.Use(infrastructure.RequestIDMiddleware)
 
 	router.POST("/user/register", cu.RegisterUser)
 	router.POST("/user/verify-email", cu.VerifyEmail)
@@ -18,6 +21,15 @@ func SetRouter(router *gin.Engine, cu *controllers.UserController, client *mongo
 	router.GET("/user/logout", infrastructure.AuthMiddleware(client), cu.LogoutUser)
 	router.PUT("/user/update", infrastructure.AuthMiddleware(client), cu.UpdateUserDetails)
 
+	router.GET("/user/notifications", infrastructure.AuthMiddleware(client), nc.Notifications)
+	router.POST("/user/notifications/read-all", infrastructure.AuthMiddleware(client), nc.MarkAllRead)
+	router.POST("/user/notifications/:id/read", infrastructure.AuthMiddleware(client), nc.MarkRead)
+
+	router.GET("/user/events", infrastructure.AuthMiddleware(client), sc.UserEvents)
+
+	router.POST("/user/kyc", infrastructure.AuthMiddleware(client), kc.SubmitKYC)
+	router.GET("/user/kyc", infrastructure.AuthMiddleware(client), kc.KYCProfile)
+
 	router.POST("/user/password-reset", cu.ForgotPassword)
 	router.POST("/user/password-update", cu.ResetPassword)
 
@@ -26,15 +38,79 @@ func SetRouter(router *gin.Engine, cu *controllers.UserController, client *mongo
 	{
 		admino.GET("/users", cu.ViewAllUsers)
 		admino.DELETE("/user/:id", cu.DeleteUser)
+		admino.POST("/user/:id/restore", cu.RestoreUser)
+
+		admino.GET("/kyc", kc.KYCQueue)
+		admino.GET("/kyc/:id", kc.GetKYCProfile)
+		admino.POST("/kyc/:id/review", kc.ReviewKYC)
+
+		admino.POST("/collateral", colc.RegisterCollateral)
+		admino.GET("/collateral", colc.ListCollateral)
+		admino.GET("/collateral/:id", colc.GetCollateral)
+		admino.PUT("/collateral/:id/appraisal", colc.AppraiseCollateral)
 	}
 
+	router.GET("/loan", infrastructure.AuthMiddleware(client), lc.UserLoans)
 	router.POST("/loan/apply", infrastructure.AuthMiddleware(client), lc.ApplyForLoan)
+	router.POST("/loan/:loan_id/parties/:party_id/respond", infrastructure.AuthMiddleware(client), lc.RespondToLoan)
 	router.GET("/loan/:loan_id", infrastructure.AuthMiddleware(client), lc.LoanDetails)
+	router.GET("/loan/:loan_id/payoff-quote", infrastructure.AuthMiddleware(client), lc.PayoffQuote)
+	router.POST("/loan/:loan_id/payments", infrastructure.AuthMiddleware(client), lc.MakePayment)
+
+	router.POST("/loan/:loan_id/documents", infrastructure.AuthMiddleware(client), dc.UploadDocument)
+	router.GET("/loan/:loan_id/documents", infrastructure.AuthMiddleware(client), dc.LoanDocuments)
+	router.GET("/loan/:loan_id/documents/:document_id/link", infrastructure.AuthMiddleware(client), dc.DocumentLink)
+
+	router.POST("/loan/:loan_id/comments", infrastructure.AuthMiddleware(client), cc.AddComment)
+	router.GET("/loan/:loan_id/comments", infrastructure.AuthMiddleware(client), cc.LoanComments)
+	router.PUT("/loan/:loan_id/comments/:comment_id", infrastructure.AuthMiddleware(client), cc.EditComment)
+	// signed download links are the credentials of their own requests
+	router.GET("/documents/:document_id", dc.DownloadDocument)
 
 	router.GET("/admin/loans", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, lc.ViewAllLoans)
 	router.PATCH("/admin/loans/:loan_id/status", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, lc.ApproveRejectLoan)
+	router.POST("/admin/loans/:loan_id/restructure", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, lc.RestructureLoan)
+	router.POST("/admin/loans/:loan_id/write-off", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, lc.WriteOffLoan)
+	router.POST("/admin/loans/:loan_id/write-off/decision", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, lc.DecideWriteOff)
+	router.POST("/admin/loans/:loan_id/recoveries", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, lc.PostRecovery)
+	router.PATCH("/admin/loans/:loan_id/charges/:charge_id/waive", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, lc.WaiveCharge)
 	router.DELETE("/admin/loans/:loan_id", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, lc.DeleteLoan)
+	router.POST("/admin/loans/:loan_id/restore", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, lc.RestoreLoan)
+	router.GET("/admin/loans/:loan_id/documents", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, dc.LoanDocuments)
+	router.GET("/admin/loans/:loan_id/documents/:document_id/link", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, dc.DocumentLink)
+	router.POST("/admin/loans/:loan_id/collateral/:id", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, colc.LinkCollateral)
+	router.DELETE("/admin/loans/:loan_id/collateral/:id", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, colc.UnlinkCollateral)
+	router.POST("/admin/loans/:loan_id/comments", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, cc.AddComment)
+	router.GET("/admin/loans/:loan_id/comments", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, cc.LoanComments)
+	router.PUT("/admin/loans/:loan_id/comments/:comment_id", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, cc.EditComment)
+
+	router.GET("/admin/reports/aging", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, lc.AgingReport)
+	router.GET("/admin/reports/write-offs", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, lc.WriteOffReport)
+
+	router.GET("/admin/logs", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, logc.ViewLogs)
+	router.GET("/admin/logs/verify", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, logc.VerifyLogs)
+	router.GET("/admin/logs/export", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, logc.ExportLogs)
+
+	router.GET("/admin/outbox/dead", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, oc.DeadMessages)
+	router.POST("/admin/outbox/:id/retry", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, oc.RetryMessage)
+
+	router.POST("/admin/webhooks", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, wc.CreateWebhook)
+	router.GET("/admin/webhooks", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, wc.ListWebhooks)
+	router.PUT("/admin/webhooks/:id", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, wc.UpdateWebhook)
+	router.DELETE("/admin/webhooks/:id", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, wc.DeleteWebhook)
+	router.POST("/admin/webhooks/:id/test", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, wc.TestWebhook)
+	router.GET("/admin/webhooks/:id/deliveries", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, wc.Deliveries)
+	router.POST("/admin/webhooks/:id/deliveries/:delivery_id/redeliver", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, wc.Redeliver)
+
+	router.GET("/admin/jobs", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, jc.Jobs)
+
+	router.GET("/admin/queue/jobs", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, qc.ListJobs)
+	router.GET("/admin/queue/jobs/:id", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, qc.GetJob)
+	router.POST("/admin/queue/jobs/:id/retry", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, qc.RetryJob)
+	router.POST("/admin/queue/jobs/:id/cancel", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, qc.CancelJob)
+
+	router.GET("/admin/events", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, sc.AdminEvents)
 
-	router.GET("/admin/logs", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, lc.ViewLogs)
+	router.GET("/admin/metrics", infrastructure.AuthMiddleware(client), infrastructure.AdminMiddleware, gin.WrapH(expvar.Handler()))
 
 }
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Types of assets pledged as collateral
+const (
+	CollateralProperty  = "property"
+	CollateralVehicle   = "vehicle"
+	CollateralEquipment = "equipment"
+	CollateralDeposit   = "deposit"
+	CollateralOther     = "other"
+)
+
+// CollateralTypes lists the types collateral can be registered with
+var CollateralTypes = []string{CollateralProperty, CollateralVehicle, CollateralEquipment, CollateralDeposit, CollateralOther}
+
+// Lien statuses of collateral, a lien is placed when a loan it secures is approved and released once no loan it secures is being repaid
+const (
+	LienNone     = "none"
+	LienActive   = "active"
+	LienReleased = "released"
+)
+
+// CollateralDocument struct represents a document backing collateral, such as a title deed or a valuation report
+type CollateralDocument struct {
+	Kind      string `json:"kind" bson:"kind"`
+	Reference string `json:"reference" bson:"reference"`
+}
+
+// Collateral struct represents an asset pledged to secure one or more loans
+type Collateral struct {
+	ID             primitive.ObjectID   `json:"id" bson:"_id"`
+	OwnerID        primitive.ObjectID   `json:"owner_id" bson:"owner_id"`
+	Type           string               `json:"type" bson:"type"`
+	Description    string               `json:"description" bson:"description"`
+	AppraisedValue float64              `json:"appraised_value" bson:"appraised_value"`
+	AppraisalDate  time.Time            `json:"appraisal_date" bson:"appraisal_date"`
+	LienStatus     string               `json:"lien_status" bson:"lien_status"`
+	Documents      []CollateralDocument `json:"documents" bson:"documents"`
+	LoanIDs        []primitive.ObjectID `json:"loan_ids" bson:"loan_ids"`
+	RegisteredBy   primitive.ObjectID   `json:"registered_by" bson:"registered_by"`
+	CreatedAt      time.Time            `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at" bson:"updated_at"`
+}
+
+// Appraisal struct represents a new valuation of collateral and the documents backing it
+type Appraisal struct {
+	Description    string               `json:"description"`
+	AppraisedValue float64              `json:"appraised_value"`
+	AppraisalDate  time.Time            `json:"appraisal_date"`
+	Documents      []CollateralDocument `json:"documents"`
+}
+
+// CollateralRepository represents the collateral repository contract
+type CollateralRepository interface {
+	AddCollateral(c context.Context, collateral *Collateral) error
+	GetCollateral(id string) (Collateral, error)
+	ListCollateral(ownerID string, page int) ([]Collateral, error)
+	LoanCollateral(loanID string) ([]Collateral, error)
+	UpdateCollateral(c context.Context, collateral *Collateral) error
+}
+
+// CollateralUsecase represents the collateral usecase contract
+type CollateralUsecase interface {
+	RegisterCollateral(c context.Context, collateral *Collateral, adminid string) error
+	ListCollateral(c context.Context, ownerID string, page int) ([]Collateral, error)
+	GetCollateral(c context.Context, id string) (Collateral, error)
+	AppraiseCollateral(c context.Context, id string, appraisal Appraisal, adminid string) (Collateral, error)
+	LinkCollateral(c context.Context, loanID, id, adminid string) (Collateral, error)
+	UnlinkCollateral(c context.Context, loanID, id, adminid string) (Collateral, error)
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Visibility of loan comments, internal comments are only shown to staff
+const (
+	CommentInternal = "internal"
+	CommentBorrower = "borrower"
+)
+
+// CommentEdit struct represents an earlier version of a comment, kept when it is edited
+type CommentEdit struct {
+	Body     string    `json:"body" bson:"body"`
+	EditedAt time.Time `json:"edited_at" bson:"edited_at"`
+}
+
+// Comment struct represents a note in the comment thread of a loan.
+// Mentions are the staff members the comment was brought to the attention of, edits hold its earlier versions, oldest first.
+type Comment struct {
+	ID         primitive.ObjectID   `json:"id" bson:"_id"`
+	LoanID     primitive.ObjectID   `json:"loan_id" bson:"loan_id"`
+	AuthorID   primitive.ObjectID   `json:"author_id" bson:"author_id"`
+	Visibility string               `json:"visibility" bson:"visibility"`
+	Body       string               `json:"body" bson:"body"`
+	Mentions   []primitive.ObjectID `json:"mentions" bson:"mentions"`
+	Edits      []CommentEdit        `json:"edits" bson:"edits"`
+	CreatedAt  time.Time            `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time            `json:"updated_at" bson:"updated_at"`
+}
+
+// CommentRepository represents the comment repository contract
+type CommentRepository interface {
+	AddComment(c context.Context, comment *Comment) error
+	GetComment(id string) (Comment, error)
+	LoanComments(loanID string, internal bool) ([]Comment, error)
+	UpdateComment(c context.Context, comment *Comment) error
+}
+
+// CommentUsecase represents the comment usecase contract
+type CommentUsecase interface {
+	AddComment(c context.Context, loanID string, comment *Comment, userid string) error
+	LoanComments(c context.Context, loanID string, userid string) ([]Comment, error)
+	EditComment(c context.Context, loanID, commentID, body string, mentions []primitive.ObjectID, userid string) (Comment, error)
+}
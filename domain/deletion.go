@@ -0,0 +1,16 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Deletion struct records who soft deleted a record, when and why.
+// Anonymized records are tombstones kept for the records that still reference them.
+type Deletion struct {
+	DeletedAt  time.Time          `json:"deleted_at" bson:"deleted_at"`
+	DeletedBy  primitive.ObjectID `json:"deleted_by" bson:"deleted_by"`
+	Reason     string             `json:"reason" bson:"reason"`
+	Anonymized bool               `json:"anonymized" bson:"anonymized"`
+}
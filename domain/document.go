@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Kinds of documents supporting a loan application
+const (
+	DocumentIdentity      = "identity"
+	DocumentPayslip       = "payslip"
+	DocumentBankStatement = "bank_statement"
+	DocumentOther         = "other"
+)
+
+// DocumentKinds lists the kinds of documents that can be attached to a loan
+var DocumentKinds = []string{DocumentIdentity, DocumentPayslip, DocumentBankStatement, DocumentOther}
+
+// DocumentContentTypes lists the content types documents can be uploaded in, they are detected from the content
+var DocumentContentTypes = []string{"application/pdf", "image/jpeg", "image/png"}
+
+// Document struct represents a file attached to a loan. Its content is kept in the blob store under the storage key,
+// the checksum is the hex encoded SHA-256 of the content.
+type Document struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	LoanID      primitive.ObjectID `json:"loan_id" bson:"loan_id"`
+	Kind        string             `json:"kind" bson:"kind"`
+	Filename    string             `json:"filename" bson:"filename"`
+	ContentType string             `json:"content_type" bson:"content_type"`
+	Size        int64              `json:"size" bson:"size"`
+	Checksum    string             `json:"checksum" bson:"checksum"`
+	StorageKey  string             `json:"-" bson:"storage_key"`
+	UploadedBy  primitive.ObjectID `json:"uploaded_by" bson:"uploaded_by"`
+	UploadedAt  time.Time          `json:"uploaded_at" bson:"uploaded_at"`
+}
+
+// DocumentUpload struct represents a file being attached to a loan
+type DocumentUpload struct {
+	Kind     string
+	Filename string
+	Size     int64
+	Content  io.Reader
+}
+
+// DocumentLink struct represents a signed URL the content of a document can be downloaded from until it expires
+type DocumentLink struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// BlobStore keeps the content of uploaded files
+type BlobStore interface {
+	Put(c context.Context, key string, content io.Reader, size int64, contentType string) error
+	Open(c context.Context, key string) (io.ReadCloser, error)
+	Delete(c context.Context, key string) error
+}
+
+// DocumentRepository represents the document repository contract
+type DocumentRepository interface {
+	AddDocument(c context.Context, document *Document) error
+	GetDocument(id string) (Document, error)
+	LoanDocuments(loanID string) ([]Document, error)
+}
+
+// DocumentUsecase represents the document usecase contract
+type DocumentUsecase interface {
+	UploadDocument(c context.Context, loanID string, upload DocumentUpload, userid string) (Document, error)
+	LoanDocuments(c context.Context, loanID string, userid string) ([]Document, error)
+	DocumentLink(c context.Context, loanID, documentID string, userid string) (DocumentLink, error)
+	OpenDocument(c context.Context, documentID string, expires int64, signature string) (Document, io.ReadCloser, error)
+}
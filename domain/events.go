@@ -0,0 +1,299 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Names of domain events
+const (
+	EventLoanApplied            = "loan.applied"
+	EventLoanApproved           = "loan.approved"
+	EventLoanRejected           = "loan.rejected"
+	EventPaymentReceived        = "loan.payment_received"
+	EventLoanPaidOff            = "loan.paid_off"
+	EventPaymentDue             = "loan.payment_due"
+	EventPaymentOverdue         = "loan.payment_overdue"
+	EventLoanDelinquencyChanged = "loan.delinquency_changed"
+	EventLoanRestructured       = "loan.restructured"
+	EventLoanWrittenOff         = "loan.written_off"
+	EventLoanPartyInvited       = "loan.party_invited"
+	EventLoanPartyResponded     = "loan.party_responded"
+	EventLoanCommentMention     = "loan.comment_mention"
+	EventUserRegistered         = "user.registered"
+	EventUserVerified           = "user.verified"
+	EventPasswordResetRequested = "user.password_reset_requested"
+	EventPasswordReset          = "user.password_reset"
+	EventUserDeleted            = "user.deleted"
+	EventKYCReviewed            = "user.kyc_reviewed"
+)
+
+// EventHeader carries what every domain event has, it is filled in when the event is published
+type EventHeader struct {
+	EventID    primitive.ObjectID `json:"event_id"`
+	OccurredAt time.Time          `json:"occurred_at"`
+}
+
+// Header returns the header of the event
+func (h *EventHeader) Header() *EventHeader {
+	return h
+}
+
+// Event is something that happened in the domain that other parts of the application may react to
+type Event interface {
+	EventName() string
+	Header() *EventHeader
+}
+
+// LoanApplied is published when a borrower applies for a loan
+type LoanApplied struct {
+	EventHeader
+	LoanID   primitive.ObjectID `json:"loan_id"`
+	UserID   primitive.ObjectID `json:"user_id"`
+	Amount   float64            `json:"amount"`
+	Duration int                `json:"duration"`
+	Product  string             `json:"product"`
+}
+
+// LoanApproved is published when an admin approves a loan
+type LoanApproved struct {
+	EventHeader
+	LoanID     primitive.ObjectID `json:"loan_id"`
+	UserID     primitive.ObjectID `json:"user_id"`
+	Amount     float64            `json:"amount"`
+	ApprovedBy primitive.ObjectID `json:"approved_by"`
+}
+
+// LoanRejected is published when an admin rejects a loan
+type LoanRejected struct {
+	EventHeader
+	LoanID     primitive.ObjectID `json:"loan_id"`
+	UserID     primitive.ObjectID `json:"user_id"`
+	RejectedBy primitive.ObjectID `json:"rejected_by"`
+	Reason     string             `json:"reason"`
+}
+
+// PaymentReceived is published when a repayment is posted against a loan
+type PaymentReceived struct {
+	EventHeader
+	LoanID      primitive.ObjectID `json:"loan_id"`
+	UserID      primitive.ObjectID `json:"user_id"`
+	PaymentID   primitive.ObjectID `json:"payment_id"`
+	Amount      float64            `json:"amount"`
+	Outstanding float64            `json:"outstanding"`
+}
+
+// LoanPaidOff is published when the last amount due on a loan is paid
+type LoanPaidOff struct {
+	EventHeader
+	LoanID primitive.ObjectID `json:"loan_id"`
+	UserID primitive.ObjectID `json:"user_id"`
+}
+
+// PaymentDue is published when an installment of a loan is coming due
+type PaymentDue struct {
+	EventHeader
+	LoanID       primitive.ObjectID `json:"loan_id"`
+	UserID       primitive.ObjectID `json:"user_id"`
+	Installment  int                `json:"installment"`
+	DueDate      time.Time          `json:"due_date"`
+	Amount       float64            `json:"amount"`
+	DaysUntilDue int                `json:"days_until_due"`
+}
+
+// PaymentOverdue is published when a borrower is sent a notice about an overdue installment.
+// Notices escalate, the last one is the final notice.
+type PaymentOverdue struct {
+	EventHeader
+	LoanID      primitive.ObjectID `json:"loan_id"`
+	UserID      primitive.ObjectID `json:"user_id"`
+	Installment int                `json:"installment"`
+	DueDate     time.Time          `json:"due_date"`
+	Amount      float64            `json:"amount"`
+	DaysPastDue int                `json:"days_past_due"`
+	Notice      int                `json:"notice"`
+	Final       bool               `json:"final"`
+}
+
+// LoanDelinquencyChanged is published when an assessment moves a loan to another standing
+type LoanDelinquencyChanged struct {
+	EventHeader
+	LoanID      primitive.ObjectID `json:"loan_id"`
+	UserID      primitive.ObjectID `json:"user_id"`
+	From        string             `json:"from"`
+	To          string             `json:"to"`
+	DaysPastDue int                `json:"days_past_due"`
+}
+
+// LoanRestructured is published when an admin restructures a loan
+type LoanRestructured struct {
+	EventHeader
+	LoanID primitive.ObjectID `json:"loan_id"`
+	UserID primitive.ObjectID `json:"user_id"`
+	Reason string             `json:"reason"`
+}
+
+// LoanWrittenOff is published when the balance of a defaulted loan is written off
+type LoanWrittenOff struct {
+	EventHeader
+	LoanID primitive.ObjectID `json:"loan_id"`
+	UserID primitive.ObjectID `json:"user_id"`
+	Amount float64            `json:"amount"`
+}
+
+// LoanPartyInvited is published for each guarantor or co-borrower named on a loan application
+type LoanPartyInvited struct {
+	EventHeader
+	LoanID         primitive.ObjectID `json:"loan_id"`
+	UserID         primitive.ObjectID `json:"user_id"`
+	PartyID        primitive.ObjectID `json:"party_id"`
+	PartyUserID    primitive.ObjectID `json:"party_user_id,omitempty"`
+	Email          string             `json:"email,omitempty"`
+	Role           string             `json:"role"`
+	LiabilityShare float64            `json:"liability_share"`
+	Amount         float64            `json:"amount"`
+	Duration       int                `json:"duration"`
+}
+
+// LoanPartyResponded is published when a guarantor or co-borrower accepts or declines a loan application
+type LoanPartyResponded struct {
+	EventHeader
+	LoanID      primitive.ObjectID `json:"loan_id"`
+	UserID      primitive.ObjectID `json:"user_id"`
+	PartyID     primitive.ObjectID `json:"party_id"`
+	PartyUserID primitive.ObjectID `json:"party_user_id"`
+	Role        string             `json:"role"`
+	Status      string             `json:"status"`
+}
+
+// LoanCommentMention is published when a staff member is mentioned in a comment on a loan
+type LoanCommentMention struct {
+	EventHeader
+	LoanID      primitive.ObjectID `json:"loan_id"`
+	CommentID   primitive.ObjectID `json:"comment_id"`
+	AuthorID    primitive.ObjectID `json:"author_id"`
+	MentionedID primitive.ObjectID `json:"mentioned_id"`
+}
+
+// UserRegistered is published when a user signs up
+type UserRegistered struct {
+	EventHeader
+	UserID   primitive.ObjectID `json:"user_id"`
+	Email    string             `json:"email"`
+	UserName string             `json:"username"`
+	Language string             `json:"language"`
+}
+
+// UserVerified is published when a user verifies their email
+type UserVerified struct {
+	EventHeader
+	UserID primitive.ObjectID `json:"user_id"`
+}
+
+// PasswordResetRequested is published when a user asks to reset their password
+type PasswordResetRequested struct {
+	EventHeader
+	UserID   primitive.ObjectID `json:"user_id"`
+	Email    string             `json:"email"`
+	Language string             `json:"language"`
+}
+
+// PasswordReset is published when a user sets a new password with a reset link
+type PasswordReset struct {
+	EventHeader
+	UserID primitive.ObjectID `json:"user_id"`
+}
+
+// UserDeleted is published when an admin deletes a user
+type UserDeleted struct {
+	EventHeader
+	UserID    primitive.ObjectID `json:"user_id"`
+	DeletedBy primitive.ObjectID `json:"deleted_by"`
+}
+
+// KYCReviewed is published when an admin approves or rejects the KYC profile of a user, or asks them for more information
+type KYCReviewed struct {
+	EventHeader
+	UserID     primitive.ObjectID `json:"user_id"`
+	Status     string             `json:"status"`
+	Note       string             `json:"note,omitempty"`
+	ReviewedBy primitive.ObjectID `json:"reviewed_by"`
+}
+
+func (LoanApplied) EventName() string            { return EventLoanApplied }
+func (LoanApproved) EventName() string           { return EventLoanApproved }
+func (LoanRejected) EventName() string           { return EventLoanRejected }
+func (PaymentReceived) EventName() string        { return EventPaymentReceived }
+func (LoanPaidOff) EventName() string            { return EventLoanPaidOff }
+func (PaymentDue) EventName() string             { return EventPaymentDue }
+func (PaymentOverdue) EventName() string         { return EventPaymentOverdue }
+func (LoanDelinquencyChanged) EventName() string { return EventLoanDelinquencyChanged }
+func (LoanRestructured) EventName() string       { return EventLoanRestructured }
+func (LoanWrittenOff) EventName() string         { return EventLoanWrittenOff }
+func (LoanPartyInvited) EventName() string       { return EventLoanPartyInvited }
+func (LoanPartyResponded) EventName() string     { return EventLoanPartyResponded }
+func (LoanCommentMention) EventName() string     { return EventLoanCommentMention }
+func (UserRegistered) EventName() string         { return EventUserRegistered }
+func (UserVerified) EventName() string           { return EventUserVerified }
+func (PasswordResetRequested) EventName() string { return EventPasswordResetRequested }
+func (PasswordReset) EventName() string          { return EventPasswordReset }
+func (UserDeleted) EventName() string            { return EventUserDeleted }
+func (KYCReviewed) EventName() string            { return EventKYCReviewed }
+
+// events lists how to create an empty event of each name so stored events can be read back into their type
+var events = map[string]func() Event{
+	EventLoanApplied:            func() Event { return &LoanApplied{} },
+	EventLoanApproved:           func() Event { return &LoanApproved{} },
+	EventLoanRejected:           func() Event { return &LoanRejected{} },
+	EventPaymentReceived:        func() Event { return &PaymentReceived{} },
+	EventLoanPaidOff:            func() Event { return &LoanPaidOff{} },
+	EventPaymentDue:             func() Event { return &PaymentDue{} },
+	EventPaymentOverdue:         func() Event { return &PaymentOverdue{} },
+	EventLoanDelinquencyChanged: func() Event { return &LoanDelinquencyChanged{} },
+	EventLoanRestructured:       func() Event { return &LoanRestructured{} },
+	EventLoanWrittenOff:         func() Event { return &LoanWrittenOff{} },
+	EventLoanPartyInvited:       func() Event { return &LoanPartyInvited{} },
+	EventLoanPartyResponded:     func() Event { return &LoanPartyResponded{} },
+	EventLoanCommentMention:     func() Event { return &LoanCommentMention{} },
+	EventUserRegistered:         func() Event { return &UserRegistered{} },
+	EventUserVerified:           func() Event { return &UserVerified{} },
+	EventPasswordResetRequested: func() Event { return &PasswordResetRequested{} },
+	EventPasswordReset:          func() Event { return &PasswordReset{} },
+	EventUserDeleted:            func() Event { return &UserDeleted{} },
+	EventKYCReviewed:            func() Event { return &KYCReviewed{} },
+}
+
+// NewEvent returns an empty event of the given name to decode a stored event into
+func NewEvent(name string) (Event, error) {
+	create, ok := events[name]
+	if !ok {
+		return nil, errors.New("Unknown event " + name)
+	}
+	return create(), nil
+}
+
+// EventNames returns the names of every domain event, sorted
+func EventNames() []string {
+	names := make([]string, 0, len(events))
+	for name := range events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EventHandler reacts to a domain event
+type EventHandler func(c context.Context, event Event) error
+
+// EventBus represents how domain events reach their subscribers.
+// Synchronous subscribers run in the unit of work of the change and fail it when they fail.
+// Asynchronous subscribers are handed the event after the change is committed and are retried until they succeed.
+type EventBus interface {
+	Subscribe(name string, handler EventHandler)
+	SubscribeAsync(name, subscriber string, handler EventHandler)
+	Publish(c context.Context, event Event) error
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// KYC statuses of a user, users who never submitted a profile have none
+const (
+	KYCNotSubmitted = "not_submitted"
+	KYCPending      = "pending"
+	KYCMoreInfo     = "more_info_requested"
+	KYCApproved     = "approved"
+	KYCRejected     = "rejected"
+)
+
+// Decisions an admin reviewing a KYC profile can take
+const (
+	KYCDecisionApprove     = "approve"
+	KYCDecisionReject      = "reject"
+	KYCDecisionRequestInfo = "request_info"
+)
+
+// Address struct represents the residential address of a user
+type Address struct {
+	Line1      string `json:"line1" bson:"line1"`
+	Line2      string `json:"line2,omitempty" bson:"line2,omitempty"`
+	City       string `json:"city" bson:"city"`
+	Region     string `json:"region,omitempty" bson:"region,omitempty"`
+	PostalCode string `json:"postal_code,omitempty" bson:"postal_code,omitempty"`
+	Country    string `json:"country" bson:"country"`
+}
+
+// KYCDocument struct represents an identity document a KYC profile refers to, such as a passport or a utility bill
+type KYCDocument struct {
+	Kind      string `json:"kind" bson:"kind"`
+	Reference string `json:"reference" bson:"reference"`
+}
+
+// KYCProfile struct represents the identity a user submitted for verification and the outcome of its review.
+// A user has a single profile, resubmitting it replaces what they submitted before.
+type KYCProfile struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	UserID      primitive.ObjectID `json:"user_id" bson:"user_id"`
+	LegalName   string             `json:"legal_name" bson:"legal_name"`
+	DateOfBirth time.Time          `json:"date_of_birth" bson:"date_of_birth"`
+	NationalID  string             `json:"national_id" bson:"national_id"`
+	Address     Address            `json:"address" bson:"address"`
+	Documents   []KYCDocument      `json:"documents" bson:"documents"`
+	Status      string             `json:"status" bson:"status"`
+	ReviewNote  string             `json:"review_note,omitempty" bson:"review_note,omitempty"`
+	ReviewedBy  primitive.ObjectID `json:"reviewed_by,omitempty" bson:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time         `json:"reviewed_at,omitempty" bson:"reviewed_at,omitempty"`
+	SubmittedAt time.Time          `json:"submitted_at" bson:"submitted_at"`
+}
+
+// KYCRepository represents the KYC profile repository contract
+type KYCRepository interface {
+	SaveKYCProfile(c context.Context, profile *KYCProfile) error
+	KYCProfile(userID string) (KYCProfile, error)
+	GetKYCProfile(id string) (KYCProfile, error)
+	KYCQueue(status string, page int) ([]KYCProfile, error)
+	DeleteKYCProfiles(c context.Context, userIDs []primitive.ObjectID) error
+}
+
+// KYCUsecase represents the KYC usecase contract
+type KYCUsecase interface {
+	SubmitKYC(c context.Context, profile *KYCProfile, userid string) error
+	KYCProfile(c context.Context, userid string) (KYCProfile, error)
+	KYCQueue(c context.Context, status string, page int) ([]KYCProfile, error)
+	GetKYCProfile(c context.Context, id string) (KYCProfile, error)
+	ReviewKYC(c context.Context, id, decision, note, adminid string) (KYCProfile, error)
+}
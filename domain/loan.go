@@ -7,34 +7,254 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Charge types recorded against a loan
+const (
+	ChargeLateFee           = "late_fee"
+	ChargePenaltyInterest   = "penalty_interest"
+	ChargePrepaymentPenalty = "prepayment_penalty"
+)
+
+// Delinquency standings of an approved loan
+const (
+	DelinquencyCurrent    = "current"
+	DelinquencyDelinquent = "delinquent"
+	DelinquencyDefaulted  = "defaulted"
+)
+
+// LoanAwaitingAcceptance is the status of an application waiting on its guarantors and co-borrowers,
+// it moves to pending review once all of them accepted
+const LoanAwaitingAcceptance = "awaiting_acceptance"
+
+// Roles people play in a loan, the borrower applies for it and names the others on the application
+const (
+	PartyBorrower   = "borrower"
+	PartyGuarantor  = "guarantor"
+	PartyCoBorrower = "co_borrower"
+)
+
+// Answers of the people named on a loan application
+const (
+	PartyInvited  = "invited"
+	PartyAccepted = "accepted"
+	PartyDeclined = "declined"
+)
+
 // Loan struct represents the loan model
 type Loan struct {
-	ID        primitive.ObjectID `json:"id" bson:"_id"`
-	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
-	Amount    float64            `json:"amount" bson:"amount"`
-	Interest  float64            `json:"interest" bson:"interest"`
-	Duration  int                `json:"duration" bson:"duration"`
-	Status    string             `json:"status" bson:"status"`
-	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	UserID      primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Amount      float64            `json:"amount" bson:"amount"`
+	Interest    float64            `json:"interest" bson:"interest"`
+	Duration    int                `json:"duration" bson:"duration"`
+	Product     string             `json:"product" bson:"product"`
+	Status      string             `json:"status" bson:"status"`
+	Delinquency string             `json:"delinquency" bson:"delinquency"`
+	DaysPastDue int                `json:"days_past_due" bson:"days_past_due"`
+	Schedule    []Installment      `json:"schedule" bson:"schedule"`
+	Charges     []Charge           `json:"charges" bson:"charges"`
+	Payments    []Payment          `json:"payments" bson:"payments"`
+	PayoffQuote *PayoffQuote       `json:"payoff_quote,omitempty" bson:"payoff_quote"`
+	History     []ScheduleVersion  `json:"schedule_history" bson:"schedule_history"`
+	Rejection   *Rejection         `json:"rejection,omitempty" bson:"rejection"`
+	WriteOff    *WriteOff          `json:"write_off,omitempty" bson:"write_off"`
+	Recoveries  []Recovery         `json:"recoveries" bson:"recoveries"`
+	Parties     []LoanParty        `json:"parties" bson:"parties"`
+	Collateral  []Collateral       `json:"collateral,omitempty" bson:"-"`
+	LTV         float64            `json:"ltv,omitempty" bson:"-"`
+	Deletion    *Deletion          `json:"deletion,omitempty" bson:"deletion"`
+	Version     int                `json:"-" bson:"version"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// LoanParty struct represents a guarantor or co-borrower named on a loan application.
+// People invited by email who have no account yet are linked to it when they answer.
+// The liability share is the part of the loan a co-borrower owes, or a guarantor stands behind.
+type LoanParty struct {
+	ID             primitive.ObjectID `json:"id" bson:"id"`
+	Role           string             `json:"role" bson:"role"`
+	UserID         primitive.ObjectID `json:"user_id,omitempty" bson:"user_id,omitempty"`
+	Email          string             `json:"email,omitempty" bson:"email,omitempty"`
+	LiabilityShare float64            `json:"liability_share" bson:"liability_share"`
+	Status         string             `json:"status" bson:"status"`
+	InvitedAt      time.Time          `json:"invited_at" bson:"invited_at"`
+	RespondedAt    *time.Time         `json:"responded_at,omitempty" bson:"responded_at,omitempty"`
+}
+
+// UserLoan struct represents a loan in the listing of a user, with the part they play in it
+type UserLoan struct {
+	Loan
+	Role           string  `json:"role"`
+	LiabilityShare float64 `json:"liability_share"`
+}
+
+// Installment struct represents a single scheduled repayment of a loan
+type Installment struct {
+	Number     int       `json:"number" bson:"number"`
+	DueDate    time.Time `json:"due_date" bson:"due_date"`
+	Principal  float64   `json:"principal" bson:"principal"`
+	Interest   float64   `json:"interest" bson:"interest"`
+	Amount     float64   `json:"amount" bson:"amount"`
+	PaidAmount float64   `json:"paid_amount" bson:"paid_amount"`
+	PaidAt     time.Time `json:"paid_at" bson:"paid_at"`
+	Reminders  []int     `json:"reminders,omitempty" bson:"reminders,omitempty"`
+	Notices    []int     `json:"notices,omitempty" bson:"notices,omitempty"`
+}
+
+// Charge struct represents a late fee or penalty interest assessed on an overdue installment
+type Charge struct {
+	ID          primitive.ObjectID `json:"id" bson:"id"`
+	Installment int                `json:"installment" bson:"installment"`
+	Type        string             `json:"type" bson:"type"`
+	Amount      float64            `json:"amount" bson:"amount"`
+	PaidAmount  float64            `json:"paid_amount" bson:"paid_amount"`
+	AccruedTo   time.Time          `json:"accrued_to" bson:"accrued_to"`
+	AssessedAt  time.Time          `json:"assessed_at" bson:"assessed_at"`
+	Capitalized bool               `json:"capitalized" bson:"capitalized"`
+	Waived      bool               `json:"waived" bson:"waived"`
+	WaivedBy    primitive.ObjectID `json:"waived_by" bson:"waived_by"`
+	WaiveReason string             `json:"waive_reason" bson:"waive_reason"`
+	WaivedAt    time.Time          `json:"waived_at" bson:"waived_at"`
+}
+
+// Payment struct represents a repayment posted by the borrower
+type Payment struct {
+	ID     primitive.ObjectID `json:"id" bson:"id"`
+	Amount float64            `json:"amount" bson:"amount"`
+	PaidAt time.Time          `json:"paid_at" bson:"paid_at"`
+}
+
+// PayoffQuote struct represents what it takes to close a loan on a given date
+type PayoffQuote struct {
+	ID                primitive.ObjectID `json:"id" bson:"id"`
+	Date              time.Time          `json:"date" bson:"date"`
+	Principal         float64            `json:"principal" bson:"principal"`
+	AccruedInterest   float64            `json:"accrued_interest" bson:"accrued_interest"`
+	Fees              float64            `json:"fees" bson:"fees"`
+	PrepaymentPenalty float64            `json:"prepayment_penalty" bson:"prepayment_penalty"`
+	Total             float64            `json:"total" bson:"total"`
+	ExpiresAt         time.Time          `json:"expires_at" bson:"expires_at"`
+}
+
+// Restructure struct represents the term changes an officer applies to a loan in hardship
+type Restructure struct {
+	ExtendMonths      int      `json:"extend_months" bson:"extend_months"`
+	Interest          *float64 `json:"interest" bson:"interest"`
+	CapitalizeArrears bool     `json:"capitalize_arrears" bson:"capitalize_arrears"`
+	HolidayMonths     int      `json:"holiday_months" bson:"holiday_months"`
+	Reason            string   `json:"reason" bson:"reason"`
+}
+
+// ScheduleVersion struct represents the terms and schedule of a loan before a restructure replaced them
+type ScheduleVersion struct {
+	Version     int                `json:"version" bson:"version"`
+	Interest    float64            `json:"interest" bson:"interest"`
+	Duration    int                `json:"duration" bson:"duration"`
+	Schedule    []Installment      `json:"schedule" bson:"schedule"`
+	Charges     []Charge           `json:"charges" bson:"charges"`
+	Restructure Restructure        `json:"restructure" bson:"restructure"`
+	ReplacedBy  primitive.ObjectID `json:"replaced_by" bson:"replaced_by"`
+	ReplacedAt  time.Time          `json:"replaced_at" bson:"replaced_at"`
+}
+
+// Rejection struct records why a loan application was turned down, the reason is shown to the borrower
+type Rejection struct {
+	Reason     string             `json:"reason" bson:"reason"`
+	RejectedBy primitive.ObjectID `json:"rejected_by" bson:"rejected_by"`
+	RejectedAt time.Time          `json:"rejected_at" bson:"rejected_at"`
+}
+
+// WriteOff struct represents the balance of a defaulted loan taken off the active book.
+// An admin requests it, and it is pending until another admin approves it.
+type WriteOff struct {
+	Amount       float64            `json:"amount" bson:"amount"`
+	Reason       string             `json:"reason" bson:"reason"`
+	RequestedBy  primitive.ObjectID `json:"requested_by" bson:"requested_by"`
+	RequestedAt  time.Time          `json:"requested_at" bson:"requested_at"`
+	ApprovedBy   primitive.ObjectID `json:"approved_by,omitempty" bson:"approved_by,omitempty"`
+	WrittenOffAt time.Time          `json:"written_off_at,omitempty" bson:"written_off_at,omitempty"`
+}
+
+// Recovery struct represents money collected on a loan after it was written off
+type Recovery struct {
+	ID         primitive.ObjectID `json:"id" bson:"id"`
+	Amount     float64            `json:"amount" bson:"amount"`
+	Note       string             `json:"note" bson:"note"`
+	PostedBy   primitive.ObjectID `json:"posted_by" bson:"posted_by"`
+	ReceivedAt time.Time          `json:"received_at" bson:"received_at"`
+}
+
+// WriteOffEntry struct represents a written-off loan in the write-off report
+type WriteOffEntry struct {
+	LoanID       primitive.ObjectID `json:"loan_id"`
+	UserID       primitive.ObjectID `json:"user_id"`
+	WrittenOff   float64            `json:"written_off"`
+	Recovered    float64            `json:"recovered"`
+	WrittenOffAt time.Time          `json:"written_off_at"`
+}
+
+// WriteOffReport struct represents the written-off book and what has been recovered from it
+type WriteOffReport struct {
+	Loans       int             `json:"loans"`
+	WrittenOff  float64         `json:"written_off"`
+	Recovered   float64         `json:"recovered"`
+	Outstanding float64         `json:"outstanding"`
+	Entries     []WriteOffEntry `json:"entries"`
+}
+
+// DPDRange bounds the days past due of listed loans, a negative Max leaves the range open
+type DPDRange struct {
+	Min int
+	Max int
+}
+
+// AgingBucket struct represents the loans falling in a days-past-due band
+type AgingBucket struct {
+	Label       string  `json:"label"`
+	MinDPD      int     `json:"min_dpd"`
+	MaxDPD      int     `json:"max_dpd"`
+	Count       int     `json:"count"`
+	Outstanding float64 `json:"outstanding"`
 }
 
 // LoanRepository represents the loan repository contract
 type LoanRepository interface {
-	ApplyForLoan(loan *Loan, userid string) error
+	ApplyForLoan(c context.Context, loan *Loan, userid string) error
 	LoanDetails(loanID string, userid string) (Loan, error)
-	ViewAllLoans(pgnum int, status, order string) ([]Loan, int, error)
-	ApproveRejectLoan(loanID string, status, userid string) error
-	DeleteLoan(loanID string, userid string) error
-	ViewLogs() ([]Log, error)
+	GetLoan(loanID string) (Loan, error)
+	ActiveLoans() ([]Loan, error)
+	LoansByStatus(status string) ([]Loan, error)
+	UserLoans(userid string) ([]Loan, error)
+	PartyLoans(userid, email string) ([]Loan, error)
+	ViewAllLoans(pgnum int, status, order string, dpd DPDRange) ([]Loan, int, error)
+	ApproveRejectLoan(c context.Context, loanID string, status string, rejection *Rejection) error
+	UpdateLoan(c context.Context, loan *Loan) error
+	DeleteLoan(c context.Context, loanID, reason, userid string) error
+	RestoreLoan(c context.Context, loanID string) error
+	PurgeDeletedLoans(c context.Context, before time.Time) (int, error)
 }
 
 // LoanUsecase represents the loan usecase contract
 type LoanUsecase interface {
 	ApplyForLoan(c context.Context, loan *Loan, userid string) error
 	LoanDetails(c context.Context, loanID string, userid string) (Loan, error)
-	ViewAllLoans(c context.Context, pgnum int, status, order string) ([]Loan, int, error)
-	ApproveRejectLoan(c context.Context, loanID string, status, userid string) error
-	DeleteLoan(c context.Context, loanID string, userid string) error
-	ViewLogs(c context.Context) ([]Log, error)
+	UserLoans(c context.Context, userid string) ([]UserLoan, error)
+	RespondToLoan(c context.Context, loanID, partyID string, accept bool, userid string) (Loan, error)
+	ViewAllLoans(c context.Context, pgnum int, status, order string, dpd DPDRange) ([]Loan, int, error)
+	ApproveRejectLoan(c context.Context, loanID string, status, reason, userid string) error
+	PayoffQuote(c context.Context, loanID string, date time.Time, userid string) (PayoffQuote, error)
+	MakePayment(c context.Context, loanID string, amount float64, quoteID, userid string) (Payment, error)
+	AssessOverdueLoans(c context.Context) error
+	AgingReport(c context.Context) ([]AgingBucket, error)
+	WaiveCharge(c context.Context, loanID, chargeID, reason, userid string) error
+	RestructureLoan(c context.Context, loanID string, terms Restructure, userid string) (Loan, error)
+	WriteOffLoan(c context.Context, loanID, reason, userid string) error
+	DecideWriteOff(c context.Context, loanID string, approve bool, userid string) error
+	PostRecovery(c context.Context, loanID string, amount float64, note, userid string) (Recovery, error)
+	WriteOffReport(c context.Context) (WriteOffReport, error)
+	DeleteLoan(c context.Context, loanID, reason, userid string) error
+	RestoreLoan(c context.Context, loanID string, userid string) error
+	PurgeDeletedLoans(c context.Context, retention time.Duration) (int, error)
+	RemindUpcomingPayments(c context.Context, days []int) (int, error)
+	NoticeOverduePayments(c context.Context, days []int) (int, error)
 }
@@ -1,14 +1,230 @@
 package domain
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Roles of the actor behind an audit entry
+const (
+	RoleAdmin  = "admin"
+	RoleUser   = "user"
+	RoleSystem = "system"
+)
+
+// Types of the records an audit entry can target
+const (
+	TargetLoan       = "loan"
+	TargetUser       = "user"
+	TargetLog        = "log"
+	TargetCollateral = "collateral"
+)
+
+// Action codes of audit entries
+const (
+	ActionLoanApplied           = "loan.applied"
+	ActionLoanApproved          = "loan.approved"
+	ActionLoanRejected          = "loan.rejected"
+	ActionLoanAssessed          = "loan.assessed"
+	ActionLoanPayoffQuoted      = "loan.payoff_quoted"
+	ActionLoanPayment           = "loan.payment"
+	ActionLoanReminded          = "loan.reminded"
+	ActionLoanOverdueNotice     = "loan.overdue_notice"
+	ActionLoanPaidOff           = "loan.paid_off"
+	ActionLoanChargeWaived      = "loan.charge_waived"
+	ActionLoanRestructured      = "loan.restructured"
+	ActionLoanWriteOffRequested = "loan.write_off_requested"
+	ActionLoanWriteOffDeclined  = "loan.write_off_declined"
+	ActionLoanWrittenOff        = "loan.written_off"
+	ActionLoanRecovery          = "loan.recovery"
+	ActionLoanDeleted           = "loan.deleted"
+	ActionLoanRestored          = "loan.restored"
+	ActionLoanPurged            = "loan.purged"
+	ActionLoanDocument          = "loan.document_uploaded"
+	ActionLoanPartyResponded    = "loan.party_responded"
+	ActionLoanCommented         = "loan.commented"
+	ActionLoanCommentEdited     = "loan.comment_edited"
+	ActionCollateralRegistered  = "collateral.registered"
+	ActionCollateralAppraised   = "collateral.appraised"
+	ActionCollateralLinked      = "collateral.linked"
+	ActionCollateralUnlinked    = "collateral.unlinked"
+	ActionCollateralLien        = "collateral.lien_changed"
+	ActionUserRegistered        = "user.registered"
+	ActionUserVerified          = "user.verified"
+	ActionUserLogin             = "user.login"
+	ActionUserLoginFailed       = "user.login_failed"
+	ActionUserLogout            = "user.logout"
+	ActionUserResetRequest      = "user.password_reset_requested"
+	ActionUserPasswordReset     = "user.password_reset"
+	ActionUserUpdated           = "user.updated"
+	ActionUserDeleted           = "user.deleted"
+	ActionUserRestored          = "user.restored"
+	ActionUserPurged            = "user.purged"
+	ActionUserKYCSubmitted      = "user.kyc_submitted"
+	ActionUserKYCReviewed       = "user.kyc_reviewed"
+	ActionLogArchived           = "log.archived"
+)
+
+// Categories audit entries are retained by
+const (
+	CategoryAuth  = "auth"
+	CategoryLoan  = "loan"
+	CategoryUser  = "user"
+	CategoryAudit = "audit"
+)
+
+// ActionCategories lists the actions of each retention category
+var ActionCategories = map[string][]string{
+	CategoryAuth: {
+		ActionUserVerified, ActionUserLogin, ActionUserLoginFailed, ActionUserLogout,
+		ActionUserResetRequest, ActionUserPasswordReset,
+	},
+	CategoryLoan: {
+		ActionLoanApplied, ActionLoanApproved, ActionLoanRejected, ActionLoanAssessed, ActionLoanPayoffQuoted,
+		ActionLoanPayment, ActionLoanReminded, ActionLoanOverdueNotice, ActionLoanPaidOff, ActionLoanChargeWaived, ActionLoanRestructured, ActionLoanWriteOffRequested,
+		ActionLoanWriteOffDeclined, ActionLoanWrittenOff, ActionLoanRecovery, ActionLoanDeleted, ActionLoanRestored, ActionLoanPurged, ActionLoanDocument,
+		ActionLoanPartyResponded, ActionCollateralRegistered, ActionCollateralAppraised, ActionCollateralLinked, ActionCollateralUnlinked,
+		ActionCollateralLien, ActionLoanCommented, ActionLoanCommentEdited,
+	},
+	CategoryUser: {
+		ActionUserRegistered, ActionUserUpdated, ActionUserDeleted, ActionUserRestored, ActionUserPurged,
+		ActionUserKYCSubmitted, ActionUserKYCReviewed,
+	},
+	CategoryAudit: {
+		ActionLogArchived,
+	},
+}
+
+// RetentionPolicy maps retention categories to how long their entries stay in the database before they are archived
+type RetentionPolicy map[string]time.Duration
+
+// FieldChange struct represents the value of a field before and after a change
+type FieldChange struct {
+	Field  string      `json:"field" bson:"field"`
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
+
+// Log struct represents an audit entry of who changed what on which record.
+// Entries are chained, each one carries the hash of the entry before it.
 type Log struct {
-	ID        primitive.ObjectID `json:"id" bson:"_id"`
-	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
-	Activity  string             `json:"activity" bson:"activity"`
-	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	ID         primitive.ObjectID `json:"id" bson:"_id"`
+	Sequence   int64              `json:"sequence" bson:"sequence"`
+	ActorID    primitive.ObjectID `json:"actor_id" bson:"actor_id"`
+	ActorRole  string             `json:"actor_role" bson:"actor_role"`
+	Action     string             `json:"action" bson:"action"`
+	TargetType string             `json:"target_type" bson:"target_type"`
+	TargetID   primitive.ObjectID `json:"target_id" bson:"target_id"`
+	Changes    []FieldChange      `json:"changes" bson:"changes"`
+	Note       string             `json:"note,omitempty" bson:"note"`
+	RequestID  string             `json:"request_id" bson:"request_id"`
+	IP         string             `json:"ip" bson:"ip"`
+	UserAgent  string             `json:"user_agent" bson:"user_agent"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	PrevHash   string             `json:"prev_hash" bson:"prev_hash"`
+	Hash       string             `json:"hash" bson:"hash"`
+	Archived   bool               `json:"archived,omitempty" bson:"archived,omitempty"`
+}
+
+// ComputeHash hashes the content of the entry together with the hash of the entry before it
+func (entry Log) ComputeHash() string {
+	entry.Hash = ""
+	entry.CreatedAt = entry.CreatedAt.UTC()
+	if entry.Changes == nil {
+		entry.Changes = []FieldChange{}
+	}
+
+	content, _ := json.Marshal(entry)
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// LogCheckpoint struct represents the head of the audit chain at a point in time, anchored outside the database
+type LogCheckpoint struct {
+	Sequence  int64     `json:"sequence"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BrokenLink struct represents the first audit entry that doesn't fit the chain and why
+type BrokenLink struct {
+	Sequence int64              `json:"sequence"`
+	EntryID  primitive.ObjectID `json:"entry_id"`
+	Reason   string             `json:"reason"`
+}
+
+// LogVerification struct represents the outcome of walking the audit chain
+type LogVerification struct {
+	Valid      bool        `json:"valid"`
+	Checked    int64       `json:"checked"`
+	BrokenLink *BrokenLink `json:"broken_link,omitempty"`
+}
+
+// LogFilter narrows the audit entries listed, empty fields match every entry
+type LogFilter struct {
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	From       time.Time
+	To         time.Time
+	Page       int
+}
+
+// RequestMeta carries who sent a request and where it came from down to the usecases
+type RequestMeta struct {
+	RequestID string
+	IP        string
+	UserAgent string
+	IsAdmin   bool
+}
+
+type requestMetaKey struct{}
+
+// WithRequestMeta returns a copy of the context carrying the request metadata
+func WithRequestMeta(c context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(c, requestMetaKey{}, meta)
+}
+
+// RequestMetaFrom returns the request metadata of the context, background work has none
+func RequestMetaFrom(c context.Context) (RequestMeta, bool) {
+	meta, ok := c.Value(requestMetaKey{}).(RequestMeta)
+	return meta, ok
+}
+
+// LogRepository represents the audit log repository contract
+type LogRepository interface {
+	AddLog(c context.Context, entry *Log) error
+	ViewLogs(filter LogFilter) ([]Log, error)
+	StreamLogs(filter LogFilter, each func(entry Log) error) error
+	LastLog() (Log, error)
+	ChainedLogs(after int64, limit int) ([]Log, error)
+	ArchivableLogs(cutoffs map[string]time.Time, limit int) ([]Log, error)
+	MarkArchived(c context.Context, ids []primitive.ObjectID) error
+}
+
+// LogArchiver represents where archived audit entries are kept once they leave the database
+type LogArchiver interface {
+	ArchiveLogs(entries []Log) (string, error)
+	ArchivedLogs(from, to int64) ([]Log, error)
+}
+
+// CheckpointStore represents where audit checkpoints are anchored
+type CheckpointStore interface {
+	AppendCheckpoint(checkpoint LogCheckpoint) error
+	Checkpoints() ([]LogCheckpoint, error)
+}
+
+// LogUsecase represents the audit log usecase contract
+type LogUsecase interface {
+	ViewLogs(c context.Context, filter LogFilter) ([]Log, error)
+	ExportLogs(c context.Context, filter LogFilter, each func(entry Log) error) error
+	VerifyLogs(c context.Context) (LogVerification, error)
+	CheckpointLogs(c context.Context) (LogCheckpoint, error)
+	ArchiveLogs(c context.Context) (int, error)
 }
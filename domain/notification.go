@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Channels notifications reach users through
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelInApp = "in_app"
+)
+
+// Types of notifications, each one is rendered from the templates of the same name
+const (
+	NotificationVerification    = "verification"
+	NotificationPasswordReset   = "password_reset"
+	NotificationLoanSubmitted   = "loan_submitted"
+	NotificationLoanApproved    = "loan_approved"
+	NotificationLoanRejected    = "loan_rejected"
+	NotificationPaymentReceived = "payment_received"
+	NotificationPaymentDue      = "payment_due"
+	NotificationPaymentOverdue  = "payment_overdue"
+	NotificationLoanPaidOff     = "loan_paid_off"
+	NotificationLoanWrittenOff  = "loan_written_off"
+	NotificationKYCReviewed     = "kyc_reviewed"
+	NotificationLoanPartyInvite = "loan_party_invite"
+	NotificationLoanMention     = "loan_mention"
+)
+
+// DefaultChannels lists the channels each type of notification is sent through unless the user chose otherwise
+var DefaultChannels = map[string][]string{
+	NotificationVerification:    {ChannelEmail},
+	NotificationPasswordReset:   {ChannelEmail},
+	NotificationLoanSubmitted:   {ChannelEmail, ChannelInApp},
+	NotificationLoanApproved:    {ChannelEmail, ChannelSMS, ChannelInApp},
+	NotificationLoanRejected:    {ChannelEmail, ChannelInApp},
+	NotificationPaymentReceived: {ChannelEmail, ChannelInApp},
+	NotificationPaymentDue:      {ChannelEmail, ChannelSMS, ChannelInApp},
+	NotificationPaymentOverdue:  {ChannelEmail, ChannelSMS, ChannelInApp},
+	NotificationLoanPaidOff:     {ChannelEmail, ChannelInApp},
+	NotificationLoanWrittenOff:  {ChannelEmail, ChannelInApp},
+	NotificationKYCReviewed:     {ChannelEmail, ChannelInApp},
+	NotificationLoanPartyInvite: {ChannelEmail, ChannelInApp},
+	NotificationLoanMention:     {ChannelEmail, ChannelInApp},
+}
+
+// RequiredNotifications are sent through their channels whatever the preferences of the user, they carry account links
+var RequiredNotifications = map[string]bool{
+	NotificationVerification:  true,
+	NotificationPasswordReset: true,
+}
+
+// Notification struct represents something a user is told about.
+// It is queued with its type and data, each channel renders it in the language of the user when it is sent.
+type Notification struct {
+	ID        primitive.ObjectID     `json:"id" bson:"_id"`
+	UserID    primitive.ObjectID     `json:"user_id" bson:"user_id"`
+	Type      string                 `json:"type" bson:"type"`
+	Title     string                 `json:"title,omitempty" bson:"title"`
+	Body      string                 `json:"body,omitempty" bson:"body"`
+	Data      map[string]interface{} `json:"data,omitempty" bson:"data,omitempty"`
+	ReadAt    *time.Time             `json:"read_at,omitempty" bson:"read_at,omitempty"`
+	CreatedAt time.Time              `json:"created_at" bson:"created_at"`
+}
+
+// Notifier represents a channel notifications reach users through
+type Notifier interface {
+	Notify(user User, notification Notification) error
+}
+
+// NotificationRepository represents the repository contract of in-app notifications
+type NotificationRepository interface {
+	AddNotification(c context.Context, notification *Notification) error
+	Notifications(userID string, unreadOnly bool, page int) ([]Notification, error)
+	UnreadCount(userID string) (int64, error)
+	MarkRead(c context.Context, userID, id string) error
+	MarkAllRead(c context.Context, userID string) (int64, error)
+}
+
+// NotificationUsecase represents the in-app notification usecase contract
+type NotificationUsecase interface {
+	Notifications(c context.Context, userid string, unreadOnly bool, page int) ([]Notification, int64, error)
+	MarkRead(c context.Context, userid, id string) error
+	MarkAllRead(c context.Context, userid string) (int64, error)
+}
+
+// NotificationDispatcher represents how notifications are queued for the channels chosen for their type and user
+type NotificationDispatcher interface {
+	Notify(c context.Context, user User, kind string, data map[string]interface{}) error
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Kinds of outbox messages, each kind is delivered by its own sender
+const (
+	OutboxEmail        = "email"
+	OutboxEvent        = "event"
+	OutboxWebhook      = "webhook"
+	OutboxNotification = "notification"
+)
+
+// Delivery statuses of outbox messages
+const (
+	OutboxPending = "pending"
+	OutboxSent    = "sent"
+	OutboxDead    = "dead"
+)
+
+// OutboxMessage struct represents a side effect recorded with the change that caused it and delivered after it is committed.
+// The recipient of an email is its address, the recipient of an event is the subscriber it is delivered to,
+// the recipient of a webhook message is the delivery it stands for and the recipient of a notification is the user it is for,
+// its topic is the channel it goes through.
+type OutboxMessage struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id"`
+	Kind          string             `json:"kind" bson:"kind"`
+	Topic         string             `json:"topic" bson:"topic"`
+	Recipient     string             `json:"recipient,omitempty" bson:"recipient,omitempty"`
+	Payload       string             `json:"payload,omitempty" bson:"payload,omitempty"`
+	Status        string             `json:"status" bson:"status"`
+	Attempts      int                `json:"attempts" bson:"attempts"`
+	LastError     string             `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	NextAttemptAt time.Time          `json:"next_attempt_at" bson:"next_attempt_at"`
+	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
+	SentAt        *time.Time         `json:"sent_at,omitempty" bson:"sent_at,omitempty"`
+}
+
+// EmailPayload struct represents the payload of an email message, what its template is rendered with and in which language.
+// Links carrying tokens are added when the email is sent. Emails are now queued as notifications, the kind remains for messages queued before.
+type EmailPayload struct {
+	Language string                 `json:"language,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+}
+
+// OutboxRepository represents the outbox repository contract
+type OutboxRepository interface {
+	AddMessage(c context.Context, message *OutboxMessage) error
+	ClaimMessages(now time.Time, lease time.Duration, limit int) ([]OutboxMessage, error)
+	UpdateMessage(message *OutboxMessage) error
+	GetMessage(id string) (OutboxMessage, error)
+	DeadMessages(page int) ([]OutboxMessage, error)
+}
+
+// OutboxSender represents what delivers outbox messages of a kind
+type OutboxSender interface {
+	Send(message OutboxMessage) error
+}
+
+// OutboxUsecase represents the outbox usecase contract
+type OutboxUsecase interface {
+	DispatchOutbox(c context.Context) (int, error)
+	DeadMessages(c context.Context, page int) ([]OutboxMessage, error)
+	RetryMessage(c context.Context, id string) error
+}
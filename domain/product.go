@@ -0,0 +1,17 @@
+package domain
+
+// DefaultProduct is the product a loan is booked under when none is given
+const DefaultProduct = "standard"
+
+// LoanProduct struct represents the configurable terms of a loan product.
+// Secured products have a maximum loan-to-value, loans under them need collateral to be approved.
+type LoanProduct struct {
+	Name                string  `json:"name"`
+	GracePeriodDays     int     `json:"grace_period_days"`
+	LateFee             float64 `json:"late_fee"`
+	PenaltyRate         float64 `json:"penalty_rate"`
+	DelinquentAfterDays int     `json:"delinquent_after_days"`
+	DefaultAfterDays    int     `json:"default_after_days"`
+	PrepaymentRate      float64 `json:"prepayment_penalty_rate"`
+	MaxLTV              float64 `json:"max_ltv"`
+}
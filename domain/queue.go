@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Statuses of queued jobs
+const (
+	QueuePending   = "pending"
+	QueueActive    = "active"
+	QueueCompleted = "completed"
+	QueueFailed    = "failed"
+	QueueCancelled = "cancelled"
+)
+
+// JobFailure struct represents a failed attempt at a queued job
+type JobFailure struct {
+	Attempt  int       `json:"attempt" bson:"attempt"`
+	Worker   string    `json:"worker" bson:"worker"`
+	Error    string    `json:"error" bson:"error"`
+	FailedAt time.Time `json:"failed_at" bson:"failed_at"`
+}
+
+// QueuedJob struct represents a unit of background work waiting in the job queue or done with.
+// A job is run when it is due, higher priorities first. An active job whose worker didn't report back
+// before its visibility timeout is due again, so a crashed worker doesn't lose it.
+type QueuedJob struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	Type        string             `json:"type" bson:"type"`
+	Payload     string             `json:"payload,omitempty" bson:"payload,omitempty"`
+	Key         string             `json:"key,omitempty" bson:"key,omitempty"`
+	Priority    int                `json:"priority" bson:"priority"`
+	Status      string             `json:"status" bson:"status"`
+	Attempts    int                `json:"attempts" bson:"attempts"`
+	MaxAttempts int                `json:"max_attempts" bson:"max_attempts"`
+	Visibility  int                `json:"visibility_seconds" bson:"visibility_seconds"`
+	LastError   string             `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	Failures    []JobFailure       `json:"failures,omitempty" bson:"failures,omitempty"`
+	Worker      string             `json:"worker,omitempty" bson:"worker,omitempty"`
+	RunAt       time.Time          `json:"run_at" bson:"run_at"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	StartedAt   *time.Time         `json:"started_at,omitempty" bson:"started_at,omitempty"`
+	FinishedAt  *time.Time         `json:"finished_at,omitempty" bson:"finished_at,omitempty"`
+}
+
+// DecodePayload reads the JSON payload of the job into v
+func (job QueuedJob) DecodePayload(v interface{}) error {
+	if job.Payload == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(job.Payload), v); err != nil {
+		return errors.New("Invalid payload of " + job.Type + " job")
+	}
+	return nil
+}
+
+// JobOptions struct represents how a job is queued. Jobs are due right away unless told otherwise.
+// A job with a key isn't queued while another job with the same key is pending or active.
+type JobOptions struct {
+	Priority    int
+	RunAt       time.Time
+	MaxAttempts int
+	Key         string
+}
+
+// JobFilter narrows the queued jobs listed, empty fields match every job
+type JobFilter struct {
+	Status string
+	Type   string
+	Page   int
+}
+
+// JobHandler runs a queued job, a returned error makes the job be retried until it runs out of attempts
+type JobHandler func(c context.Context, job QueuedJob) error
+
+// QueueRepository represents the job queue repository contract
+type QueueRepository interface {
+	EnqueueJob(c context.Context, job *QueuedJob) (bool, error)
+	ClaimNextJob(types []string, worker string, now time.Time) (QueuedJob, bool, error)
+	FinishJob(job *QueuedJob) error
+	UpdateJob(job *QueuedJob, from string) error
+	GetJob(id string) (QueuedJob, error)
+	ListJobs(filter JobFilter) ([]QueuedJob, error)
+}
+
+// JobQueueUsecase represents the job queue usecase contract
+type JobQueueUsecase interface {
+	Enqueue(c context.Context, jobType string, payload interface{}, options JobOptions) (QueuedJob, error)
+	ListJobs(c context.Context, filter JobFilter) ([]QueuedJob, error)
+	GetJob(c context.Context, id string) (QueuedJob, error)
+	RetryJob(c context.Context, id string) error
+	CancelJob(c context.Context, id string) error
+}
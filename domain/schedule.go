@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Statuses of job runs
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// JobFunc runs a scheduled job and returns how many records it processed
+type JobFunc func(c context.Context) (int, error)
+
+// ScheduledJob struct represents a job the scheduler runs, its cron expression and when it runs next
+type ScheduledJob struct {
+	Name     string    `json:"name"`
+	Schedule string    `json:"schedule"`
+	NextRun  time.Time `json:"next_run"`
+}
+
+// JobRun struct represents one run of a scheduled job and the instance it ran on.
+// A job run by the workers of the queue links the queued job that ran it.
+type JobRun struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	Job         string             `json:"job" bson:"job"`
+	Instance    string             `json:"instance" bson:"instance"`
+	QueuedJob   string             `json:"queued_job,omitempty" bson:"queued_job,omitempty"`
+	ScheduledAt time.Time          `json:"scheduled_at" bson:"scheduled_at"`
+	Status      string             `json:"status" bson:"status"`
+	Processed   int                `json:"processed" bson:"processed"`
+	Error       string             `json:"error,omitempty" bson:"error,omitempty"`
+	StartedAt   time.Time          `json:"started_at" bson:"started_at"`
+	FinishedAt  *time.Time         `json:"finished_at,omitempty" bson:"finished_at,omitempty"`
+}
+
+// SchedulerRepository represents the scheduler repository contract.
+// A job is claimed by one instance for each minute it is scheduled at, and held until its run ends or the lease runs out.
+type SchedulerRepository interface {
+	ClaimJob(c context.Context, job string, scheduledAt time.Time, instance string, lease time.Duration) (bool, error)
+	ReleaseJob(c context.Context, job, instance string) error
+	AddJobRun(c context.Context, run *JobRun) error
+	FinishJobRun(c context.Context, run *JobRun) error
+	JobRuns(job string, page int) ([]JobRun, error)
+}
+
+// SchedulerUsecase represents the scheduler usecase contract
+type SchedulerUsecase interface {
+	Jobs(c context.Context) []ScheduledJob
+	JobRuns(c context.Context, job string, page int) ([]JobRun, error)
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StreamNotification is the name of the stream events pushing in-app notifications
+const StreamNotification = "notification.created"
+
+// StreamTruncated is the name of the event telling a resuming client it missed more events than were replayed
+const StreamTruncated = "stream.truncated"
+
+// StreamedEvents lists the domain events pushed to the event streams
+var StreamedEvents = []string{
+	EventLoanApplied, EventLoanApproved, EventLoanRejected, EventLoanDelinquencyChanged, EventLoanRestructured,
+	EventLoanWrittenOff, EventLoanPaidOff, EventPaymentReceived, EventPaymentDue, EventPaymentOverdue,
+}
+
+// StreamEvent struct represents an entry of the event log clients of the event streams read from.
+// The user is who the event is pushed to besides admins, the source is the domain event or notification it was recorded from.
+type StreamEvent struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	SourceID  primitive.ObjectID `json:"-" bson:"source_id"`
+	Name      string             `json:"name" bson:"name"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Data      string             `json:"data" bson:"data"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// StreamSubscription struct represents a client of an event stream, the events it missed and the events still to come.
+// The events channel is closed when the client falls too far behind, it is expected to reconnect and resume.
+// Truncated is set when the client missed more events than are replayed, the oldest are replayed and the rest are lost.
+type StreamSubscription struct {
+	Replay    []StreamEvent
+	Truncated bool
+	Events    <-chan StreamEvent
+	Close     func()
+}
+
+// StreamRepository represents the event log repository contract
+type StreamRepository interface {
+	AddStreamEvent(c context.Context, event *StreamEvent) error
+	StreamEventsAfter(lastID, userID string, limit int) ([]StreamEvent, error)
+	RecentStreamEvents(since time.Time, limit int) ([]StreamEvent, error)
+}
+
+// EventStream represents the event streams contract.
+// Events are recorded in the log and pushed to the clients of every instance reading it.
+type EventStream interface {
+	RecordEvent(c context.Context, event Event) error
+	RecordNotification(c context.Context, notification Notification) error
+	Subscribe(c context.Context, userid string, all bool, lastEventID string) (*StreamSubscription, error)
+}
@@ -0,0 +1,9 @@
+package domain
+
+import "context"
+
+// UnitOfWork runs a group of writes so they are applied together or not at all.
+// Repositories take part in the unit through the context handed to work.
+type UnitOfWork interface {
+	Do(c context.Context, work func(c context.Context) error) error
+}
@@ -19,6 +19,11 @@ type User struct {
 	JoinedAt     time.Time          `json:"joinedat"`
 	RefreshToken string             `json:"refreshtoken"`
 	IsVerified   bool               `json:"isverified"`
+	KYCStatus    string             `json:"kyc_status"`
+	Language     string             `json:"language,omitempty"`
+	// channels the user chose for each type of notification, types they didn't choose for use the configured channels
+	NotificationChannels map[string][]string `json:"notification_channels,omitempty"`
+	Deletion             *Deletion           `json:"deletion,omitempty"`
 	// Oauth        bool               `json:"oauth,omitempty"`
 }
 
@@ -38,19 +43,24 @@ type UserUsecase interface {
 	UpdateUserDetails(c context.Context, user *User) error
 	LogoutUser(c context.Context, uid string) error
 	ViewAllUsers(c context.Context) ([]User, error)
-	DeleteUser(c context.Context, uid string) error
+	DeleteUser(c context.Context, uid, reason, adminid string) error
+	RestoreUser(c context.Context, uid, adminid string) error
+	PurgeDeletedUsers(c context.Context, retention time.Duration) (int, error)
 }
 
 type UserRepository interface {
-	RegisterUser(user *User) error
-	VerifyUserEmail(token string) error
-	LoginUser(user User) (string, string, error)
+	RegisterUser(c context.Context, user *User) error
+	VerifyUserEmail(c context.Context, token string) (User, error)
+	LoginUser(c context.Context, user User) (string, string, error)
 	TokenRefresh(uid string) (string, error)
 	UserProfile(uid string) (User, error)
-	ForgotPassword(email string) error
-	ResetPassword(token string, newPassword string) error
-	UpdateUserDetails(user *User) error
-	LogoutUser(uid string) error
+	UserByEmail(email string) (User, error)
+	ResetPassword(c context.Context, token string, newPassword string) (User, error)
+	UpdateUserDetails(c context.Context, user *User) error
+	LogoutUser(c context.Context, uid string) error
 	ViewAllUsers() ([]User, error)
-	DeleteUser(uid string) error
+	DeleteUser(c context.Context, user *User) error
+	RestoreUser(c context.Context, uid string) error
+	PurgeDeletedUsers(c context.Context, before time.Time) ([]primitive.ObjectID, error)
+	SetKYCStatus(c context.Context, uid, status string) error
 }
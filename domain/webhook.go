@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// the event sent by POST /admin/webhooks/:id/test
+const EventWebhookTest = "webhook.test"
+
+// Statuses of webhook deliveries
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook struct represents an endpoint of another system that is sent the domain events it subscribed to.
+// The secret signs every payload, it is only shown when the webhook is created.
+type Webhook struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	URL       string             `json:"url" bson:"url"`
+	Events    []string           `json:"events" bson:"events"`
+	Secret    string             `json:"secret,omitempty" bson:"secret"`
+	Active    bool               `json:"active" bson:"active"`
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// DeliveryAttempt struct represents one attempt at posting a payload to a webhook
+type DeliveryAttempt struct {
+	AttemptedAt time.Time `json:"attempted_at" bson:"attempted_at"`
+	StatusCode  int       `json:"status_code" bson:"status_code"`
+	Error       string    `json:"error,omitempty" bson:"error,omitempty"`
+	DurationMS  int64     `json:"duration_ms" bson:"duration_ms"`
+}
+
+// WebhookDelivery struct represents an event sent to a webhook and every attempt made at it
+type WebhookDelivery struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	WebhookID   primitive.ObjectID `json:"webhook_id" bson:"webhook_id"`
+	EventID     primitive.ObjectID `json:"event_id" bson:"event_id"`
+	Event       string             `json:"event" bson:"event"`
+	Payload     string             `json:"payload" bson:"payload"`
+	Status      string             `json:"status" bson:"status"`
+	Attempts    []DeliveryAttempt  `json:"attempts" bson:"attempts"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	DeliveredAt *time.Time         `json:"delivered_at,omitempty" bson:"delivered_at,omitempty"`
+}
+
+// WebhookRepository represents the webhook repository contract
+type WebhookRepository interface {
+	AddWebhook(webhook *Webhook) error
+	UpdateWebhook(webhook *Webhook) error
+	DeleteWebhook(id string) error
+	GetWebhook(id string) (Webhook, error)
+	ListWebhooks() ([]Webhook, error)
+	WebhooksFor(event string) ([]Webhook, error)
+	AddDelivery(c context.Context, delivery *WebhookDelivery) error
+	GetDelivery(id string) (WebhookDelivery, error)
+	RecordAttempt(id primitive.ObjectID, attempt DeliveryAttempt, status string) error
+	Deliveries(webhookID string, page int) ([]WebhookDelivery, error)
+}
+
+// WebhookClient represents how payloads are posted to webhooks
+type WebhookClient interface {
+	Post(url string, headers map[string]string, body []byte) (int, error)
+}
+
+// WebhookUsecase represents the webhook usecase contract
+type WebhookUsecase interface {
+	CreateWebhook(c context.Context, webhook *Webhook) error
+	ListWebhooks(c context.Context) ([]Webhook, error)
+	UpdateWebhook(c context.Context, webhook *Webhook) error
+	DeleteWebhook(c context.Context, id string) error
+	TestWebhook(c context.Context, id string) (WebhookDelivery, error)
+	Deliveries(c context.Context, webhookID string, page int) ([]WebhookDelivery, error)
+	Redeliver(c context.Context, webhookID, deliveryID string) error
+}
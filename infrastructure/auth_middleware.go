@@ -54,7 +54,7 @@ func AuthMiddleware(client *mongo.Client) gin.HandlerFunc {
 		collection := client.Database("Loan-Tracker").Collection("Users")
 		log.Println("Claims: ", claims)
 		uid, _ := primitive.ObjectIDFromHex(claims.UserID)
-		filter := bson.M{"_id": uid} // Assuming UserID is the _id in the database
+		filter := bson.M{"_id": uid, "deletion": nil} // Assuming UserID is the _id in the database
 
 		var user domain.User
 		err = collection.FindOne(context.TODO(), filter).Decode(&user)
@@ -113,3 +113,16 @@ func AdminMiddleware(c *gin.Context) {
 
 	c.Next()
 }
+
+// RequestIDMiddleware tags every request with an ID, a caller supplied X-Request-ID is kept so requests can be traced across services
+func RequestIDMiddleware(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+	if requestID == "" {
+		requestID = primitive.NewObjectID().Hex()
+	}
+
+	c.Set("requestid", requestID)
+	c.Header("X-Request-ID", requestID)
+
+	c.Next()
+}
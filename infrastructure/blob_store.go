@@ -0,0 +1,295 @@
+package infrastructure
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"loan_tracker_api/domain"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LocalBlobStore keeps blobs as files under a directory
+type LocalBlobStore struct {
+	dir string
+}
+
+// NewLocalBlobStore creates a new instance of LocalBlobStore, the directory is created when missing
+func NewLocalBlobStore(dir string) domain.BlobStore {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		log.Fatal("Error creating blob directory: ", err)
+	}
+	return &LocalBlobStore{dir: dir}
+}
+
+// path returns the file a key is stored in, keys can't reach outside the directory
+func (bs *LocalBlobStore) path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	if cleaned == "/" {
+		return "", errors.New("Invalid blob key")
+	}
+	return filepath.Join(bs.dir, filepath.FromSlash(cleaned)), nil
+}
+
+// Put writes a blob to a temporary file first, so a failed write never leaves a partial blob behind
+func (bs *LocalBlobStore) Put(c context.Context, key string, content io.Reader, size int64, contentType string) error {
+	path, err := bs.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return errors.New("Error storing blob")
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return errors.New("Error storing blob")
+	}
+	defer os.Remove(file.Name())
+
+	_, err = io.Copy(file, content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.New("Error storing blob")
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return errors.New("Error storing blob")
+	}
+
+	return nil
+}
+
+func (bs *LocalBlobStore) Open(c context.Context, key string) (io.ReadCloser, error) {
+	path, err := bs.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, errors.New("Blob not found")
+	}
+	if err != nil {
+		return nil, errors.New("Error reading blob")
+	}
+
+	return file, nil
+}
+
+func (bs *LocalBlobStore) Delete(c context.Context, key string) error {
+	path, err := bs.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.New("Error deleting blob")
+	}
+
+	return nil
+}
+
+// S3BlobStore keeps blobs as objects of a bucket of an S3 compatible store, such as AWS S3 or MinIO.
+// Buckets are addressed by path, requests are signed with AWS signature version 4.
+type S3BlobStore struct {
+	endpoint  *url.URL
+	bucket    string
+	region    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+}
+
+// NewS3BlobStore creates a new instance of S3BlobStore, requests taking longer than the timeout fail
+func NewS3BlobStore(endpoint, bucket, region, accessKey, secretKey string, timeout time.Duration) (domain.BlobStore, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, errors.New("Invalid S3 endpoint")
+	}
+	if bucket == "" {
+		return nil, errors.New("Missing S3 bucket")
+	}
+
+	return &S3BlobStore{
+		endpoint:  parsed,
+		bucket:    bucket,
+		region:    region,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		client:    &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// s3Escape encodes a path the way signature version 4 expects, keeping the slashes between its segments
+func s3Escape(path string) string {
+	var escaped strings.Builder
+	for _, b := range []byte(path) {
+		if b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || strings.IndexByte("-._~/", b) >= 0 {
+			escaped.WriteByte(b)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", b)
+		}
+	}
+	return escaped.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// request builds a signed request for an object. The payload is left unsigned so uploads can be streamed.
+func (bs *S3BlobStore) request(c context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	path := strings.TrimSuffix(bs.endpoint.Path, "/") + "/" + bs.bucket + "/" + strings.TrimPrefix(key, "/")
+	escaped := s3Escape(path)
+
+	request, err := http.NewRequestWithContext(c, method, bs.endpoint.Scheme+"://"+bs.endpoint.Host+escaped, body)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := "UNSIGNED-PAYLOAD"
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{
+		"host":                 bs.endpoint.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{method, escaped, "", canonicalHeaders.String(), signedHeaders, payloadHash}, "\n")
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := day + "/" + bs.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+bs.secretKey), day)
+	signingKey = hmacSHA256(signingKey, bs.region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", bs.accessKey, scope, signedHeaders, signature))
+
+	return request, nil
+}
+
+func (bs *S3BlobStore) Put(c context.Context, key string, content io.Reader, size int64, contentType string) error {
+	request, err := bs.request(c, http.MethodPut, key, content)
+	if err != nil {
+		return errors.New("Error storing blob")
+	}
+	request.ContentLength = size
+	request.Header.Set("Content-Type", contentType)
+
+	response, err := bs.client.Do(request)
+	if err != nil {
+		return errors.New("Error storing blob")
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("Error storing blob: %s", response.Status)
+	}
+
+	return nil
+}
+
+func (bs *S3BlobStore) Open(c context.Context, key string) (io.ReadCloser, error) {
+	request, err := bs.request(c, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, errors.New("Error reading blob")
+	}
+
+	response, err := bs.client.Do(request)
+	if err != nil {
+		return nil, errors.New("Error reading blob")
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		response.Body.Close()
+		return nil, errors.New("Blob not found")
+	}
+	if response.StatusCode/100 != 2 {
+		response.Body.Close()
+		return nil, fmt.Errorf("Error reading blob: %s", response.Status)
+	}
+
+	return response.Body, nil
+}
+
+// Delete removes an object, deleting one that doesn't exist succeeds
+func (bs *S3BlobStore) Delete(c context.Context, key string) error {
+	request, err := bs.request(c, http.MethodDelete, key, nil)
+	if err != nil {
+		return errors.New("Error deleting blob")
+	}
+
+	response, err := bs.client.Do(request)
+	if err != nil {
+		return errors.New("Error deleting blob")
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode/100 != 2 && response.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("Error deleting blob: %s", response.Status)
+	}
+
+	return nil
+}
+
+// loads the blob store selected by BLOB_STORE, files under BLOB_DIR unless it is "s3"
+func LoadBlobStore() domain.BlobStore {
+	switch store := DotEnvLookup("BLOB_STORE", "local"); store {
+	case "local":
+		return NewLocalBlobStore(DotEnvLookup("BLOB_DIR", "documents"))
+	case "s3":
+		blobs, err := NewS3BlobStore(
+			DotEnvLookup("S3_ENDPOINT", "https://s3.amazonaws.com"),
+			DotEnvLookup("S3_BUCKET", ""),
+			DotEnvLookup("S3_REGION", "us-east-1"),
+			DotEnvLookup("S3_ACCESS_KEY", ""),
+			DotEnvLookup("S3_SECRET_KEY", ""),
+			30*time.Second,
+		)
+		if err != nil {
+			log.Fatal("Invalid S3 blob store: ", err)
+		}
+		return blobs
+	default:
+		log.Fatal("Invalid BLOB_STORE: ", store)
+		return nil
+	}
+}
@@ -0,0 +1,133 @@
+package infrastructure_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/infrastructure"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// s3StandIn is an in-memory stand-in for an S3 compatible store, it keeps objects by path and checks requests are signed
+type s3StandIn struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (s *s3StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=access/") ||
+		!strings.Contains(authorization, "/eu-west-1/s3/aws4_request") ||
+		!strings.Contains(authorization, "SignedHeaders=host;x-amz-content-sha256;x-amz-date") ||
+		r.Header.Get("X-Amz-Date") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		s.objects[r.URL.Path] = body
+		s.types[r.URL.Path] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		body, ok := s.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(body)
+	case http.MethodDelete:
+		delete(s.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+type BlobStoreTestSuite struct {
+	suite.Suite
+	standIn *s3StandIn
+	server  *httptest.Server
+}
+
+func (suite *BlobStoreTestSuite) SetupTest() {
+	suite.standIn = &s3StandIn{objects: map[string][]byte{}, types: map[string]string{}}
+	suite.server = httptest.NewServer(suite.standIn)
+}
+
+func (suite *BlobStoreTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+// roundTrip stores, reads back and deletes a blob
+func (suite *BlobStoreTestSuite) roundTrip(blobs domain.BlobStore, key string) {
+	content := []byte("%PDF-1.4\n")
+	suite.Require().NoError(blobs.Put(context.Background(), key, bytes.NewReader(content), int64(len(content)), "application/pdf"))
+
+	reader, err := blobs.Open(context.Background(), key)
+	suite.Require().NoError(err)
+	stored, _ := io.ReadAll(reader)
+	reader.Close()
+	suite.Equal(content, stored)
+
+	suite.NoError(blobs.Delete(context.Background(), key))
+	_, err = blobs.Open(context.Background(), key)
+	suite.EqualError(err, "Blob not found")
+
+	// deleting a blob that is gone succeeds
+	suite.NoError(blobs.Delete(context.Background(), key))
+}
+
+func (suite *BlobStoreTestSuite) TestS3BlobStore() {
+	blobs, err := infrastructure.NewS3BlobStore(suite.server.URL, "documents", "eu-west-1", "access", "secret", 5*time.Second)
+	suite.Require().NoError(err)
+
+	suite.roundTrip(blobs, "loans/66f1c0e5a7b3c2d1e0f9a8b7/report 1.pdf")
+
+	suite.Equal("application/pdf", suite.standIn.types["/documents/loans/66f1c0e5a7b3c2d1e0f9a8b7/report 1.pdf"])
+}
+
+func (suite *BlobStoreTestSuite) TestS3BlobStoreRejectedRequest() {
+	blobs, err := infrastructure.NewS3BlobStore(suite.server.URL, "documents", "us-east-1", "access", "secret", 5*time.Second)
+	suite.Require().NoError(err)
+
+	err = blobs.Put(context.Background(), "key", strings.NewReader("data"), 4, "text/plain")
+
+	suite.EqualError(err, "Error storing blob: 403 Forbidden")
+}
+
+func (suite *BlobStoreTestSuite) TestS3BlobStoreInvalidEndpoint() {
+	_, err := infrastructure.NewS3BlobStore("s3.local", "documents", "us-east-1", "access", "secret", 5*time.Second)
+
+	suite.EqualError(err, "Invalid S3 endpoint")
+}
+
+func (suite *BlobStoreTestSuite) TestLocalBlobStore() {
+	dir := suite.T().TempDir()
+	blobs := infrastructure.NewLocalBlobStore(dir)
+
+	suite.roundTrip(blobs, "loans/66f1c0e5a7b3c2d1e0f9a8b7/66f1c0e5a7b3c2d1e0f9a8b8")
+
+	// keys can't reach outside the directory
+	suite.NoError(blobs.Put(context.Background(), "../../escaped", strings.NewReader("data"), 4, "text/plain"))
+	_, err := os.Stat(filepath.Join(dir, "escaped"))
+	suite.NoError(err)
+	_, err = os.Stat(filepath.Join(filepath.Dir(dir), "escaped"))
+	suite.True(os.IsNotExist(err))
+}
+
+func TestBlobStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(BlobStoreTestSuite))
+}
@@ -0,0 +1,80 @@
+package infrastructure
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"loan_tracker_api/domain"
+	"os"
+	"sync"
+)
+
+// FileCheckpointStore anchors audit checkpoints in an append-only JSON Lines file kept outside the database
+type FileCheckpointStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileCheckpointStore creates a checkpoint store writing to the given file
+func NewFileCheckpointStore(path string) domain.CheckpointStore {
+	return &FileCheckpointStore{path: path}
+}
+
+// AppendCheckpoint adds a checkpoint at the end of the file
+func (fs *FileCheckpointStore) AppendCheckpoint(checkpoint domain.LogCheckpoint) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	line, err := json.Marshal(checkpoint)
+	if err != nil {
+		return errors.New("Error encoding checkpoint")
+	}
+
+	file, err := os.OpenFile(fs.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.New("Error opening checkpoint file")
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return errors.New("Error writing checkpoint")
+	}
+
+	return file.Sync()
+}
+
+// Checkpoints returns every checkpoint in the file, oldest first
+func (fs *FileCheckpointStore) Checkpoints() ([]domain.LogCheckpoint, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	checkpoints := []domain.LogCheckpoint{}
+
+	file, err := os.Open(fs.path)
+	if os.IsNotExist(err) {
+		return checkpoints, nil
+	}
+	if err != nil {
+		return nil, errors.New("Error opening checkpoint file")
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var checkpoint domain.LogCheckpoint
+		if err := json.Unmarshal(scanner.Bytes(), &checkpoint); err != nil {
+			return nil, errors.New("Error reading checkpoint file")
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.New("Error reading checkpoint file")
+	}
+
+	return checkpoints, nil
+}
@@ -21,3 +21,15 @@ func DotEnvLoader(identifier string) string {
 
 	return result
 }
+
+// optional dotenv loader function, falls back to the given value when the entry is missing
+func DotEnvLookup(identifier string, fallback string) string {
+	godotenv.Load()
+	result, exists := os.LookupEnv(identifier)
+
+	if !exists || result == "" {
+		return fallback
+	}
+
+	return result
+}
@@ -0,0 +1,65 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"errors"
+	"loan_tracker_api/domain"
+	"net/url"
+)
+
+// EmailNotifier sends notifications as emails with their text and HTML versions.
+// Links are generated when the email is sent so they stay valid for their whole lifetime whatever the delay.
+type EmailNotifier struct {
+	templates *NotificationTemplates
+}
+
+// NewEmailNotifier creates a new instance of EmailNotifier
+func NewEmailNotifier(templates *NotificationTemplates) *EmailNotifier {
+	return &EmailNotifier{
+		templates: templates,
+	}
+}
+
+// Notify emails the notification to the user in their preferred language
+func (en *EmailNotifier) Notify(user domain.User, notification domain.Notification) error {
+	return en.send(user.Email, user.Language, notification.Type, notification.Data)
+}
+
+// Send sends an email queued in the outbox before emails were queued as notifications
+func (en *EmailNotifier) Send(message domain.OutboxMessage) error {
+	var payload domain.EmailPayload
+	if message.Payload != "" {
+		if err := json.Unmarshal([]byte(message.Payload), &payload); err != nil {
+			return errors.New("Invalid email payload")
+		}
+	}
+
+	return en.send(message.Recipient, payload.Language, message.Topic, payload.Data)
+}
+
+// send renders the email in the language and sends it to the address
+func (en *EmailNotifier) send(address, language, kind string, data map[string]interface{}) error {
+	values := make(map[string]interface{}, len(data)+1)
+	for key, value := range data {
+		values[key] = value
+	}
+
+	switch kind {
+	case domain.NotificationVerification:
+		values["Link"] = en.templates.Link("/user/verify-email?token=" + url.QueryEscape(EmailToken(address)))
+	case domain.NotificationPasswordReset:
+		values["Link"] = en.templates.Link("/user/password-update?token=" + url.QueryEscape(EmailToken(address)))
+	}
+
+	email, err := en.templates.Render(kind, language, values)
+	if err != nil {
+		return err
+	}
+
+	emailConfig, err := NewEmailConfig()
+	if err != nil {
+		return err
+	}
+
+	return NewEmailService(emailConfig).SendEmail(address, email)
+}
@@ -2,7 +2,6 @@ package infrastructure
 
 import (
 	"fmt"
-	"net/url"
 	"strconv"
 
 	"gopkg.in/gomail.v2"
@@ -14,6 +13,7 @@ type EmailConfig struct {
 	SMTPPort       int
 	SenderEmail    string
 	SenderPassword string
+	SenderName     string
 }
 
 // NewEmailConfig initializes and returns a new EmailConfig instance.
@@ -28,6 +28,7 @@ func NewEmailConfig() (*EmailConfig, error) {
 		SMTPPort:       port,
 		SenderEmail:    DotEnvLoader("SMTPUSER"),
 		SenderPassword: DotEnvLoader("SMTPPASS"),
+		SenderName:     DotEnvLookup("BRAND_NAME", defaultBrandName),
 	}, nil
 }
 
@@ -41,35 +42,16 @@ func NewEmailService(config *EmailConfig) *EmailService {
 	return &EmailService{config: config}
 }
 
-// SendResetEmail sends the password reset email using gomail.
-func (es *EmailService) SendResetEmail(userEmail, resetToken string) error {
-	resetURL := fmt.Sprintf("http://localhost:8080/user/password-update?token=%s", url.QueryEscape(resetToken))
-	body := fmt.Sprintf(
-		"Click the following link to reset your password:\n%s\n\n"+
-			"If you did not request a password reset, please ignore this email.",
-		resetURL)
-
-	return es.sendEmail(userEmail, "Password Reset Request", body)
-}
-
-// SendVerificationEmail sends the email verification email using gomail.
-func (es *EmailService) SendVerificationEmail(userEmail, verificationToken string) error {
-	verificationURL := fmt.Sprintf("http://localhost:8080/user/verify-email?token=%s", verificationToken)
-	body := fmt.Sprintf(
-		"Click the following link to verify your email:\n%s\n\n"+
-			"If you did not sign up for this account, please ignore this email.",
-		verificationURL)
-
-	return es.sendEmail(userEmail, "Email Verification", body)
-}
-
-// sendEmail is a helper method to send an email with the given subject and body.
-func (es *EmailService) sendEmail(toEmail, subject, body string) error {
+// SendEmail sends a rendered email with its text and HTML alternatives using gomail.
+func (es *EmailService) SendEmail(toEmail string, email RenderedNotification) error {
 	m := gomail.NewMessage()
-	m.SetHeader("From", fmt.Sprintf("A2SV G55-G2 Blog <%s>", es.config.SenderEmail))
+	m.SetHeader("From", m.FormatAddress(es.config.SenderEmail, es.config.SenderName))
 	m.SetHeader("To", toEmail)
-	m.SetHeader("Subject", subject)
-	m.SetBody("text/plain", body)
+	m.SetHeader("Subject", email.Subject)
+	m.SetBody("text/plain", email.Text)
+	if email.HTML != "" {
+		m.AddAlternative("text/html", email.HTML)
+	}
 
 	d := gomail.NewDialer(es.config.SMTPHost, es.config.SMTPPort, es.config.SenderEmail, es.config.SenderPassword)
 
@@ -0,0 +1,23 @@
+package infrastructure
+
+import (
+	"context"
+	"expvar"
+	"loan_tracker_api/domain"
+)
+
+// EventMetrics counts the domain events handed to it, the counts are published with expvar as domain_events
+type EventMetrics struct {
+	counts *expvar.Map
+}
+
+// NewEventMetrics creates a new instance of EventMetrics, there can only be one per process
+func NewEventMetrics() *EventMetrics {
+	return &EventMetrics{counts: expvar.NewMap("domain_events")}
+}
+
+// CountEvent counts an event under its name
+func (em *EventMetrics) CountEvent(c context.Context, event domain.Event) error {
+	em.counts.Add(event.EventName(), 1)
+	return nil
+}
@@ -0,0 +1,44 @@
+package infrastructure
+
+import (
+	"context"
+	"loan_tracker_api/domain"
+)
+
+// InAppNotifier stores notifications in the inbox of the user with their subject as title and their short version as body,
+// and pushes them to the event stream of the user
+type InAppNotifier struct {
+	templates        *NotificationTemplates
+	notificationRepo domain.NotificationRepository
+	stream           domain.EventStream
+}
+
+// NewInAppNotifier creates a new instance of InAppNotifier
+func NewInAppNotifier(templates *NotificationTemplates, notificationrepo domain.NotificationRepository, stream domain.EventStream) domain.Notifier {
+	return &InAppNotifier{
+		templates:        templates,
+		notificationRepo: notificationrepo,
+		stream:           stream,
+	}
+}
+
+// Notify renders the notification in the language of the user and adds it to their inbox
+func (in *InAppNotifier) Notify(user domain.User, notification domain.Notification) error {
+	rendered, err := in.templates.Render(notification.Type, user.Language, notification.Data)
+	if err != nil {
+		return err
+	}
+
+	notification.UserID = user.ID
+	notification.Title = rendered.Subject
+	notification.Body = rendered.Short
+	if notification.Body == "" {
+		notification.Body = rendered.Text
+	}
+
+	if err := in.notificationRepo.AddNotification(context.Background(), &notification); err != nil {
+		return err
+	}
+
+	return in.stream.RecordNotification(context.Background(), notification)
+}
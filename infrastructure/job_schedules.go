@@ -0,0 +1,43 @@
+package infrastructure
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadJobSchedule returns the cron expression of a scheduled job, JOB_SCHEDULE_<JOB> overrides the default,
+// for example JOB_SCHEDULE_PAYMENT_REMINDERS="0 9 * * 1-5"
+func LoadJobSchedule(job, fallback string) string {
+	return DotEnvLookup("JOB_SCHEDULE_"+strings.ToUpper(job), fallback)
+}
+
+// LoadJobLocation returns the time zone cron expressions are read in, JOB_TIMEZONE overrides UTC
+func LoadJobLocation() *time.Location {
+	location, err := time.LoadLocation(DotEnvLookup("JOB_TIMEZONE", "UTC"))
+	if err != nil {
+		log.Fatal("Invalid JOB_TIMEZONE: ", err)
+	}
+	return location
+}
+
+// LoadDays returns a comma separated list of day counts such as "7,3,1", sorted
+func LoadDays(name, fallback string) []int {
+	days := []int{}
+	for _, value := range strings.Split(DotEnvLookup(name, fallback), ",") {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+
+		day, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || day < 0 {
+			log.Fatal("Invalid "+name+" entry: ", value)
+		}
+		days = append(days, day)
+	}
+
+	sort.Ints(days)
+	return days
+}
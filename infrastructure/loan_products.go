@@ -0,0 +1,45 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"loan_tracker_api/domain"
+	"log"
+	"os"
+)
+
+// the product used when no LOAN_PRODUCTS_FILE is configured
+var defaultProducts = []domain.LoanProduct{
+	{
+		Name:                domain.DefaultProduct,
+		GracePeriodDays:     5,
+		LateFee:             25,
+		PenaltyRate:         0.1,
+		DelinquentAfterDays: 1,
+		DefaultAfterDays:    90,
+		PrepaymentRate:      0.01,
+	},
+}
+
+// loads the loan products from the JSON file referenced by LOAN_PRODUCTS_FILE
+func LoadLoanProducts() map[string]domain.LoanProduct {
+	products := defaultProducts
+
+	if path := DotEnvLookup("LOAN_PRODUCTS_FILE", ""); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatal("Error reading loan products file: ", err)
+		}
+
+		products = nil
+		if err := json.Unmarshal(data, &products); err != nil {
+			log.Fatal("Error parsing loan products file: ", err)
+		}
+	}
+
+	catalog := make(map[string]domain.LoanProduct, len(products))
+	for _, product := range products {
+		catalog[product.Name] = product
+	}
+
+	return catalog
+}
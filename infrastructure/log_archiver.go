@@ -0,0 +1,174 @@
+package infrastructure
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"loan_tracker_api/domain"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the retention of each category when AUDIT_RETENTION_DAYS doesn't override it
+var defaultRetentionDays = map[string]int{
+	domain.CategoryAuth:  90,
+	domain.CategoryUser:  5 * 365,
+	domain.CategoryLoan:  7 * 365,
+	domain.CategoryAudit: 7 * 365,
+}
+
+// loads the audit retention policy, AUDIT_RETENTION_DAYS overrides categories as in "auth=30,loan=3650"
+func LoadRetentionPolicy() domain.RetentionPolicy {
+	days := make(map[string]int, len(defaultRetentionDays))
+	for category, retention := range defaultRetentionDays {
+		days[category] = retention
+	}
+
+	if overrides := DotEnvLookup("AUDIT_RETENTION_DAYS", ""); overrides != "" {
+		for _, override := range strings.Split(overrides, ",") {
+			category, value, found := strings.Cut(strings.TrimSpace(override), "=")
+			if _, known := domain.ActionCategories[category]; !found || !known {
+				log.Fatal("Invalid AUDIT_RETENTION_DAYS entry: ", override)
+			}
+
+			retention, err := strconv.Atoi(value)
+			if err != nil || retention <= 0 {
+				log.Fatal("Invalid AUDIT_RETENTION_DAYS entry: ", override)
+			}
+			days[category] = retention
+		}
+	}
+
+	policy := make(domain.RetentionPolicy, len(days))
+	for category, retention := range days {
+		policy[category] = time.Duration(retention) * 24 * time.Hour
+	}
+
+	return policy
+}
+
+// FileLogArchiver writes archived audit entries to gzip compressed JSON Lines files in a local directory
+type FileLogArchiver struct {
+	dir string
+}
+
+// NewFileLogArchiver creates an archiver writing to the given directory
+func NewFileLogArchiver(dir string) domain.LogArchiver {
+	return &FileLogArchiver{dir: dir}
+}
+
+// ArchiveLogs writes the entries to a new archive file named after the chain range it holds and returns its path
+func (fa *FileLogArchiver) ArchiveLogs(entries []domain.Log) (string, error) {
+	if len(entries) == 0 {
+		return "", errors.New("No entries to archive")
+	}
+
+	if err := os.MkdirAll(fa.dir, 0700); err != nil {
+		return "", errors.New("Error creating archive directory")
+	}
+
+	name := fmt.Sprintf("audit-%d-%d-%s.jsonl.gz", entries[0].Sequence, entries[len(entries)-1].Sequence, time.Now().UTC().Format("20060102T150405"))
+	path := filepath.Join(fa.dir, name)
+
+	// write to a temporary file first so a half written archive never looks complete
+	file, err := os.OpenFile(path+".tmp", os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", errors.New("Error creating archive file")
+	}
+
+	zipper := gzip.NewWriter(file)
+	encoder := json.NewEncoder(zipper)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			file.Close()
+			os.Remove(path + ".tmp")
+			return "", errors.New("Error writing archive file")
+		}
+	}
+
+	if err := zipper.Close(); err != nil {
+		file.Close()
+		os.Remove(path + ".tmp")
+		return "", errors.New("Error writing archive file")
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(path + ".tmp")
+		return "", errors.New("Error writing archive file")
+	}
+	file.Close()
+
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return "", errors.New("Error writing archive file")
+	}
+
+	return path, nil
+}
+
+// ArchivedLogs reads back the archived entries with sequences from the first to the last given, from every file holding part of that range
+func (fa *FileLogArchiver) ArchivedLogs(from, to int64) ([]domain.Log, error) {
+	files, err := os.ReadDir(fa.dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.New("Error reading archive directory")
+	}
+
+	entries := []domain.Log{}
+	for _, file := range files {
+		var first, last int64
+		var stamp string
+		if _, err := fmt.Sscanf(strings.ReplaceAll(file.Name(), "-", " "), "audit %d %d %s", &first, &last, &stamp); err != nil {
+			continue
+		}
+		if !strings.HasSuffix(stamp, ".jsonl.gz") || last < from || first > to {
+			continue
+		}
+
+		archived, err := readArchive(filepath.Join(fa.dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range archived {
+			if entry.Sequence >= from && entry.Sequence <= to {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// readArchive decodes every entry of an archive file
+func readArchive(path string) ([]domain.Log, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.New("Error opening archive file")
+	}
+	defer file.Close()
+
+	unzipper, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, errors.New("Error reading archive file")
+	}
+	defer unzipper.Close()
+
+	entries := []domain.Log{}
+	decoder := json.NewDecoder(unzipper)
+	for {
+		var entry domain.Log
+		err := decoder.Decode(&entry)
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, errors.New("Error reading archive file")
+		}
+		entries = append(entries, entry)
+	}
+}
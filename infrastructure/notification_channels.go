@@ -0,0 +1,39 @@
+package infrastructure
+
+import (
+	"loan_tracker_api/domain"
+	"log"
+	"strings"
+)
+
+// LoadNotificationChannels returns the channels of each notification type, NOTIFICATION_CHANNELS overrides them as
+// comma separated type=channel|channel entries such as "payment_due=email|sms,loan_submitted=in_app"
+func LoadNotificationChannels() map[string][]string {
+	channels := make(map[string][]string, len(domain.DefaultChannels))
+	for kind, defaults := range domain.DefaultChannels {
+		channels[kind] = defaults
+	}
+
+	if overrides := DotEnvLookup("NOTIFICATION_CHANNELS", ""); overrides != "" {
+		for _, override := range strings.Split(overrides, ",") {
+			kind, value, found := strings.Cut(strings.TrimSpace(override), "=")
+			if _, known := domain.DefaultChannels[kind]; !found || !known {
+				log.Fatal("Invalid NOTIFICATION_CHANNELS entry: ", override)
+			}
+
+			chosen := []string{}
+			for _, channel := range strings.Split(value, "|") {
+				switch channel {
+				case domain.ChannelEmail, domain.ChannelSMS, domain.ChannelInApp:
+					chosen = append(chosen, channel)
+				case "":
+				default:
+					log.Fatal("Invalid NOTIFICATION_CHANNELS entry: ", override)
+				}
+			}
+			channels[kind] = chosen
+		}
+	}
+
+	return channels
+}
@@ -0,0 +1,220 @@
+package infrastructure
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// brand notifications are sent under when none is configured
+const defaultBrandName = "Loan Tracker"
+
+// RenderedNotification holds a notification ready to be sent, each channel uses the versions it needs
+type RenderedNotification struct {
+	Subject string
+	Text    string
+	HTML    string
+	Short   string
+}
+
+// notificationTemplate holds the versions of a notification in one language, the text version defines the subject
+type notificationTemplate struct {
+	text  *texttemplate.Template
+	html  *htmltemplate.Template
+	short *texttemplate.Template
+}
+
+// NotificationTemplates renders notifications from the templates of a directory holding one folder per language.
+// Each notification is a name.txt file defining a "subject" template and the text body of its email,
+// an optional name.html file defining the "content" of layout.html at the root of the directory
+// and an optional name.short file with the text of SMS and in-app notifications.
+type NotificationTemplates struct {
+	Brand           string
+	BaseURL         string
+	DefaultLanguage string
+	templates       map[string]map[string]notificationTemplate
+}
+
+// functions available to every template
+var templateFuncs = map[string]interface{}{
+	"money": func(amount interface{}) string {
+		switch v := amount.(type) {
+		case float64:
+			return fmt.Sprintf("%.2f", v)
+		case int:
+			return fmt.Sprintf("%d.00", v)
+		}
+		return fmt.Sprint(amount)
+	},
+	"date": func(value interface{}) string {
+		switch v := value.(type) {
+		case time.Time:
+			return v.Format("2 Jan 2006")
+		case string:
+			if t, err := time.Parse(time.RFC3339, v); err == nil {
+				return t.Format("2 Jan 2006")
+			}
+		}
+		return fmt.Sprint(value)
+	},
+}
+
+// LoadNotificationTemplates parses the notification templates of every language in the directory.
+// The default language must have a text version of every notification the others have.
+func LoadNotificationTemplates(dir, brand, baseURL, defaultLanguage string) (*NotificationTemplates, error) {
+	layout, err := os.ReadFile(filepath.Join(dir, "layout.html"))
+	if err != nil {
+		return nil, fmt.Errorf("error reading email layout: %w", err)
+	}
+
+	languages, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading notification templates: %w", err)
+	}
+
+	templates := map[string]map[string]notificationTemplate{}
+	for _, language := range languages {
+		if !language.IsDir() {
+			continue
+		}
+
+		files, err := filepath.Glob(filepath.Join(dir, language.Name(), "*.txt"))
+		if err != nil {
+			return nil, err
+		}
+
+		templates[language.Name()] = map[string]notificationTemplate{}
+		for _, file := range files {
+			name := strings.TrimSuffix(filepath.Base(file), ".txt")
+
+			text, err := texttemplate.New(filepath.Base(file)).Funcs(templateFuncs).ParseFiles(file)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing notification template %s: %w", file, err)
+			}
+			if text.Lookup("subject") == nil {
+				return nil, fmt.Errorf("notification template %s doesn't define a subject", file)
+			}
+
+			notification := notificationTemplate{text: text}
+
+			htmlFile := strings.TrimSuffix(file, ".txt") + ".html"
+			if _, err := os.Stat(htmlFile); err == nil {
+				html, err := htmltemplate.New("layout").Funcs(templateFuncs).Parse(string(layout))
+				if err == nil {
+					html, err = html.ParseFiles(htmlFile)
+				}
+				if err != nil {
+					return nil, fmt.Errorf("error parsing notification template %s: %w", htmlFile, err)
+				}
+				notification.html = html
+			}
+
+			shortFile := strings.TrimSuffix(file, ".txt") + ".short"
+			if _, err := os.Stat(shortFile); err == nil {
+				short, err := texttemplate.New(filepath.Base(shortFile)).Funcs(templateFuncs).ParseFiles(shortFile)
+				if err != nil {
+					return nil, fmt.Errorf("error parsing notification template %s: %w", shortFile, err)
+				}
+				notification.short = short
+			}
+
+			templates[language.Name()][name] = notification
+		}
+	}
+
+	defaults, ok := templates[defaultLanguage]
+	if !ok {
+		return nil, fmt.Errorf("no notification templates for the default language %s", defaultLanguage)
+	}
+	for language, notifications := range templates {
+		for name := range notifications {
+			if _, ok := defaults[name]; !ok {
+				return nil, fmt.Errorf("notification template %s of %s has no %s version", name, language, defaultLanguage)
+			}
+		}
+	}
+
+	return &NotificationTemplates{
+		Brand:           brand,
+		BaseURL:         strings.TrimSuffix(baseURL, "/"),
+		DefaultLanguage: defaultLanguage,
+		templates:       templates,
+	}, nil
+}
+
+// LoadNotificationTemplatesFromEnv loads the notification templates with the brand and public URL the API is configured with
+func LoadNotificationTemplatesFromEnv() (*NotificationTemplates, error) {
+	return LoadNotificationTemplates(
+		DotEnvLookup("NOTIFICATION_TEMPLATE_DIR", "templates/notifications"),
+		DotEnvLookup("BRAND_NAME", defaultBrandName),
+		DotEnvLookup("PUBLIC_BASE_URL", "http://localhost:8080"),
+		DotEnvLookup("DEFAULT_LANGUAGE", "en"),
+	)
+}
+
+// lookup finds the notification in the closest language available, "pt-BR" falls back to "pt" and then to the default language
+func (nt *NotificationTemplates) lookup(name, language string) (notificationTemplate, bool) {
+	language = strings.ToLower(language)
+	for language != "" {
+		if notification, ok := nt.templates[language][name]; ok {
+			return notification, true
+		}
+		cut := strings.LastIndex(language, "-")
+		if cut < 0 {
+			break
+		}
+		language = language[:cut]
+	}
+
+	notification, ok := nt.templates[nt.DefaultLanguage][name]
+	return notification, ok
+}
+
+// Render renders a notification in the language of its recipient, the brand and public URL are added to its data
+func (nt *NotificationTemplates) Render(name, language string, data map[string]interface{}) (RenderedNotification, error) {
+	notification, ok := nt.lookup(name, language)
+	if !ok {
+		return RenderedNotification{}, errors.New("Unknown notification " + name)
+	}
+
+	values := map[string]interface{}{"Brand": nt.Brand, "BaseURL": nt.BaseURL}
+	for key, value := range data {
+		values[key] = value
+	}
+
+	var subject, text, html, short bytes.Buffer
+	if err := notification.text.ExecuteTemplate(&subject, "subject", values); err != nil {
+		return RenderedNotification{}, fmt.Errorf("error rendering notification %s: %w", name, err)
+	}
+	if err := notification.text.Execute(&text, values); err != nil {
+		return RenderedNotification{}, fmt.Errorf("error rendering notification %s: %w", name, err)
+	}
+	if notification.html != nil {
+		if err := notification.html.ExecuteTemplate(&html, "layout", values); err != nil {
+			return RenderedNotification{}, fmt.Errorf("error rendering notification %s: %w", name, err)
+		}
+	}
+	if notification.short != nil {
+		if err := notification.short.Execute(&short, values); err != nil {
+			return RenderedNotification{}, fmt.Errorf("error rendering notification %s: %w", name, err)
+		}
+	}
+
+	return RenderedNotification{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+		Short:   strings.TrimSpace(short.String()),
+	}, nil
+}
+
+// Link returns the public URL of a path of the API
+func (nt *NotificationTemplates) Link(path string) string {
+	return nt.BaseURL + path
+}
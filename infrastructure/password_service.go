@@ -10,8 +10,6 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-var hashedEmail [32]byte
-
 // compares the inputted password from the existing hash
 func PasswordComparator(hash string, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
@@ -26,21 +24,12 @@ func PasswordHasher(password string) (string, error) {
 	return string(hash), nil
 }
 
-// Handels forgot password
-func ForgotPasswordHandler(email string) error {
+// EmailToken generates the token of verification and password reset links, valid for 1 hour
+func EmailToken(email string) string {
 	secretKey := DotEnvLoader("Reset_Password")
 
-	// Generate a token valid for 1 hour
-	hashedEmail = sha256.Sum256([]byte(email))
-
-	emailConfig, _ := NewEmailConfig()
-	emailserv := NewEmailService(emailConfig)
-
-	token := passwordreset.NewToken(email, time.Hour*1, hashedEmail[:], []byte(secretKey))
-	if err := emailserv.SendResetEmail(email, token); err != nil {
-		return err
-	}
-	return nil
+	hashedEmail := sha256.Sum256([]byte(email))
+	return passwordreset.NewToken(email, time.Hour*1, hashedEmail[:], []byte(secretKey))
 }
 
 func VerifyToken(token string) (string, error) {
@@ -106,17 +95,3 @@ func PasswordValidator(password string) error {
 
 	return nil
 }
-
-func UserVerification(email string) error {
-	secretKey := DotEnvLoader("Reset_Password")
-
-	// Generate a token valid for 1 hour
-	hashedEmail = sha256.Sum256([]byte(email))
-	emailConfig, _ := NewEmailConfig()
-	emailserv := NewEmailService(emailConfig)
-	token := passwordreset.NewToken(email, time.Hour*1, hashedEmail[:], []byte(secretKey))
-	if err := emailserv.SendVerificationEmail(email, token); err != nil {
-		return err
-	}
-	return nil
-}
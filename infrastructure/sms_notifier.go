@@ -0,0 +1,76 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"loan_tracker_api/domain"
+	"net/http"
+	"time"
+)
+
+// SMSNotifier sends the short version of notifications as text messages through an HTTP gateway.
+// The gateway is posted {"to", "message"} with the token as a bearer token, any 2xx response counts as sent,
+// so a local stub can stand in for a real provider.
+type SMSNotifier struct {
+	templates  *NotificationTemplates
+	gatewayURL string
+	token      string
+	client     *http.Client
+}
+
+// NewSMSNotifier creates a new instance of SMSNotifier
+func NewSMSNotifier(templates *NotificationTemplates, gatewayURL, token string, timeout time.Duration) domain.Notifier {
+	return &SMSNotifier{
+		templates:  templates,
+		gatewayURL: gatewayURL,
+		token:      token,
+		client:     &http.Client{Timeout: timeout},
+	}
+}
+
+// Notify texts the notification to the contact number of the user, users without one can't be reached by SMS
+func (sn *SMSNotifier) Notify(user domain.User, notification domain.Notification) error {
+	if user.Contact == "" {
+		return nil
+	}
+
+	rendered, err := sn.templates.Render(notification.Type, user.Language, notification.Data)
+	if err != nil {
+		return err
+	}
+	if rendered.Short == "" {
+		return fmt.Errorf("Notification %s has no short version", notification.Type)
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"to":      user.Contact,
+		"message": sn.templates.Brand + ": " + rendered.Short,
+	})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, sn.gatewayURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if sn.token != "" {
+		request.Header.Set("Authorization", "Bearer "+sn.token)
+	}
+
+	response, err := sn.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to send SMS: %w", err)
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("SMS gateway responded with status %d", response.StatusCode)
+	}
+
+	return nil
+}
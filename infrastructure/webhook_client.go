@@ -0,0 +1,44 @@
+package infrastructure
+
+import (
+	"bytes"
+	"io"
+	"loan_tracker_api/domain"
+	"net/http"
+	"time"
+)
+
+// HTTPWebhookClient posts webhook payloads over HTTP
+type HTTPWebhookClient struct {
+	client *http.Client
+}
+
+// NewHTTPWebhookClient creates a new instance of HTTPWebhookClient, requests taking longer than the timeout fail
+func NewHTTPWebhookClient(timeout time.Duration) domain.WebhookClient {
+	return &HTTPWebhookClient{client: &http.Client{Timeout: timeout}}
+}
+
+// Post sends the JSON body with the given headers and returns the status code of the response
+func (wc *HTTPWebhookClient) Post(url string, headers map[string]string, body []byte) (int, error) {
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "loan-tracker-webhooks")
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+
+	response, err := wc.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	// drain what is left of the response so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	return response.StatusCode, nil
+}
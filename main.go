@@ -1,11 +1,17 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"loan_tracker_api/deliveries/controllers"
 	"loan_tracker_api/deliveries/router"
+	"loan_tracker_api/domain"
 	"loan_tracker_api/infrastructure"
 	"loan_tracker_api/repository"
 	"loan_tracker_api/usecase"
+	"log"
+	"os"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -15,14 +21,273 @@ func main() {
 	client := infrastructure.MongoDBInit() //mongodb initialization
 
 	userrepo := repository.NewUserRepository(client)
-	useruse := usecase.NewUserUsecase(userrepo, time.Second*300)
+	loanrepo := repository.NewLoanRepository(client)
+	logrepo := repository.NewLogRepository(client)
+	outboxrepo := repository.NewOutboxRepository(client)
+	webhookrepo := repository.NewWebhookRepository(client)
+	notificationrepo := repository.NewNotificationRepository(client)
+	streamrepo := repository.NewStreamRepository(client)
+	schedulerrepo := repository.NewSchedulerRepository(client)
+	queuerepo := repository.NewQueueRepository(client)
+	documentrepo := repository.NewDocumentRepository(client)
+	kycrepo := repository.NewKYCRepository(client)
+	collateralrepo := repository.NewCollateralRepository(client)
+	commentrepo := repository.NewCommentRepository(client)
+	unitofwork := repository.NewUnitOfWork(client)
+
+	// side effects of domain events, synchronous subscribers run in the unit of work of the change,
+	// asynchronous ones are delivered through the outbox once it is committed
+	events := usecase.NewEventBus(outboxrepo)
+
+	// loan changes and in-app notifications are recorded in the event log with the change, and pushed to the event streams
+	streamLookback, err := strconv.Atoi(infrastructure.DotEnvLookup("STREAM_LOOKBACK_SECONDS", "30"))
+	if err != nil {
+		log.Fatal("Invalid STREAM_LOOKBACK_SECONDS: ", err)
+	}
+	stream := usecase.NewEventStream(streamrepo, time.Duration(streamLookback)*time.Second)
+	for _, name := range domain.StreamedEvents {
+		events.Subscribe(name, stream.RecordEvent)
+	}
+
+	// notifications are rendered from templates in the preferred language of their recipient
+	templates, err := infrastructure.LoadNotificationTemplatesFromEnv()
+	if err != nil {
+		log.Fatal("Invalid notification templates: ", err)
+	}
+
+	emailNotifier := infrastructure.NewEmailNotifier(templates)
+	notifiers := map[string]domain.Notifier{
+		domain.ChannelEmail: emailNotifier,
+		domain.ChannelInApp: infrastructure.NewInAppNotifier(templates, notificationrepo, stream),
+	}
+	// SMS goes through the HTTP gateway when one is configured
+	if gateway := infrastructure.DotEnvLookup("SMS_GATEWAY_URL", ""); gateway != "" {
+		notifiers[domain.ChannelSMS] = infrastructure.NewSMSNotifier(templates, gateway, infrastructure.DotEnvLookup("SMS_GATEWAY_TOKEN", ""), 10*time.Second)
+	}
+	notifications := usecase.NewNotificationDispatcher(userrepo, outboxrepo, notifiers, infrastructure.LoadNotificationChannels())
+
+	subscriber := usecase.NewNotificationSubscriber(notifications, userrepo)
+	events.Subscribe(domain.EventUserRegistered, subscriber.Verification)
+	events.Subscribe(domain.EventPasswordResetRequested, subscriber.PasswordResetRequested)
+	events.Subscribe(domain.EventLoanApplied, subscriber.LoanSubmitted)
+	events.Subscribe(domain.EventLoanApproved, subscriber.LoanApproved)
+	events.Subscribe(domain.EventLoanRejected, subscriber.LoanRejected)
+	events.Subscribe(domain.EventPaymentReceived, subscriber.PaymentReceived)
+	events.Subscribe(domain.EventPaymentDue, subscriber.PaymentDue)
+	events.Subscribe(domain.EventPaymentOverdue, subscriber.PaymentOverdue)
+	events.Subscribe(domain.EventLoanPaidOff, subscriber.LoanPaidOff)
+	events.Subscribe(domain.EventLoanWrittenOff, subscriber.LoanWrittenOff)
+	events.Subscribe(domain.EventKYCReviewed, subscriber.KYCReviewed)
+	events.Subscribe(domain.EventLoanPartyInvited, subscriber.LoanPartyInvited)
+	events.Subscribe(domain.EventLoanCommentMention, subscriber.LoanCommentMention)
+
+	metrics := infrastructure.NewEventMetrics()
+	for _, name := range []string{
+		domain.EventLoanApplied, domain.EventLoanApproved, domain.EventLoanRejected, domain.EventPaymentReceived,
+		domain.EventLoanPaidOff, domain.EventLoanWrittenOff, domain.EventUserRegistered,
+	} {
+		events.SubscribeAsync(name, "metrics", metrics.CountEvent)
+	}
+
+	// every event can be subscribed to by webhooks, deliveries are queued for the webhooks subscribed to it
+	webhookuse := usecase.NewWebhookUsecase(webhookrepo, outboxrepo, unitofwork, infrastructure.NewHTTPWebhookClient(10*time.Second), time.Second*300)
+	webhookcont := controllers.NewWebhookController(webhookuse)
+	for _, name := range domain.EventNames() {
+		events.SubscribeAsync(name, "webhooks", webhookuse.EnqueueDeliveries)
+	}
+
+	useruse := usecase.NewUserUsecase(userrepo, loanrepo, kycrepo, logrepo, unitofwork, events, time.Second*300)
 	usercont := controllers.NewUserController(useruse)
 
-	loanrepo := repository.NewLoanRepository(client)
-	loanuse := usecase.NewLoanUsecase(loanrepo, time.Second*300)
+	// identities are verified before loans can be applied for
+	kycuse := usecase.NewKYCUsecase(kycrepo, userrepo, logrepo, unitofwork, events, time.Second*300)
+	kyccont := controllers.NewKYCController(kycuse)
+
+	// liens on collateral are placed when the loans it secures are approved and released once they are paid off
+	collateraluse := usecase.NewCollateralUsecase(collateralrepo, loanrepo, userrepo, logrepo, unitofwork, time.Second*300)
+	collateralcont := controllers.NewCollateralController(collateraluse)
+	events.Subscribe(domain.EventLoanApproved, collateraluse.LoanApproved)
+	events.Subscribe(domain.EventLoanPaidOff, collateraluse.LoanPaidOff)
+
+	loanuse := usecase.NewLoanUsecase(loanrepo, userrepo, collateralrepo, logrepo, unitofwork, events, infrastructure.LoadLoanProducts(), time.Second*300)
 	loancont := controllers.NewLoanController(loanuse)
 
+	// staff keep internal notes on loans next to the comments the borrower sees
+	commentuse := usecase.NewCommentUsecase(commentrepo, loanrepo, userrepo, logrepo, unitofwork, events, time.Second*300)
+	commentcont := controllers.NewCommentController(commentuse)
+
+	// documents are kept in the blob store, downloads go through short-lived signed links
+	documentMegabytes, err := strconv.Atoi(infrastructure.DotEnvLookup("DOCUMENT_MAX_MEGABYTES", "10"))
+	if err != nil {
+		log.Fatal("Invalid DOCUMENT_MAX_MEGABYTES: ", err)
+	}
+	documentLinkSeconds, err := strconv.Atoi(infrastructure.DotEnvLookup("DOCUMENT_LINK_TTL_SECONDS", "300"))
+	if err != nil {
+		log.Fatal("Invalid DOCUMENT_LINK_TTL_SECONDS: ", err)
+	}
+	// links are signed with a key of their own, a leaked link key can't sign access tokens
+	documentSecret := infrastructure.DotEnvLookup("DOCUMENT_LINK_SECRET", "")
+	if documentSecret == "" {
+		log.Fatal("DOCUMENT_LINK_SECRET is required")
+	}
+	documentMaxSize := int64(documentMegabytes) << 20
+	documentuse := usecase.NewDocumentUsecase(documentrepo, loanrepo, logrepo, unitofwork, infrastructure.LoadBlobStore(), documentSecret, time.Duration(documentLinkSeconds)*time.Second, documentMaxSize, time.Second*300)
+	documentcont := controllers.NewDocumentController(documentuse, documentMaxSize)
+
+	notificationuse := usecase.NewNotificationUsecase(notificationrepo, time.Second*300)
+	notificationcont := controllers.NewNotificationController(notificationuse)
+
+	heartbeatSeconds, err := strconv.Atoi(infrastructure.DotEnvLookup("STREAM_HEARTBEAT_SECONDS", "15"))
+	if err != nil {
+		log.Fatal("Invalid STREAM_HEARTBEAT_SECONDS: ", err)
+	}
+	streamcont := controllers.NewStreamController(stream, time.Duration(heartbeatSeconds)*time.Second)
+
+	checkpoints := infrastructure.NewFileCheckpointStore(infrastructure.DotEnvLookup("AUDIT_CHECKPOINT_FILE", "audit_checkpoints.jsonl"))
+	archiver := infrastructure.NewFileLogArchiver(infrastructure.DotEnvLookup("AUDIT_ARCHIVE_DIR", "audit_archive"))
+	loguse := usecase.NewLogUsecase(logrepo, checkpoints, archiver, infrastructure.LoadRetentionPolicy(), time.Second*300)
+	logcont := controllers.NewLogController(loguse)
+
+	outboxuse := usecase.NewOutboxUsecase(outboxrepo, map[string]domain.OutboxSender{
+		domain.OutboxEmail:        emailNotifier,
+		domain.OutboxEvent:        events,
+		domain.OutboxWebhook:      webhookuse,
+		domain.OutboxNotification: notifications,
+	}, time.Second*300)
+	outboxcont := controllers.NewOutboxController(outboxuse)
+
+	// deliver the notifications, events and webhooks recorded in the outbox once the changes behind them are committed
+	outboxSeconds, err := strconv.Atoi(infrastructure.DotEnvLookup("OUTBOX_POLL_INTERVAL_SECONDS", "10"))
+	if err != nil {
+		log.Fatal("Invalid OUTBOX_POLL_INTERVAL_SECONDS: ", err)
+	}
+
+	go func() {
+		for range time.Tick(time.Duration(outboxSeconds) * time.Second) {
+			if _, err := outboxuse.DispatchOutbox(context.Background()); err != nil {
+				log.Println("Outbox dispatch failed:", err)
+			}
+		}
+	}()
+
+	// push the events recorded by every instance to the stream clients of this one
+	streamPollMillis, err := strconv.Atoi(infrastructure.DotEnvLookup("STREAM_POLL_INTERVAL_MILLISECONDS", "1000"))
+	if err != nil {
+		log.Fatal("Invalid STREAM_POLL_INTERVAL_MILLISECONDS: ", err)
+	}
+
+	go func() {
+		for range time.Tick(time.Duration(streamPollMillis) * time.Millisecond) {
+			if _, err := stream.Poll(context.Background()); err != nil {
+				log.Println("Event stream poll failed:", err)
+			}
+		}
+	}()
+
+	// jobs run on the minutes their schedules match, only one instance runs each of them at a time
+	hostname, _ := os.Hostname()
+	leaseMinutes, err := strconv.Atoi(infrastructure.DotEnvLookup("JOB_LOCK_LEASE_MINUTES", "60"))
+	if err != nil {
+		log.Fatal("Invalid JOB_LOCK_LEASE_MINUTES: ", err)
+	}
+	instance := fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	scheduler := usecase.NewScheduler(schedulerrepo, instance, infrastructure.LoadJobLocation(), time.Duration(leaseMinutes)*time.Minute, time.Second*300)
+	jobcont := controllers.NewJobController(scheduler)
+
+	// background work is queued in the database and run by the workers of every instance
+	queue := usecase.NewJobQueue(queuerepo, time.Second*300)
+	queuecont := controllers.NewQueueController(queue)
+
+	// permanently remove soft deleted loans and users once their retention period is over
+	retentionDays, err := strconv.Atoi(infrastructure.DotEnvLookup("PURGE_RETENTION_DAYS", "30"))
+	if err != nil {
+		log.Fatal("Invalid PURGE_RETENTION_DAYS: ", err)
+	}
+	retention := time.Duration(retentionDays) * 24 * time.Hour
+
+	// anchor the head of the audit chain outside the database so rewriting the chain can be detected
+	checkpointHours, err := strconv.Atoi(infrastructure.DotEnvLookup("AUDIT_CHECKPOINT_INTERVAL_HOURS", "24"))
+	if err != nil {
+		log.Fatal("Invalid AUDIT_CHECKPOINT_INTERVAL_HOURS: ", err)
+	}
+
+	// remind borrowers of installments coming due and send escalating notices once they are overdue
+	reminderDays := infrastructure.LoadDays("PAYMENT_REMINDER_DAYS", "3")
+	noticeDays := infrastructure.LoadDays("OVERDUE_NOTICE_DAYS", "1,7,30")
+
+	jobs := []struct {
+		name     string
+		schedule string
+		run      domain.JobFunc
+	}{
+		// assess late fees, penalty interest and delinquency of overdue loans
+		{"loan_assessment", "@hourly", func(c context.Context) (int, error) {
+			return 0, loanuse.AssessOverdueLoans(c)
+		}},
+		{"payment_reminders", "0 8 * * *", func(c context.Context) (int, error) {
+			return loanuse.RemindUpcomingPayments(c, reminderDays)
+		}},
+		{"overdue_notices", "0 9 * * *", func(c context.Context) (int, error) {
+			return loanuse.NoticeOverduePayments(c, noticeDays)
+		}},
+		{"loan_purge", "0 3 * * *", func(c context.Context) (int, error) {
+			return loanuse.PurgeDeletedLoans(c, retention)
+		}},
+		{"user_purge", "0 3 * * *", func(c context.Context) (int, error) {
+			return useruse.PurgeDeletedUsers(c, retention)
+		}},
+		{"audit_checkpoint", fmt.Sprintf("@every %dh", checkpointHours), func(c context.Context) (int, error) {
+			if _, err := loguse.CheckpointLogs(c); err != nil {
+				return 0, err
+			}
+			return 1, nil
+		}},
+		// move audit entries past the retention of their category to the archive
+		{"audit_archive", "0 4 * * *", loguse.ArchiveLogs},
+	}
+	// scheduled jobs are queued when they are due and run by the workers, the worker records how the run ended.
+	// A run that is still queued or running keeps the next one from being queued.
+	for _, job := range jobs {
+		if err := scheduler.AddQueuedJob(job.name, infrastructure.LoadJobSchedule(job.name, job.schedule), queue, time.Hour, job.run); err != nil {
+			log.Fatal("Invalid schedule of ", job.name, ": ", err)
+		}
+	}
+
+	go func() {
+		for now := range time.Tick(15 * time.Second) {
+			go func(now time.Time) {
+				if err := scheduler.RunDue(context.Background(), now); err != nil {
+					log.Println("Scheduled jobs failed:", err)
+				}
+			}(now)
+		}
+	}()
+
+	workers, err := strconv.Atoi(infrastructure.DotEnvLookup("JOB_WORKERS", "4"))
+	if err != nil {
+		log.Fatal("Invalid JOB_WORKERS: ", err)
+	}
+	workerSeconds, err := strconv.Atoi(infrastructure.DotEnvLookup("JOB_POLL_INTERVAL_SECONDS", "5"))
+	if err != nil {
+		log.Fatal("Invalid JOB_POLL_INTERVAL_SECONDS: ", err)
+	}
+
+	// every worker runs queued jobs one after the other, and waits for more once the queue has none due
+	for i := 1; i <= workers; i++ {
+		go func(worker string) {
+			for {
+				worked, err := queue.Work(context.Background(), worker)
+				if err != nil {
+					log.Println("Job worker failed:", err)
+				}
+				if err != nil || !worked {
+					time.Sleep(time.Duration(workerSeconds) * time.Second)
+				}
+			}
+		}(fmt.Sprintf("%s-%d", instance, i))
+	}
+
 	r := gin.Default()
-	router.SetRouter(r, usercont, client, loancont)
+	router.SetRouter(r, usercont, client, loancont, logcont, outboxcont, webhookcont, notificationcont, streamcont, jobcont, queuecont, documentcont, kyccont, collateralcont, commentcont)
 	r.Run()
 }
@@ -0,0 +1,76 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CheckpointStore is an autogenerated mock type for the CheckpointStore type
+type CheckpointStore struct {
+	mock.Mock
+}
+
+// AppendCheckpoint provides a mock function with given fields: checkpoint
+func (_m *CheckpointStore) AppendCheckpoint(checkpoint domain.LogCheckpoint) error {
+	ret := _m.Called(checkpoint)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AppendCheckpoint")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(domain.LogCheckpoint) error); ok {
+		r0 = rf(checkpoint)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Checkpoints provides a mock function with given fields:
+func (_m *CheckpointStore) Checkpoints() ([]domain.LogCheckpoint, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Checkpoints")
+	}
+
+	var r0 []domain.LogCheckpoint
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]domain.LogCheckpoint, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []domain.LogCheckpoint); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.LogCheckpoint)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewCheckpointStore creates a new instance of CheckpointStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCheckpointStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CheckpointStore {
+	mock := &CheckpointStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
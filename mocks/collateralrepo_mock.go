@@ -0,0 +1,153 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CollateralRepository is an autogenerated mock type for the CollateralRepository type
+type CollateralRepository struct {
+	mock.Mock
+}
+
+// AddCollateral provides a mock function with given fields: c, collateral
+func (_m *CollateralRepository) AddCollateral(c context.Context, collateral *domain.Collateral) error {
+	ret := _m.Called(c, collateral)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddCollateral")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Collateral) error); ok {
+		r0 = rf(c, collateral)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetCollateral provides a mock function with given fields: id
+func (_m *CollateralRepository) GetCollateral(id string) (domain.Collateral, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCollateral")
+	}
+
+	var r0 domain.Collateral
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (domain.Collateral, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(string) domain.Collateral); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(domain.Collateral)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListCollateral provides a mock function with given fields: ownerID, page
+func (_m *CollateralRepository) ListCollateral(ownerID string, page int) ([]domain.Collateral, error) {
+	ret := _m.Called(ownerID, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListCollateral")
+	}
+
+	var r0 []domain.Collateral
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int) ([]domain.Collateral, error)); ok {
+		return rf(ownerID, page)
+	}
+	if rf, ok := ret.Get(0).(func(string, int) []domain.Collateral); ok {
+		r0 = rf(ownerID, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Collateral)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(ownerID, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LoanCollateral provides a mock function with given fields: loanID
+func (_m *CollateralRepository) LoanCollateral(loanID string) ([]domain.Collateral, error) {
+	ret := _m.Called(loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoanCollateral")
+	}
+
+	var r0 []domain.Collateral
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]domain.Collateral, error)); ok {
+		return rf(loanID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []domain.Collateral); ok {
+		r0 = rf(loanID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Collateral)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(loanID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateCollateral provides a mock function with given fields: c, collateral
+func (_m *CollateralRepository) UpdateCollateral(c context.Context, collateral *domain.Collateral) error {
+	ret := _m.Called(c, collateral)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCollateral")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Collateral) error); ok {
+		r0 = rf(c, collateral)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewCollateralRepository creates a new instance of CollateralRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCollateralRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CollateralRepository {
+	mock := &CollateralRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,189 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CollateralUsecase is an autogenerated mock type for the CollateralUsecase type
+type CollateralUsecase struct {
+	mock.Mock
+}
+
+// AppraiseCollateral provides a mock function with given fields: c, id, appraisal, adminid
+func (_m *CollateralUsecase) AppraiseCollateral(c context.Context, id string, appraisal domain.Appraisal, adminid string) (domain.Collateral, error) {
+	ret := _m.Called(c, id, appraisal, adminid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AppraiseCollateral")
+	}
+
+	var r0 domain.Collateral
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.Appraisal, string) (domain.Collateral, error)); ok {
+		return rf(c, id, appraisal, adminid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.Appraisal, string) domain.Collateral); ok {
+		r0 = rf(c, id, appraisal, adminid)
+	} else {
+		r0 = ret.Get(0).(domain.Collateral)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, domain.Appraisal, string) error); ok {
+		r1 = rf(c, id, appraisal, adminid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCollateral provides a mock function with given fields: c, id
+func (_m *CollateralUsecase) GetCollateral(c context.Context, id string) (domain.Collateral, error) {
+	ret := _m.Called(c, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCollateral")
+	}
+
+	var r0 domain.Collateral
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.Collateral, error)); ok {
+		return rf(c, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.Collateral); ok {
+		r0 = rf(c, id)
+	} else {
+		r0 = ret.Get(0).(domain.Collateral)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(c, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LinkCollateral provides a mock function with given fields: c, loanID, id, adminid
+func (_m *CollateralUsecase) LinkCollateral(c context.Context, loanID string, id string, adminid string) (domain.Collateral, error) {
+	ret := _m.Called(c, loanID, id, adminid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LinkCollateral")
+	}
+
+	var r0 domain.Collateral
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (domain.Collateral, error)); ok {
+		return rf(c, loanID, id, adminid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) domain.Collateral); ok {
+		r0 = rf(c, loanID, id, adminid)
+	} else {
+		r0 = ret.Get(0).(domain.Collateral)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(c, loanID, id, adminid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListCollateral provides a mock function with given fields: c, ownerID, page
+func (_m *CollateralUsecase) ListCollateral(c context.Context, ownerID string, page int) ([]domain.Collateral, error) {
+	ret := _m.Called(c, ownerID, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListCollateral")
+	}
+
+	var r0 []domain.Collateral
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]domain.Collateral, error)); ok {
+		return rf(c, ownerID, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []domain.Collateral); ok {
+		r0 = rf(c, ownerID, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Collateral)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(c, ownerID, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RegisterCollateral provides a mock function with given fields: c, collateral, adminid
+func (_m *CollateralUsecase) RegisterCollateral(c context.Context, collateral *domain.Collateral, adminid string) error {
+	ret := _m.Called(c, collateral, adminid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RegisterCollateral")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Collateral, string) error); ok {
+		r0 = rf(c, collateral, adminid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UnlinkCollateral provides a mock function with given fields: c, loanID, id, adminid
+func (_m *CollateralUsecase) UnlinkCollateral(c context.Context, loanID string, id string, adminid string) (domain.Collateral, error) {
+	ret := _m.Called(c, loanID, id, adminid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnlinkCollateral")
+	}
+
+	var r0 domain.Collateral
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (domain.Collateral, error)); ok {
+		return rf(c, loanID, id, adminid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) domain.Collateral); ok {
+		r0 = rf(c, loanID, id, adminid)
+	} else {
+		r0 = ret.Get(0).(domain.Collateral)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(c, loanID, id, adminid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewCollateralUsecase creates a new instance of CollateralUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCollateralUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CollateralUsecase {
+	mock := &CollateralUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,107 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CommentUsecase is an autogenerated mock type for the CommentUsecase type
+type CommentUsecase struct {
+	mock.Mock
+}
+
+// AddComment provides a mock function with given fields: c, loanID, comment, userid
+func (_m *CommentUsecase) AddComment(c context.Context, loanID string, comment *domain.Comment, userid string) error {
+	ret := _m.Called(c, loanID, comment, userid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddComment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *domain.Comment, string) error); ok {
+		r0 = rf(c, loanID, comment, userid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EditComment provides a mock function with given fields: c, loanID, commentID, body, mentions, userid
+func (_m *CommentUsecase) EditComment(c context.Context, loanID string, commentID string, body string, mentions []primitive.ObjectID, userid string) (domain.Comment, error) {
+	ret := _m.Called(c, loanID, commentID, body, mentions, userid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EditComment")
+	}
+
+	var r0 domain.Comment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, []primitive.ObjectID, string) (domain.Comment, error)); ok {
+		return rf(c, loanID, commentID, body, mentions, userid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, []primitive.ObjectID, string) domain.Comment); ok {
+		r0 = rf(c, loanID, commentID, body, mentions, userid)
+	} else {
+		r0 = ret.Get(0).(domain.Comment)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, []primitive.ObjectID, string) error); ok {
+		r1 = rf(c, loanID, commentID, body, mentions, userid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LoanComments provides a mock function with given fields: c, loanID, userid
+func (_m *CommentUsecase) LoanComments(c context.Context, loanID string, userid string) ([]domain.Comment, error) {
+	ret := _m.Called(c, loanID, userid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoanComments")
+	}
+
+	var r0 []domain.Comment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]domain.Comment, error)); ok {
+		return rf(c, loanID, userid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []domain.Comment); ok {
+		r0 = rf(c, loanID, userid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Comment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(c, loanID, userid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewCommentUsecase creates a new instance of CommentUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCommentUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CommentUsecase {
+	mock := &CommentUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
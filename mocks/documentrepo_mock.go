@@ -0,0 +1,105 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DocumentRepository is an autogenerated mock type for the DocumentRepository type
+type DocumentRepository struct {
+	mock.Mock
+}
+
+// AddDocument provides a mock function with given fields: c, document
+func (_m *DocumentRepository) AddDocument(c context.Context, document *domain.Document) error {
+	ret := _m.Called(c, document)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddDocument")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Document) error); ok {
+		r0 = rf(c, document)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetDocument provides a mock function with given fields: id
+func (_m *DocumentRepository) GetDocument(id string) (domain.Document, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDocument")
+	}
+
+	var r0 domain.Document
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (domain.Document, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(string) domain.Document); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(domain.Document)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LoanDocuments provides a mock function with given fields: loanID
+func (_m *DocumentRepository) LoanDocuments(loanID string) ([]domain.Document, error) {
+	ret := _m.Called(loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoanDocuments")
+	}
+
+	var r0 []domain.Document
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]domain.Document, error)); ok {
+		return rf(loanID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []domain.Document); ok {
+		r0 = rf(loanID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Document)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(loanID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewDocumentRepository creates a new instance of DocumentRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDocumentRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DocumentRepository {
+	mock := &DocumentRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
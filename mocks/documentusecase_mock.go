@@ -0,0 +1,153 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	io "io"
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DocumentUsecase is an autogenerated mock type for the DocumentUsecase type
+type DocumentUsecase struct {
+	mock.Mock
+}
+
+// DocumentLink provides a mock function with given fields: c, loanID, documentID, userid
+func (_m *DocumentUsecase) DocumentLink(c context.Context, loanID string, documentID string, userid string) (domain.DocumentLink, error) {
+	ret := _m.Called(c, loanID, documentID, userid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DocumentLink")
+	}
+
+	var r0 domain.DocumentLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (domain.DocumentLink, error)); ok {
+		return rf(c, loanID, documentID, userid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) domain.DocumentLink); ok {
+		r0 = rf(c, loanID, documentID, userid)
+	} else {
+		r0 = ret.Get(0).(domain.DocumentLink)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(c, loanID, documentID, userid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LoanDocuments provides a mock function with given fields: c, loanID, userid
+func (_m *DocumentUsecase) LoanDocuments(c context.Context, loanID string, userid string) ([]domain.Document, error) {
+	ret := _m.Called(c, loanID, userid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoanDocuments")
+	}
+
+	var r0 []domain.Document
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]domain.Document, error)); ok {
+		return rf(c, loanID, userid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []domain.Document); ok {
+		r0 = rf(c, loanID, userid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Document)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(c, loanID, userid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OpenDocument provides a mock function with given fields: c, documentID, expires, signature
+func (_m *DocumentUsecase) OpenDocument(c context.Context, documentID string, expires int64, signature string) (domain.Document, io.ReadCloser, error) {
+	ret := _m.Called(c, documentID, expires, signature)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OpenDocument")
+	}
+
+	var r0 domain.Document
+	var r1 io.ReadCloser
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, string) (domain.Document, io.ReadCloser, error)); ok {
+		return rf(c, documentID, expires, signature)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, string) domain.Document); ok {
+		r0 = rf(c, documentID, expires, signature)
+	} else {
+		r0 = ret.Get(0).(domain.Document)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, string) io.ReadCloser); ok {
+		r1 = rf(c, documentID, expires, signature)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(io.ReadCloser)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, int64, string) error); ok {
+		r2 = rf(c, documentID, expires, signature)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UploadDocument provides a mock function with given fields: c, loanID, upload, userid
+func (_m *DocumentUsecase) UploadDocument(c context.Context, loanID string, upload domain.DocumentUpload, userid string) (domain.Document, error) {
+	ret := _m.Called(c, loanID, upload, userid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UploadDocument")
+	}
+
+	var r0 domain.Document
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.DocumentUpload, string) (domain.Document, error)); ok {
+		return rf(c, loanID, upload, userid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.DocumentUpload, string) domain.Document); ok {
+		r0 = rf(c, loanID, upload, userid)
+	} else {
+		r0 = ret.Get(0).(domain.Document)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, domain.DocumentUpload, string) error); ok {
+		r1 = rf(c, loanID, upload, userid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewDocumentUsecase creates a new instance of DocumentUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDocumentUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DocumentUsecase {
+	mock := &DocumentUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,57 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// EventBus is an autogenerated mock type for the EventBus type
+type EventBus struct {
+	mock.Mock
+}
+
+// Publish provides a mock function with given fields: c, event
+func (_m *EventBus) Publish(c context.Context, event domain.Event) error {
+	ret := _m.Called(c, event)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Publish")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Event) error); ok {
+		r0 = rf(c, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Subscribe provides a mock function with given fields: name, handler
+func (_m *EventBus) Subscribe(name string, handler domain.EventHandler) {
+	_m.Called(name, handler)
+}
+
+// SubscribeAsync provides a mock function with given fields: name, subscriber, handler
+func (_m *EventBus) SubscribeAsync(name string, subscriber string, handler domain.EventHandler) {
+	_m.Called(name, subscriber, handler)
+}
+
+// NewEventBus creates a new instance of EventBus. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewEventBus(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *EventBus {
+	mock := &EventBus{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,95 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// EventStream is an autogenerated mock type for the EventStream type
+type EventStream struct {
+	mock.Mock
+}
+
+// RecordEvent provides a mock function with given fields: c, event
+func (_m *EventStream) RecordEvent(c context.Context, event domain.Event) error {
+	ret := _m.Called(c, event)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordEvent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Event) error); ok {
+		r0 = rf(c, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RecordNotification provides a mock function with given fields: c, notification
+func (_m *EventStream) RecordNotification(c context.Context, notification domain.Notification) error {
+	ret := _m.Called(c, notification)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordNotification")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Notification) error); ok {
+		r0 = rf(c, notification)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Subscribe provides a mock function with given fields: c, userid, all, lastEventID
+func (_m *EventStream) Subscribe(c context.Context, userid string, all bool, lastEventID string) (*domain.StreamSubscription, error) {
+	ret := _m.Called(c, userid, all, lastEventID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Subscribe")
+	}
+
+	var r0 *domain.StreamSubscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool, string) (*domain.StreamSubscription, error)); ok {
+		return rf(c, userid, all, lastEventID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool, string) *domain.StreamSubscription); ok {
+		r0 = rf(c, userid, all, lastEventID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.StreamSubscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool, string) error); ok {
+		r1 = rf(c, userid, all, lastEventID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewEventStream creates a new instance of EventStream. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewEventStream(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *EventStream {
+	mock := &EventStream{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,151 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// JobQueueUsecase is an autogenerated mock type for the JobQueueUsecase type
+type JobQueueUsecase struct {
+	mock.Mock
+}
+
+// CancelJob provides a mock function with given fields: c, id
+func (_m *JobQueueUsecase) CancelJob(c context.Context, id string) error {
+	ret := _m.Called(c, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelJob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(c, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Enqueue provides a mock function with given fields: c, jobType, payload, options
+func (_m *JobQueueUsecase) Enqueue(c context.Context, jobType string, payload interface{}, options domain.JobOptions) (domain.QueuedJob, error) {
+	ret := _m.Called(c, jobType, payload, options)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Enqueue")
+	}
+
+	var r0 domain.QueuedJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}, domain.JobOptions) (domain.QueuedJob, error)); ok {
+		return rf(c, jobType, payload, options)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, interface{}, domain.JobOptions) domain.QueuedJob); ok {
+		r0 = rf(c, jobType, payload, options)
+	} else {
+		r0 = ret.Get(0).(domain.QueuedJob)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, interface{}, domain.JobOptions) error); ok {
+		r1 = rf(c, jobType, payload, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetJob provides a mock function with given fields: c, id
+func (_m *JobQueueUsecase) GetJob(c context.Context, id string) (domain.QueuedJob, error) {
+	ret := _m.Called(c, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetJob")
+	}
+
+	var r0 domain.QueuedJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.QueuedJob, error)); ok {
+		return rf(c, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.QueuedJob); ok {
+		r0 = rf(c, id)
+	} else {
+		r0 = ret.Get(0).(domain.QueuedJob)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(c, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListJobs provides a mock function with given fields: c, filter
+func (_m *JobQueueUsecase) ListJobs(c context.Context, filter domain.JobFilter) ([]domain.QueuedJob, error) {
+	ret := _m.Called(c, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListJobs")
+	}
+
+	var r0 []domain.QueuedJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.JobFilter) ([]domain.QueuedJob, error)); ok {
+		return rf(c, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.JobFilter) []domain.QueuedJob); ok {
+		r0 = rf(c, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.QueuedJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.JobFilter) error); ok {
+		r1 = rf(c, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RetryJob provides a mock function with given fields: c, id
+func (_m *JobQueueUsecase) RetryJob(c context.Context, id string) error {
+	ret := _m.Called(c, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetryJob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(c, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewJobQueueUsecase creates a new instance of JobQueueUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewJobQueueUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *JobQueueUsecase {
+	mock := &JobQueueUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
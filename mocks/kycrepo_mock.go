@@ -0,0 +1,153 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// KYCRepository is an autogenerated mock type for the KYCRepository type
+type KYCRepository struct {
+	mock.Mock
+}
+
+// DeleteKYCProfiles provides a mock function with given fields: c, userIDs
+func (_m *KYCRepository) DeleteKYCProfiles(c context.Context, userIDs []primitive.ObjectID) error {
+	ret := _m.Called(c, userIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteKYCProfiles")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []primitive.ObjectID) error); ok {
+		r0 = rf(c, userIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetKYCProfile provides a mock function with given fields: id
+func (_m *KYCRepository) GetKYCProfile(id string) (domain.KYCProfile, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetKYCProfile")
+	}
+
+	var r0 domain.KYCProfile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (domain.KYCProfile, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(string) domain.KYCProfile); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(domain.KYCProfile)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// KYCProfile provides a mock function with given fields: userID
+func (_m *KYCRepository) KYCProfile(userID string) (domain.KYCProfile, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for KYCProfile")
+	}
+
+	var r0 domain.KYCProfile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (domain.KYCProfile, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(string) domain.KYCProfile); ok {
+		r0 = rf(userID)
+	} else {
+		r0 = ret.Get(0).(domain.KYCProfile)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// KYCQueue provides a mock function with given fields: status, page
+func (_m *KYCRepository) KYCQueue(status string, page int) ([]domain.KYCProfile, error) {
+	ret := _m.Called(status, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for KYCQueue")
+	}
+
+	var r0 []domain.KYCProfile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int) ([]domain.KYCProfile, error)); ok {
+		return rf(status, page)
+	}
+	if rf, ok := ret.Get(0).(func(string, int) []domain.KYCProfile); ok {
+		r0 = rf(status, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.KYCProfile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(status, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveKYCProfile provides a mock function with given fields: c, profile
+func (_m *KYCRepository) SaveKYCProfile(c context.Context, profile *domain.KYCProfile) error {
+	ret := _m.Called(c, profile)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveKYCProfile")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.KYCProfile) error); ok {
+		r0 = rf(c, profile)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewKYCRepository creates a new instance of KYCRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewKYCRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *KYCRepository {
+	mock := &KYCRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
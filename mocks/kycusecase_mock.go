@@ -0,0 +1,161 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// KYCUsecase is an autogenerated mock type for the KYCUsecase type
+type KYCUsecase struct {
+	mock.Mock
+}
+
+// GetKYCProfile provides a mock function with given fields: c, id
+func (_m *KYCUsecase) GetKYCProfile(c context.Context, id string) (domain.KYCProfile, error) {
+	ret := _m.Called(c, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetKYCProfile")
+	}
+
+	var r0 domain.KYCProfile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.KYCProfile, error)); ok {
+		return rf(c, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.KYCProfile); ok {
+		r0 = rf(c, id)
+	} else {
+		r0 = ret.Get(0).(domain.KYCProfile)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(c, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// KYCProfile provides a mock function with given fields: c, userid
+func (_m *KYCUsecase) KYCProfile(c context.Context, userid string) (domain.KYCProfile, error) {
+	ret := _m.Called(c, userid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for KYCProfile")
+	}
+
+	var r0 domain.KYCProfile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.KYCProfile, error)); ok {
+		return rf(c, userid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.KYCProfile); ok {
+		r0 = rf(c, userid)
+	} else {
+		r0 = ret.Get(0).(domain.KYCProfile)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(c, userid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// KYCQueue provides a mock function with given fields: c, status, page
+func (_m *KYCUsecase) KYCQueue(c context.Context, status string, page int) ([]domain.KYCProfile, error) {
+	ret := _m.Called(c, status, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for KYCQueue")
+	}
+
+	var r0 []domain.KYCProfile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]domain.KYCProfile, error)); ok {
+		return rf(c, status, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []domain.KYCProfile); ok {
+		r0 = rf(c, status, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.KYCProfile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(c, status, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReviewKYC provides a mock function with given fields: c, id, decision, note, adminid
+func (_m *KYCUsecase) ReviewKYC(c context.Context, id string, decision string, note string, adminid string) (domain.KYCProfile, error) {
+	ret := _m.Called(c, id, decision, note, adminid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReviewKYC")
+	}
+
+	var r0 domain.KYCProfile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) (domain.KYCProfile, error)); ok {
+		return rf(c, id, decision, note, adminid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) domain.KYCProfile); ok {
+		r0 = rf(c, id, decision, note, adminid)
+	} else {
+		r0 = ret.Get(0).(domain.KYCProfile)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, string) error); ok {
+		r1 = rf(c, id, decision, note, adminid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SubmitKYC provides a mock function with given fields: c, profile, userid
+func (_m *KYCUsecase) SubmitKYC(c context.Context, profile *domain.KYCProfile, userid string) error {
+	ret := _m.Called(c, profile, userid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SubmitKYC")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.KYCProfile, string) error); ok {
+		r0 = rf(c, profile, userid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewKYCUsecase creates a new instance of KYCUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewKYCUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *KYCUsecase {
+	mock := &KYCUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
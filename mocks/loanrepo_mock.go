@@ -3,9 +3,12 @@
 package mocks
 
 import (
+	context "context"
 	domain "loan_tracker_api/domain"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // LoanRepository is an autogenerated mock type for the LoanRepository type
@@ -13,17 +16,47 @@ type LoanRepository struct {
 	mock.Mock
 }
 
-// ApplyForLoan provides a mock function with given fields: loan, userid
-func (_m *LoanRepository) ApplyForLoan(loan *domain.Loan, userid string) error {
-	ret := _m.Called(loan, userid)
+// ActiveLoans provides a mock function with given fields:
+func (_m *LoanRepository) ActiveLoans() ([]domain.Loan, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ActiveLoans")
+	}
+
+	var r0 []domain.Loan
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]domain.Loan, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []domain.Loan); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Loan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ApplyForLoan provides a mock function with given fields: c, loan, userid
+func (_m *LoanRepository) ApplyForLoan(c context.Context, loan *domain.Loan, userid string) error {
+	ret := _m.Called(c, loan, userid)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ApplyForLoan")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*domain.Loan, string) error); ok {
-		r0 = rf(loan, userid)
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Loan, string) error); ok {
+		r0 = rf(c, loan, userid)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -31,17 +64,17 @@ func (_m *LoanRepository) ApplyForLoan(loan *domain.Loan, userid string) error {
 	return r0
 }
 
-// ApproveRejectLoan provides a mock function with given fields: loanID, status, userid
-func (_m *LoanRepository) ApproveRejectLoan(loanID string, status string, userid string) error {
-	ret := _m.Called(loanID, status, userid)
+// ApproveRejectLoan provides a mock function with given fields: c, loanID, status, rejection
+func (_m *LoanRepository) ApproveRejectLoan(c context.Context, loanID string, status string, rejection *domain.Rejection) error {
+	ret := _m.Called(c, loanID, status, rejection)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ApproveRejectLoan")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
-		r0 = rf(loanID, status, userid)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *domain.Rejection) error); ok {
+		r0 = rf(c, loanID, status, rejection)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -49,17 +82,17 @@ func (_m *LoanRepository) ApproveRejectLoan(loanID string, status string, userid
 	return r0
 }
 
-// DeleteLoan provides a mock function with given fields: loanID, userid
-func (_m *LoanRepository) DeleteLoan(loanID string, userid string) error {
-	ret := _m.Called(loanID, userid)
+// DeleteLoan provides a mock function with given fields: c, loanID, reason, userid
+func (_m *LoanRepository) DeleteLoan(c context.Context, loanID string, reason string, userid string) error {
+	ret := _m.Called(c, loanID, reason, userid)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteLoan")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string) error); ok {
-		r0 = rf(loanID, userid)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(c, loanID, reason, userid)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -67,6 +100,34 @@ func (_m *LoanRepository) DeleteLoan(loanID string, userid string) error {
 	return r0
 }
 
+// GetLoan provides a mock function with given fields: loanID
+func (_m *LoanRepository) GetLoan(loanID string) (domain.Loan, error) {
+	ret := _m.Called(loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLoan")
+	}
+
+	var r0 domain.Loan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (domain.Loan, error)); ok {
+		return rf(loanID)
+	}
+	if rf, ok := ret.Get(0).(func(string) domain.Loan); ok {
+		r0 = rf(loanID)
+	} else {
+		r0 = ret.Get(0).(domain.Loan)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(loanID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // LoanDetails provides a mock function with given fields: loanID, userid
 func (_m *LoanRepository) LoanDetails(loanID string, userid string) (domain.Loan, error) {
 	ret := _m.Called(loanID, userid)
@@ -95,66 +156,153 @@ func (_m *LoanRepository) LoanDetails(loanID string, userid string) (domain.Loan
 	return r0, r1
 }
 
-// ViewAllLoans provides a mock function with given fields: pgnum, status, order
-func (_m *LoanRepository) ViewAllLoans(pgnum int, status string, order string) ([]domain.Loan, int, error) {
-	ret := _m.Called(pgnum, status, order)
+// LoansByStatus provides a mock function with given fields: status
+func (_m *LoanRepository) LoansByStatus(status string) ([]domain.Loan, error) {
+	ret := _m.Called(status)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ViewAllLoans")
+		panic("no return value specified for LoansByStatus")
 	}
 
 	var r0 []domain.Loan
-	var r1 int
-	var r2 error
-	if rf, ok := ret.Get(0).(func(int, string, string) ([]domain.Loan, int, error)); ok {
-		return rf(pgnum, status, order)
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]domain.Loan, error)); ok {
+		return rf(status)
 	}
-	if rf, ok := ret.Get(0).(func(int, string, string) []domain.Loan); ok {
-		r0 = rf(pgnum, status, order)
+	if rf, ok := ret.Get(0).(func(string) []domain.Loan); ok {
+		r0 = rf(status)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]domain.Loan)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(int, string, string) int); ok {
-		r1 = rf(pgnum, status, order)
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(status)
 	} else {
-		r1 = ret.Get(1).(int)
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PartyLoans provides a mock function with given fields: userid, email
+func (_m *LoanRepository) PartyLoans(userid string, email string) ([]domain.Loan, error) {
+	ret := _m.Called(userid, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PartyLoans")
 	}
 
-	if rf, ok := ret.Get(2).(func(int, string, string) error); ok {
-		r2 = rf(pgnum, status, order)
+	var r0 []domain.Loan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) ([]domain.Loan, error)); ok {
+		return rf(userid, email)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) []domain.Loan); ok {
+		r0 = rf(userid, email)
 	} else {
-		r2 = ret.Error(2)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Loan)
+		}
 	}
 
-	return r0, r1, r2
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(userid, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// ViewLogs provides a mock function with given fields:
-func (_m *LoanRepository) ViewLogs() ([]domain.Log, error) {
-	ret := _m.Called()
+// PurgeDeletedLoans provides a mock function with given fields: c, before
+func (_m *LoanRepository) PurgeDeletedLoans(c context.Context, before time.Time) (int, error) {
+	ret := _m.Called(c, before)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ViewLogs")
+		panic("no return value specified for PurgeDeletedLoans")
 	}
 
-	var r0 []domain.Log
+	var r0 int
 	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]domain.Log, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int, error)); ok {
+		return rf(c, before)
 	}
-	if rf, ok := ret.Get(0).(func() []domain.Log); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int); ok {
+		r0 = rf(c, before)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(c, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreLoan provides a mock function with given fields: c, loanID
+func (_m *LoanRepository) RestoreLoan(c context.Context, loanID string) error {
+	ret := _m.Called(c, loanID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreLoan")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(c, loanID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateLoan provides a mock function with given fields: c, loan
+func (_m *LoanRepository) UpdateLoan(c context.Context, loan *domain.Loan) error {
+	ret := _m.Called(c, loan)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateLoan")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Loan) error); ok {
+		r0 = rf(c, loan)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserLoans provides a mock function with given fields: userid
+func (_m *LoanRepository) UserLoans(userid string) ([]domain.Loan, error) {
+	ret := _m.Called(userid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UserLoans")
+	}
+
+	var r0 []domain.Loan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]domain.Loan, error)); ok {
+		return rf(userid)
+	}
+	if rf, ok := ret.Get(0).(func(string) []domain.Loan); ok {
+		r0 = rf(userid)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]domain.Log)
+			r0 = ret.Get(0).([]domain.Loan)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userid)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -162,6 +310,43 @@ func (_m *LoanRepository) ViewLogs() ([]domain.Log, error) {
 	return r0, r1
 }
 
+// ViewAllLoans provides a mock function with given fields: pgnum, status, order, dpd
+func (_m *LoanRepository) ViewAllLoans(pgnum int, status string, order string, dpd domain.DPDRange) ([]domain.Loan, int, error) {
+	ret := _m.Called(pgnum, status, order, dpd)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ViewAllLoans")
+	}
+
+	var r0 []domain.Loan
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(int, string, string, domain.DPDRange) ([]domain.Loan, int, error)); ok {
+		return rf(pgnum, status, order, dpd)
+	}
+	if rf, ok := ret.Get(0).(func(int, string, string, domain.DPDRange) []domain.Loan); ok {
+		r0 = rf(pgnum, status, order, dpd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Loan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int, string, string, domain.DPDRange) int); ok {
+		r1 = rf(pgnum, status, order, dpd)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(int, string, string, domain.DPDRange) error); ok {
+		r2 = rf(pgnum, status, order, dpd)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // NewLoanRepository creates a new instance of LoanRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewLoanRepository(t interface {
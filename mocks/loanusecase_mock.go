@@ -7,6 +7,8 @@ import (
 	domain "loan_tracker_api/domain"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // LoanUsecase is an autogenerated mock type for the LoanUsecase type
@@ -14,6 +16,36 @@ type LoanUsecase struct {
 	mock.Mock
 }
 
+// AgingReport provides a mock function with given fields: c
+func (_m *LoanUsecase) AgingReport(c context.Context) ([]domain.AgingBucket, error) {
+	ret := _m.Called(c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AgingReport")
+	}
+
+	var r0 []domain.AgingBucket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.AgingBucket, error)); ok {
+		return rf(c)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.AgingBucket); ok {
+		r0 = rf(c)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.AgingBucket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(c)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ApplyForLoan provides a mock function with given fields: c, loan, userid
 func (_m *LoanUsecase) ApplyForLoan(c context.Context, loan *domain.Loan, userid string) error {
 	ret := _m.Called(c, loan, userid)
@@ -32,17 +64,17 @@ func (_m *LoanUsecase) ApplyForLoan(c context.Context, loan *domain.Loan, userid
 	return r0
 }
 
-// ApproveRejectLoan provides a mock function with given fields: c, loanID, status, userid
-func (_m *LoanUsecase) ApproveRejectLoan(c context.Context, loanID string, status string, userid string) error {
-	ret := _m.Called(c, loanID, status, userid)
+// ApproveRejectLoan provides a mock function with given fields: c, loanID, status, reason, userid
+func (_m *LoanUsecase) ApproveRejectLoan(c context.Context, loanID string, status string, reason string, userid string) error {
+	ret := _m.Called(c, loanID, status, reason, userid)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ApproveRejectLoan")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
-		r0 = rf(c, loanID, status, userid)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) error); ok {
+		r0 = rf(c, loanID, status, reason, userid)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -50,17 +82,53 @@ func (_m *LoanUsecase) ApproveRejectLoan(c context.Context, loanID string, statu
 	return r0
 }
 
-// DeleteLoan provides a mock function with given fields: c, loanID, userid
-func (_m *LoanUsecase) DeleteLoan(c context.Context, loanID string, userid string) error {
-	ret := _m.Called(c, loanID, userid)
+// AssessOverdueLoans provides a mock function with given fields: c
+func (_m *LoanUsecase) AssessOverdueLoans(c context.Context) error {
+	ret := _m.Called(c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AssessOverdueLoans")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DecideWriteOff provides a mock function with given fields: c, loanID, approve, userid
+func (_m *LoanUsecase) DecideWriteOff(c context.Context, loanID string, approve bool, userid string) error {
+	ret := _m.Called(c, loanID, approve, userid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DecideWriteOff")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool, string) error); ok {
+		r0 = rf(c, loanID, approve, userid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteLoan provides a mock function with given fields: c, loanID, reason, userid
+func (_m *LoanUsecase) DeleteLoan(c context.Context, loanID string, reason string, userid string) error {
+	ret := _m.Called(c, loanID, reason, userid)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteLoan")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
-		r0 = rf(c, loanID, userid)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(c, loanID, reason, userid)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -96,9 +164,281 @@ func (_m *LoanUsecase) LoanDetails(c context.Context, loanID string, userid stri
 	return r0, r1
 }
 
-// ViewAllLoans provides a mock function with given fields: c, pgnum, status, order
-func (_m *LoanUsecase) ViewAllLoans(c context.Context, pgnum int, status string, order string) ([]domain.Loan, int, error) {
-	ret := _m.Called(c, pgnum, status, order)
+// MakePayment provides a mock function with given fields: c, loanID, amount, quoteID, userid
+func (_m *LoanUsecase) MakePayment(c context.Context, loanID string, amount float64, quoteID string, userid string) (domain.Payment, error) {
+	ret := _m.Called(c, loanID, amount, quoteID, userid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MakePayment")
+	}
+
+	var r0 domain.Payment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, float64, string, string) (domain.Payment, error)); ok {
+		return rf(c, loanID, amount, quoteID, userid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, float64, string, string) domain.Payment); ok {
+		r0 = rf(c, loanID, amount, quoteID, userid)
+	} else {
+		r0 = ret.Get(0).(domain.Payment)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, float64, string, string) error); ok {
+		r1 = rf(c, loanID, amount, quoteID, userid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NoticeOverduePayments provides a mock function with given fields: c, days
+func (_m *LoanUsecase) NoticeOverduePayments(c context.Context, days []int) (int, error) {
+	ret := _m.Called(c, days)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NoticeOverduePayments")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int) (int, error)); ok {
+		return rf(c, days)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int) int); ok {
+		r0 = rf(c, days)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int) error); ok {
+		r1 = rf(c, days)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PayoffQuote provides a mock function with given fields: c, loanID, date, userid
+func (_m *LoanUsecase) PayoffQuote(c context.Context, loanID string, date time.Time, userid string) (domain.PayoffQuote, error) {
+	ret := _m.Called(c, loanID, date, userid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PayoffQuote")
+	}
+
+	var r0 domain.PayoffQuote
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, string) (domain.PayoffQuote, error)); ok {
+		return rf(c, loanID, date, userid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, string) domain.PayoffQuote); ok {
+		r0 = rf(c, loanID, date, userid)
+	} else {
+		r0 = ret.Get(0).(domain.PayoffQuote)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, string) error); ok {
+		r1 = rf(c, loanID, date, userid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PostRecovery provides a mock function with given fields: c, loanID, amount, note, userid
+func (_m *LoanUsecase) PostRecovery(c context.Context, loanID string, amount float64, note string, userid string) (domain.Recovery, error) {
+	ret := _m.Called(c, loanID, amount, note, userid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PostRecovery")
+	}
+
+	var r0 domain.Recovery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, float64, string, string) (domain.Recovery, error)); ok {
+		return rf(c, loanID, amount, note, userid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, float64, string, string) domain.Recovery); ok {
+		r0 = rf(c, loanID, amount, note, userid)
+	} else {
+		r0 = ret.Get(0).(domain.Recovery)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, float64, string, string) error); ok {
+		r1 = rf(c, loanID, amount, note, userid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeDeletedLoans provides a mock function with given fields: c, retention
+func (_m *LoanUsecase) PurgeDeletedLoans(c context.Context, retention time.Duration) (int, error) {
+	ret := _m.Called(c, retention)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeDeletedLoans")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) (int, error)); ok {
+		return rf(c, retention)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) int); ok {
+		r0 = rf(c, retention)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Duration) error); ok {
+		r1 = rf(c, retention)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemindUpcomingPayments provides a mock function with given fields: c, days
+func (_m *LoanUsecase) RemindUpcomingPayments(c context.Context, days []int) (int, error) {
+	ret := _m.Called(c, days)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemindUpcomingPayments")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int) (int, error)); ok {
+		return rf(c, days)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int) int); ok {
+		r0 = rf(c, days)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int) error); ok {
+		r1 = rf(c, days)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RespondToLoan provides a mock function with given fields: c, loanID, partyID, accept, userid
+func (_m *LoanUsecase) RespondToLoan(c context.Context, loanID string, partyID string, accept bool, userid string) (domain.Loan, error) {
+	ret := _m.Called(c, loanID, partyID, accept, userid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RespondToLoan")
+	}
+
+	var r0 domain.Loan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool, string) (domain.Loan, error)); ok {
+		return rf(c, loanID, partyID, accept, userid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool, string) domain.Loan); ok {
+		r0 = rf(c, loanID, partyID, accept, userid)
+	} else {
+		r0 = ret.Get(0).(domain.Loan)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, bool, string) error); ok {
+		r1 = rf(c, loanID, partyID, accept, userid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreLoan provides a mock function with given fields: c, loanID, userid
+func (_m *LoanUsecase) RestoreLoan(c context.Context, loanID string, userid string) error {
+	ret := _m.Called(c, loanID, userid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreLoan")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(c, loanID, userid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestructureLoan provides a mock function with given fields: c, loanID, terms, userid
+func (_m *LoanUsecase) RestructureLoan(c context.Context, loanID string, terms domain.Restructure, userid string) (domain.Loan, error) {
+	ret := _m.Called(c, loanID, terms, userid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestructureLoan")
+	}
+
+	var r0 domain.Loan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.Restructure, string) (domain.Loan, error)); ok {
+		return rf(c, loanID, terms, userid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.Restructure, string) domain.Loan); ok {
+		r0 = rf(c, loanID, terms, userid)
+	} else {
+		r0 = ret.Get(0).(domain.Loan)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, domain.Restructure, string) error); ok {
+		r1 = rf(c, loanID, terms, userid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserLoans provides a mock function with given fields: c, userid
+func (_m *LoanUsecase) UserLoans(c context.Context, userid string) ([]domain.UserLoan, error) {
+	ret := _m.Called(c, userid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UserLoans")
+	}
+
+	var r0 []domain.UserLoan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.UserLoan, error)); ok {
+		return rf(c, userid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.UserLoan); ok {
+		r0 = rf(c, userid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.UserLoan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(c, userid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ViewAllLoans provides a mock function with given fields: c, pgnum, status, order, dpd
+func (_m *LoanUsecase) ViewAllLoans(c context.Context, pgnum int, status string, order string, dpd domain.DPDRange) ([]domain.Loan, int, error) {
+	ret := _m.Called(c, pgnum, status, order, dpd)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ViewAllLoans")
@@ -107,25 +447,25 @@ func (_m *LoanUsecase) ViewAllLoans(c context.Context, pgnum int, status string,
 	var r0 []domain.Loan
 	var r1 int
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, string, string) ([]domain.Loan, int, error)); ok {
-		return rf(c, pgnum, status, order)
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, string, domain.DPDRange) ([]domain.Loan, int, error)); ok {
+		return rf(c, pgnum, status, order, dpd)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, string, string) []domain.Loan); ok {
-		r0 = rf(c, pgnum, status, order)
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, string, domain.DPDRange) []domain.Loan); ok {
+		r0 = rf(c, pgnum, status, order, dpd)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]domain.Loan)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int, string, string) int); ok {
-		r1 = rf(c, pgnum, status, order)
+	if rf, ok := ret.Get(1).(func(context.Context, int, string, string, domain.DPDRange) int); ok {
+		r1 = rf(c, pgnum, status, order, dpd)
 	} else {
 		r1 = ret.Get(1).(int)
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, int, string, string) error); ok {
-		r2 = rf(c, pgnum, status, order)
+	if rf, ok := ret.Get(2).(func(context.Context, int, string, string, domain.DPDRange) error); ok {
+		r2 = rf(c, pgnum, status, order, dpd)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -133,25 +473,59 @@ func (_m *LoanUsecase) ViewAllLoans(c context.Context, pgnum int, status string,
 	return r0, r1, r2
 }
 
-// ViewLogs provides a mock function with given fields: c
-func (_m *LoanUsecase) ViewLogs(c context.Context) ([]domain.Log, error) {
+// WaiveCharge provides a mock function with given fields: c, loanID, chargeID, reason, userid
+func (_m *LoanUsecase) WaiveCharge(c context.Context, loanID string, chargeID string, reason string, userid string) error {
+	ret := _m.Called(c, loanID, chargeID, reason, userid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WaiveCharge")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) error); ok {
+		r0 = rf(c, loanID, chargeID, reason, userid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WriteOffLoan provides a mock function with given fields: c, loanID, reason, userid
+func (_m *LoanUsecase) WriteOffLoan(c context.Context, loanID string, reason string, userid string) error {
+	ret := _m.Called(c, loanID, reason, userid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WriteOffLoan")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(c, loanID, reason, userid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WriteOffReport provides a mock function with given fields: c
+func (_m *LoanUsecase) WriteOffReport(c context.Context) (domain.WriteOffReport, error) {
 	ret := _m.Called(c)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ViewLogs")
+		panic("no return value specified for WriteOffReport")
 	}
 
-	var r0 []domain.Log
+	var r0 domain.WriteOffReport
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.Log, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context) (domain.WriteOffReport, error)); ok {
 		return rf(c)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) []domain.Log); ok {
+	if rf, ok := ret.Get(0).(func(context.Context) domain.WriteOffReport); ok {
 		r0 = rf(c)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]domain.Log)
-		}
+		r0 = ret.Get(0).(domain.WriteOffReport)
 	}
 
 	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
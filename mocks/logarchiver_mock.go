@@ -0,0 +1,86 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LogArchiver is an autogenerated mock type for the LogArchiver type
+type LogArchiver struct {
+	mock.Mock
+}
+
+// ArchiveLogs provides a mock function with given fields: entries
+func (_m *LogArchiver) ArchiveLogs(entries []domain.Log) (string, error) {
+	ret := _m.Called(entries)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ArchiveLogs")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]domain.Log) (string, error)); ok {
+		return rf(entries)
+	}
+	if rf, ok := ret.Get(0).(func([]domain.Log) string); ok {
+		r0 = rf(entries)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func([]domain.Log) error); ok {
+		r1 = rf(entries)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ArchivedLogs provides a mock function with given fields: from, to
+func (_m *LogArchiver) ArchivedLogs(from int64, to int64) ([]domain.Log, error) {
+	ret := _m.Called(from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ArchivedLogs")
+	}
+
+	var r0 []domain.Log
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, int64) ([]domain.Log, error)); ok {
+		return rf(from, to)
+	}
+	if rf, ok := ret.Get(0).(func(int64, int64) []domain.Log); ok {
+		r0 = rf(from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Log)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, int64) error); ok {
+		r1 = rf(from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewLogArchiver creates a new instance of LogArchiver. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLogArchiver(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LogArchiver {
+	mock := &LogArchiver{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
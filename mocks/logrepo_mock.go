@@ -0,0 +1,205 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+
+	time "time"
+)
+
+// LogRepository is an autogenerated mock type for the LogRepository type
+type LogRepository struct {
+	mock.Mock
+}
+
+// AddLog provides a mock function with given fields: c, entry
+func (_m *LogRepository) AddLog(c context.Context, entry *domain.Log) error {
+	ret := _m.Called(c, entry)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddLog")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Log) error); ok {
+		r0 = rf(c, entry)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ArchivableLogs provides a mock function with given fields: cutoffs, limit
+func (_m *LogRepository) ArchivableLogs(cutoffs map[string]time.Time, limit int) ([]domain.Log, error) {
+	ret := _m.Called(cutoffs, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ArchivableLogs")
+	}
+
+	var r0 []domain.Log
+	var r1 error
+	if rf, ok := ret.Get(0).(func(map[string]time.Time, int) ([]domain.Log, error)); ok {
+		return rf(cutoffs, limit)
+	}
+	if rf, ok := ret.Get(0).(func(map[string]time.Time, int) []domain.Log); ok {
+		r0 = rf(cutoffs, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Log)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(map[string]time.Time, int) error); ok {
+		r1 = rf(cutoffs, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ChainedLogs provides a mock function with given fields: after, limit
+func (_m *LogRepository) ChainedLogs(after int64, limit int) ([]domain.Log, error) {
+	ret := _m.Called(after, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChainedLogs")
+	}
+
+	var r0 []domain.Log
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, int) ([]domain.Log, error)); ok {
+		return rf(after, limit)
+	}
+	if rf, ok := ret.Get(0).(func(int64, int) []domain.Log); ok {
+		r0 = rf(after, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Log)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, int) error); ok {
+		r1 = rf(after, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LastLog provides a mock function with given fields:
+func (_m *LogRepository) LastLog() (domain.Log, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for LastLog")
+	}
+
+	var r0 domain.Log
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (domain.Log, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() domain.Log); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(domain.Log)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkArchived provides a mock function with given fields: c, ids
+func (_m *LogRepository) MarkArchived(c context.Context, ids []primitive.ObjectID) error {
+	ret := _m.Called(c, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkArchived")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []primitive.ObjectID) error); ok {
+		r0 = rf(c, ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StreamLogs provides a mock function with given fields: filter, each
+func (_m *LogRepository) StreamLogs(filter domain.LogFilter, each func(domain.Log) error) error {
+	ret := _m.Called(filter, each)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StreamLogs")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(domain.LogFilter, func(domain.Log) error) error); ok {
+		r0 = rf(filter, each)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ViewLogs provides a mock function with given fields: filter
+func (_m *LogRepository) ViewLogs(filter domain.LogFilter) ([]domain.Log, error) {
+	ret := _m.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ViewLogs")
+	}
+
+	var r0 []domain.Log
+	var r1 error
+	if rf, ok := ret.Get(0).(func(domain.LogFilter) ([]domain.Log, error)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(domain.LogFilter) []domain.Log); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Log)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(domain.LogFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewLogRepository creates a new instance of LogRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLogRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LogRepository {
+	mock := &LogRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,161 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LogUsecase is an autogenerated mock type for the LogUsecase type
+type LogUsecase struct {
+	mock.Mock
+}
+
+// ArchiveLogs provides a mock function with given fields: c
+func (_m *LogUsecase) ArchiveLogs(c context.Context) (int, error) {
+	ret := _m.Called(c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ArchiveLogs")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(c)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(c)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(c)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CheckpointLogs provides a mock function with given fields: c
+func (_m *LogUsecase) CheckpointLogs(c context.Context) (domain.LogCheckpoint, error) {
+	ret := _m.Called(c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckpointLogs")
+	}
+
+	var r0 domain.LogCheckpoint
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (domain.LogCheckpoint, error)); ok {
+		return rf(c)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) domain.LogCheckpoint); ok {
+		r0 = rf(c)
+	} else {
+		r0 = ret.Get(0).(domain.LogCheckpoint)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(c)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExportLogs provides a mock function with given fields: c, filter, each
+func (_m *LogUsecase) ExportLogs(c context.Context, filter domain.LogFilter, each func(domain.Log) error) error {
+	ret := _m.Called(c, filter, each)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportLogs")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.LogFilter, func(domain.Log) error) error); ok {
+		r0 = rf(c, filter, each)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// VerifyLogs provides a mock function with given fields: c
+func (_m *LogUsecase) VerifyLogs(c context.Context) (domain.LogVerification, error) {
+	ret := _m.Called(c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyLogs")
+	}
+
+	var r0 domain.LogVerification
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (domain.LogVerification, error)); ok {
+		return rf(c)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) domain.LogVerification); ok {
+		r0 = rf(c)
+	} else {
+		r0 = ret.Get(0).(domain.LogVerification)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(c)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ViewLogs provides a mock function with given fields: c, filter
+func (_m *LogUsecase) ViewLogs(c context.Context, filter domain.LogFilter) ([]domain.Log, error) {
+	ret := _m.Called(c, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ViewLogs")
+	}
+
+	var r0 []domain.Log
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.LogFilter) ([]domain.Log, error)); ok {
+		return rf(c, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.LogFilter) []domain.Log); ok {
+		r0 = rf(c, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Log)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.LogFilter) error); ok {
+		r1 = rf(c, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewLogUsecase creates a new instance of LogUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLogUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LogUsecase {
+	mock := &LogUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,47 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NotificationDispatcher is an autogenerated mock type for the NotificationDispatcher type
+type NotificationDispatcher struct {
+	mock.Mock
+}
+
+// Notify provides a mock function with given fields: c, user, kind, data
+func (_m *NotificationDispatcher) Notify(c context.Context, user domain.User, kind string, data map[string]interface{}) error {
+	ret := _m.Called(c, user, kind, data)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Notify")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.User, string, map[string]interface{}) error); ok {
+		r0 = rf(c, user, kind, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewNotificationDispatcher creates a new instance of NotificationDispatcher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewNotificationDispatcher(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *NotificationDispatcher {
+	mock := &NotificationDispatcher{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
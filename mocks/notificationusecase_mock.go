@@ -0,0 +1,112 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NotificationUsecase is an autogenerated mock type for the NotificationUsecase type
+type NotificationUsecase struct {
+	mock.Mock
+}
+
+// MarkAllRead provides a mock function with given fields: c, userid
+func (_m *NotificationUsecase) MarkAllRead(c context.Context, userid string) (int64, error) {
+	ret := _m.Called(c, userid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkAllRead")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int64, error)); ok {
+		return rf(c, userid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = rf(c, userid)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(c, userid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkRead provides a mock function with given fields: c, userid, id
+func (_m *NotificationUsecase) MarkRead(c context.Context, userid string, id string) error {
+	ret := _m.Called(c, userid, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkRead")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(c, userid, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Notifications provides a mock function with given fields: c, userid, unreadOnly, page
+func (_m *NotificationUsecase) Notifications(c context.Context, userid string, unreadOnly bool, page int) ([]domain.Notification, int64, error) {
+	ret := _m.Called(c, userid, unreadOnly, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Notifications")
+	}
+
+	var r0 []domain.Notification
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool, int) ([]domain.Notification, int64, error)); ok {
+		return rf(c, userid, unreadOnly, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool, int) []domain.Notification); ok {
+		r0 = rf(c, userid, unreadOnly, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Notification)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool, int) int64); ok {
+		r1 = rf(c, userid, unreadOnly, page)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, bool, int) error); ok {
+		r2 = rf(c, userid, unreadOnly, page)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// NewNotificationUsecase creates a new instance of NotificationUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewNotificationUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *NotificationUsecase {
+	mock := &NotificationUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,155 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// OutboxRepository is an autogenerated mock type for the OutboxRepository type
+type OutboxRepository struct {
+	mock.Mock
+}
+
+// AddMessage provides a mock function with given fields: c, message
+func (_m *OutboxRepository) AddMessage(c context.Context, message *domain.OutboxMessage) error {
+	ret := _m.Called(c, message)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.OutboxMessage) error); ok {
+		r0 = rf(c, message)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ClaimMessages provides a mock function with given fields: now, lease, limit
+func (_m *OutboxRepository) ClaimMessages(now time.Time, lease time.Duration, limit int) ([]domain.OutboxMessage, error) {
+	ret := _m.Called(now, lease, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimMessages")
+	}
+
+	var r0 []domain.OutboxMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time, time.Duration, int) ([]domain.OutboxMessage, error)); ok {
+		return rf(now, lease, limit)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time, time.Duration, int) []domain.OutboxMessage); ok {
+		r0 = rf(now, lease, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.OutboxMessage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time, time.Duration, int) error); ok {
+		r1 = rf(now, lease, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeadMessages provides a mock function with given fields: page
+func (_m *OutboxRepository) DeadMessages(page int) ([]domain.OutboxMessage, error) {
+	ret := _m.Called(page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeadMessages")
+	}
+
+	var r0 []domain.OutboxMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int) ([]domain.OutboxMessage, error)); ok {
+		return rf(page)
+	}
+	if rf, ok := ret.Get(0).(func(int) []domain.OutboxMessage); ok {
+		r0 = rf(page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.OutboxMessage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMessage provides a mock function with given fields: id
+func (_m *OutboxRepository) GetMessage(id string) (domain.OutboxMessage, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessage")
+	}
+
+	var r0 domain.OutboxMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (domain.OutboxMessage, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(string) domain.OutboxMessage); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(domain.OutboxMessage)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateMessage provides a mock function with given fields: message
+func (_m *OutboxRepository) UpdateMessage(message *domain.OutboxMessage) error {
+	ret := _m.Called(message)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*domain.OutboxMessage) error); ok {
+		r0 = rf(message)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewOutboxRepository creates a new instance of OutboxRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOutboxRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OutboxRepository {
+	mock := &OutboxRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,46 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// OutboxSender is an autogenerated mock type for the OutboxSender type
+type OutboxSender struct {
+	mock.Mock
+}
+
+// Send provides a mock function with given fields: message
+func (_m *OutboxSender) Send(message domain.OutboxMessage) error {
+	ret := _m.Called(message)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Send")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(domain.OutboxMessage) error); ok {
+		r0 = rf(message)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewOutboxSender creates a new instance of OutboxSender. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOutboxSender(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OutboxSender {
+	mock := &OutboxSender{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,105 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// OutboxUsecase is an autogenerated mock type for the OutboxUsecase type
+type OutboxUsecase struct {
+	mock.Mock
+}
+
+// DeadMessages provides a mock function with given fields: c, page
+func (_m *OutboxUsecase) DeadMessages(c context.Context, page int) ([]domain.OutboxMessage, error) {
+	ret := _m.Called(c, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeadMessages")
+	}
+
+	var r0 []domain.OutboxMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]domain.OutboxMessage, error)); ok {
+		return rf(c, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []domain.OutboxMessage); ok {
+		r0 = rf(c, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.OutboxMessage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(c, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DispatchOutbox provides a mock function with given fields: c
+func (_m *OutboxUsecase) DispatchOutbox(c context.Context) (int, error) {
+	ret := _m.Called(c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DispatchOutbox")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(c)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(c)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(c)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RetryMessage provides a mock function with given fields: c, id
+func (_m *OutboxUsecase) RetryMessage(c context.Context, id string) error {
+	ret := _m.Called(c, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetryMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(c, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewOutboxUsecase creates a new instance of OutboxUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOutboxUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OutboxUsecase {
+	mock := &OutboxUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
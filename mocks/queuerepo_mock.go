@@ -0,0 +1,188 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// QueueRepository is an autogenerated mock type for the QueueRepository type
+type QueueRepository struct {
+	mock.Mock
+}
+
+// ClaimNextJob provides a mock function with given fields: types, worker, now
+func (_m *QueueRepository) ClaimNextJob(types []string, worker string, now time.Time) (domain.QueuedJob, bool, error) {
+	ret := _m.Called(types, worker, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimNextJob")
+	}
+
+	var r0 domain.QueuedJob
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func([]string, string, time.Time) (domain.QueuedJob, bool, error)); ok {
+		return rf(types, worker, now)
+	}
+	if rf, ok := ret.Get(0).(func([]string, string, time.Time) domain.QueuedJob); ok {
+		r0 = rf(types, worker, now)
+	} else {
+		r0 = ret.Get(0).(domain.QueuedJob)
+	}
+
+	if rf, ok := ret.Get(1).(func([]string, string, time.Time) bool); ok {
+		r1 = rf(types, worker, now)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func([]string, string, time.Time) error); ok {
+		r2 = rf(types, worker, now)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// EnqueueJob provides a mock function with given fields: c, job
+func (_m *QueueRepository) EnqueueJob(c context.Context, job *domain.QueuedJob) (bool, error) {
+	ret := _m.Called(c, job)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnqueueJob")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.QueuedJob) (bool, error)); ok {
+		return rf(c, job)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.QueuedJob) bool); ok {
+		r0 = rf(c, job)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.QueuedJob) error); ok {
+		r1 = rf(c, job)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FinishJob provides a mock function with given fields: job
+func (_m *QueueRepository) FinishJob(job *domain.QueuedJob) error {
+	ret := _m.Called(job)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FinishJob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*domain.QueuedJob) error); ok {
+		r0 = rf(job)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetJob provides a mock function with given fields: id
+func (_m *QueueRepository) GetJob(id string) (domain.QueuedJob, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetJob")
+	}
+
+	var r0 domain.QueuedJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (domain.QueuedJob, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(string) domain.QueuedJob); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(domain.QueuedJob)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListJobs provides a mock function with given fields: filter
+func (_m *QueueRepository) ListJobs(filter domain.JobFilter) ([]domain.QueuedJob, error) {
+	ret := _m.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListJobs")
+	}
+
+	var r0 []domain.QueuedJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(domain.JobFilter) ([]domain.QueuedJob, error)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(domain.JobFilter) []domain.QueuedJob); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.QueuedJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(domain.JobFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateJob provides a mock function with given fields: job, from
+func (_m *QueueRepository) UpdateJob(job *domain.QueuedJob, from string) error {
+	ret := _m.Called(job, from)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateJob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*domain.QueuedJob, string) error); ok {
+		r0 = rf(job, from)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewQueueRepository creates a new instance of QueueRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewQueueRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *QueueRepository {
+	mock := &QueueRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,143 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// SchedulerRepository is an autogenerated mock type for the SchedulerRepository type
+type SchedulerRepository struct {
+	mock.Mock
+}
+
+// AddJobRun provides a mock function with given fields: c, run
+func (_m *SchedulerRepository) AddJobRun(c context.Context, run *domain.JobRun) error {
+	ret := _m.Called(c, run)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddJobRun")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.JobRun) error); ok {
+		r0 = rf(c, run)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ClaimJob provides a mock function with given fields: c, job, scheduledAt, instance, lease
+func (_m *SchedulerRepository) ClaimJob(c context.Context, job string, scheduledAt time.Time, instance string, lease time.Duration) (bool, error) {
+	ret := _m.Called(c, job, scheduledAt, instance, lease)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimJob")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, string, time.Duration) (bool, error)); ok {
+		return rf(c, job, scheduledAt, instance, lease)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, string, time.Duration) bool); ok {
+		r0 = rf(c, job, scheduledAt, instance, lease)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, string, time.Duration) error); ok {
+		r1 = rf(c, job, scheduledAt, instance, lease)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FinishJobRun provides a mock function with given fields: c, run
+func (_m *SchedulerRepository) FinishJobRun(c context.Context, run *domain.JobRun) error {
+	ret := _m.Called(c, run)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FinishJobRun")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.JobRun) error); ok {
+		r0 = rf(c, run)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// JobRuns provides a mock function with given fields: job, page
+func (_m *SchedulerRepository) JobRuns(job string, page int) ([]domain.JobRun, error) {
+	ret := _m.Called(job, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for JobRuns")
+	}
+
+	var r0 []domain.JobRun
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int) ([]domain.JobRun, error)); ok {
+		return rf(job, page)
+	}
+	if rf, ok := ret.Get(0).(func(string, int) []domain.JobRun); ok {
+		r0 = rf(job, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.JobRun)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(job, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReleaseJob provides a mock function with given fields: c, job, instance
+func (_m *SchedulerRepository) ReleaseJob(c context.Context, job string, instance string) error {
+	ret := _m.Called(c, job, instance)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReleaseJob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(c, job, instance)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewSchedulerRepository creates a new instance of SchedulerRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSchedulerRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SchedulerRepository {
+	mock := &SchedulerRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
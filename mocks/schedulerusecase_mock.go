@@ -0,0 +1,79 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SchedulerUsecase is an autogenerated mock type for the SchedulerUsecase type
+type SchedulerUsecase struct {
+	mock.Mock
+}
+
+// JobRuns provides a mock function with given fields: c, job, page
+func (_m *SchedulerUsecase) JobRuns(c context.Context, job string, page int) ([]domain.JobRun, error) {
+	ret := _m.Called(c, job, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for JobRuns")
+	}
+
+	var r0 []domain.JobRun
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]domain.JobRun, error)); ok {
+		return rf(c, job, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []domain.JobRun); ok {
+		r0 = rf(c, job, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.JobRun)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(c, job, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Jobs provides a mock function with given fields: c
+func (_m *SchedulerUsecase) Jobs(c context.Context) []domain.ScheduledJob {
+	ret := _m.Called(c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Jobs")
+	}
+
+	var r0 []domain.ScheduledJob
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.ScheduledJob); ok {
+		r0 = rf(c)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ScheduledJob)
+		}
+	}
+
+	return r0
+}
+
+// NewSchedulerUsecase creates a new instance of SchedulerUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSchedulerUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SchedulerUsecase {
+	mock := &SchedulerUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,109 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "loan_tracker_api/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// StreamRepository is an autogenerated mock type for the StreamRepository type
+type StreamRepository struct {
+	mock.Mock
+}
+
+// AddStreamEvent provides a mock function with given fields: c, event
+func (_m *StreamRepository) AddStreamEvent(c context.Context, event *domain.StreamEvent) error {
+	ret := _m.Called(c, event)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddStreamEvent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.StreamEvent) error); ok {
+		r0 = rf(c, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RecentStreamEvents provides a mock function with given fields: since, limit
+func (_m *StreamRepository) RecentStreamEvents(since time.Time, limit int) ([]domain.StreamEvent, error) {
+	ret := _m.Called(since, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecentStreamEvents")
+	}
+
+	var r0 []domain.StreamEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time, int) ([]domain.StreamEvent, error)); ok {
+		return rf(since, limit)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time, int) []domain.StreamEvent); ok {
+		r0 = rf(since, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.StreamEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time, int) error); ok {
+		r1 = rf(since, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StreamEventsAfter provides a mock function with given fields: lastID, userID, limit
+func (_m *StreamRepository) StreamEventsAfter(lastID string, userID string, limit int) ([]domain.StreamEvent, error) {
+	ret := _m.Called(lastID, userID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StreamEventsAfter")
+	}
+
+	var r0 []domain.StreamEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, int) ([]domain.StreamEvent, error)); ok {
+		return rf(lastID, userID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, int) []domain.StreamEvent); ok {
+		r0 = rf(lastID, userID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.StreamEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, int) error); ok {
+		r1 = rf(lastID, userID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewStreamRepository creates a new instance of StreamRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStreamRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *StreamRepository {
+	mock := &StreamRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
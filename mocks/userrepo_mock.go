@@ -3,9 +3,14 @@
 package mocks
 
 import (
+	context "context"
 	domain "loan_tracker_api/domain"
 
 	mock "github.com/stretchr/testify/mock"
+
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+
+	time "time"
 )
 
 // UserRepository is an autogenerated mock type for the UserRepository type
@@ -13,35 +18,17 @@ type UserRepository struct {
 	mock.Mock
 }
 
-// DeleteUser provides a mock function with given fields: uid
-func (_m *UserRepository) DeleteUser(uid string) error {
-	ret := _m.Called(uid)
+// DeleteUser provides a mock function with given fields: c, user
+func (_m *UserRepository) DeleteUser(c context.Context, user *domain.User) error {
+	ret := _m.Called(c, user)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteUser")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(uid)
-	} else {
-		r0 = ret.Error(0)
-	}
-
-	return r0
-}
-
-// ForgotPassword provides a mock function with given fields: email
-func (_m *UserRepository) ForgotPassword(email string) error {
-	ret := _m.Called(email)
-
-	if len(ret) == 0 {
-		panic("no return value specified for ForgotPassword")
-	}
-
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(email)
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.User) error); ok {
+		r0 = rf(c, user)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -49,9 +36,9 @@ func (_m *UserRepository) ForgotPassword(email string) error {
 	return r0
 }
 
-// LoginUser provides a mock function with given fields: user
-func (_m *UserRepository) LoginUser(user domain.User) (string, string, error) {
-	ret := _m.Called(user)
+// LoginUser provides a mock function with given fields: c, user
+func (_m *UserRepository) LoginUser(c context.Context, user domain.User) (string, string, error) {
+	ret := _m.Called(c, user)
 
 	if len(ret) == 0 {
 		panic("no return value specified for LoginUser")
@@ -60,23 +47,23 @@ func (_m *UserRepository) LoginUser(user domain.User) (string, string, error) {
 	var r0 string
 	var r1 string
 	var r2 error
-	if rf, ok := ret.Get(0).(func(domain.User) (string, string, error)); ok {
-		return rf(user)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.User) (string, string, error)); ok {
+		return rf(c, user)
 	}
-	if rf, ok := ret.Get(0).(func(domain.User) string); ok {
-		r0 = rf(user)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.User) string); ok {
+		r0 = rf(c, user)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(domain.User) string); ok {
-		r1 = rf(user)
+	if rf, ok := ret.Get(1).(func(context.Context, domain.User) string); ok {
+		r1 = rf(c, user)
 	} else {
 		r1 = ret.Get(1).(string)
 	}
 
-	if rf, ok := ret.Get(2).(func(domain.User) error); ok {
-		r2 = rf(user)
+	if rf, ok := ret.Get(2).(func(context.Context, domain.User) error); ok {
+		r2 = rf(c, user)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -84,17 +71,17 @@ func (_m *UserRepository) LoginUser(user domain.User) (string, string, error) {
 	return r0, r1, r2
 }
 
-// LogoutUser provides a mock function with given fields: uid
-func (_m *UserRepository) LogoutUser(uid string) error {
-	ret := _m.Called(uid)
+// LogoutUser provides a mock function with given fields: c, uid
+func (_m *UserRepository) LogoutUser(c context.Context, uid string) error {
+	ret := _m.Called(c, uid)
 
 	if len(ret) == 0 {
 		panic("no return value specified for LogoutUser")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(uid)
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(c, uid)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -102,17 +89,47 @@ func (_m *UserRepository) LogoutUser(uid string) error {
 	return r0
 }
 
-// RegisterUser provides a mock function with given fields: user
-func (_m *UserRepository) RegisterUser(user *domain.User) error {
-	ret := _m.Called(user)
+// PurgeDeletedUsers provides a mock function with given fields: c, before
+func (_m *UserRepository) PurgeDeletedUsers(c context.Context, before time.Time) ([]primitive.ObjectID, error) {
+	ret := _m.Called(c, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeDeletedUsers")
+	}
+
+	var r0 []primitive.ObjectID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]primitive.ObjectID, error)); ok {
+		return rf(c, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []primitive.ObjectID); ok {
+		r0 = rf(c, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]primitive.ObjectID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(c, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RegisterUser provides a mock function with given fields: c, user
+func (_m *UserRepository) RegisterUser(c context.Context, user *domain.User) error {
+	ret := _m.Called(c, user)
 
 	if len(ret) == 0 {
 		panic("no return value specified for RegisterUser")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*domain.User) error); ok {
-		r0 = rf(user)
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.User) error); ok {
+		r0 = rf(c, user)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -120,17 +137,63 @@ func (_m *UserRepository) RegisterUser(user *domain.User) error {
 	return r0
 }
 
-// ResetPassword provides a mock function with given fields: token, newPassword
-func (_m *UserRepository) ResetPassword(token string, newPassword string) error {
-	ret := _m.Called(token, newPassword)
+// ResetPassword provides a mock function with given fields: c, token, newPassword
+func (_m *UserRepository) ResetPassword(c context.Context, token string, newPassword string) (domain.User, error) {
+	ret := _m.Called(c, token, newPassword)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ResetPassword")
 	}
 
+	var r0 domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (domain.User, error)); ok {
+		return rf(c, token, newPassword)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) domain.User); ok {
+		r0 = rf(c, token, newPassword)
+	} else {
+		r0 = ret.Get(0).(domain.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(c, token, newPassword)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreUser provides a mock function with given fields: c, uid
+func (_m *UserRepository) RestoreUser(c context.Context, uid string) error {
+	ret := _m.Called(c, uid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(c, uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetKYCStatus provides a mock function with given fields: c, uid, status
+func (_m *UserRepository) SetKYCStatus(c context.Context, uid string, status string) error {
+	ret := _m.Called(c, uid, status)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetKYCStatus")
+	}
+
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string) error); ok {
-		r0 = rf(token, newPassword)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(c, uid, status)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -166,17 +229,17 @@ func (_m *UserRepository) TokenRefresh(uid string) (string, error) {
 	return r0, r1
 }
 
-// UpdateUserDetails provides a mock function with given fields: user
-func (_m *UserRepository) UpdateUserDetails(user *domain.User) error {
-	ret := _m.Called(user)
+// UpdateUserDetails provides a mock function with given fields: c, user
+func (_m *UserRepository) UpdateUserDetails(c context.Context, user *domain.User) error {
+	ret := _m.Called(c, user)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateUserDetails")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*domain.User) error); ok {
-		r0 = rf(user)
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.User) error); ok {
+		r0 = rf(c, user)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -184,6 +247,34 @@ func (_m *UserRepository) UpdateUserDetails(user *domain.User) error {
 	return r0
 }
 
+// UserByEmail provides a mock function with given fields: email
+func (_m *UserRepository) UserByEmail(email string) (domain.User, error) {
+	ret := _m.Called(email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UserByEmail")
+	}
+
+	var r0 domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (domain.User, error)); ok {
+		return rf(email)
+	}
+	if rf, ok := ret.Get(0).(func(string) domain.User); ok {
+		r0 = rf(email)
+	} else {
+		r0 = ret.Get(0).(domain.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UserProfile provides a mock function with given fields: uid
 func (_m *UserRepository) UserProfile(uid string) (domain.User, error) {
 	ret := _m.Called(uid)
@@ -212,22 +303,32 @@ func (_m *UserRepository) UserProfile(uid string) (domain.User, error) {
 	return r0, r1
 }
 
-// VerifyUserEmail provides a mock function with given fields: token
-func (_m *UserRepository) VerifyUserEmail(token string) error {
-	ret := _m.Called(token)
+// VerifyUserEmail provides a mock function with given fields: c, token
+func (_m *UserRepository) VerifyUserEmail(c context.Context, token string) (domain.User, error) {
+	ret := _m.Called(c, token)
 
 	if len(ret) == 0 {
 		panic("no return value specified for VerifyUserEmail")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(token)
+	var r0 domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.User, error)); ok {
+		return rf(c, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.User); ok {
+		r0 = rf(c, token)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(domain.User)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(c, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // ViewAllUsers provides a mock function with given fields:
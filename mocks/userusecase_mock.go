@@ -7,6 +7,8 @@ import (
 	domain "loan_tracker_api/domain"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // UserUsecase is an autogenerated mock type for the UserUsecase type
@@ -14,17 +16,17 @@ type UserUsecase struct {
 	mock.Mock
 }
 
-// DeleteUser provides a mock function with given fields: c, uid
-func (_m *UserUsecase) DeleteUser(c context.Context, uid string) error {
-	ret := _m.Called(c, uid)
+// DeleteUser provides a mock function with given fields: c, uid, reason, adminid
+func (_m *UserUsecase) DeleteUser(c context.Context, uid string, reason string, adminid string) error {
+	ret := _m.Called(c, uid, reason, adminid)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteUser")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
-		r0 = rf(c, uid)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(c, uid, reason, adminid)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -103,6 +105,34 @@ func (_m *UserUsecase) LogoutUser(c context.Context, uid string) error {
 	return r0
 }
 
+// PurgeDeletedUsers provides a mock function with given fields: c, retention
+func (_m *UserUsecase) PurgeDeletedUsers(c context.Context, retention time.Duration) (int, error) {
+	ret := _m.Called(c, retention)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeDeletedUsers")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) (int, error)); ok {
+		return rf(c, retention)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) int); ok {
+		r0 = rf(c, retention)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Duration) error); ok {
+		r1 = rf(c, retention)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RegisterUser provides a mock function with given fields: c, user
 func (_m *UserUsecase) RegisterUser(c context.Context, user *domain.User) error {
 	ret := _m.Called(c, user)
@@ -139,6 +169,24 @@ func (_m *UserUsecase) ResetPassword(c context.Context, token string, newPasswor
 	return r0
 }
 
+// RestoreUser provides a mock function with given fields: c, uid, adminid
+func (_m *UserUsecase) RestoreUser(c context.Context, uid string, adminid string) error {
+	ret := _m.Called(c, uid, adminid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(c, uid, adminid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // TokenRefresh provides a mock function with given fields: c, uid
 func (_m *UserUsecase) TokenRefresh(c context.Context, uid string) (string, error) {
 	ret := _m.Called(c, uid)
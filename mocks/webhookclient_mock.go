@@ -0,0 +1,52 @@
+// Code generated by mockery v2.44.1. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// WebhookClient is an autogenerated mock type for the WebhookClient type
+type WebhookClient struct {
+	mock.Mock
+}
+
+// Post provides a mock function with given fields: url, headers, body
+func (_m *WebhookClient) Post(url string, headers map[string]string, body []byte) (int, error) {
+	ret := _m.Called(url, headers, body)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Post")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, map[string]string, []byte) (int, error)); ok {
+		return rf(url, headers, body)
+	}
+	if rf, ok := ret.Get(0).(func(string, map[string]string, []byte) int); ok {
+		r0 = rf(url, headers, body)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, map[string]string, []byte) error); ok {
+		r1 = rf(url, headers, body)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewWebhookClient creates a new instance of WebhookClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWebhookClient(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WebhookClient {
+	mock := &WebhookClient{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CollateralRepository represents the collateral repository contract
+type CollateralRepository struct {
+	collateralDB *mongo.Collection
+}
+
+// NewCollateralRepository creates a new instance of CollateralRepository
+func NewCollateralRepository(client *mongo.Client) domain.CollateralRepository {
+	collateralDB := client.Database("Loan-Tracker").Collection("Collateral")
+
+	_, err := collateralDB.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		// collateral is listed by owner, newest first
+		{Keys: bson.D{{Key: "owner_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		// and looked up by the loans it secures
+		{Keys: bson.D{{Key: "loan_ids", Value: 1}}},
+	})
+	if err != nil {
+		log.Println("Error creating collateral indexes:", err)
+	}
+
+	return &CollateralRepository{
+		collateralDB: collateralDB,
+	}
+}
+
+// AddCollateral stores newly registered collateral
+func (cr *CollateralRepository) AddCollateral(c context.Context, collateral *domain.Collateral) error {
+	collateral.ID = primitive.NewObjectID()
+	collateral.CreatedAt = time.Now()
+	collateral.UpdatedAt = collateral.CreatedAt
+	if collateral.LoanIDs == nil {
+		collateral.LoanIDs = []primitive.ObjectID{}
+	}
+
+	_, err := cr.collateralDB.InsertOne(c, collateral)
+	if err != nil {
+		return wrapError("Error storing collateral", err)
+	}
+
+	return nil
+}
+
+// GetCollateral returns collateral by its ID
+func (cr *CollateralRepository) GetCollateral(id string) (domain.Collateral, error) {
+	var collateral domain.Collateral
+
+	idObj, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return collateral, errors.New("Invalid collateral ID")
+	}
+
+	err = cr.collateralDB.FindOne(context.Background(), bson.M{"_id": idObj}).Decode(&collateral)
+	if err != nil {
+		return collateral, errors.New("Collateral not found")
+	}
+
+	return collateral, nil
+}
+
+// ListCollateral returns a page of the collateral of an owner, or of everyone when no owner is given, newest first
+func (cr *CollateralRepository) ListCollateral(ownerID string, page int) ([]domain.Collateral, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	filter := bson.M{}
+	if ownerID != "" {
+		ownerIDObj, err := primitive.ObjectIDFromHex(ownerID)
+		if err != nil {
+			return nil, errors.New("Invalid owner ID")
+		}
+		filter["owner_id"] = ownerIDObj
+	}
+
+	findoptions := options.Find()
+	findoptions.SetSkip(int64(perpage * (page - 1)))
+	findoptions.SetLimit(perpage)
+	findoptions.SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	collateral := []domain.Collateral{}
+	cursor, err := cr.collateralDB.Find(context.Background(), filter, findoptions)
+	if err != nil {
+		return nil, errors.New("Error fetching collateral")
+	}
+	defer cursor.Close(context.Background())
+	err = cursor.All(context.Background(), &collateral)
+	return collateral, err
+}
+
+// LoanCollateral returns the collateral securing a loan
+func (cr *CollateralRepository) LoanCollateral(loanID string) ([]domain.Collateral, error) {
+	loanIDObj, err := primitive.ObjectIDFromHex(loanID)
+	if err != nil {
+		return nil, errors.New("Invalid loan ID")
+	}
+
+	collateral := []domain.Collateral{}
+	cursor, err := cr.collateralDB.Find(context.Background(), bson.M{"loan_ids": loanIDObj}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, errors.New("Error fetching collateral")
+	}
+	defer cursor.Close(context.Background())
+	err = cursor.All(context.Background(), &collateral)
+	return collateral, err
+}
+
+// UpdateCollateral stores the appraisal, lien and loans of collateral
+func (cr *CollateralRepository) UpdateCollateral(c context.Context, collateral *domain.Collateral) error {
+	collateral.UpdatedAt = time.Now()
+
+	update := bson.M{"$set": bson.M{
+		"description":     collateral.Description,
+		"appraised_value": collateral.AppraisedValue,
+		"appraisal_date":  collateral.AppraisalDate,
+		"lien_status":     collateral.LienStatus,
+		"documents":       collateral.Documents,
+		"loan_ids":        collateral.LoanIDs,
+		"updated_at":      collateral.UpdatedAt,
+	}}
+
+	res, err := cr.collateralDB.UpdateOne(c, bson.M{"_id": collateral.ID}, update)
+	if err != nil {
+		return wrapError("Error updating collateral", err)
+	}
+
+	if res.MatchedCount == 0 {
+		return errors.New("Collateral not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CommentRepository represents the loan comment repository contract
+type CommentRepository struct {
+	commentDB *mongo.Collection
+}
+
+// NewCommentRepository creates a new instance of CommentRepository
+func NewCommentRepository(client *mongo.Client) domain.CommentRepository {
+	commentDB := client.Database("Loan-Tracker").Collection("Comments")
+
+	// the thread of a loan is read oldest first, with or without the internal comments
+	_, err := commentDB.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "loan_id", Value: 1}, {Key: "visibility", Value: 1}, {Key: "created_at", Value: 1}},
+	})
+	if err != nil {
+		log.Println("Error creating comment index:", err)
+	}
+
+	return &CommentRepository{
+		commentDB: commentDB,
+	}
+}
+
+// AddComment stores a new comment
+func (cr *CommentRepository) AddComment(c context.Context, comment *domain.Comment) error {
+	comment.ID = primitive.NewObjectID()
+	comment.CreatedAt = time.Now()
+	comment.UpdatedAt = comment.CreatedAt
+	if comment.Mentions == nil {
+		comment.Mentions = []primitive.ObjectID{}
+	}
+	if comment.Edits == nil {
+		comment.Edits = []domain.CommentEdit{}
+	}
+
+	_, err := cr.commentDB.InsertOne(c, comment)
+	if err != nil {
+		return wrapError("Error storing comment", err)
+	}
+
+	return nil
+}
+
+// GetComment returns a comment
+func (cr *CommentRepository) GetComment(id string) (domain.Comment, error) {
+	var comment domain.Comment
+
+	idObj, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return comment, errors.New("Invalid comment ID")
+	}
+
+	err = cr.commentDB.FindOne(context.Background(), bson.M{"_id": idObj}).Decode(&comment)
+	if err != nil {
+		return comment, errors.New("Comment not found")
+	}
+
+	return comment, nil
+}
+
+// LoanComments returns the comment thread of a loan, oldest first. Internal comments are left out unless asked for.
+func (cr *CommentRepository) LoanComments(loanID string, internal bool) ([]domain.Comment, error) {
+	loanIDObj, err := primitive.ObjectIDFromHex(loanID)
+	if err != nil {
+		return nil, errors.New("Invalid loan ID")
+	}
+
+	filter := bson.M{"loan_id": loanIDObj}
+	if !internal {
+		filter["visibility"] = domain.CommentBorrower
+	}
+
+	findoptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	comments := []domain.Comment{}
+	cursor, err := cr.commentDB.Find(context.Background(), filter, findoptions)
+	if err != nil {
+		return nil, errors.New("Error fetching comments")
+	}
+	defer cursor.Close(context.Background())
+	err = cursor.All(context.Background(), &comments)
+	return comments, err
+}
+
+// UpdateComment stores the body, mentions and edit history of a comment
+func (cr *CommentRepository) UpdateComment(c context.Context, comment *domain.Comment) error {
+	comment.UpdatedAt = time.Now()
+
+	update := bson.M{"$set": bson.M{
+		"body":       comment.Body,
+		"mentions":   comment.Mentions,
+		"edits":      comment.Edits,
+		"updated_at": comment.UpdatedAt,
+	}}
+
+	res, err := cr.commentDB.UpdateOne(c, bson.M{"_id": comment.ID}, update)
+	if err != nil {
+		return wrapError("Error updating comment", err)
+	}
+
+	if res.MatchedCount == 0 {
+		return errors.New("Comment not found")
+	}
+
+	return nil
+}
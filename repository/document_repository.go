@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DocumentRepository represents the loan document repository contract
+type DocumentRepository struct {
+	documentDB *mongo.Collection
+}
+
+// NewDocumentRepository creates a new instance of DocumentRepository
+func NewDocumentRepository(client *mongo.Client) domain.DocumentRepository {
+	documentDB := client.Database("Loan-Tracker").Collection("Documents")
+
+	// documents are listed by loan, oldest first
+	_, err := documentDB.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "loan_id", Value: 1}, {Key: "uploaded_at", Value: 1}},
+	})
+	if err != nil {
+		log.Println("Error creating document index:", err)
+	}
+
+	return &DocumentRepository{
+		documentDB: documentDB,
+	}
+}
+
+// AddDocument stores the record of an uploaded document
+func (dr *DocumentRepository) AddDocument(c context.Context, document *domain.Document) error {
+	if document.ID.IsZero() {
+		document.ID = primitive.NewObjectID()
+	}
+	if document.UploadedAt.IsZero() {
+		document.UploadedAt = time.Now()
+	}
+
+	_, err := dr.documentDB.InsertOne(c, document)
+	if err != nil {
+		return wrapError("Error storing document", err)
+	}
+
+	return nil
+}
+
+// GetDocument returns a document
+func (dr *DocumentRepository) GetDocument(id string) (domain.Document, error) {
+	var document domain.Document
+
+	idObj, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return document, errors.New("Invalid document ID")
+	}
+
+	err = dr.documentDB.FindOne(context.Background(), bson.M{"_id": idObj}).Decode(&document)
+	if err != nil {
+		return document, errors.New("Document not found")
+	}
+
+	return document, nil
+}
+
+// LoanDocuments returns the documents attached to a loan, oldest first
+func (dr *DocumentRepository) LoanDocuments(loanID string) ([]domain.Document, error) {
+	loanIDObj, err := primitive.ObjectIDFromHex(loanID)
+	if err != nil {
+		return nil, errors.New("Invalid loan ID")
+	}
+
+	findoptions := options.Find().SetSort(bson.D{{Key: "uploaded_at", Value: 1}})
+
+	documents := []domain.Document{}
+	cursor, err := dr.documentDB.Find(context.Background(), bson.M{"loan_id": loanIDObj}, findoptions)
+	if err != nil {
+		return nil, errors.New("Error fetching documents")
+	}
+	defer cursor.Close(context.Background())
+	err = cursor.All(context.Background(), &documents)
+	return documents, err
+}
@@ -0,0 +1,21 @@
+package repository
+
+// storeError keeps the driver error behind the message shown to clients,
+// so a unit of work can still tell conflicts worth retrying from real failures
+type storeError struct {
+	message string
+	cause   error
+}
+
+func (e *storeError) Error() string {
+	return e.message
+}
+
+func (e *storeError) Unwrap() error {
+	return e.cause
+}
+
+// wrapError returns an error reading as message that unwraps to the driver error
+func wrapError(message string, cause error) error {
+	return &storeError{message: message, cause: cause}
+}
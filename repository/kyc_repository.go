@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// KYCRepository represents the KYC profile repository contract
+type KYCRepository struct {
+	kycDB *mongo.Collection
+}
+
+// NewKYCRepository creates a new instance of KYCRepository
+func NewKYCRepository(client *mongo.Client) domain.KYCRepository {
+	kycDB := client.Database("Loan-Tracker").Collection("KYCProfiles")
+
+	_, err := kycDB.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		// a user has a single profile
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		// the review queue is worked oldest submission first
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "submitted_at", Value: 1}},
+		},
+	})
+	if err != nil {
+		log.Println("Error creating KYC indexes:", err)
+	}
+
+	return &KYCRepository{
+		kycDB: kycDB,
+	}
+}
+
+// SaveKYCProfile stores the profile of a user, replacing the one they had.
+// A replaced profile must keep the ID it was first stored with.
+func (kr *KYCRepository) SaveKYCProfile(c context.Context, profile *domain.KYCProfile) error {
+	if profile.ID.IsZero() {
+		profile.ID = primitive.NewObjectID()
+	}
+
+	_, err := kr.kycDB.ReplaceOne(c, bson.M{"user_id": profile.UserID}, profile, options.Replace().SetUpsert(true))
+	if err != nil {
+		return wrapError("Error storing KYC profile", err)
+	}
+
+	return nil
+}
+
+// KYCProfile returns the profile of a user
+func (kr *KYCRepository) KYCProfile(userID string) (domain.KYCProfile, error) {
+	var profile domain.KYCProfile
+
+	userIDObj, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return profile, errors.New("Invalid user ID")
+	}
+
+	err = kr.kycDB.FindOne(context.Background(), bson.M{"user_id": userIDObj}).Decode(&profile)
+	if err != nil {
+		return profile, errors.New("KYC profile not found")
+	}
+
+	return profile, nil
+}
+
+// GetKYCProfile returns a profile by its ID
+func (kr *KYCRepository) GetKYCProfile(id string) (domain.KYCProfile, error) {
+	var profile domain.KYCProfile
+
+	idObj, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return profile, errors.New("Invalid KYC profile ID")
+	}
+
+	err = kr.kycDB.FindOne(context.Background(), bson.M{"_id": idObj}).Decode(&profile)
+	if err != nil {
+		return profile, errors.New("KYC profile not found")
+	}
+
+	return profile, nil
+}
+
+// KYCQueue returns a page of the profiles in the given status, oldest submission first
+func (kr *KYCRepository) KYCQueue(status string, page int) ([]domain.KYCProfile, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	findoptions := options.Find()
+	findoptions.SetSkip(int64(perpage * (page - 1)))
+	findoptions.SetLimit(perpage)
+	findoptions.SetSort(bson.D{{Key: "submitted_at", Value: 1}})
+
+	profiles := []domain.KYCProfile{}
+	cursor, err := kr.kycDB.Find(context.Background(), bson.M{"status": status}, findoptions)
+	if err != nil {
+		return nil, errors.New("Error fetching KYC profiles")
+	}
+	defer cursor.Close(context.Background())
+	err = cursor.All(context.Background(), &profiles)
+	return profiles, err
+}
+
+// DeleteKYCProfiles removes the profiles of the given users, with the identity and document references they held
+func (kr *KYCRepository) DeleteKYCProfiles(c context.Context, userIDs []primitive.ObjectID) error {
+	_, err := kr.kycDB.DeleteMany(c, bson.M{"user_id": bson.M{"$in": userIDs}})
+	if err != nil {
+		return wrapError("Error deleting KYC profiles", err)
+	}
+
+	return nil
+}
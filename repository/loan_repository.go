@@ -3,8 +3,9 @@ package repository
 import (
 	"context"
 	"errors"
-	"fmt"
 	"loan_tracker_api/domain"
+	"log"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -19,42 +20,53 @@ const perpage = 10
 type LoanRepository struct {
 	client *mongo.Client
 	loanDB *mongo.Collection
-	logDB  *mongo.Collection
 }
 
 // NewLoanRepository creates a new instance of LoanRepository
 func NewLoanRepository(client *mongo.Client) domain.LoanRepository {
+	loanDB := client.Database("Loan-Tracker").Collection("Loans")
+
+	// guarantors and co-borrowers find the loans they are named on by account or by the email they were invited at
+	_, err := loanDB.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "parties.user_id", Value: 1}}},
+		{Keys: bson.D{{Key: "parties.email", Value: 1}}},
+	})
+	if err != nil {
+		log.Println("Error creating loan party indexes:", err)
+	}
+
 	return &LoanRepository{
 		client: client,
-		loanDB: client.Database("Loan-Tracker").Collection("Loans"),
-		logDB:  client.Database("Loan-Tracker").Collection("Logs"),
+		loanDB: loanDB,
 	}
 }
 
 // ApplyForLoan applies for a loan
-func (lr *LoanRepository) ApplyForLoan(loan *domain.Loan, userid string) error {
+func (lr *LoanRepository) ApplyForLoan(c context.Context, loan *domain.Loan, userid string) error {
 	useridobj, _ := primitive.ObjectIDFromHex(userid)
 	loan.CreatedAt = time.Now()
 	loan.UpdatedAt = time.Now()
 	loan.UserID = useridobj
 	loan.Status = "pending"
+	if len(loan.Parties) > 0 {
+		loan.Status = domain.LoanAwaitingAcceptance
+	} else {
+		loan.Parties = []domain.LoanParty{}
+	}
 	loan.Interest = 0.05
 	loan.ID = primitive.NewObjectID()
+	loan.Schedule = []domain.Installment{}
+	loan.Charges = []domain.Charge{}
+	loan.Payments = []domain.Payment{}
+	loan.Recoveries = []domain.Recovery{}
+	loan.Deletion = nil
 
-	log := domain.Log{
-		ID:        primitive.NewObjectID(),
-		UserID:    loan.UserID,
-		Activity:  "Applied for a loan",
-		CreatedAt: time.Now(),
-	}
-
-	_, err := lr.loanDB.InsertOne(context.Background(), loan)
-
-	if err == nil {
-		_, err = lr.logDB.InsertOne(context.Background(), log)
+	_, err := lr.loanDB.InsertOne(c, loan)
+	if err != nil {
+		return wrapError("Error applying for loan", err)
 	}
 
-	return err
+	return nil
 }
 
 // LoanDetails returns the details of a loan
@@ -64,13 +76,82 @@ func (lr *LoanRepository) LoanDetails(loanID string, userid string) (domain.Loan
 	loanIDObj, _ := primitive.ObjectIDFromHex(loanID)
 	userIDObj, _ := primitive.ObjectIDFromHex(userid)
 
-	err := lr.loanDB.FindOne(context.Background(), bson.M{"_id": loanIDObj, "user_id": userIDObj}).Decode(&loan)
+	err := lr.loanDB.FindOne(context.Background(), bson.M{"_id": loanIDObj, "user_id": userIDObj, "deletion": nil}).Decode(&loan)
 
 	return loan, err
 }
 
-// ViewAllLoans returns all loans
-func (lr *LoanRepository) ViewAllLoans(pgnum int, status, order string) ([]domain.Loan, int, error) {
+// GetLoan returns a loan regardless of its owner
+func (lr *LoanRepository) GetLoan(loanID string) (domain.Loan, error) {
+	var loan domain.Loan
+
+	loanIDObj, _ := primitive.ObjectIDFromHex(loanID)
+
+	err := lr.loanDB.FindOne(context.Background(), bson.M{"_id": loanIDObj, "deletion": nil}).Decode(&loan)
+	if err != nil {
+		return loan, errors.New("Loan not found")
+	}
+
+	return loan, nil
+}
+
+// ActiveLoans returns all approved loans that are being repaid
+func (lr *LoanRepository) ActiveLoans() ([]domain.Loan, error) {
+	return lr.LoansByStatus("approved")
+}
+
+// LoansByStatus returns all loans with the given status
+func (lr *LoanRepository) LoansByStatus(status string) ([]domain.Loan, error) {
+	var loans []domain.Loan
+	cursor, err := lr.loanDB.Find(context.Background(), bson.M{"status": status, "deletion": nil})
+	if err != nil {
+		return nil, errors.New("Error fetching loans")
+	}
+	defer cursor.Close(context.Background())
+	err = cursor.All(context.Background(), &loans)
+	return loans, err
+}
+
+// UserLoans returns all loans of a user
+func (lr *LoanRepository) UserLoans(userid string) ([]domain.Loan, error) {
+	userIDObj, _ := primitive.ObjectIDFromHex(userid)
+
+	var loans []domain.Loan
+	cursor, err := lr.loanDB.Find(context.Background(), bson.M{"user_id": userIDObj, "deletion": nil})
+	if err != nil {
+		return nil, errors.New("Error fetching loans")
+	}
+	defer cursor.Close(context.Background())
+	err = cursor.All(context.Background(), &loans)
+	return loans, err
+}
+
+// PartyLoans lists the loans a user borrows or is named on as a guarantor or co-borrower,
+// including those they were invited to by email before they had an account
+func (lr *LoanRepository) PartyLoans(userid, email string) ([]domain.Loan, error) {
+	userIDObj, _ := primitive.ObjectIDFromHex(userid)
+
+	named := bson.A{
+		bson.M{"user_id": userIDObj},
+		bson.M{"parties.user_id": userIDObj},
+	}
+	// invitations are stored with the email lowercased
+	if email != "" {
+		named = append(named, bson.M{"parties": bson.M{"$elemMatch": bson.M{"email": strings.ToLower(email), "user_id": bson.M{"$exists": false}}}})
+	}
+	filter := bson.M{"deletion": nil, "$or": named}
+
+	loans := []domain.Loan{}
+	cursor, err := lr.loanDB.Find(context.Background(), filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, wrapError("Error fetching loans", err)
+	}
+	defer cursor.Close(context.Background())
+	err = cursor.All(context.Background(), &loans)
+	return loans, err
+}
+
+func (lr *LoanRepository) ViewAllLoans(pgnum int, status, order string, dpd domain.DPDRange) ([]domain.Loan, int, error) {
 
 	if pgnum == 0 {
 		pgnum = 1
@@ -78,7 +159,7 @@ func (lr *LoanRepository) ViewAllLoans(pgnum int, status, order string) ([]domai
 
 	sorto := -1
 	skip := perpage * (pgnum - 1)
-	filter := bson.M{}
+	filter := bson.M{"deletion": nil}
 
 	if status == "" {
 		status = "all"
@@ -92,7 +173,7 @@ func (lr *LoanRepository) ViewAllLoans(pgnum int, status, order string) ([]domai
 
 	if status == "all" {
 		status = ""
-	} else if status == "pending" || status == "approved" || status == "rejected" {
+	} else if status == "pending" || status == "approved" || status == "rejected" || status == "paid_off" || status == "written_off" || status == domain.LoanAwaitingAcceptance {
 		filter["status"] = status
 	} else {
 		return nil, 0, errors.New("Invalid status parameter")
@@ -102,11 +183,26 @@ func (lr *LoanRepository) ViewAllLoans(pgnum int, status, order string) ([]domai
 		return nil, 0, errors.New("Invalid order parameter")
 	}
 
+	if dpd.Max >= 0 && dpd.Max < dpd.Min {
+		return nil, 0, errors.New("Invalid days past due range")
+	}
+
+	dpdFilter := bson.M{}
+	if dpd.Min > 0 {
+		dpdFilter["$gte"] = dpd.Min
+	}
+	if dpd.Max >= 0 {
+		dpdFilter["$lte"] = dpd.Max
+	}
+	if len(dpdFilter) > 0 {
+		filter["days_past_due"] = dpdFilter
+	}
+
 	if order == "asc" {
 		sorto = 1
 	}
 
-	count, err := lr.loanDB.CountDocuments(context.TODO(), bson.M{})
+	count, err := lr.loanDB.CountDocuments(context.TODO(), bson.M{"deletion": nil})
 	if err != nil {
 		return nil, 0, errors.New("Error counting documents")
 	}
@@ -128,70 +224,126 @@ func (lr *LoanRepository) ViewAllLoans(pgnum int, status, order string) ([]domai
 	return loans, int(count), err
 }
 
-// ApproveRejectLoan approves or rejects a loan
-func (lr *LoanRepository) ApproveRejectLoan(loanID string, status string, userid string) error {
-	userIDObj, _ := primitive.ObjectIDFromHex(userid)
-	//findout if the loan was accepted or rejected beforehand
-	var loan domain.Loan
+// ApproveRejectLoan approves or rejects a loan, recording why it was rejected. Only applications still pending,
+// or waiting on their guarantors and co-borrowers, can be decided on.
+func (lr *LoanRepository) ApproveRejectLoan(c context.Context, loanID string, status string, rejection *domain.Rejection) error {
 	loanIDObj, _ := primitive.ObjectIDFromHex(loanID)
-	err := lr.loanDB.FindOne(context.Background(), bson.M{"_id": loanIDObj}).Decode(&loan)
+
+	// the status is checked in the filter so two decisions made at once can't both go through
+	filter := bson.M{"_id": loanIDObj, "deletion": nil, "status": bson.M{"$in": bson.A{"pending", domain.LoanAwaitingAcceptance}}}
+	res, err := lr.loanDB.UpdateOne(c, filter, bson.M{
+		"$set": bson.M{"status": status, "rejection": rejection, "updated_at": time.Now()},
+		"$inc": bson.M{"version": 1},
+	})
 	if err != nil {
-		return errors.New("Loan not found")
+		return wrapError("Error updating loan", err)
 	}
 
-	if loan.Status == "approved" || loan.Status == "rejected" {
+	if res.MatchedCount == 0 {
+		count, err := lr.loanDB.CountDocuments(c, bson.M{"_id": loanIDObj, "deletion": nil})
+		if err != nil || count == 0 {
+			return errors.New("Loan not found")
+		}
 		return errors.New("Loan already processed")
 	}
 
-	fmt.Println("loan", loan)
+	return nil
+}
+
+// UpdateLoan saves the repayment state of a loan. The loan is only saved over the version it was read at,
+// a loan changed by another request since then has to be read again.
+func (lr *LoanRepository) UpdateLoan(c context.Context, loan *domain.Loan) error {
+	updatedAt := time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":           loan.Status,
+			"interest":         loan.Interest,
+			"duration":         loan.Duration,
+			"delinquency":      loan.Delinquency,
+			"days_past_due":    loan.DaysPastDue,
+			"schedule":         loan.Schedule,
+			"charges":          loan.Charges,
+			"payments":         loan.Payments,
+			"payoff_quote":     loan.PayoffQuote,
+			"schedule_history": loan.History,
+			"write_off":        loan.WriteOff,
+			"recoveries":       loan.Recoveries,
+			"parties":          loan.Parties,
+			"updated_at":       updatedAt,
+		},
+		"$inc": bson.M{"version": 1},
+	}
 
-	log := domain.Log{
-		ID:        primitive.NewObjectID(),
-		UserID:    userIDObj,
-		Activity:  "Loan " + status + "by Admin",
-		CreatedAt: time.Now(),
+	// loans stored before versioning have no version field, they are at version 0
+	version := bson.M{"$in": bson.A{loan.Version, nil}}
+	if loan.Version > 0 {
+		version = bson.M{"$eq": loan.Version}
 	}
 
-	//update the status of the loan
-	res, erro := lr.loanDB.UpdateOne(context.Background(), bson.M{"_id": loanIDObj}, bson.M{"$set": bson.M{"status": status}})
+	res, err := lr.loanDB.UpdateOne(c, bson.M{"_id": loan.ID, "deletion": nil, "version": version}, update)
+	if err != nil {
+		return wrapError("Error updating loan", err)
+	}
 
-	if erro == nil && res.ModifiedCount != 0 {
-		_, erro = lr.logDB.InsertOne(context.Background(), log)
+	if res.MatchedCount == 0 {
+		count, err := lr.loanDB.CountDocuments(c, bson.M{"_id": loan.ID, "deletion": nil})
+		if err != nil || count == 0 {
+			return errors.New("Loan not found")
+		}
+		return errors.New("Loan was changed by another request, please try again")
 	}
 
-	return erro
+	loan.Version++
+	loan.UpdatedAt = updatedAt
+	return nil
 }
 
-// DeleteLoan deletes a loan
-func (lr *LoanRepository) DeleteLoan(loanID string, userid string) error {
+// DeleteLoan soft deletes a loan, keeping it until it is purged
+func (lr *LoanRepository) DeleteLoan(c context.Context, loanID, reason, userid string) error {
 	userIDObj, _ := primitive.ObjectIDFromHex(userid)
 	loanIDObj, _ := primitive.ObjectIDFromHex(loanID)
 
-	log := domain.Log{
-		ID:        primitive.NewObjectID(),
-		UserID:    userIDObj,
-		Activity:  "Deleted a loan",
-		CreatedAt: time.Now(),
+	deletion := domain.Deletion{
+		DeletedAt: time.Now(),
+		DeletedBy: userIDObj,
+		Reason:    reason,
 	}
 
-	res, err := lr.loanDB.DeleteOne(context.Background(), bson.M{"_id": loanIDObj})
+	res, err := lr.loanDB.UpdateOne(c, bson.M{"_id": loanIDObj, "deletion": nil}, bson.M{"$set": bson.M{"deletion": deletion}})
+	if err != nil {
+		return wrapError("Error deleting loan", err)
+	}
 
-	if err == nil && res.DeletedCount != 0 {
-		fmt.Println("log", log)
-		_, err = lr.logDB.InsertOne(context.Background(), log)
+	if res.MatchedCount == 0 {
+		return errors.New("Loan not found")
 	}
 
-	return err
+	return nil
 }
 
-// ViewLogs returns all logs
-func (lr *LoanRepository) ViewLogs() ([]domain.Log, error) {
-	var logs []domain.Log
-	cursor, err := lr.logDB.Find(context.Background(), bson.M{})
+// RestoreLoan brings back a soft deleted loan
+func (lr *LoanRepository) RestoreLoan(c context.Context, loanID string) error {
+	loanIDObj, _ := primitive.ObjectIDFromHex(loanID)
+
+	res, err := lr.loanDB.UpdateOne(c, bson.M{"_id": loanIDObj, "deletion": bson.M{"$ne": nil}}, bson.M{"$set": bson.M{"deletion": nil}})
 	if err != nil {
-		return nil, err
+		return wrapError("Error restoring loan", err)
 	}
-	defer cursor.Close(context.Background())
-	err = cursor.All(context.Background(), &logs)
-	return logs, err
+
+	if res.MatchedCount == 0 {
+		return errors.New("Deleted loan not found")
+	}
+
+	return nil
+}
+
+// PurgeDeletedLoans permanently removes loans soft deleted before the given time
+func (lr *LoanRepository) PurgeDeletedLoans(c context.Context, before time.Time) (int, error) {
+	res, err := lr.loanDB.DeleteMany(c, bson.M{"deletion.deleted_at": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, wrapError("Error purging loans", err)
+	}
+
+	return int(res.DeletedCount), nil
 }
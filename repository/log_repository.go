@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LogRepository represents the audit log repository contract
+type LogRepository struct {
+	client *mongo.Client
+	logDB  *mongo.Collection
+}
+
+// NewLogRepository creates a new instance of LogRepository
+func NewLogRepository(client *mongo.Client) domain.LogRepository {
+	// field diffs hold arbitrary values, decode their documents as maps so they read back as plain JSON objects
+	collectionOptions := options.Collection().SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true})
+
+	logDB := client.Database("Loan-Tracker").Collection("Logs", collectionOptions)
+
+	// two entries can't claim the same place in the chain, entries written before chaining have no sequence
+	_, err := logDB.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "sequence", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"sequence": bson.M{"$gt": 0}}),
+	})
+	if err != nil {
+		log.Println("Error creating audit log index:", err)
+	}
+
+	return &LogRepository{
+		client: client,
+		logDB:  logDB,
+	}
+}
+
+// how many times an entry is rechained when another one takes its place in the chain first
+const chainRetries = 5
+
+// AddLog appends an audit entry to the end of the chain.
+// Inside a transaction a lost race for the next place aborts the transaction, the error is left for the unit of work to replay it.
+func (lr *LogRepository) AddLog(c context.Context, entry *domain.Log) error {
+	if entry.ID.IsZero() {
+		entry.ID = primitive.NewObjectID()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	// stored times keep millisecond precision, hash the entry as it will read back
+	entry.CreatedAt = entry.CreatedAt.UTC().Truncate(time.Millisecond)
+
+	inTransaction := mongo.SessionFromContext(c) != nil
+
+	for attempt := 0; attempt < chainRetries; attempt++ {
+		last, err := lr.lastLog(c)
+		if err != nil {
+			return err
+		}
+
+		entry.Sequence = last.Sequence + 1
+		entry.PrevHash = last.Hash
+		entry.Hash = entry.ComputeHash()
+
+		_, err = lr.logDB.InsertOne(c, entry)
+		if mongo.IsDuplicateKeyError(err) && !inTransaction {
+			continue
+		}
+		if err != nil {
+			return wrapError("Error writing audit log", err)
+		}
+
+		return nil
+	}
+
+	return errors.New("Error writing audit log")
+}
+
+// LastLog returns the entry at the end of the chain, an empty entry when the chain hasn't started
+func (lr *LogRepository) LastLog() (domain.Log, error) {
+	return lr.lastLog(context.Background())
+}
+
+// lastLog reads the end of the chain within the context of the write about to extend it
+func (lr *LogRepository) lastLog(c context.Context) (domain.Log, error) {
+	var last domain.Log
+
+	findoptions := options.FindOne().SetSort(bson.D{{Key: "sequence", Value: -1}})
+	err := lr.logDB.FindOne(c, bson.M{"sequence": bson.M{"$gt": 0}}, findoptions).Decode(&last)
+	if err == mongo.ErrNoDocuments {
+		return domain.Log{}, nil
+	}
+	if err != nil {
+		return domain.Log{}, wrapError("Error fetching logs", err)
+	}
+
+	return last, nil
+}
+
+// ChainedLogs returns up to limit entries of the chain following the given sequence, in chain order
+func (lr *LogRepository) ChainedLogs(after int64, limit int) ([]domain.Log, error) {
+	findoptions := options.Find()
+	findoptions.SetLimit(int64(limit))
+	findoptions.SetSort(bson.D{{Key: "sequence", Value: 1}})
+
+	logs := []domain.Log{}
+	cursor, err := lr.logDB.Find(context.Background(), bson.M{"sequence": bson.M{"$gt": after}}, findoptions)
+	if err != nil {
+		return nil, errors.New("Error fetching logs")
+	}
+	defer cursor.Close(context.Background())
+	err = cursor.All(context.Background(), &logs)
+	return logs, err
+}
+
+// logQuery builds the query of the entries matching the filter, archived entries only keep their place in the chain
+func logQuery(filter domain.LogFilter) (bson.M, error) {
+	query := bson.M{"archived": bson.M{"$ne": true}}
+
+	if filter.ActorID != "" {
+		actorID, err := primitive.ObjectIDFromHex(filter.ActorID)
+		if err != nil {
+			return nil, errors.New("Invalid actor ID")
+		}
+		query["actor_id"] = actorID
+	}
+
+	if filter.TargetID != "" {
+		targetID, err := primitive.ObjectIDFromHex(filter.TargetID)
+		if err != nil {
+			return nil, errors.New("Invalid target ID")
+		}
+		query["target_id"] = targetID
+	}
+
+	if filter.TargetType != "" {
+		query["target_type"] = filter.TargetType
+	}
+
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+
+	createdAt := bson.M{}
+	if !filter.From.IsZero() {
+		createdAt["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		createdAt["$lte"] = filter.To
+	}
+	if len(createdAt) > 0 {
+		query["created_at"] = createdAt
+	}
+
+	return query, nil
+}
+
+// ViewLogs returns the audit entries matching the filter, newest first
+func (lr *LogRepository) ViewLogs(filter domain.LogFilter) ([]domain.Log, error) {
+	query, err := logQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	pgnum := filter.Page
+	if pgnum <= 0 {
+		pgnum = 1
+	}
+
+	findoptions := options.Find()
+	findoptions.SetSkip(int64(perpage * (pgnum - 1)))
+	findoptions.SetLimit(perpage)
+	findoptions.SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	logs := []domain.Log{}
+	cursor, err := lr.logDB.Find(context.Background(), query, findoptions)
+	if err != nil {
+		return nil, errors.New("Error fetching logs")
+	}
+	defer cursor.Close(context.Background())
+	err = cursor.All(context.Background(), &logs)
+	return logs, err
+}
+
+// StreamLogs hands every entry matching the filter to each, oldest first, without holding them all in memory
+func (lr *LogRepository) StreamLogs(filter domain.LogFilter, each func(entry domain.Log) error) error {
+	query, err := logQuery(filter)
+	if err != nil {
+		return err
+	}
+
+	findoptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := lr.logDB.Find(context.Background(), query, findoptions)
+	if err != nil {
+		return errors.New("Error fetching logs")
+	}
+	defer cursor.Close(context.Background())
+
+	for cursor.Next(context.Background()) {
+		var entry domain.Log
+		if err := cursor.Decode(&entry); err != nil {
+			return errors.New("Error reading logs")
+		}
+		if err := each(entry); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// ArchivableLogs returns up to limit chained entries older than the cutoff of their category, in chain order
+func (lr *LogRepository) ArchivableLogs(cutoffs map[string]time.Time, limit int) ([]domain.Log, error) {
+	expired := bson.A{}
+	for category, cutoff := range cutoffs {
+		expired = append(expired, bson.M{
+			"action":     bson.M{"$in": domain.ActionCategories[category]},
+			"created_at": bson.M{"$lt": cutoff},
+		})
+	}
+
+	logs := []domain.Log{}
+	if len(expired) == 0 {
+		return logs, nil
+	}
+
+	query := bson.M{"sequence": bson.M{"$gt": 0}, "archived": bson.M{"$ne": true}, "$or": expired}
+
+	findoptions := options.Find()
+	findoptions.SetLimit(int64(limit))
+	findoptions.SetSort(bson.D{{Key: "sequence", Value: 1}})
+
+	cursor, err := lr.logDB.Find(context.Background(), query, findoptions)
+	if err != nil {
+		return nil, errors.New("Error fetching logs")
+	}
+	defer cursor.Close(context.Background())
+	err = cursor.All(context.Background(), &logs)
+	return logs, err
+}
+
+// MarkArchived strips archived entries down to their place in the chain, their content now lives in the archive
+func (lr *LogRepository) MarkArchived(c context.Context, ids []primitive.ObjectID) error {
+	update := bson.M{
+		"$set": bson.M{"archived": true},
+		"$unset": bson.M{
+			"actor_id":    "",
+			"actor_role":  "",
+			"target_type": "",
+			"target_id":   "",
+			"changes":     "",
+			"note":        "",
+			"request_id":  "",
+			"ip":          "",
+			"user_agent":  "",
+		},
+	}
+
+	_, err := lr.logDB.UpdateMany(c, bson.M{"_id": bson.M{"$in": ids}}, update)
+	if err != nil {
+		return wrapError("Error archiving logs", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NotificationRepository represents the in-app notification repository contract
+type NotificationRepository struct {
+	notificationDB *mongo.Collection
+}
+
+// NewNotificationRepository creates a new instance of NotificationRepository
+func NewNotificationRepository(client *mongo.Client) domain.NotificationRepository {
+	// notification data holds arbitrary values, decode their documents as maps so they read back as plain JSON objects
+	collectionOptions := options.Collection().SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true})
+
+	notificationDB := client.Database("Loan-Tracker").Collection("Notifications", collectionOptions)
+
+	// inboxes are listed newest first
+	_, err := notificationDB.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	if err != nil {
+		log.Println("Error creating notification index:", err)
+	}
+
+	return &NotificationRepository{
+		notificationDB: notificationDB,
+	}
+}
+
+// AddNotification stores a notification in the inbox of its user.
+// Notifications keep the ID they were queued with, storing one again when its delivery is retried is a no-op.
+func (nr *NotificationRepository) AddNotification(c context.Context, notification *domain.Notification) error {
+	if notification.ID.IsZero() {
+		notification.ID = primitive.NewObjectID()
+	}
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+
+	_, err := nr.notificationDB.InsertOne(c, notification)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	if err != nil {
+		return wrapError("Error storing notification", err)
+	}
+
+	return nil
+}
+
+// inboxQuery builds the query of the notifications of a user
+func inboxQuery(userID string, unreadOnly bool) (bson.M, error) {
+	userIDObj, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, errors.New("Invalid user ID")
+	}
+
+	query := bson.M{"user_id": userIDObj}
+	if unreadOnly {
+		query["read_at"] = nil
+	}
+	return query, nil
+}
+
+// Notifications returns a page of the notifications of a user, newest first
+func (nr *NotificationRepository) Notifications(userID string, unreadOnly bool, page int) ([]domain.Notification, error) {
+	query, err := inboxQuery(userID, unreadOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+
+	findoptions := options.Find()
+	findoptions.SetSkip(int64(perpage * (page - 1)))
+	findoptions.SetLimit(perpage)
+	findoptions.SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	notifications := []domain.Notification{}
+	cursor, err := nr.notificationDB.Find(context.Background(), query, findoptions)
+	if err != nil {
+		return nil, errors.New("Error fetching notifications")
+	}
+	defer cursor.Close(context.Background())
+	err = cursor.All(context.Background(), &notifications)
+	return notifications, err
+}
+
+// UnreadCount returns how many notifications of a user are unread
+func (nr *NotificationRepository) UnreadCount(userID string) (int64, error) {
+	query, err := inboxQuery(userID, true)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := nr.notificationDB.CountDocuments(context.Background(), query)
+	if err != nil {
+		return 0, errors.New("Error counting notifications")
+	}
+	return count, nil
+}
+
+// MarkRead marks a notification of a user as read, reading it again keeps the time it was first read
+func (nr *NotificationRepository) MarkRead(c context.Context, userID, id string) error {
+	query, err := inboxQuery(userID, false)
+	if err != nil {
+		return err
+	}
+
+	idObj, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("Invalid notification ID")
+	}
+	query["_id"] = idObj
+
+	res, err := nr.notificationDB.UpdateOne(c, query, bson.A{
+		bson.M{"$set": bson.M{"read_at": bson.M{"$ifNull": bson.A{"$read_at", "$$NOW"}}}},
+	})
+	if err != nil {
+		return wrapError("Error updating notification", err)
+	}
+
+	if res.MatchedCount == 0 {
+		return errors.New("Notification not found")
+	}
+
+	return nil
+}
+
+// MarkAllRead marks every unread notification of a user as read and returns how many there were
+func (nr *NotificationRepository) MarkAllRead(c context.Context, userID string) (int64, error) {
+	query, err := inboxQuery(userID, true)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := nr.notificationDB.UpdateMany(c, query, bson.M{"$set": bson.M{"read_at": time.Now()}})
+	if err != nil {
+		return 0, wrapError("Error updating notifications", err)
+	}
+
+	return res.ModifiedCount, nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OutboxRepository represents the outbox repository contract
+type OutboxRepository struct {
+	client   *mongo.Client
+	outboxDB *mongo.Collection
+}
+
+// NewOutboxRepository creates a new instance of OutboxRepository
+func NewOutboxRepository(client *mongo.Client) domain.OutboxRepository {
+	outboxDB := client.Database("Loan-Tracker").Collection("Outbox")
+
+	_, err := outboxDB.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}},
+	})
+	if err != nil {
+		log.Println("Error creating outbox index:", err)
+	}
+
+	return &OutboxRepository{
+		client:   client,
+		outboxDB: outboxDB,
+	}
+}
+
+// AddMessage records a message to be delivered once the unit it is written in commits
+func (orepo *OutboxRepository) AddMessage(c context.Context, message *domain.OutboxMessage) error {
+	now := time.Now()
+	message.ID = primitive.NewObjectID()
+	message.Status = domain.OutboxPending
+	message.Attempts = 0
+	message.CreatedAt = now
+	message.NextAttemptAt = now
+
+	_, err := orepo.outboxDB.InsertOne(c, message)
+	if err != nil {
+		return wrapError("Error writing outbox message", err)
+	}
+
+	return nil
+}
+
+// ClaimMessages takes up to limit pending messages that are due, oldest first.
+// Claimed messages aren't due again until the lease runs out, so a dispatcher that dies mid-delivery doesn't lose them.
+func (orepo *OutboxRepository) ClaimMessages(now time.Time, lease time.Duration, limit int) ([]domain.OutboxMessage, error) {
+	messages := []domain.OutboxMessage{}
+
+	filter := bson.M{"status": domain.OutboxPending, "next_attempt_at": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"next_attempt_at": now.Add(lease)}}
+	findoptions := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	for len(messages) < limit {
+		var message domain.OutboxMessage
+		err := orepo.outboxDB.FindOneAndUpdate(context.Background(), filter, update, findoptions).Decode(&message)
+		if err == mongo.ErrNoDocuments {
+			break
+		}
+		if err != nil {
+			return messages, errors.New("Error claiming outbox messages")
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+// UpdateMessage saves the delivery state of a message
+func (orepo *OutboxRepository) UpdateMessage(message *domain.OutboxMessage) error {
+	update := bson.M{"$set": bson.M{
+		"status":          message.Status,
+		"attempts":        message.Attempts,
+		"last_error":      message.LastError,
+		"next_attempt_at": message.NextAttemptAt,
+		"sent_at":         message.SentAt,
+	}}
+
+	res, err := orepo.outboxDB.UpdateOne(context.Background(), bson.M{"_id": message.ID}, update)
+	if err != nil {
+		return errors.New("Error updating outbox message")
+	}
+
+	if res.MatchedCount == 0 {
+		return errors.New("Outbox message not found")
+	}
+
+	return nil
+}
+
+// GetMessage returns an outbox message
+func (orepo *OutboxRepository) GetMessage(id string) (domain.OutboxMessage, error) {
+	var message domain.OutboxMessage
+
+	idObj, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return message, errors.New("Invalid message ID")
+	}
+
+	err = orepo.outboxDB.FindOne(context.Background(), bson.M{"_id": idObj}).Decode(&message)
+	if err != nil {
+		return message, errors.New("Outbox message not found")
+	}
+
+	return message, nil
+}
+
+// DeadMessages returns the messages that ran out of attempts, newest first
+func (orepo *OutboxRepository) DeadMessages(page int) ([]domain.OutboxMessage, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	findoptions := options.Find()
+	findoptions.SetSkip(int64(perpage * (page - 1)))
+	findoptions.SetLimit(perpage)
+	findoptions.SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	messages := []domain.OutboxMessage{}
+	cursor, err := orepo.outboxDB.Find(context.Background(), bson.M{"status": domain.OutboxDead}, findoptions)
+	if err != nil {
+		return nil, errors.New("Error fetching outbox messages")
+	}
+	defer cursor.Close(context.Background())
+	err = cursor.All(context.Background(), &messages)
+	return messages, err
+}
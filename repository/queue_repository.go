@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// QueueRepository represents the job queue repository contract
+type QueueRepository struct {
+	queueDB *mongo.Collection
+}
+
+// NewQueueRepository creates a new instance of QueueRepository
+func NewQueueRepository(client *mongo.Client) domain.QueueRepository {
+	queueDB := client.Database("Loan-Tracker").Collection("Queue")
+
+	_, err := queueDB.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "priority", Value: -1}, {Key: "run_at", Value: 1}},
+		},
+		// jobs only keep their key while they are pending or active
+		{
+			Keys:    bson.D{{Key: "key", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
+	})
+	if err != nil {
+		log.Println("Error creating queue indexes:", err)
+	}
+
+	return &QueueRepository{
+		queueDB: queueDB,
+	}
+}
+
+// EnqueueJob adds a job to the queue, it reports false when a job with the same key is already pending or active
+func (qr *QueueRepository) EnqueueJob(c context.Context, job *domain.QueuedJob) (bool, error) {
+	now := time.Now()
+	job.ID = primitive.NewObjectID()
+	job.Status = domain.QueuePending
+	job.Attempts = 0
+	job.CreatedAt = now
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+
+	_, err := qr.queueDB.InsertOne(c, job)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, wrapError("Error queueing job", err)
+	}
+
+	return true, nil
+}
+
+// ClaimNextJob takes the due job of the given types with the highest priority, the oldest first among equals.
+// Active jobs are due again once their visibility timeout runs out. Claiming a job counts as an attempt.
+func (qr *QueueRepository) ClaimNextJob(types []string, worker string, now time.Time) (domain.QueuedJob, bool, error) {
+	var job domain.QueuedJob
+
+	filter := bson.M{
+		"type":   bson.M{"$in": types},
+		"status": bson.M{"$in": bson.A{domain.QueuePending, domain.QueueActive}},
+		"run_at": bson.M{"$lte": now},
+	}
+	update := bson.A{bson.M{"$set": bson.M{
+		"status":     domain.QueueActive,
+		"worker":     worker,
+		"attempts":   bson.M{"$add": bson.A{"$attempts", 1}},
+		"started_at": now,
+		"run_at":     bson.M{"$add": bson.A{now, bson.M{"$multiply": bson.A{"$visibility_seconds", 1000}}}},
+	}}}
+	findoptions := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "priority", Value: -1}, {Key: "run_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	err := qr.queueDB.FindOneAndUpdate(context.Background(), filter, update, findoptions).Decode(&job)
+	if err == mongo.ErrNoDocuments {
+		return job, false, nil
+	}
+	if err != nil {
+		return job, false, errors.New("Error claiming job")
+	}
+
+	return job, true, nil
+}
+
+// jobUpdate sets the state of a job, its key is dropped once it is no longer pending or active
+func jobUpdate(job *domain.QueuedJob) bson.M {
+	set := bson.M{
+		"status":      job.Status,
+		"attempts":    job.Attempts,
+		"last_error":  job.LastError,
+		"failures":    job.Failures,
+		"run_at":      job.RunAt,
+		"finished_at": job.FinishedAt,
+	}
+
+	if job.Status == domain.QueuePending || job.Status == domain.QueueActive {
+		if job.Key != "" {
+			set["key"] = job.Key
+		}
+		return bson.M{"$set": set}
+	}
+
+	return bson.M{"$set": set, "$unset": bson.M{"key": ""}}
+}
+
+// FinishJob records how an attempt at a job ended, unless another worker claimed the job since
+func (qr *QueueRepository) FinishJob(job *domain.QueuedJob) error {
+	filter := bson.M{"_id": job.ID, "status": domain.QueueActive, "worker": job.Worker, "attempts": job.Attempts}
+
+	res, err := qr.queueDB.UpdateOne(context.Background(), filter, jobUpdate(job))
+	if err != nil {
+		return wrapError("Error updating job", err)
+	}
+
+	if res.MatchedCount == 0 {
+		return errors.New("Job was claimed by another worker")
+	}
+
+	return nil
+}
+
+// UpdateJob saves a job that is still in the status it was read in
+func (qr *QueueRepository) UpdateJob(job *domain.QueuedJob, from string) error {
+	res, err := qr.queueDB.UpdateOne(context.Background(), bson.M{"_id": job.ID, "status": from}, jobUpdate(job))
+	if mongo.IsDuplicateKeyError(err) {
+		return errors.New("A job with the same key is already queued")
+	}
+	if err != nil {
+		return wrapError("Error updating job", err)
+	}
+
+	if res.MatchedCount == 0 {
+		return errors.New("Job changed in the meantime, try again")
+	}
+
+	return nil
+}
+
+// GetJob returns a queued job
+func (qr *QueueRepository) GetJob(id string) (domain.QueuedJob, error) {
+	var job domain.QueuedJob
+
+	idObj, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return job, errors.New("Invalid job ID")
+	}
+
+	err = qr.queueDB.FindOne(context.Background(), bson.M{"_id": idObj}).Decode(&job)
+	if err != nil {
+		return job, errors.New("Job not found")
+	}
+
+	return job, nil
+}
+
+// ListJobs returns the jobs matching the filter, newest first
+func (qr *QueueRepository) ListJobs(filter domain.JobFilter) ([]domain.QueuedJob, error) {
+	query := bson.M{}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.Type != "" {
+		query["type"] = filter.Type
+	}
+
+	page := filter.Page
+	if page <= 0 {
+		page = 1
+	}
+
+	findoptions := options.Find()
+	findoptions.SetSkip(int64(perpage * (page - 1)))
+	findoptions.SetLimit(perpage)
+	findoptions.SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	jobs := []domain.QueuedJob{}
+	cursor, err := qr.queueDB.Find(context.Background(), query, findoptions)
+	if err != nil {
+		return nil, errors.New("Error fetching jobs")
+	}
+	defer cursor.Close(context.Background())
+	err = cursor.All(context.Background(), &jobs)
+	return jobs, err
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SchedulerRepository represents the scheduler repository contract
+type SchedulerRepository struct {
+	lockDB *mongo.Collection
+	runDB  *mongo.Collection
+}
+
+// NewSchedulerRepository creates a new instance of SchedulerRepository
+func NewSchedulerRepository(client *mongo.Client) domain.SchedulerRepository {
+	lockDB := client.Database("Loan-Tracker").Collection("JobLocks")
+	runDB := client.Database("Loan-Tracker").Collection("JobRuns")
+
+	// run history is listed newest first, by job
+	_, err := runDB.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "job", Value: 1}, {Key: "started_at", Value: -1}},
+	})
+	if err != nil {
+		log.Println("Error creating job run index:", err)
+	}
+
+	return &SchedulerRepository{
+		lockDB: lockDB,
+		runDB:  runDB,
+	}
+}
+
+// ClaimJob takes the lock of a job for the run scheduled at the given minute.
+// It fails when another instance already claimed that run or a previous run still holds the lock.
+func (sr *SchedulerRepository) ClaimJob(c context.Context, job string, scheduledAt time.Time, instance string, lease time.Duration) (bool, error) {
+	now := time.Now()
+	query := bson.M{
+		"_id":          job,
+		"scheduled_at": bson.M{"$lt": scheduledAt},
+		"locked_until": bson.M{"$lt": now},
+	}
+	update := bson.M{"$set": bson.M{
+		"scheduled_at": scheduledAt,
+		"instance":     instance,
+		"locked_until": now.Add(lease),
+	}}
+
+	// the lock of a job that never ran is created by the first instance to claim it
+	res, err := sr.lockDB.UpdateOne(c, query, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, wrapError("Error claiming job", err)
+	}
+
+	return res.MatchedCount+res.UpsertedCount > 0, nil
+}
+
+// ReleaseJob frees the lock of a job held by the instance once its run ends
+func (sr *SchedulerRepository) ReleaseJob(c context.Context, job, instance string) error {
+	_, err := sr.lockDB.UpdateOne(c, bson.M{"_id": job, "instance": instance}, bson.M{"$set": bson.M{"locked_until": time.Now()}})
+	if err != nil {
+		return wrapError("Error releasing job", err)
+	}
+
+	return nil
+}
+
+// AddJobRun records the start of a job run
+func (sr *SchedulerRepository) AddJobRun(c context.Context, run *domain.JobRun) error {
+	if run.ID.IsZero() {
+		run.ID = primitive.NewObjectID()
+	}
+
+	_, err := sr.runDB.InsertOne(c, run)
+	if err != nil {
+		return wrapError("Error recording job run", err)
+	}
+
+	return nil
+}
+
+// FinishJobRun records how a job run ended, or that a worker of the queue started it
+func (sr *SchedulerRepository) FinishJobRun(c context.Context, run *domain.JobRun) error {
+	set := bson.M{
+		"status":      run.Status,
+		"processed":   run.Processed,
+		"error":       run.Error,
+		"finished_at": run.FinishedAt,
+	}
+	if run.QueuedJob != "" {
+		set["instance"] = run.Instance
+		set["queued_job"] = run.QueuedJob
+	}
+
+	_, err := sr.runDB.UpdateOne(c, bson.M{"_id": run.ID}, bson.M{"$set": set})
+	if err != nil {
+		return wrapError("Error recording job run", err)
+	}
+
+	return nil
+}
+
+// JobRuns returns a page of the runs of a job, or of every job, newest first
+func (sr *SchedulerRepository) JobRuns(job string, page int) ([]domain.JobRun, error) {
+	query := bson.M{}
+	if job != "" {
+		query["job"] = job
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+
+	findoptions := options.Find()
+	findoptions.SetSkip(int64(perpage * (page - 1)))
+	findoptions.SetLimit(perpage)
+	findoptions.SetSort(bson.D{{Key: "started_at", Value: -1}})
+
+	runs := []domain.JobRun{}
+	cursor, err := sr.runDB.Find(context.Background(), query, findoptions)
+	if err != nil {
+		return nil, errors.New("Error fetching job runs")
+	}
+	defer cursor.Close(context.Background())
+	err = cursor.All(context.Background(), &runs)
+	return runs, err
+}
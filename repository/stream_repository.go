@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// how long events stay in the log for clients to resume from
+const streamRetention = 7 * 24 * time.Hour
+
+// StreamRepository represents the event log repository contract
+type StreamRepository struct {
+	streamDB *mongo.Collection
+}
+
+// NewStreamRepository creates a new instance of StreamRepository
+func NewStreamRepository(client *mongo.Client) domain.StreamRepository {
+	streamDB := client.Database("Loan-Tracker").Collection("StreamEvents")
+
+	// an event or notification is recorded once however many times it is delivered
+	_, err := streamDB.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "source_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(streamRetention.Seconds())),
+		},
+	})
+	if err != nil {
+		log.Println("Error creating stream event indexes:", err)
+	}
+
+	return &StreamRepository{
+		streamDB: streamDB,
+	}
+}
+
+// AddStreamEvent appends an event to the log, recording the same source again is a no-op
+func (sr *StreamRepository) AddStreamEvent(c context.Context, event *domain.StreamEvent) error {
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	_, err := sr.streamDB.InsertOne(c, event)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	if err != nil {
+		return wrapError("Error recording stream event", err)
+	}
+
+	return nil
+}
+
+// StreamEventsAfter returns up to limit events recorded after the given one, oldest first.
+// Without a user the events of every user are returned, but not their notifications.
+func (sr *StreamRepository) StreamEventsAfter(lastID, userID string, limit int) ([]domain.StreamEvent, error) {
+	lastIDObj, err := primitive.ObjectIDFromHex(lastID)
+	if err != nil {
+		return nil, errors.New("Invalid Last-Event-ID")
+	}
+
+	query := bson.M{"_id": bson.M{"$gt": lastIDObj}}
+	if userID != "" {
+		userIDObj, err := primitive.ObjectIDFromHex(userID)
+		if err != nil {
+			return nil, errors.New("Invalid user ID")
+		}
+		query["user_id"] = userIDObj
+	} else {
+		query["name"] = bson.M{"$ne": domain.StreamNotification}
+	}
+
+	findoptions := options.Find()
+	findoptions.SetLimit(int64(limit))
+	findoptions.SetSort(bson.D{{Key: "_id", Value: 1}})
+
+	return sr.findStreamEvents(query, findoptions)
+}
+
+// RecentStreamEvents returns up to limit events recorded since the given time, in the order they were recorded
+func (sr *StreamRepository) RecentStreamEvents(since time.Time, limit int) ([]domain.StreamEvent, error) {
+	findoptions := options.Find()
+	findoptions.SetLimit(int64(limit))
+	findoptions.SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+
+	return sr.findStreamEvents(bson.M{"created_at": bson.M{"$gte": since}}, findoptions)
+}
+
+func (sr *StreamRepository) findStreamEvents(query bson.M, findoptions *options.FindOptions) ([]domain.StreamEvent, error) {
+	events := []domain.StreamEvent{}
+	cursor, err := sr.streamDB.Find(context.Background(), query, findoptions)
+	if err != nil {
+		return nil, errors.New("Error fetching stream events")
+	}
+	defer cursor.Close(context.Background())
+	err = cursor.All(context.Background(), &events)
+	return events, err
+}
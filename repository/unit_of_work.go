@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"loan_tracker_api/domain"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// how many times a unit is replayed when its audit entry loses its place in the chain to a concurrent one
+const unitRetries = 3
+
+// MongoUnitOfWork runs units of work in MongoDB multi-document transactions
+type MongoUnitOfWork struct {
+	client *mongo.Client
+}
+
+// noopUnitOfWork runs units of work as they come, for deployments without transactions
+type noopUnitOfWork struct{}
+
+// NewUnitOfWork creates a unit of work for the deployment the client is connected to.
+// Transactions need a replica set or a sharded cluster, standalone servers get writes applied one at a time.
+func NewUnitOfWork(client *mongo.Client) domain.UnitOfWork {
+	var hello bson.M
+	err := client.Database("admin").RunCommand(context.Background(), bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	_, replicaSet := hello["setName"]
+	sharded := hello["msg"] == "isdbgrid"
+
+	if err != nil || (!replicaSet && !sharded) {
+		log.Println("MongoDB deployment doesn't support transactions, writes won't be grouped")
+		return &noopUnitOfWork{}
+	}
+
+	return &MongoUnitOfWork{client: client}
+}
+
+// Do runs work in a transaction, committing its writes only when it succeeds
+func (uow *MongoUnitOfWork) Do(c context.Context, work func(c context.Context) error) error {
+	session, err := uow.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(context.Background())
+
+	for attempt := 1; ; attempt++ {
+		// WithTransaction already retries transient errors, a duplicate sequence in the
+		// audit chain aborts the transaction instead and the whole unit is replayed
+		_, err = session.WithTransaction(c, func(sc mongo.SessionContext) (interface{}, error) {
+			return nil, work(sc)
+		})
+		if !mongo.IsDuplicateKeyError(err) || attempt == unitRetries {
+			return err
+		}
+	}
+}
+
+// Do runs work directly, writes already made stay when it fails
+func (uow *noopUnitOfWork) Do(c context.Context, work func(c context.Context) error) error {
+	return work(c)
+}
@@ -11,13 +11,13 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type UserRepository struct {
 	client     *mongo.Client
 	database   *mongo.Database
 	collection *mongo.Collection
-	logDB      *mongo.Collection
 }
 
 func NewUserRepository(mongoClient *mongo.Client) domain.UserRepository {
@@ -25,12 +25,11 @@ func NewUserRepository(mongoClient *mongo.Client) domain.UserRepository {
 		client:     mongoClient,
 		database:   mongoClient.Database("Loan-Tracker"),
 		collection: mongoClient.Database("Loan-Tracker").Collection("Users"),
-		logDB:      mongoClient.Database("Loan-Tracker").Collection("Logs"),
 	}
 
 }
 
-func (urepo *UserRepository) RegisterUser(user *domain.User) error {
+func (urepo *UserRepository) RegisterUser(c context.Context, user *domain.User) error {
 
 	emailFilter := bson.M{"email": user.Email}
 	emailExists, err := urepo.collection.CountDocuments(context.TODO(), emailFilter)
@@ -84,6 +83,8 @@ func (urepo *UserRepository) RegisterUser(user *domain.User) error {
 
 	user.ID = primitive.NewObjectID()
 	user.IsVerified = false
+	user.KYCStatus = domain.KYCNotSubmitted
+	user.Deletion = nil
 
 	password, err := infrastructure.PasswordHasher(user.Password)
 	if err != nil {
@@ -91,68 +92,51 @@ func (urepo *UserRepository) RegisterUser(user *domain.User) error {
 	}
 	user.Password = password
 
-	err = infrastructure.UserVerification(user.Email)
+	_, err = urepo.collection.InsertOne(c, user)
 	if err != nil {
-		return errors.New("User registration failed")
-	}
-
-	_, err = urepo.collection.InsertOne(context.TODO(), user)
-	if err != nil {
-		return errors.New("User registration failed")
+		return wrapError("User registration failed", err)
 	}
 
 	return nil
 }
 
-func (urepo *UserRepository) VerifyUserEmail(token string) error {
+// VerifyUserEmail marks the user the token was issued to as verified and returns them as they were before
+func (urepo *UserRepository) VerifyUserEmail(c context.Context, token string) (domain.User, error) {
 	email, err := infrastructure.VerifyToken(token)
 	if err != nil {
-		return errors.New("Token verification failed")
+		return domain.User{}, errors.New("Token verification failed")
+	}
+
+	var user domain.User
+	filter := bson.M{"email": email, "deletion": nil}
+	if err := urepo.collection.FindOne(c, filter).Decode(&user); err != nil {
+		return domain.User{}, wrapError("User not found", err)
 	}
 
-	filter := bson.M{"email": email}
 	update := bson.M{"$set": bson.M{"isverified": true}}
 
-	_, err = urepo.collection.UpdateOne(context.TODO(), filter, update)
+	_, err = urepo.collection.UpdateOne(c, filter, update)
 	if err != nil {
-		return errors.New("Email verification failed")
+		return domain.User{}, wrapError("Email verification failed", err)
 	}
 
-	return nil
+	return user, nil
 }
 
-func (urepo *UserRepository) LoginUser(user domain.User) (string, string, error) {
-	filter := bson.M{"email": user.Email}
+func (urepo *UserRepository) LoginUser(c context.Context, user domain.User) (string, string, error) {
+	filter := bson.M{"email": user.Email, "deletion": nil}
 	var u domain.User
-	err := urepo.collection.FindOne(context.TODO(), filter).Decode(&u)
+	err := urepo.collection.FindOne(c, filter).Decode(&u)
 	if err != nil {
 		return "", "", errors.New("User not found")
 	}
 
 	if !u.IsVerified {
-		log := domain.Log{
-			ID:        primitive.NewObjectID(),
-			UserID:    u.ID,
-			Activity:  "Failed login attempt due to unverified email",
-			CreatedAt: time.Now(),
-		}
-
-		_, err = urepo.logDB.InsertOne(context.TODO(), log)
-
 		return "", "", errors.New("Email not verified")
 	}
 
 	check := infrastructure.PasswordComparator(u.Password, user.Password)
 	if check != nil {
-		log := domain.Log{
-			ID:        primitive.NewObjectID(),
-			UserID:    u.ID,
-			Activity:  "Failed login attempt due to invalid password",
-			CreatedAt: time.Now(),
-		}
-
-		_, err = urepo.logDB.InsertOne(context.TODO(), log)
-
 		return "", "", errors.New("Invalid password")
 	}
 
@@ -193,20 +177,11 @@ func (urepo *UserRepository) LoginUser(user domain.User) (string, string, error)
 	}
 
 	update := bson.M{"$set": bson.M{"refreshtoken": refreshToken}}
-	_, err = urepo.collection.UpdateOne(context.TODO(), filter, update)
+	_, err = urepo.collection.UpdateOne(c, filter, update)
 	if err != nil {
-		return "", "", errors.New("Refresh token update failed")
+		return "", "", wrapError("Refresh token update failed", err)
 	}
 
-	log := domain.Log{
-		ID:        primitive.NewObjectID(),
-		UserID:    u.ID,
-		Activity:  "User logged in",
-		CreatedAt: time.Now(),
-	}
-
-	_, err = urepo.logDB.InsertOne(context.TODO(), log)
-
 	return refreshToken, accessToken, nil
 }
 
@@ -229,7 +204,7 @@ func (urepo *UserRepository) TokenRefresh(refresh_token string) (string, error)
 func (urepo *UserRepository) UserProfile(uid string) (domain.User, error) {
 	var user domain.User
 	uidObj, _ := primitive.ObjectIDFromHex(uid)
-	filter := bson.M{"_id": uidObj}
+	filter := bson.M{"_id": uidObj, "deletion": nil}
 	err := urepo.collection.FindOne(context.TODO(), filter).Decode(&user)
 	if err != nil {
 		return domain.User{}, errors.New("User not found")
@@ -238,71 +213,49 @@ func (urepo *UserRepository) UserProfile(uid string) (domain.User, error) {
 	return user, nil
 }
 
-func (urepo *UserRepository) ForgotPassword(email string) error {
+func (urepo *UserRepository) UserByEmail(email string) (domain.User, error) {
 	var user domain.User
-
-	query := bson.M{"email": email}
-	if err := urepo.collection.FindOne(context.TODO(), query).Decode(&user); err != nil {
-		return errors.New("User not found")
-	}
-
-	err := infrastructure.ForgotPasswordHandler(email)
+	filter := bson.M{"email": email, "deletion": nil}
+	err := urepo.collection.FindOne(context.TODO(), filter).Decode(&user)
 	if err != nil {
-		return errors.New("Password reset failed")
-	}
-
-	log := domain.Log{
-		ID:        primitive.NewObjectID(),
-		UserID:    user.ID,
-		Activity:  "Password reset request",
-		CreatedAt: time.Now(),
+		return domain.User{}, errors.New("User not found")
 	}
 
-	_, err = urepo.logDB.InsertOne(context.TODO(), log)
-
-	return nil
+	return user, nil
 }
 
-func (urepo *UserRepository) ResetPassword(token string, newPassword string) error {
+// ResetPassword sets a new password for the user the token was issued to and returns them as they were before
+func (urepo *UserRepository) ResetPassword(c context.Context, token string, newPassword string) (domain.User, error) {
 	email, err := infrastructure.VerifyToken(token)
 	if err != nil {
-		return errors.New("Token verification failed")
+		return domain.User{}, errors.New("Token verification failed")
 	}
 
 	var user domain.User
 
-	query := bson.M{"email": email}
-	if err := urepo.collection.FindOne(context.TODO(), query).Decode(&user); err != nil {
-		return errors.New("User not found")
+	query := bson.M{"email": email, "deletion": nil}
+	if err := urepo.collection.FindOne(c, query).Decode(&user); err != nil {
+		return domain.User{}, wrapError("User not found", err)
 	}
 
 	hashedPassword, err := infrastructure.PasswordHasher(newPassword)
 	if err != nil {
-		return errors.New("Password reset failed")
+		return domain.User{}, errors.New("Password reset failed")
 	}
 
-	filter := bson.M{"email": email}
+	filter := bson.M{"email": email, "deletion": nil}
 	update := bson.M{"$set": bson.M{"password": string(hashedPassword)}}
 
-	_, err = urepo.collection.UpdateOne(context.TODO(), filter, update)
+	_, err = urepo.collection.UpdateOne(c, filter, update)
 	if err != nil {
-		return errors.New("Password reset failed")
-	}
-
-	log := domain.Log{
-		ID:        primitive.NewObjectID(),
-		UserID:    user.ID,
-		Activity:  "Password reset successfully",
-		CreatedAt: time.Now(),
+		return domain.User{}, wrapError("Password reset failed", err)
 	}
 
-	_, err = urepo.logDB.InsertOne(context.TODO(), log)
-
-	return nil
+	return user, nil
 }
 
-func (urepo *UserRepository) UpdateUserDetails(user *domain.User) error {
-	filter := bson.M{"_id": user.ID}
+func (urepo *UserRepository) UpdateUserDetails(c context.Context, user *domain.User) error {
+	filter := bson.M{"_id": user.ID, "deletion": nil}
 
 	update := bson.M{}
 	setFields := bson.M{}
@@ -319,6 +272,12 @@ func (urepo *UserRepository) UpdateUserDetails(user *domain.User) error {
 	if user.Contact != "" {
 		setFields["contact"] = user.Contact
 	}
+	if user.Language != "" {
+		setFields["language"] = user.Language
+	}
+	if user.NotificationChannels != nil {
+		setFields["notificationchannels"] = user.NotificationChannels
+	}
 
 	if len(setFields) > 0 {
 		update["$set"] = setFields
@@ -328,9 +287,9 @@ func (urepo *UserRepository) UpdateUserDetails(user *domain.User) error {
 		return errors.New("No fields to update")
 	}
 
-	result, err := urepo.collection.UpdateOne(context.TODO(), filter, update)
+	result, err := urepo.collection.UpdateOne(c, filter, update)
 	if err != nil {
-		return errors.New("Update failed")
+		return wrapError("Update failed", err)
 	}
 
 	if result.ModifiedCount == 0 {
@@ -340,7 +299,7 @@ func (urepo *UserRepository) UpdateUserDetails(user *domain.User) error {
 	return nil
 }
 
-func (urepo *UserRepository) LogoutUser(uid string) error {
+func (urepo *UserRepository) LogoutUser(c context.Context, uid string) error {
 	uuid, err := primitive.ObjectIDFromHex(uid)
 	if err != nil {
 		return errors.New("Invalid user ID")
@@ -348,9 +307,9 @@ func (urepo *UserRepository) LogoutUser(uid string) error {
 
 	filter := bson.M{"_id": uuid}
 	update := bson.M{"$set": bson.M{"refreshtoken": ""}}
-	_, err = urepo.collection.UpdateOne(context.TODO(), filter, update)
+	_, err = urepo.collection.UpdateOne(c, filter, update)
 	if err != nil {
-		return errors.New("Logout failed")
+		return wrapError("Logout failed", err)
 	}
 
 	return nil
@@ -360,7 +319,7 @@ func (urepo *UserRepository) LogoutUser(uid string) error {
 
 func (urepo *UserRepository) ViewAllUsers() ([]domain.User, error) {
 	var users []domain.User
-	cursor, err := urepo.collection.Find(context.Background(), bson.M{})
+	cursor, err := urepo.collection.Find(context.Background(), bson.M{"deletion": nil})
 	if err != nil {
 		return nil, errors.New("Error fetching users")
 	}
@@ -375,17 +334,141 @@ func (urepo *UserRepository) ViewAllUsers() ([]domain.User, error) {
 	return users, nil
 }
 
-func (urepo *UserRepository) DeleteUser(uid string) error {
+// DeleteUser saves a deleted user, their personal fields already anonymized and their tokens revoked.
+// The document is kept as a tombstone so their loans stay linked to it.
+func (urepo *UserRepository) DeleteUser(c context.Context, user *domain.User) error {
+	filter := bson.M{"_id": user.ID, "deletion": nil}
+	update := bson.M{"$set": bson.M{
+		"deletion":     user.Deletion,
+		"username":     user.UserName,
+		"email":        user.Email,
+		"imageuri":     user.Imageuri,
+		"bio":          user.Bio,
+		"contact":      user.Contact,
+		"password":     user.Password,
+		"refreshtoken": user.RefreshToken,
+		"isverified":   user.IsVerified,
+	}}
+	result, err := urepo.collection.UpdateOne(c, filter, update)
+	if err != nil {
+		return wrapError("User deletion failed", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("User not found")
+	}
+
+	return nil
+}
+
+func (urepo *UserRepository) RestoreUser(c context.Context, uid string) error {
 	uuid, err := primitive.ObjectIDFromHex(uid)
 	if err != nil {
 		return errors.New("Invalid user ID")
 	}
 
+	var user domain.User
+	err = urepo.collection.FindOne(c, bson.M{"_id": uuid, "deletion": bson.M{"$ne": nil}}).Decode(&user)
+	if err != nil {
+		return wrapError("Deleted user not found", err)
+	}
+
+	if user.Deletion.Anonymized {
+		return errors.New("Anonymized users cannot be restored")
+	}
+
 	filter := bson.M{"_id": uuid}
-	_, err = urepo.collection.DeleteOne(context.TODO(), filter)
+	update := bson.M{"$set": bson.M{"deletion": nil}}
+	_, err = urepo.collection.UpdateOne(c, filter, update)
 	if err != nil {
-		return errors.New("User deletion failed")
+		return wrapError("User restore failed", err)
 	}
 
 	return nil
 }
+
+// SetKYCStatus records the outcome of the latest KYC submission of a user
+func (urepo *UserRepository) SetKYCStatus(c context.Context, uid, status string) error {
+	uuid, err := primitive.ObjectIDFromHex(uid)
+	if err != nil {
+		return errors.New("Invalid user ID")
+	}
+
+	result, err := urepo.collection.UpdateOne(c, bson.M{"_id": uuid, "deletion": nil}, bson.M{"$set": bson.M{"kycstatus": status}})
+	if err != nil {
+		return wrapError("Error updating KYC status", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("User not found")
+	}
+
+	return nil
+}
+
+// PurgeDeletedUsers permanently removes users deleted before the given time and returns the IDs of the users it purged.
+// Users that loans still reference, as borrowers, guarantors or co-borrowers, are kept as tombstones.
+// Those deleted before deletion anonymized them are anonymized now.
+func (urepo *UserRepository) PurgeDeletedUsers(c context.Context, before time.Time) ([]primitive.ObjectID, error) {
+	loans := urepo.database.Collection("Loans")
+	borrowers, err := loans.Distinct(c, "user_id", bson.M{})
+	if err != nil {
+		return nil, wrapError("User purge failed", err)
+	}
+	parties, err := loans.Distinct(c, "parties.user_id", bson.M{})
+	if err != nil {
+		return nil, wrapError("User purge failed", err)
+	}
+	referenced := append(borrowers, parties...)
+
+	var deleted []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	filter := bson.M{
+		"deletion.deleted_at": bson.M{"$lt": before},
+		"$or": bson.A{
+			bson.M{"_id": bson.M{"$nin": referenced}},
+			bson.M{"deletion.anonymized": bson.M{"$ne": true}},
+		},
+	}
+	cursor, err := urepo.collection.Find(c, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, wrapError("User purge failed", err)
+	}
+	defer cursor.Close(c)
+	if err := cursor.All(c, &deleted); err != nil {
+		return nil, wrapError("User purge failed", err)
+	}
+
+	ids := []primitive.ObjectID{}
+	for _, user := range deleted {
+		ids = append(ids, user.ID)
+	}
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	_, err = urepo.collection.DeleteMany(c, bson.M{"_id": bson.M{"$in": ids, "$nin": referenced}})
+	if err != nil {
+		return nil, wrapError("User purge failed", err)
+	}
+
+	// the tombstone keeps its ID only, the username and email are made unique from it
+	anonymize := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"deletion.anonymized": true,
+		"username":            bson.M{"$concat": bson.A{"deleted-user-", bson.M{"$toString": "$_id"}}},
+		"email":               bson.M{"$concat": bson.A{"deleted-user-", bson.M{"$toString": "$_id"}, "@deleted.invalid"}},
+		"imageuri":            "",
+		"bio":                 "",
+		"contact":             "",
+		"password":            "",
+		"refreshtoken":        "",
+		"isverified":          false,
+	}}}}
+	_, err = urepo.collection.UpdateMany(c, bson.M{"_id": bson.M{"$in": ids}, "deletion.anonymized": bson.M{"$ne": true}}, anonymize)
+	if err != nil {
+		return nil, wrapError("User purge failed", err)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WebhookRepository represents the webhook repository contract
+type WebhookRepository struct {
+	client       *mongo.Client
+	webhookDB    *mongo.Collection
+	deliveriesDB *mongo.Collection
+}
+
+// NewWebhookRepository creates a new instance of WebhookRepository
+func NewWebhookRepository(client *mongo.Client) domain.WebhookRepository {
+	return &WebhookRepository{
+		client:       client,
+		webhookDB:    client.Database("Loan-Tracker").Collection("Webhooks"),
+		deliveriesDB: client.Database("Loan-Tracker").Collection("WebhookDeliveries"),
+	}
+}
+
+// AddWebhook stores a new webhook
+func (wr *WebhookRepository) AddWebhook(webhook *domain.Webhook) error {
+	webhook.ID = primitive.NewObjectID()
+	webhook.CreatedAt = time.Now()
+	webhook.UpdatedAt = webhook.CreatedAt
+
+	_, err := wr.webhookDB.InsertOne(context.Background(), webhook)
+	if err != nil {
+		return errors.New("Error creating webhook")
+	}
+
+	return nil
+}
+
+// UpdateWebhook saves the URL, events, secret and state of a webhook
+func (wr *WebhookRepository) UpdateWebhook(webhook *domain.Webhook) error {
+	webhook.UpdatedAt = time.Now()
+
+	update := bson.M{"$set": bson.M{
+		"url":        webhook.URL,
+		"events":     webhook.Events,
+		"secret":     webhook.Secret,
+		"active":     webhook.Active,
+		"updated_at": webhook.UpdatedAt,
+	}}
+
+	res, err := wr.webhookDB.UpdateOne(context.Background(), bson.M{"_id": webhook.ID}, update)
+	if err != nil {
+		return errors.New("Error updating webhook")
+	}
+
+	if res.MatchedCount == 0 {
+		return errors.New("Webhook not found")
+	}
+
+	return nil
+}
+
+// DeleteWebhook removes a webhook, its past deliveries are kept
+func (wr *WebhookRepository) DeleteWebhook(id string) error {
+	idObj, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("Invalid webhook ID")
+	}
+
+	res, err := wr.webhookDB.DeleteOne(context.Background(), bson.M{"_id": idObj})
+	if err != nil {
+		return errors.New("Error deleting webhook")
+	}
+
+	if res.DeletedCount == 0 {
+		return errors.New("Webhook not found")
+	}
+
+	return nil
+}
+
+// GetWebhook returns a webhook
+func (wr *WebhookRepository) GetWebhook(id string) (domain.Webhook, error) {
+	var webhook domain.Webhook
+
+	idObj, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return webhook, errors.New("Invalid webhook ID")
+	}
+
+	err = wr.webhookDB.FindOne(context.Background(), bson.M{"_id": idObj}).Decode(&webhook)
+	if err != nil {
+		return webhook, errors.New("Webhook not found")
+	}
+
+	return webhook, nil
+}
+
+// ListWebhooks returns every webhook, oldest first
+func (wr *WebhookRepository) ListWebhooks() ([]domain.Webhook, error) {
+	return wr.findWebhooks(bson.M{})
+}
+
+// WebhooksFor returns the active webhooks subscribed to the event
+func (wr *WebhookRepository) WebhooksFor(event string) ([]domain.Webhook, error) {
+	return wr.findWebhooks(bson.M{"active": true, "events": event})
+}
+
+func (wr *WebhookRepository) findWebhooks(filter bson.M) ([]domain.Webhook, error) {
+	findoptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	webhooks := []domain.Webhook{}
+	cursor, err := wr.webhookDB.Find(context.Background(), filter, findoptions)
+	if err != nil {
+		return nil, errors.New("Error fetching webhooks")
+	}
+	defer cursor.Close(context.Background())
+	err = cursor.All(context.Background(), &webhooks)
+	return webhooks, err
+}
+
+// AddDelivery records an event to be sent to a webhook
+func (wr *WebhookRepository) AddDelivery(c context.Context, delivery *domain.WebhookDelivery) error {
+	delivery.ID = primitive.NewObjectID()
+	delivery.Status = domain.DeliveryPending
+	delivery.Attempts = []domain.DeliveryAttempt{}
+	delivery.CreatedAt = time.Now()
+
+	_, err := wr.deliveriesDB.InsertOne(c, delivery)
+	if err != nil {
+		return wrapError("Error recording webhook delivery", err)
+	}
+
+	return nil
+}
+
+// GetDelivery returns a webhook delivery with its attempts
+func (wr *WebhookRepository) GetDelivery(id string) (domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+
+	idObj, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return delivery, errors.New("Invalid delivery ID")
+	}
+
+	err = wr.deliveriesDB.FindOne(context.Background(), bson.M{"_id": idObj}).Decode(&delivery)
+	if err != nil {
+		return delivery, errors.New("Delivery not found")
+	}
+
+	return delivery, nil
+}
+
+// RecordAttempt adds an attempt to a delivery and moves it to the status the attempt left it in
+func (wr *WebhookRepository) RecordAttempt(id primitive.ObjectID, attempt domain.DeliveryAttempt, status string) error {
+	set := bson.M{"status": status}
+	if status == domain.DeliveryDelivered {
+		set["delivered_at"] = attempt.AttemptedAt
+	}
+
+	update := bson.M{"$set": set, "$push": bson.M{"attempts": attempt}}
+	_, err := wr.deliveriesDB.UpdateOne(context.Background(), bson.M{"_id": id}, update)
+	if err != nil {
+		return errors.New("Error recording delivery attempt")
+	}
+
+	return nil
+}
+
+// Deliveries returns the deliveries of a webhook, newest first
+func (wr *WebhookRepository) Deliveries(webhookID string, page int) ([]domain.WebhookDelivery, error) {
+	webhookIDObj, err := primitive.ObjectIDFromHex(webhookID)
+	if err != nil {
+		return nil, errors.New("Invalid webhook ID")
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+
+	findoptions := options.Find()
+	findoptions.SetSkip(int64(perpage * (page - 1)))
+	findoptions.SetLimit(perpage)
+	findoptions.SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	deliveries := []domain.WebhookDelivery{}
+	cursor, err := wr.deliveriesDB.Find(context.Background(), bson.M{"webhook_id": webhookIDObj}, findoptions)
+	if err != nil {
+		return nil, errors.New("Error fetching deliveries")
+	}
+	defer cursor.Close(context.Background())
+	err = cursor.All(context.Background(), &deliveries)
+	return deliveries, err
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"loan_tracker_api/domain"
+	"reflect"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fields left out of audit diffs, they change on every write or never change at all
+var unaudited = map[string]bool{
+	"id":         true,
+	"updated_at": true,
+}
+
+// fields whose values never reach the audit log, only the fact that they changed.
+// Secrets are kept out, and so is the personal data of users, which would outlive its erasure in the log.
+var redacted = map[string]bool{
+	"password":     true,
+	"refreshtoken": true,
+	"email":        true,
+	"username":     true,
+	"contact":      true,
+	"bio":          true,
+	"imageuri":     true,
+}
+
+// snapshot captures the fields of a record as they are now so later changes can be diffed against it
+func snapshot(record interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if record == nil {
+		return fields
+	}
+
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return fields
+	}
+	json.Unmarshal(raw, &fields)
+	return fields
+}
+
+// diff lists the fields that differ between two snapshots of a record
+func diff(before, after map[string]interface{}) []domain.FieldChange {
+	names := map[string]bool{}
+	for name := range before {
+		names[name] = true
+	}
+	for name := range after {
+		names[name] = true
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		if !unaudited[name] {
+			sorted = append(sorted, name)
+		}
+	}
+	sort.Strings(sorted)
+
+	changes := []domain.FieldChange{}
+	for _, name := range sorted {
+		if isEmpty(before[name]) && isEmpty(after[name]) {
+			continue
+		}
+		if reflect.DeepEqual(before[name], after[name]) {
+			continue
+		}
+
+		change := domain.FieldChange{Field: name, Before: before[name], After: after[name]}
+		if redacted[name] {
+			change.Before, change.After = "[redacted]", "[redacted]"
+		}
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// isEmpty treats a missing field, null and empty arrays or objects alike
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// audit stores an audit entry on behalf of the actor, filling in the request it came from.
+// Entries without an actor are recorded as made by the system.
+func audit(c context.Context, logs domain.LogRepository, actorid string, entry domain.Log) error {
+	meta, _ := domain.RequestMetaFrom(c)
+
+	entry.ActorID, _ = primitive.ObjectIDFromHex(actorid)
+	switch {
+	case actorid == "":
+		entry.ActorRole = domain.RoleSystem
+	case meta.IsAdmin:
+		entry.ActorRole = domain.RoleAdmin
+	default:
+		entry.ActorRole = domain.RoleUser
+	}
+
+	entry.RequestID = meta.RequestID
+	entry.IP = meta.IP
+	entry.UserAgent = meta.UserAgent
+	if entry.Changes == nil {
+		entry.Changes = []domain.FieldChange{}
+	}
+
+	return logs.AddLog(c, &entry)
+}
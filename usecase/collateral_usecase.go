@@ -0,0 +1,318 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CollateralUsecase struct {
+	CollateralRepo domain.CollateralRepository
+	LoanRepo       domain.LoanRepository
+	UserRepo       domain.UserRepository
+	LogRepo        domain.LogRepository
+	UnitOfWork     domain.UnitOfWork
+	contextTimeout time.Duration
+}
+
+func NewCollateralUsecase(Collateralrepo domain.CollateralRepository, Loanrepo domain.LoanRepository, Userrepo domain.UserRepository, Logrepo domain.LogRepository, Unitofwork domain.UnitOfWork, timeout time.Duration) *CollateralUsecase {
+	return &CollateralUsecase{
+		CollateralRepo: Collateralrepo,
+		LoanRepo:       Loanrepo,
+		UserRepo:       Userrepo,
+		LogRepo:        Logrepo,
+		UnitOfWork:     Unitofwork,
+		contextTimeout: timeout,
+	}
+}
+
+// loanToValue is the amount of a loan over the appraised value of the collateral securing it, zero when nothing secures it
+func loanToValue(amount float64, collateral []domain.Collateral) float64 {
+	value := 0.0
+	for _, item := range collateral {
+		value += item.AppraisedValue
+	}
+	if value <= 0 {
+		return 0
+	}
+	return math.Round(amount/value*10000) / 10000
+}
+
+// checkAppraisal trims an appraisal and rejects incomplete ones
+func checkAppraisal(appraisal *domain.Appraisal, now time.Time) error {
+	appraisal.Description = strings.TrimSpace(appraisal.Description)
+
+	if appraisal.Description == "" {
+		return errors.New("Description is required")
+	}
+	if appraisal.AppraisedValue <= 0 {
+		return errors.New("Appraised value must be positive")
+	}
+	if appraisal.AppraisalDate.IsZero() {
+		return errors.New("Appraisal date is required")
+	}
+	if appraisal.AppraisalDate.After(now) {
+		return errors.New("Appraisal date cannot be in the future")
+	}
+	for i := range appraisal.Documents {
+		appraisal.Documents[i].Kind = strings.TrimSpace(appraisal.Documents[i].Kind)
+		appraisal.Documents[i].Reference = strings.TrimSpace(appraisal.Documents[i].Reference)
+		if appraisal.Documents[i].Kind == "" || appraisal.Documents[i].Reference == "" {
+			return errors.New("Every document needs a kind and a reference")
+		}
+	}
+	return nil
+}
+
+// auditCollateral records the changes made to collateral since the snapshot taken before them
+func (cuse *CollateralUsecase) auditCollateral(c context.Context, action, userid string, collateral *domain.Collateral, before map[string]interface{}, note string) error {
+	return audit(c, cuse.LogRepo, userid, domain.Log{
+		Action:     action,
+		TargetType: domain.TargetCollateral,
+		TargetID:   collateral.ID,
+		Changes:    diff(before, snapshot(collateral)),
+		Note:       note,
+	})
+}
+
+// saveCollateral stores collateral together with the audit entry of what changed since the snapshot
+func (cuse *CollateralUsecase) saveCollateral(c context.Context, action, userid string, collateral *domain.Collateral, before map[string]interface{}, note string) error {
+	return cuse.UnitOfWork.Do(c, func(c context.Context) error {
+		if err := cuse.CollateralRepo.UpdateCollateral(c, collateral); err != nil {
+			return err
+		}
+		return cuse.auditCollateral(c, action, userid, collateral, before, note)
+	})
+}
+
+// RegisterCollateral records an asset a user pledges, it has no lien until a loan it secures is approved
+func (cuse *CollateralUsecase) RegisterCollateral(c context.Context, collateral *domain.Collateral, adminid string) error {
+	_, cancel := context.WithTimeout(c, cuse.contextTimeout)
+	defer cancel()
+
+	if !contains(domain.CollateralTypes, collateral.Type) {
+		return errors.New("Invalid collateral type")
+	}
+
+	appraisal := domain.Appraisal{
+		Description:    collateral.Description,
+		AppraisedValue: collateral.AppraisedValue,
+		AppraisalDate:  collateral.AppraisalDate,
+		Documents:      collateral.Documents,
+	}
+	if err := checkAppraisal(&appraisal, time.Now()); err != nil {
+		return err
+	}
+
+	if _, err := cuse.UserRepo.UserProfile(collateral.OwnerID.Hex()); err != nil {
+		return errors.New("Owner not found")
+	}
+
+	collateral.Description = appraisal.Description
+	if collateral.Documents == nil {
+		collateral.Documents = []domain.CollateralDocument{}
+	}
+	collateral.LienStatus = domain.LienNone
+	collateral.LoanIDs = []primitive.ObjectID{}
+	collateral.RegisteredBy, _ = primitive.ObjectIDFromHex(adminid)
+
+	return cuse.UnitOfWork.Do(c, func(c context.Context) error {
+		if err := cuse.CollateralRepo.AddCollateral(c, collateral); err != nil {
+			return err
+		}
+		return cuse.auditCollateral(c, domain.ActionCollateralRegistered, adminid, collateral, nil, "")
+	})
+}
+
+func (cuse *CollateralUsecase) ListCollateral(c context.Context, ownerID string, page int) ([]domain.Collateral, error) {
+	_, cancel := context.WithTimeout(c, cuse.contextTimeout)
+	defer cancel()
+	return cuse.CollateralRepo.ListCollateral(ownerID, page)
+}
+
+func (cuse *CollateralUsecase) GetCollateral(c context.Context, id string) (domain.Collateral, error) {
+	_, cancel := context.WithTimeout(c, cuse.contextTimeout)
+	defer cancel()
+	return cuse.CollateralRepo.GetCollateral(id)
+}
+
+// AppraiseCollateral records a new valuation of collateral, the loans it secures show the new loan-to-value
+func (cuse *CollateralUsecase) AppraiseCollateral(c context.Context, id string, appraisal domain.Appraisal, adminid string) (domain.Collateral, error) {
+	_, cancel := context.WithTimeout(c, cuse.contextTimeout)
+	defer cancel()
+
+	if err := checkAppraisal(&appraisal, time.Now()); err != nil {
+		return domain.Collateral{}, err
+	}
+
+	collateral, err := cuse.CollateralRepo.GetCollateral(id)
+	if err != nil {
+		return domain.Collateral{}, err
+	}
+
+	before := snapshot(collateral)
+	collateral.Description = appraisal.Description
+	collateral.AppraisedValue = appraisal.AppraisedValue
+	collateral.AppraisalDate = appraisal.AppraisalDate
+	if appraisal.Documents != nil {
+		collateral.Documents = appraisal.Documents
+	}
+
+	if err := cuse.saveCollateral(c, domain.ActionCollateralAppraised, adminid, &collateral, before, ""); err != nil {
+		return domain.Collateral{}, err
+	}
+
+	return collateral, nil
+}
+
+// LinkCollateral pledges collateral to a loan that is still open. The collateral must belong to the borrower
+// or to one of the guarantors and co-borrowers of the loan, and can't be under a lien for another loan,
+// its whole value secures a single loan being repaid. Linking it to an approved loan places the lien right away.
+func (cuse *CollateralUsecase) LinkCollateral(c context.Context, loanID, id, adminid string) (domain.Collateral, error) {
+	_, cancel := context.WithTimeout(c, cuse.contextTimeout)
+	defer cancel()
+
+	loan, err := cuse.LoanRepo.GetLoan(loanID)
+	if err != nil {
+		return domain.Collateral{}, errors.New("Loan not found")
+	}
+	switch loan.Status {
+	case domain.LoanAwaitingAcceptance, "pending", "approved":
+	default:
+		return domain.Collateral{}, errors.New("Collateral can only be linked to open loans")
+	}
+
+	collateral, err := cuse.CollateralRepo.GetCollateral(id)
+	if err != nil {
+		return domain.Collateral{}, err
+	}
+
+	for _, linked := range collateral.LoanIDs {
+		if linked == loan.ID {
+			return domain.Collateral{}, errors.New("Collateral is already linked to the loan")
+		}
+	}
+	if collateral.LienStatus == domain.LienActive {
+		return domain.Collateral{}, errors.New("Collateral already secures another loan")
+	}
+
+	owned := collateral.OwnerID == loan.UserID
+	for _, party := range loan.Parties {
+		owned = owned || (party.UserID == collateral.OwnerID && party.Status != domain.PartyDeclined)
+	}
+	if !owned {
+		return domain.Collateral{}, errors.New("Collateral must belong to the borrower, a guarantor or a co-borrower of the loan")
+	}
+
+	before := snapshot(collateral)
+	collateral.LoanIDs = append(collateral.LoanIDs, loan.ID)
+	if loan.Status == "approved" {
+		collateral.LienStatus = domain.LienActive
+	}
+
+	if err := cuse.saveCollateral(c, domain.ActionCollateralLinked, adminid, &collateral, before, "Linked to loan "+loan.ID.Hex()); err != nil {
+		return domain.Collateral{}, err
+	}
+
+	return collateral, nil
+}
+
+// UnlinkCollateral takes collateral off a loan that hasn't been approved yet
+func (cuse *CollateralUsecase) UnlinkCollateral(c context.Context, loanID, id, adminid string) (domain.Collateral, error) {
+	_, cancel := context.WithTimeout(c, cuse.contextTimeout)
+	defer cancel()
+
+	loan, err := cuse.LoanRepo.GetLoan(loanID)
+	if err != nil {
+		return domain.Collateral{}, errors.New("Loan not found")
+	}
+	if loan.Status == "approved" {
+		return domain.Collateral{}, errors.New("Collateral securing an approved loan can't be unlinked")
+	}
+
+	collateral, err := cuse.CollateralRepo.GetCollateral(id)
+	if err != nil {
+		return domain.Collateral{}, err
+	}
+
+	before := snapshot(collateral)
+	linked := []primitive.ObjectID{}
+	for _, other := range collateral.LoanIDs {
+		if other != loan.ID {
+			linked = append(linked, other)
+		}
+	}
+	if len(linked) == len(collateral.LoanIDs) {
+		return domain.Collateral{}, errors.New("Collateral is not linked to the loan")
+	}
+	collateral.LoanIDs = linked
+
+	if err := cuse.saveCollateral(c, domain.ActionCollateralUnlinked, adminid, &collateral, before, "Unlinked from loan "+loan.ID.Hex()); err != nil {
+		return domain.Collateral{}, err
+	}
+
+	return collateral, nil
+}
+
+// LoanApproved places a lien on the collateral securing a loan once it is approved
+func (cuse *CollateralUsecase) LoanApproved(c context.Context, event domain.Event) error {
+	approved, ok := event.(*domain.LoanApproved)
+	if !ok {
+		return nil
+	}
+
+	collateral, err := cuse.CollateralRepo.LoanCollateral(approved.LoanID.Hex())
+	if err != nil {
+		return err
+	}
+
+	for i := range collateral {
+		if collateral[i].LienStatus == domain.LienActive {
+			continue
+		}
+		before := snapshot(collateral[i])
+		collateral[i].LienStatus = domain.LienActive
+		if err := cuse.CollateralRepo.UpdateCollateral(c, &collateral[i]); err != nil {
+			return err
+		}
+		if err := cuse.auditCollateral(c, domain.ActionCollateralLien, approved.ApprovedBy.Hex(), &collateral[i], before, "Lien placed for loan "+approved.LoanID.Hex()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoanPaidOff releases the lien on the collateral of a paid off loan
+func (cuse *CollateralUsecase) LoanPaidOff(c context.Context, event domain.Event) error {
+	paidOff, ok := event.(*domain.LoanPaidOff)
+	if !ok {
+		return nil
+	}
+
+	collateral, err := cuse.CollateralRepo.LoanCollateral(paidOff.LoanID.Hex())
+	if err != nil {
+		return err
+	}
+
+	for i := range collateral {
+		if collateral[i].LienStatus != domain.LienActive {
+			continue
+		}
+		before := snapshot(collateral[i])
+		collateral[i].LienStatus = domain.LienReleased
+		if err := cuse.CollateralRepo.UpdateCollateral(c, &collateral[i]); err != nil {
+			return err
+		}
+		if err := cuse.auditCollateral(c, domain.ActionCollateralLien, "", &collateral[i], before, "Lien released, loan "+paidOff.LoanID.Hex()+" paid off"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
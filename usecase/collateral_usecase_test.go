@@ -0,0 +1,215 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"loan_tracker_api/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CollateralUsecaseTestSuite struct {
+	suite.Suite
+	mockCollateralRepository *mocks.CollateralRepository
+	mockLoanRepository       *mocks.LoanRepository
+	mockUserRepository       *mocks.UserRepository
+	mockLogRepository        *mocks.LogRepository
+	mockUnitOfWork           *mocks.UnitOfWork
+	CollateralUsecase        *usecase.CollateralUsecase
+	loan                     domain.Loan
+}
+
+func (s *CollateralUsecaseTestSuite) SetupTest() {
+	s.mockCollateralRepository = new(mocks.CollateralRepository)
+	s.mockLoanRepository = new(mocks.LoanRepository)
+	s.mockUserRepository = new(mocks.UserRepository)
+	s.mockLogRepository = new(mocks.LogRepository)
+	s.mockUnitOfWork = new(mocks.UnitOfWork)
+	s.mockUnitOfWork.On("Do", mock.Anything, mock.Anything).Return(func(c context.Context, work func(context.Context) error) error {
+		return work(c)
+	}).Maybe()
+	s.CollateralUsecase = usecase.NewCollateralUsecase(s.mockCollateralRepository, s.mockLoanRepository, s.mockUserRepository, s.mockLogRepository, s.mockUnitOfWork, time.Second*2)
+
+	s.loan = domain.Loan{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), Amount: 60000, Status: "pending"}
+}
+
+func (s *CollateralUsecaseTestSuite) collateral() *domain.Collateral {
+	return &domain.Collateral{
+		OwnerID:        s.loan.UserID,
+		Type:           domain.CollateralVehicle,
+		Description:    " 2021 Toyota Hilux ",
+		AppraisedValue: 80000,
+		AppraisalDate:  time.Now().AddDate(0, -1, 0),
+		Documents:      []domain.CollateralDocument{{Kind: "title", Reference: "VT-88231"}},
+	}
+}
+
+func (s *CollateralUsecaseTestSuite) TestRegisterCollateral() {
+	adminID := primitive.NewObjectID()
+	s.mockUserRepository.On("UserProfile", s.loan.UserID.Hex()).Return(domain.User{ID: s.loan.UserID}, nil).Once()
+	s.mockCollateralRepository.On("AddCollateral", mock.Anything, mock.MatchedBy(func(c *domain.Collateral) bool {
+		return c.Description == "2021 Toyota Hilux" && c.LienStatus == domain.LienNone && c.RegisteredBy == adminID && len(c.LoanIDs) == 0
+	})).Return(nil).Once()
+	s.mockLogRepository.On("AddLog", mock.Anything, mock.MatchedBy(func(entry *domain.Log) bool {
+		return entry.Action == domain.ActionCollateralRegistered && entry.TargetType == domain.TargetCollateral
+	})).Return(nil).Once()
+
+	err := s.CollateralUsecase.RegisterCollateral(context.Background(), s.collateral(), adminID.Hex())
+
+	s.NoError(err)
+	s.mockCollateralRepository.AssertExpectations(s.T())
+	s.mockLogRepository.AssertExpectations(s.T())
+}
+
+func (s *CollateralUsecaseTestSuite) TestRegisterCollateralInvalid() {
+	invalidType := s.collateral()
+	invalidType.Type = "jewellery"
+	futureAppraisal := s.collateral()
+	futureAppraisal.AppraisalDate = time.Now().AddDate(0, 0, 1)
+	noValue := s.collateral()
+	noValue.AppraisedValue = 0
+
+	s.EqualError(s.CollateralUsecase.RegisterCollateral(context.Background(), invalidType, ""), "Invalid collateral type")
+	s.EqualError(s.CollateralUsecase.RegisterCollateral(context.Background(), futureAppraisal, ""), "Appraisal date cannot be in the future")
+	s.EqualError(s.CollateralUsecase.RegisterCollateral(context.Background(), noValue, ""), "Appraised value must be positive")
+	s.mockCollateralRepository.AssertNotCalled(s.T(), "AddCollateral", mock.Anything, mock.Anything)
+}
+
+func (s *CollateralUsecaseTestSuite) TestLinkCollateralToApprovedLoanPlacesLien() {
+	s.loan.Status = "approved"
+	collateral := *s.collateral()
+	collateral.ID = primitive.NewObjectID()
+	collateral.LienStatus = domain.LienNone
+	s.mockLoanRepository.On("GetLoan", s.loan.ID.Hex()).Return(s.loan, nil).Once()
+	s.mockCollateralRepository.On("GetCollateral", collateral.ID.Hex()).Return(collateral, nil).Once()
+	s.mockCollateralRepository.On("UpdateCollateral", mock.Anything, mock.MatchedBy(func(c *domain.Collateral) bool {
+		return c.LienStatus == domain.LienActive && len(c.LoanIDs) == 1 && c.LoanIDs[0] == s.loan.ID
+	})).Return(nil).Once()
+	s.mockLogRepository.On("AddLog", mock.Anything, mock.MatchedBy(func(entry *domain.Log) bool {
+		return entry.Action == domain.ActionCollateralLinked
+	})).Return(nil).Once()
+
+	linked, err := s.CollateralUsecase.LinkCollateral(context.Background(), s.loan.ID.Hex(), collateral.ID.Hex(), primitive.NewObjectID().Hex())
+
+	s.NoError(err)
+	s.Equal(domain.LienActive, linked.LienStatus)
+	s.mockCollateralRepository.AssertExpectations(s.T())
+}
+
+func (s *CollateralUsecaseTestSuite) TestLinkCollateralOfGuarantor() {
+	guarantorID := primitive.NewObjectID()
+	s.loan.Parties = []domain.LoanParty{{Role: domain.PartyGuarantor, UserID: guarantorID, Status: domain.PartyAccepted}}
+	collateral := *s.collateral()
+	collateral.ID = primitive.NewObjectID()
+	collateral.OwnerID = guarantorID
+	collateral.LienStatus = domain.LienNone
+	s.mockLoanRepository.On("GetLoan", s.loan.ID.Hex()).Return(s.loan, nil).Once()
+	s.mockCollateralRepository.On("GetCollateral", collateral.ID.Hex()).Return(collateral, nil).Once()
+	s.mockCollateralRepository.On("UpdateCollateral", mock.Anything, mock.Anything).Return(nil).Once()
+	s.mockLogRepository.On("AddLog", mock.Anything, mock.Anything).Return(nil).Once()
+
+	linked, err := s.CollateralUsecase.LinkCollateral(context.Background(), s.loan.ID.Hex(), collateral.ID.Hex(), primitive.NewObjectID().Hex())
+
+	s.NoError(err)
+	s.Equal(domain.LienNone, linked.LienStatus)
+}
+
+func (s *CollateralUsecaseTestSuite) TestLinkCollateralOfStranger() {
+	collateral := *s.collateral()
+	collateral.ID = primitive.NewObjectID()
+	collateral.OwnerID = primitive.NewObjectID()
+	s.mockLoanRepository.On("GetLoan", s.loan.ID.Hex()).Return(s.loan, nil).Once()
+	s.mockCollateralRepository.On("GetCollateral", collateral.ID.Hex()).Return(collateral, nil).Once()
+
+	_, err := s.CollateralUsecase.LinkCollateral(context.Background(), s.loan.ID.Hex(), collateral.ID.Hex(), primitive.NewObjectID().Hex())
+
+	s.EqualError(err, "Collateral must belong to the borrower, a guarantor or a co-borrower of the loan")
+	s.mockCollateralRepository.AssertNotCalled(s.T(), "UpdateCollateral", mock.Anything, mock.Anything)
+}
+
+func (s *CollateralUsecaseTestSuite) TestLinkCollateralUnderLien() {
+	collateral := *s.collateral()
+	collateral.ID = primitive.NewObjectID()
+	collateral.LienStatus = domain.LienActive
+	collateral.LoanIDs = []primitive.ObjectID{primitive.NewObjectID()}
+	s.mockLoanRepository.On("GetLoan", s.loan.ID.Hex()).Return(s.loan, nil).Once()
+	s.mockCollateralRepository.On("GetCollateral", collateral.ID.Hex()).Return(collateral, nil).Once()
+
+	_, err := s.CollateralUsecase.LinkCollateral(context.Background(), s.loan.ID.Hex(), collateral.ID.Hex(), primitive.NewObjectID().Hex())
+
+	s.EqualError(err, "Collateral already secures another loan")
+	s.mockCollateralRepository.AssertNotCalled(s.T(), "UpdateCollateral", mock.Anything, mock.Anything)
+}
+
+func (s *CollateralUsecaseTestSuite) TestLinkCollateralToClosedLoan() {
+	s.loan.Status = "paid_off"
+	s.mockLoanRepository.On("GetLoan", s.loan.ID.Hex()).Return(s.loan, nil).Once()
+
+	_, err := s.CollateralUsecase.LinkCollateral(context.Background(), s.loan.ID.Hex(), primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex())
+
+	s.EqualError(err, "Collateral can only be linked to open loans")
+}
+
+func (s *CollateralUsecaseTestSuite) TestUnlinkCollateralFromApprovedLoan() {
+	s.loan.Status = "approved"
+	s.mockLoanRepository.On("GetLoan", s.loan.ID.Hex()).Return(s.loan, nil).Once()
+
+	_, err := s.CollateralUsecase.UnlinkCollateral(context.Background(), s.loan.ID.Hex(), primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex())
+
+	s.EqualError(err, "Collateral securing an approved loan can't be unlinked")
+	s.mockCollateralRepository.AssertNotCalled(s.T(), "GetCollateral", mock.Anything)
+}
+
+func (s *CollateralUsecaseTestSuite) TestLoanApprovedPlacesLiens() {
+	adminID := primitive.NewObjectID()
+	collateral := []domain.Collateral{{ID: primitive.NewObjectID(), LienStatus: domain.LienNone, LoanIDs: []primitive.ObjectID{s.loan.ID}}}
+	s.mockCollateralRepository.On("LoanCollateral", s.loan.ID.Hex()).Return(collateral, nil).Once()
+	s.mockCollateralRepository.On("UpdateCollateral", mock.Anything, mock.MatchedBy(func(c *domain.Collateral) bool {
+		return c.LienStatus == domain.LienActive
+	})).Return(nil).Once()
+	s.mockLogRepository.On("AddLog", mock.Anything, mock.MatchedBy(func(entry *domain.Log) bool {
+		return entry.Action == domain.ActionCollateralLien && entry.ActorID == adminID
+	})).Return(nil).Once()
+
+	err := s.CollateralUsecase.LoanApproved(context.Background(), &domain.LoanApproved{LoanID: s.loan.ID, ApprovedBy: adminID})
+
+	s.NoError(err)
+	s.mockCollateralRepository.AssertExpectations(s.T())
+	s.mockLogRepository.AssertExpectations(s.T())
+}
+
+func (s *CollateralUsecaseTestSuite) TestLoanPaidOffReleasesLiens() {
+	released := domain.Collateral{ID: primitive.NewObjectID(), LienStatus: domain.LienActive, LoanIDs: []primitive.ObjectID{s.loan.ID}}
+	pending := domain.Collateral{ID: primitive.NewObjectID(), LienStatus: domain.LienNone, LoanIDs: []primitive.ObjectID{s.loan.ID}}
+	s.mockCollateralRepository.On("LoanCollateral", s.loan.ID.Hex()).Return([]domain.Collateral{released, pending}, nil).Once()
+	s.mockCollateralRepository.On("UpdateCollateral", mock.Anything, mock.MatchedBy(func(c *domain.Collateral) bool {
+		return c.ID == released.ID && c.LienStatus == domain.LienReleased
+	})).Return(nil).Once()
+	s.mockLogRepository.On("AddLog", mock.Anything, mock.Anything).Return(nil).Once()
+
+	err := s.CollateralUsecase.LoanPaidOff(context.Background(), &domain.LoanPaidOff{LoanID: s.loan.ID})
+
+	s.NoError(err)
+	s.mockCollateralRepository.AssertExpectations(s.T())
+}
+
+func (s *CollateralUsecaseTestSuite) TestLoanPaidOffFailure() {
+	collateral := domain.Collateral{ID: primitive.NewObjectID(), LienStatus: domain.LienActive, LoanIDs: []primitive.ObjectID{s.loan.ID}}
+	s.mockCollateralRepository.On("LoanCollateral", s.loan.ID.Hex()).Return([]domain.Collateral{collateral}, nil).Once()
+	s.mockCollateralRepository.On("UpdateCollateral", mock.Anything, mock.Anything).Return(errors.New("Error updating collateral")).Once()
+
+	err := s.CollateralUsecase.LoanPaidOff(context.Background(), &domain.LoanPaidOff{LoanID: s.loan.ID})
+
+	s.EqualError(err, "Error updating collateral")
+	s.mockLogRepository.AssertNotCalled(s.T(), "AddLog", mock.Anything, mock.Anything)
+}
+
+func TestCollateralUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(CollateralUsecaseTestSuite))
+}
@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"loan_tracker_api/domain"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// longest comment that can be posted, in characters
+const maxCommentLength = 5000
+
+type CommentUsecase struct {
+	CommentRepo    domain.CommentRepository
+	LoanRepo       domain.LoanRepository
+	UserRepo       domain.UserRepository
+	LogRepo        domain.LogRepository
+	UnitOfWork     domain.UnitOfWork
+	Events         domain.EventBus
+	contextTimeout time.Duration
+}
+
+func NewCommentUsecase(Commentrepo domain.CommentRepository, Loanrepo domain.LoanRepository, Userrepo domain.UserRepository, Logrepo domain.LogRepository, Unitofwork domain.UnitOfWork, Events domain.EventBus, timeout time.Duration) domain.CommentUsecase {
+	return &CommentUsecase{
+		CommentRepo:    Commentrepo,
+		LoanRepo:       Loanrepo,
+		UserRepo:       Userrepo,
+		LogRepo:        Logrepo,
+		UnitOfWork:     Unitofwork,
+		Events:         Events,
+		contextTimeout: timeout,
+	}
+}
+
+// loan returns a loan the user may comment on, borrowers only comment on their own loans
+func (cuse *CommentUsecase) loan(c context.Context, loanID, userid string) (domain.Loan, bool, error) {
+	loan, err := cuse.LoanRepo.GetLoan(loanID)
+	if err != nil {
+		return loan, false, err
+	}
+
+	meta, _ := domain.RequestMetaFrom(c)
+	if !meta.IsAdmin && loan.UserID.Hex() != userid {
+		return domain.Loan{}, false, errors.New("Loan not found")
+	}
+
+	return loan, meta.IsAdmin, nil
+}
+
+// checkBody trims the body of a comment and rejects empty and overlong ones
+func checkBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", errors.New("Comment can't be empty")
+	}
+	if len([]rune(body)) > maxCommentLength {
+		return "", fmt.Errorf("Comment is longer than %d characters", maxCommentLength)
+	}
+	return body, nil
+}
+
+// newMentions returns the staff members mentioned who weren't already, leaving out the author
+func (cuse *CommentUsecase) newMentions(mentions, already []primitive.ObjectID, author primitive.ObjectID) ([]primitive.ObjectID, error) {
+	added := []primitive.ObjectID{}
+	for _, id := range mentions {
+		if id == author || containsID(already, id) || containsID(added, id) {
+			continue
+		}
+		user, err := cuse.UserRepo.UserProfile(id.Hex())
+		if err != nil || !user.IsAdmin {
+			return nil, errors.New("Only staff members can be mentioned")
+		}
+		added = append(added, id)
+	}
+	return added, nil
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
+
+// mentionEvents tells the staff members newly mentioned in a comment about it
+func mentionEvents(comment *domain.Comment, mentioned []primitive.ObjectID) []domain.Event {
+	events := []domain.Event{}
+	for _, id := range mentioned {
+		events = append(events, &domain.LoanCommentMention{LoanID: comment.LoanID, CommentID: comment.ID, AuthorID: comment.AuthorID, MentionedID: id})
+	}
+	return events
+}
+
+// saveComment stores a comment with its audit entry and the mentions it makes, storing is either adding or updating it
+func (cuse *CommentUsecase) saveComment(c context.Context, action, userid string, comment *domain.Comment, mentioned []primitive.ObjectID, store func(c context.Context, comment *domain.Comment) error) error {
+	return cuse.UnitOfWork.Do(c, func(c context.Context) error {
+		if err := store(c, comment); err != nil {
+			return err
+		}
+
+		// the audit log points at the comment, its body and earlier versions stay in the thread
+		if err := audit(c, cuse.LogRepo, userid, domain.Log{
+			Action:     action,
+			TargetType: domain.TargetLoan,
+			TargetID:   comment.LoanID,
+			Note:       comment.Visibility + " comment " + comment.ID.Hex(),
+		}); err != nil {
+			return err
+		}
+
+		for _, event := range mentionEvents(comment, mentioned) {
+			if err := cuse.Events.Publish(c, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AddComment posts a comment to the thread of a loan. Staff comments are internal unless made visible to the borrower,
+// borrowers' own comments are always visible to them. Only staff can mention, and only other staff.
+func (cuse *CommentUsecase) AddComment(c context.Context, loanID string, comment *domain.Comment, userid string) error {
+	_, cancel := context.WithTimeout(c, cuse.contextTimeout)
+	defer cancel()
+
+	body, err := checkBody(comment.Body)
+	if err != nil {
+		return err
+	}
+
+	loan, staff, err := cuse.loan(c, loanID, userid)
+	if err != nil {
+		return err
+	}
+
+	switch comment.Visibility {
+	case "":
+		comment.Visibility = domain.CommentBorrower
+		if staff {
+			comment.Visibility = domain.CommentInternal
+		}
+	case domain.CommentBorrower:
+	case domain.CommentInternal:
+		if !staff {
+			return errors.New("Only staff can post internal comments")
+		}
+	default:
+		return errors.New("Invalid visibility")
+	}
+
+	if len(comment.Mentions) > 0 && !staff {
+		return errors.New("Only staff can mention others")
+	}
+
+	author, _ := primitive.ObjectIDFromHex(userid)
+	mentioned, err := cuse.newMentions(comment.Mentions, nil, author)
+	if err != nil {
+		return err
+	}
+
+	comment.LoanID = loan.ID
+	comment.AuthorID = author
+	comment.Body = body
+	comment.Mentions = mentioned
+	comment.Edits = []domain.CommentEdit{}
+
+	return cuse.saveComment(c, domain.ActionLoanCommented, userid, comment, mentioned, cuse.CommentRepo.AddComment)
+}
+
+// LoanComments returns the comment thread of a loan, oldest first. Borrowers don't see the internal comments.
+func (cuse *CommentUsecase) LoanComments(c context.Context, loanID string, userid string) ([]domain.Comment, error) {
+	_, cancel := context.WithTimeout(c, cuse.contextTimeout)
+	defer cancel()
+
+	loan, staff, err := cuse.loan(c, loanID, userid)
+	if err != nil {
+		return nil, err
+	}
+
+	return cuse.CommentRepo.LoanComments(loan.ID.Hex(), staff)
+}
+
+// EditComment replaces the body of a comment, keeping the earlier version in its edit history.
+// Mentions given with the edit are added to those of the comment, only the newly mentioned are told about it.
+func (cuse *CommentUsecase) EditComment(c context.Context, loanID, commentID, body string, mentions []primitive.ObjectID, userid string) (domain.Comment, error) {
+	_, cancel := context.WithTimeout(c, cuse.contextTimeout)
+	defer cancel()
+
+	body, err := checkBody(body)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	loan, staff, err := cuse.loan(c, loanID, userid)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	comment, err := cuse.CommentRepo.GetComment(commentID)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	if comment.LoanID != loan.ID || (!staff && comment.Visibility == domain.CommentInternal) {
+		return domain.Comment{}, errors.New("Comment not found")
+	}
+	if comment.AuthorID.Hex() != userid {
+		return domain.Comment{}, errors.New("Only the author can edit a comment")
+	}
+
+	if len(mentions) > 0 && !staff {
+		return domain.Comment{}, errors.New("Only staff can mention others")
+	}
+	mentioned, err := cuse.newMentions(mentions, comment.Mentions, comment.AuthorID)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	if body == comment.Body && len(mentioned) == 0 {
+		return comment, nil
+	}
+
+	if body != comment.Body {
+		comment.Edits = append(comment.Edits, domain.CommentEdit{Body: comment.Body, EditedAt: time.Now()})
+		comment.Body = body
+	}
+	comment.Mentions = append(comment.Mentions, mentioned...)
+
+	if err := cuse.saveComment(c, domain.ActionLoanCommentEdited, userid, &comment, mentioned, cuse.CommentRepo.UpdateComment); err != nil {
+		return domain.Comment{}, err
+	}
+
+	return comment, nil
+}
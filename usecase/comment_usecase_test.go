@@ -0,0 +1,173 @@
+package usecase_test
+
+import (
+	"context"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"loan_tracker_api/usecase"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CommentUsecaseTestSuite struct {
+	suite.Suite
+	mockCommentRepository *mocks.CommentRepository
+	mockLoanRepository    *mocks.LoanRepository
+	mockUserRepository    *mocks.UserRepository
+	mockLogRepository     *mocks.LogRepository
+	mockUnitOfWork        *mocks.UnitOfWork
+	mockEventBus          *mocks.EventBus
+	CommentUsecase        domain.CommentUsecase
+	loan                  domain.Loan
+	staff                 context.Context
+}
+
+func (s *CommentUsecaseTestSuite) SetupTest() {
+	s.mockCommentRepository = new(mocks.CommentRepository)
+	s.mockLoanRepository = new(mocks.LoanRepository)
+	s.mockUserRepository = new(mocks.UserRepository)
+	s.mockLogRepository = new(mocks.LogRepository)
+	s.mockUnitOfWork = new(mocks.UnitOfWork)
+	s.mockUnitOfWork.On("Do", mock.Anything, mock.Anything).Return(func(c context.Context, work func(context.Context) error) error {
+		return work(c)
+	}).Maybe()
+	s.mockEventBus = new(mocks.EventBus)
+	s.CommentUsecase = usecase.NewCommentUsecase(s.mockCommentRepository, s.mockLoanRepository, s.mockUserRepository, s.mockLogRepository, s.mockUnitOfWork, s.mockEventBus, time.Second*2)
+
+	s.loan = domain.Loan{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), Status: "pending"}
+	s.staff = domain.WithRequestMeta(context.Background(), domain.RequestMeta{IsAdmin: true})
+}
+
+func (s *CommentUsecaseTestSuite) TestAddInternalCommentWithMention() {
+	authorID := primitive.NewObjectID()
+	underwriter := domain.User{ID: primitive.NewObjectID(), IsAdmin: true}
+	s.mockLoanRepository.On("GetLoan", s.loan.ID.Hex()).Return(s.loan, nil).Once()
+	s.mockUserRepository.On("UserProfile", underwriter.ID.Hex()).Return(underwriter, nil).Once()
+	s.mockCommentRepository.On("AddComment", mock.Anything, mock.MatchedBy(func(c *domain.Comment) bool {
+		return c.Visibility == domain.CommentInternal && c.Body == "Payslips look edited, can you check?" && c.AuthorID == authorID && len(c.Mentions) == 1
+	})).Return(nil).Once()
+	s.mockLogRepository.On("AddLog", mock.Anything, mock.MatchedBy(func(entry *domain.Log) bool {
+		return entry.Action == domain.ActionLoanCommented && entry.TargetID == s.loan.ID && !strings.Contains(entry.Note, "Payslips")
+	})).Return(nil).Once()
+	s.mockEventBus.On("Publish", mock.Anything, mock.MatchedBy(func(event domain.Event) bool {
+		mention, ok := event.(*domain.LoanCommentMention)
+		return ok && mention.MentionedID == underwriter.ID && mention.AuthorID == authorID
+	})).Return(nil).Once()
+
+	// mentioning yourself or someone twice tells nobody twice
+	comment := &domain.Comment{Body: " Payslips look edited, can you check? ", Mentions: []primitive.ObjectID{underwriter.ID, authorID, underwriter.ID}}
+	err := s.CommentUsecase.AddComment(s.staff, s.loan.ID.Hex(), comment, authorID.Hex())
+
+	s.NoError(err)
+	s.mockCommentRepository.AssertExpectations(s.T())
+	s.mockLogRepository.AssertExpectations(s.T())
+	s.mockEventBus.AssertExpectations(s.T())
+}
+
+func (s *CommentUsecaseTestSuite) TestAddCommentMentioningBorrower() {
+	borrower := domain.User{ID: s.loan.UserID}
+	s.mockLoanRepository.On("GetLoan", s.loan.ID.Hex()).Return(s.loan, nil).Once()
+	s.mockUserRepository.On("UserProfile", borrower.ID.Hex()).Return(borrower, nil).Once()
+
+	comment := &domain.Comment{Body: "See this", Mentions: []primitive.ObjectID{borrower.ID}}
+	err := s.CommentUsecase.AddComment(s.staff, s.loan.ID.Hex(), comment, primitive.NewObjectID().Hex())
+
+	s.EqualError(err, "Only staff members can be mentioned")
+	s.mockCommentRepository.AssertNotCalled(s.T(), "AddComment", mock.Anything, mock.Anything)
+}
+
+func (s *CommentUsecaseTestSuite) TestBorrowerComment() {
+	s.mockLoanRepository.On("GetLoan", s.loan.ID.Hex()).Return(s.loan, nil).Once()
+	s.mockCommentRepository.On("AddComment", mock.Anything, mock.MatchedBy(func(c *domain.Comment) bool {
+		return c.Visibility == domain.CommentBorrower && c.AuthorID == s.loan.UserID
+	})).Return(nil).Once()
+	s.mockLogRepository.On("AddLog", mock.Anything, mock.Anything).Return(nil).Once()
+
+	err := s.CommentUsecase.AddComment(context.Background(), s.loan.ID.Hex(), &domain.Comment{Body: "I uploaded my March payslip"}, s.loan.UserID.Hex())
+
+	s.NoError(err)
+	s.mockEventBus.AssertNotCalled(s.T(), "Publish", mock.Anything, mock.Anything)
+}
+
+func (s *CommentUsecaseTestSuite) TestBorrowerCantPostInternalComment() {
+	s.mockLoanRepository.On("GetLoan", s.loan.ID.Hex()).Return(s.loan, nil).Once()
+
+	err := s.CommentUsecase.AddComment(context.Background(), s.loan.ID.Hex(), &domain.Comment{Body: "Hidden", Visibility: domain.CommentInternal}, s.loan.UserID.Hex())
+
+	s.EqualError(err, "Only staff can post internal comments")
+}
+
+func (s *CommentUsecaseTestSuite) TestCommentOnSomeoneElsesLoan() {
+	s.mockLoanRepository.On("GetLoan", s.loan.ID.Hex()).Return(s.loan, nil).Once()
+
+	err := s.CommentUsecase.AddComment(context.Background(), s.loan.ID.Hex(), &domain.Comment{Body: "Hello"}, primitive.NewObjectID().Hex())
+
+	s.EqualError(err, "Loan not found")
+}
+
+func (s *CommentUsecaseTestSuite) TestEmptyComment() {
+	err := s.CommentUsecase.AddComment(s.staff, s.loan.ID.Hex(), &domain.Comment{Body: "   "}, primitive.NewObjectID().Hex())
+
+	s.EqualError(err, "Comment can't be empty")
+	s.mockLoanRepository.AssertNotCalled(s.T(), "GetLoan", mock.Anything)
+}
+
+func (s *CommentUsecaseTestSuite) TestBorrowerSeesOnlyBorrowerComments() {
+	s.mockLoanRepository.On("GetLoan", s.loan.ID.Hex()).Return(s.loan, nil).Once()
+	s.mockCommentRepository.On("LoanComments", s.loan.ID.Hex(), false).Return([]domain.Comment{{Visibility: domain.CommentBorrower}}, nil).Once()
+
+	comments, err := s.CommentUsecase.LoanComments(context.Background(), s.loan.ID.Hex(), s.loan.UserID.Hex())
+
+	s.NoError(err)
+	s.Len(comments, 1)
+	s.mockCommentRepository.AssertExpectations(s.T())
+}
+
+func (s *CommentUsecaseTestSuite) TestEditCommentKeepsHistory() {
+	authorID := primitive.NewObjectID()
+	comment := domain.Comment{ID: primitive.NewObjectID(), LoanID: s.loan.ID, AuthorID: authorID, Visibility: domain.CommentInternal, Body: "DTI is 52%", Edits: []domain.CommentEdit{}}
+	s.mockLoanRepository.On("GetLoan", s.loan.ID.Hex()).Return(s.loan, nil).Once()
+	s.mockCommentRepository.On("GetComment", comment.ID.Hex()).Return(comment, nil).Once()
+	s.mockCommentRepository.On("UpdateComment", mock.Anything, mock.MatchedBy(func(c *domain.Comment) bool {
+		return c.Body == "DTI is 48%" && len(c.Edits) == 1 && c.Edits[0].Body == "DTI is 52%"
+	})).Return(nil).Once()
+	s.mockLogRepository.On("AddLog", mock.Anything, mock.MatchedBy(func(entry *domain.Log) bool {
+		return entry.Action == domain.ActionLoanCommentEdited
+	})).Return(nil).Once()
+
+	edited, err := s.CommentUsecase.EditComment(s.staff, s.loan.ID.Hex(), comment.ID.Hex(), "DTI is 48%", nil, authorID.Hex())
+
+	s.NoError(err)
+	s.Equal("DTI is 48%", edited.Body)
+	s.mockCommentRepository.AssertExpectations(s.T())
+}
+
+func (s *CommentUsecaseTestSuite) TestEditSomeoneElsesComment() {
+	comment := domain.Comment{ID: primitive.NewObjectID(), LoanID: s.loan.ID, AuthorID: primitive.NewObjectID(), Visibility: domain.CommentInternal, Body: "DTI is 52%"}
+	s.mockLoanRepository.On("GetLoan", s.loan.ID.Hex()).Return(s.loan, nil).Once()
+	s.mockCommentRepository.On("GetComment", comment.ID.Hex()).Return(comment, nil).Once()
+
+	_, err := s.CommentUsecase.EditComment(s.staff, s.loan.ID.Hex(), comment.ID.Hex(), "DTI is 48%", nil, primitive.NewObjectID().Hex())
+
+	s.EqualError(err, "Only the author can edit a comment")
+	s.mockCommentRepository.AssertNotCalled(s.T(), "UpdateComment", mock.Anything, mock.Anything)
+}
+
+func (s *CommentUsecaseTestSuite) TestBorrowerCantEditInternalComment() {
+	comment := domain.Comment{ID: primitive.NewObjectID(), LoanID: s.loan.ID, AuthorID: s.loan.UserID, Visibility: domain.CommentInternal, Body: "Note"}
+	s.mockLoanRepository.On("GetLoan", s.loan.ID.Hex()).Return(s.loan, nil).Once()
+	s.mockCommentRepository.On("GetComment", comment.ID.Hex()).Return(comment, nil).Once()
+
+	_, err := s.CommentUsecase.EditComment(context.Background(), s.loan.ID.Hex(), comment.ID.Hex(), "Changed", nil, s.loan.UserID.Hex())
+
+	s.EqualError(err, "Comment not found")
+}
+
+func TestCommentUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(CommentUsecaseTestSuite))
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule tells which minutes a job runs at.
+// It reads the five fields of a cron expression, minute hour day-of-month month day-of-week,
+// as well as @hourly, @daily, @weekly, @monthly and @every <duration>.
+type cronSchedule struct {
+	minutes, hours, days, months, weekdays map[int]bool
+	// whether day of month and day of week were both restricted, a day then matches either of them like in cron
+	eitherDay bool
+	every     time.Duration
+}
+
+var cronAliases = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// parseSchedule reads a cron expression
+func parseSchedule(spec string) (*cronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if strings.HasPrefix(spec, "@every ") {
+		every, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil || every < time.Minute || every%time.Minute != 0 {
+			return nil, errors.New("Invalid schedule " + spec + ", @every takes a whole number of minutes")
+		}
+		return &cronSchedule{every: every}, nil
+	}
+
+	if alias, ok := cronAliases[spec]; ok {
+		spec = alias
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.New("Invalid schedule " + spec + ", expected minute hour day-of-month month day-of-week")
+	}
+
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	sets := [5]map[int]bool{}
+	for i, field := range fields {
+		set, err := parseCronField(field, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, errors.New("Invalid schedule " + spec + ", " + err.Error())
+		}
+		sets[i] = set
+	}
+
+	// Sunday is both 0 and 7
+	if sets[4][7] {
+		sets[4][0] = true
+	}
+
+	return &cronSchedule{
+		minutes:   sets[0],
+		hours:     sets[1],
+		days:      sets[2],
+		months:    sets[3],
+		weekdays:  sets[4],
+		eitherDay: fields[2] != "*" && fields[4] != "*",
+	}, nil
+}
+
+// parseCronField reads a comma separated list of values, ranges and steps such as 1-5, */15 or 0-30/10
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	set := map[int]bool{}
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if base, stepText, ok := strings.Cut(part, "/"); ok {
+			var err error
+			step, err = strconv.Atoi(stepText)
+			if err != nil || step <= 0 {
+				return nil, errors.New("invalid step in " + field)
+			}
+			part = base
+		}
+
+		from, to := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			low, high, _ := strings.Cut(part, "-")
+			var errLow, errHigh error
+			from, errLow = strconv.Atoi(low)
+			to, errHigh = strconv.Atoi(high)
+			if errLow != nil || errHigh != nil {
+				return nil, errors.New("invalid range in " + field)
+			}
+		default:
+			value, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, errors.New("invalid value in " + field)
+			}
+			from = value
+			// a single value with a step runs from it to the end of the range
+			if strings.Contains(field, "/") {
+				to = max
+			} else {
+				to = value
+			}
+		}
+
+		if from < min || to > max || from > to {
+			return nil, errors.New("value out of range in " + field)
+		}
+
+		for value := from; value <= to; value += step {
+			set[value] = true
+		}
+	}
+
+	return set, nil
+}
+
+// Matches reports whether the job runs at the minute of the given time
+func (schedule *cronSchedule) Matches(t time.Time) bool {
+	if schedule.every > 0 {
+		return t.Truncate(time.Minute).Equal(t.Truncate(schedule.every))
+	}
+
+	if !schedule.minutes[t.Minute()] || !schedule.hours[t.Hour()] || !schedule.months[int(t.Month())] {
+		return false
+	}
+
+	day, weekday := schedule.days[t.Day()], schedule.weekdays[int(t.Weekday())]
+	if schedule.eitherDay {
+		return day || weekday
+	}
+	return day && weekday
+}
+
+// Next returns the first minute after the given time the job runs at, the zero time when it doesn't run within the next four years
+func (schedule *cronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(4, 0, 0)
+
+	for t.Before(limit) {
+		if schedule.every == 0 {
+			// skip the months and hours the job never runs in
+			if !schedule.months[int(t.Month())] {
+				t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+				continue
+			}
+			if !schedule.hours[t.Hour()] {
+				t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+				continue
+			}
+		}
+		if schedule.Matches(t) {
+			return t
+		}
+		t = t.Add(time.Minute)
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"loan_tracker_api/domain"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type DocumentUsecase struct {
+	DocumentRepo   domain.DocumentRepository
+	LoanRepo       domain.LoanRepository
+	LogRepo        domain.LogRepository
+	UnitOfWork     domain.UnitOfWork
+	Blobs          domain.BlobStore
+	secret         []byte
+	linkTTL        time.Duration
+	maxSize        int64
+	contextTimeout time.Duration
+}
+
+// NewDocumentUsecase creates the document usecase. Download links are signed with the secret and expire after the link TTL,
+// uploads larger than the max size in bytes are refused.
+func NewDocumentUsecase(Documentrepo domain.DocumentRepository, Loanrepo domain.LoanRepository, Logrepo domain.LogRepository, Unitofwork domain.UnitOfWork, blobs domain.BlobStore, secret string, linkTTL time.Duration, maxSize int64, timeout time.Duration) domain.DocumentUsecase {
+	return &DocumentUsecase{
+		DocumentRepo:   Documentrepo,
+		LoanRepo:       Loanrepo,
+		LogRepo:        Logrepo,
+		UnitOfWork:     Unitofwork,
+		Blobs:          blobs,
+		secret:         []byte(secret),
+		linkTTL:        linkTTL,
+		maxSize:        maxSize,
+		contextTimeout: timeout,
+	}
+}
+
+// SignDocumentLink signs the ID of a document and the unix time its download link expires at
+func SignDocumentLink(secret []byte, documentID string, expires int64) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(documentID))
+	mac.Write([]byte("."))
+	mac.Write([]byte(strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// loan returns a loan the user may see the documents of, borrowers only see their own loans
+func (duse *DocumentUsecase) loan(c context.Context, loanID, userid string) (domain.Loan, error) {
+	loan, err := duse.LoanRepo.GetLoan(loanID)
+	if err != nil {
+		return loan, err
+	}
+
+	meta, _ := domain.RequestMetaFrom(c)
+	if !meta.IsAdmin && loan.UserID.Hex() != userid {
+		return domain.Loan{}, errors.New("Loan not found")
+	}
+
+	return loan, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// countingHash hashes what is written to it and counts the bytes
+type countingHash struct {
+	hash.Hash
+	written int64
+}
+
+func (h *countingHash) Write(p []byte) (int, error) {
+	h.written += int64(len(p))
+	return h.Hash.Write(p)
+}
+
+// UploadDocument stores the content of a document in the blob store and attaches it to the loan.
+// The content type is detected from the content rather than trusted from the upload.
+func (duse *DocumentUsecase) UploadDocument(c context.Context, loanID string, upload domain.DocumentUpload, userid string) (domain.Document, error) {
+	_, cancel := context.WithTimeout(c, duse.contextTimeout)
+	defer cancel()
+
+	if !contains(domain.DocumentKinds, upload.Kind) {
+		return domain.Document{}, errors.New("Invalid document kind")
+	}
+	if upload.Size <= 0 {
+		return domain.Document{}, errors.New("Document is empty")
+	}
+	if upload.Size > duse.maxSize {
+		return domain.Document{}, fmt.Errorf("Document is larger than %d bytes", duse.maxSize)
+	}
+
+	loan, err := duse.loan(c, loanID, userid)
+	if err != nil {
+		return domain.Document{}, err
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(upload.Content, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return domain.Document{}, errors.New("Error reading document")
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	if !contains(domain.DocumentContentTypes, contentType) {
+		return domain.Document{}, errors.New("Documents must be PDF, JPEG or PNG files")
+	}
+
+	filename := strings.TrimSpace(filepath.Base(upload.Filename))
+	if filename == "" || filename == "." || filename == string(filepath.Separator) {
+		filename = "document"
+	}
+
+	uploader, _ := primitive.ObjectIDFromHex(userid)
+	document := domain.Document{
+		ID:          primitive.NewObjectID(),
+		LoanID:      loan.ID,
+		Kind:        upload.Kind,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        upload.Size,
+		UploadedBy:  uploader,
+		UploadedAt:  time.Now(),
+	}
+	document.StorageKey = "loans/" + loan.ID.Hex() + "/" + document.ID.Hex()
+
+	checksum := &countingHash{Hash: sha256.New()}
+	content := io.TeeReader(io.LimitReader(io.MultiReader(bytes.NewReader(head), upload.Content), upload.Size), checksum)
+	if err := duse.Blobs.Put(c, document.StorageKey, content, upload.Size, contentType); err != nil {
+		return domain.Document{}, err
+	}
+	if checksum.written != upload.Size {
+		duse.Blobs.Delete(c, document.StorageKey)
+		return domain.Document{}, errors.New("Document was cut short while uploading")
+	}
+	document.Checksum = hex.EncodeToString(checksum.Sum(nil))
+
+	err = duse.UnitOfWork.Do(c, func(c context.Context) error {
+		if err := duse.DocumentRepo.AddDocument(c, &document); err != nil {
+			return err
+		}
+		return audit(c, duse.LogRepo, userid, domain.Log{
+			Action:     domain.ActionLoanDocument,
+			TargetType: domain.TargetLoan,
+			TargetID:   loan.ID,
+			Note:       document.Kind + " " + document.Filename + " (sha256 " + document.Checksum + ")",
+		})
+	})
+	if err != nil {
+		// the content isn't referenced by anything without its record
+		duse.Blobs.Delete(c, document.StorageKey)
+		return domain.Document{}, err
+	}
+
+	return document, nil
+}
+
+func (duse *DocumentUsecase) LoanDocuments(c context.Context, loanID string, userid string) ([]domain.Document, error) {
+	_, cancel := context.WithTimeout(c, duse.contextTimeout)
+	defer cancel()
+
+	if _, err := duse.loan(c, loanID, userid); err != nil {
+		return nil, err
+	}
+
+	return duse.DocumentRepo.LoanDocuments(loanID)
+}
+
+// DocumentLink signs a URL the content of a document can be downloaded from without signing in until it expires
+func (duse *DocumentUsecase) DocumentLink(c context.Context, loanID, documentID string, userid string) (domain.DocumentLink, error) {
+	_, cancel := context.WithTimeout(c, duse.contextTimeout)
+	defer cancel()
+
+	loan, err := duse.loan(c, loanID, userid)
+	if err != nil {
+		return domain.DocumentLink{}, err
+	}
+
+	document, err := duse.DocumentRepo.GetDocument(documentID)
+	if err != nil {
+		return domain.DocumentLink{}, err
+	}
+	if document.LoanID != loan.ID {
+		return domain.DocumentLink{}, errors.New("Document not found")
+	}
+
+	expiresAt := time.Now().Add(duse.linkTTL).Truncate(time.Second)
+	signature := SignDocumentLink(duse.secret, document.ID.Hex(), expiresAt.Unix())
+
+	return domain.DocumentLink{
+		URL:       fmt.Sprintf("/documents/%s?expires=%d&signature=%s", document.ID.Hex(), expiresAt.Unix(), signature),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// OpenDocument checks the signature of a download link and opens the content of its document, the caller closes it
+func (duse *DocumentUsecase) OpenDocument(c context.Context, documentID string, expires int64, signature string) (domain.Document, io.ReadCloser, error) {
+	expected := SignDocumentLink(duse.secret, documentID, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return domain.Document{}, nil, errors.New("Invalid download link")
+	}
+	if time.Now().Unix() > expires {
+		return domain.Document{}, nil, errors.New("Download link expired")
+	}
+
+	document, err := duse.DocumentRepo.GetDocument(documentID)
+	if err != nil {
+		return domain.Document{}, nil, err
+	}
+
+	content, err := duse.Blobs.Open(c, document.StorageKey)
+	if err != nil {
+		return domain.Document{}, nil, err
+	}
+
+	return document, content, nil
+}
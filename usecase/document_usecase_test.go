@@ -0,0 +1,178 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"loan_tracker_api/usecase"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type DocumentUsecaseTestSuite struct {
+	suite.Suite
+	mockDocumentRepository *mocks.DocumentRepository
+	mockLoanRepository     *mocks.LoanRepository
+	mockLogRepository      *mocks.LogRepository
+	mockUnitOfWork         *mocks.UnitOfWork
+	mockBlobStore          *mocks.BlobStore
+	DocumentUsecase        domain.DocumentUsecase
+	loan                   domain.Loan
+}
+
+// a minimal PDF, enough for its content type to be detected
+var pdfContent = []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\ntrailer\n<< /Root 1 0 R >>\n%%EOF\n")
+
+func (s *DocumentUsecaseTestSuite) SetupTest() {
+	s.mockDocumentRepository = new(mocks.DocumentRepository)
+	s.mockLoanRepository = new(mocks.LoanRepository)
+	s.mockLogRepository = new(mocks.LogRepository)
+	s.mockUnitOfWork = new(mocks.UnitOfWork)
+	s.mockUnitOfWork.On("Do", mock.Anything, mock.Anything).Return(func(c context.Context, work func(context.Context) error) error {
+		return work(c)
+	}).Maybe()
+	s.mockBlobStore = new(mocks.BlobStore)
+	s.DocumentUsecase = usecase.NewDocumentUsecase(s.mockDocumentRepository, s.mockLoanRepository, s.mockLogRepository, s.mockUnitOfWork, s.mockBlobStore, "secret", 5*time.Minute, 1024, time.Second*2)
+
+	s.loan = domain.Loan{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), Status: "pending"}
+	s.mockLoanRepository.On("GetLoan", s.loan.ID.Hex()).Return(s.loan, nil).Maybe()
+}
+
+func (s *DocumentUsecaseTestSuite) upload(kind string, content []byte) domain.DocumentUpload {
+	return domain.DocumentUpload{Kind: kind, Filename: "../payslip.pdf", Size: int64(len(content)), Content: bytes.NewReader(content)}
+}
+
+func (s *DocumentUsecaseTestSuite) TestUploadDocument() {
+	sum := sha256.Sum256(pdfContent)
+	checksum := hex.EncodeToString(sum[:])
+
+	var stored []byte
+	s.mockBlobStore.On("Put", mock.Anything, mock.MatchedBy(func(key string) bool {
+		return strings.HasPrefix(key, "loans/"+s.loan.ID.Hex()+"/")
+	}), mock.Anything, int64(len(pdfContent)), "application/pdf").Run(func(args mock.Arguments) {
+		stored, _ = io.ReadAll(args.Get(2).(io.Reader))
+	}).Return(nil).Once()
+	s.mockDocumentRepository.On("AddDocument", mock.Anything, mock.MatchedBy(func(d *domain.Document) bool {
+		return d.LoanID == s.loan.ID && d.Kind == domain.DocumentPayslip && d.Filename == "payslip.pdf" && d.Checksum == checksum
+	})).Return(nil).Once()
+	s.mockLogRepository.On("AddLog", mock.Anything, mock.MatchedBy(func(entry *domain.Log) bool {
+		return entry.Action == domain.ActionLoanDocument && entry.TargetID == s.loan.ID && strings.Contains(entry.Note, checksum)
+	})).Return(nil).Once()
+
+	document, err := s.DocumentUsecase.UploadDocument(context.Background(), s.loan.ID.Hex(), s.upload(domain.DocumentPayslip, pdfContent), s.loan.UserID.Hex())
+
+	s.NoError(err)
+	s.Equal(pdfContent, stored)
+	s.Equal("application/pdf", document.ContentType)
+	s.Equal(checksum, document.Checksum)
+	s.mockBlobStore.AssertExpectations(s.T())
+	s.mockDocumentRepository.AssertExpectations(s.T())
+}
+
+func (s *DocumentUsecaseTestSuite) TestUploadDocumentRejectsContentType() {
+	_, err := s.DocumentUsecase.UploadDocument(context.Background(), s.loan.ID.Hex(), s.upload(domain.DocumentPayslip, []byte("#!/bin/sh\necho hello\n")), s.loan.UserID.Hex())
+
+	s.EqualError(err, "Documents must be PDF, JPEG or PNG files")
+	s.mockBlobStore.AssertNotCalled(s.T(), "Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *DocumentUsecaseTestSuite) TestUploadDocumentTooLarge() {
+	_, err := s.DocumentUsecase.UploadDocument(context.Background(), s.loan.ID.Hex(), s.upload(domain.DocumentPayslip, make([]byte, 2048)), s.loan.UserID.Hex())
+
+	s.EqualError(err, "Document is larger than 1024 bytes")
+}
+
+func (s *DocumentUsecaseTestSuite) TestUploadDocumentInvalidKind() {
+	_, err := s.DocumentUsecase.UploadDocument(context.Background(), s.loan.ID.Hex(), s.upload("selfie", pdfContent), s.loan.UserID.Hex())
+
+	s.EqualError(err, "Invalid document kind")
+}
+
+func (s *DocumentUsecaseTestSuite) TestUploadDocumentToLoanOfAnotherUser() {
+	_, err := s.DocumentUsecase.UploadDocument(context.Background(), s.loan.ID.Hex(), s.upload(domain.DocumentPayslip, pdfContent), primitive.NewObjectID().Hex())
+
+	s.EqualError(err, "Loan not found")
+}
+
+func (s *DocumentUsecaseTestSuite) TestUploadDocumentRemovesBlobWhenRecordFails() {
+	s.mockBlobStore.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		io.ReadAll(args.Get(2).(io.Reader))
+	}).Return(nil).Once()
+	s.mockDocumentRepository.On("AddDocument", mock.Anything, mock.Anything).Return(errors.New("Error storing document")).Once()
+	s.mockBlobStore.On("Delete", mock.Anything, mock.Anything).Return(nil).Once()
+
+	_, err := s.DocumentUsecase.UploadDocument(context.Background(), s.loan.ID.Hex(), s.upload(domain.DocumentPayslip, pdfContent), s.loan.UserID.Hex())
+
+	s.EqualError(err, "Error storing document")
+	s.mockBlobStore.AssertExpectations(s.T())
+}
+
+func (s *DocumentUsecaseTestSuite) TestLoanDocumentsForAdmin() {
+	ctx := domain.WithRequestMeta(context.Background(), domain.RequestMeta{IsAdmin: true})
+	s.mockDocumentRepository.On("LoanDocuments", s.loan.ID.Hex()).Return([]domain.Document{{LoanID: s.loan.ID}}, nil).Once()
+
+	documents, err := s.DocumentUsecase.LoanDocuments(ctx, s.loan.ID.Hex(), primitive.NewObjectID().Hex())
+
+	s.NoError(err)
+	s.Len(documents, 1)
+}
+
+func (s *DocumentUsecaseTestSuite) TestDocumentLinkOpensDocument() {
+	document := domain.Document{ID: primitive.NewObjectID(), LoanID: s.loan.ID, StorageKey: "loans/key"}
+	s.mockDocumentRepository.On("GetDocument", document.ID.Hex()).Return(document, nil)
+	s.mockBlobStore.On("Open", mock.Anything, "loans/key").Return(io.NopCloser(bytes.NewReader(pdfContent)), nil).Once()
+
+	link, err := s.DocumentUsecase.DocumentLink(context.Background(), s.loan.ID.Hex(), document.ID.Hex(), s.loan.UserID.Hex())
+	s.Require().NoError(err)
+	s.WithinDuration(time.Now().Add(5*time.Minute), link.ExpiresAt, time.Second)
+
+	parsed, err := url.Parse(link.URL)
+	s.Require().NoError(err)
+	s.Equal("/documents/"+document.ID.Hex(), parsed.Path)
+	expires, _ := strconv.ParseInt(parsed.Query().Get("expires"), 10, 64)
+
+	opened, content, err := s.DocumentUsecase.OpenDocument(context.Background(), document.ID.Hex(), expires, parsed.Query().Get("signature"))
+
+	s.NoError(err)
+	s.Equal(document.ID, opened.ID)
+	s.NotNil(content)
+
+	// a link can't be stretched past its expiry
+	_, _, err = s.DocumentUsecase.OpenDocument(context.Background(), document.ID.Hex(), expires+3600, parsed.Query().Get("signature"))
+	s.EqualError(err, "Invalid download link")
+}
+
+func (s *DocumentUsecaseTestSuite) TestDocumentLinkOfAnotherLoan() {
+	document := domain.Document{ID: primitive.NewObjectID(), LoanID: primitive.NewObjectID()}
+	s.mockDocumentRepository.On("GetDocument", document.ID.Hex()).Return(document, nil).Once()
+
+	_, err := s.DocumentUsecase.DocumentLink(context.Background(), s.loan.ID.Hex(), document.ID.Hex(), s.loan.UserID.Hex())
+
+	s.EqualError(err, "Document not found")
+}
+
+func (s *DocumentUsecaseTestSuite) TestOpenDocumentExpiredLink() {
+	id := primitive.NewObjectID().Hex()
+	expires := time.Now().Add(-time.Minute).Unix()
+
+	_, _, err := s.DocumentUsecase.OpenDocument(context.Background(), id, expires, usecase.SignDocumentLink([]byte("secret"), id, expires))
+
+	s.EqualError(err, "Download link expired")
+	s.mockDocumentRepository.AssertNotCalled(s.T(), "GetDocument", mock.Anything)
+}
+
+func TestDocumentUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(DocumentUsecaseTestSuite))
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"loan_tracker_api/domain"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EventBus delivers domain events to their subscribers.
+// Asynchronous deliveries go through the outbox, one message per subscriber, so each is retried on its own.
+type EventBus struct {
+	OutboxRepo domain.OutboxRepository
+
+	mu          sync.RWMutex
+	handlers    map[string][]domain.EventHandler
+	subscribers map[string]map[string]domain.EventHandler
+}
+
+func NewEventBus(Outboxrepo domain.OutboxRepository) *EventBus {
+	return &EventBus{
+		OutboxRepo:  Outboxrepo,
+		handlers:    map[string][]domain.EventHandler{},
+		subscribers: map[string]map[string]domain.EventHandler{},
+	}
+}
+
+// Subscribe registers a handler run with every event of the name, in the unit of work it is published in
+func (bus *EventBus) Subscribe(name string, handler domain.EventHandler) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	bus.handlers[name] = append(bus.handlers[name], handler)
+}
+
+// SubscribeAsync registers a handler run with every event of the name once the change that published it is committed.
+// The subscriber name identifies the handler in the outbox, it must stay the same across restarts.
+func (bus *EventBus) SubscribeAsync(name, subscriber string, handler domain.EventHandler) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	if bus.subscribers[name] == nil {
+		bus.subscribers[name] = map[string]domain.EventHandler{}
+	}
+	bus.subscribers[name][subscriber] = handler
+}
+
+// Publish runs the synchronous handlers of the event and queues it for the asynchronous ones
+func (bus *EventBus) Publish(c context.Context, event domain.Event) error {
+	header := event.Header()
+	if header.EventID.IsZero() {
+		header.EventID = primitive.NewObjectID()
+	}
+	if header.OccurredAt.IsZero() {
+		header.OccurredAt = time.Now()
+	}
+
+	bus.mu.RLock()
+	handlers := bus.handlers[event.EventName()]
+	subscribers := make([]string, 0, len(bus.subscribers[event.EventName()]))
+	for subscriber := range bus.subscribers[event.EventName()] {
+		subscribers = append(subscribers, subscriber)
+	}
+	bus.mu.RUnlock()
+	sort.Strings(subscribers)
+
+	for _, handler := range handlers {
+		if err := handler(c, event); err != nil {
+			return err
+		}
+	}
+
+	if len(subscribers) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.New("Error encoding event")
+	}
+
+	for _, subscriber := range subscribers {
+		err := bus.OutboxRepo.AddMessage(c, &domain.OutboxMessage{
+			Kind:      domain.OutboxEvent,
+			Topic:     event.EventName(),
+			Recipient: subscriber,
+			Payload:   string(payload),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Send hands an event taken from the outbox to the asynchronous subscriber it was queued for
+func (bus *EventBus) Send(message domain.OutboxMessage) error {
+	bus.mu.RLock()
+	handler, ok := bus.subscribers[message.Topic][message.Recipient]
+	bus.mu.RUnlock()
+	if !ok {
+		return errors.New("No subscriber " + message.Recipient + " for " + message.Topic + " events")
+	}
+
+	event, err := domain.NewEvent(message.Topic)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal([]byte(message.Payload), event); err != nil {
+		return errors.New("Error decoding event")
+	}
+
+	return handler(context.Background(), event)
+}
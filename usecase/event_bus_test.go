@@ -0,0 +1,116 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"loan_tracker_api/usecase"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type EventBusTestSuite struct {
+	suite.Suite
+	mockOutboxRepository *mocks.OutboxRepository
+	EventBus             *usecase.EventBus
+}
+
+func (s *EventBusTestSuite) SetupTest() {
+	s.mockOutboxRepository = new(mocks.OutboxRepository)
+	s.EventBus = usecase.NewEventBus(s.mockOutboxRepository)
+}
+
+func (s *EventBusTestSuite) TestPublishRunsSynchronousHandlers() {
+	var handled []domain.Event
+	s.EventBus.Subscribe(domain.EventLoanApplied, func(c context.Context, event domain.Event) error {
+		handled = append(handled, event)
+		return nil
+	})
+
+	event := &domain.LoanApplied{LoanID: primitive.NewObjectID(), Amount: 1200}
+	err := s.EventBus.Publish(context.Background(), event)
+
+	s.NoError(err)
+	s.Len(handled, 1)
+	s.False(event.EventID.IsZero())
+	s.False(event.OccurredAt.IsZero())
+	s.mockOutboxRepository.AssertNotCalled(s.T(), "AddMessage", mock.Anything, mock.Anything)
+}
+
+func (s *EventBusTestSuite) TestPublishFailsWithSynchronousHandler() {
+	s.EventBus.Subscribe(domain.EventUserRegistered, func(c context.Context, event domain.Event) error {
+		return errors.New("Error writing outbox message")
+	})
+	s.EventBus.SubscribeAsync(domain.EventUserRegistered, "metrics", func(c context.Context, event domain.Event) error {
+		return nil
+	})
+
+	err := s.EventBus.Publish(context.Background(), &domain.UserRegistered{UserID: primitive.NewObjectID()})
+
+	s.EqualError(err, "Error writing outbox message")
+	s.mockOutboxRepository.AssertNotCalled(s.T(), "AddMessage", mock.Anything, mock.Anything)
+}
+
+func (s *EventBusTestSuite) TestPublishQueuesEachAsynchronousSubscriber() {
+	noop := func(c context.Context, event domain.Event) error { return nil }
+	s.EventBus.SubscribeAsync(domain.EventLoanApproved, "metrics", noop)
+	s.EventBus.SubscribeAsync(domain.EventLoanApproved, "analytics", noop)
+
+	event := &domain.LoanApproved{LoanID: primitive.NewObjectID(), Amount: 1200}
+	for _, subscriber := range []string{"analytics", "metrics"} {
+		subscriber := subscriber
+		s.mockOutboxRepository.On("AddMessage", mock.Anything, mock.MatchedBy(func(message *domain.OutboxMessage) bool {
+			var queued domain.LoanApproved
+			json.Unmarshal([]byte(message.Payload), &queued)
+			return message.Kind == domain.OutboxEvent && message.Topic == domain.EventLoanApproved &&
+				message.Recipient == subscriber && queued.LoanID == event.LoanID && queued.EventID == event.EventID
+		})).Return(nil).Once()
+	}
+
+	err := s.EventBus.Publish(context.Background(), event)
+
+	s.NoError(err)
+	s.mockOutboxRepository.AssertExpectations(s.T())
+}
+
+func (s *EventBusTestSuite) TestSendDeliversTypedEvent() {
+	var delivered *domain.PaymentReceived
+	s.EventBus.SubscribeAsync(domain.EventPaymentReceived, "metrics", func(c context.Context, event domain.Event) error {
+		delivered, _ = event.(*domain.PaymentReceived)
+		return nil
+	})
+
+	event := domain.PaymentReceived{LoanID: primitive.NewObjectID(), Amount: 250, Outstanding: 750}
+	payload, _ := json.Marshal(event)
+	err := s.EventBus.Send(domain.OutboxMessage{
+		Kind:      domain.OutboxEvent,
+		Topic:     domain.EventPaymentReceived,
+		Recipient: "metrics",
+		Payload:   string(payload),
+	})
+
+	s.NoError(err)
+	s.Require().NotNil(delivered)
+	s.Equal(event.LoanID, delivered.LoanID)
+	s.Equal(750.0, delivered.Outstanding)
+}
+
+func (s *EventBusTestSuite) TestSendUnknownSubscriber() {
+	err := s.EventBus.Send(domain.OutboxMessage{
+		Kind:      domain.OutboxEvent,
+		Topic:     domain.EventLoanApproved,
+		Recipient: "retired",
+		Payload:   "{}",
+	})
+
+	s.EqualError(err, "No subscriber retired for loan.approved events")
+}
+
+func TestEventBusTestSuite(t *testing.T) {
+	suite.Run(t, new(EventBusTestSuite))
+}
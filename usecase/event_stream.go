@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"loan_tracker_api/domain"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// how many missed events a client resuming a stream is sent at most
+const streamReplayLimit = 1000
+
+// how many events a poll of the log reads at most
+const streamPollLimit = 1000
+
+// how many events a client can fall behind before it is disconnected
+const streamBuffer = 64
+
+// streamClient is a client of an event stream connected to this instance
+type streamClient struct {
+	userID primitive.ObjectID
+	all    bool
+	events chan domain.StreamEvent
+	// events already sent while resuming
+	skip map[primitive.ObjectID]bool
+}
+
+// wants reports whether an event is for the client. Admins get the events of every user, but not their notifications.
+func (client *streamClient) wants(event domain.StreamEvent) bool {
+	if client.all {
+		return event.Name != domain.StreamNotification
+	}
+	return event.UserID == client.userID
+}
+
+// EventStream records domain events and notifications in the event log and pushes them to the stream clients of this instance.
+// Every instance polls the log, so a client sees the events recorded by any of them.
+type EventStream struct {
+	StreamRepo domain.StreamRepository
+	// how far back each poll reads again, events committed late are still pushed
+	lookback time.Duration
+
+	mu      sync.Mutex
+	clients map[*streamClient]bool
+	cursor  time.Time
+	seen    map[primitive.ObjectID]time.Time
+}
+
+func NewEventStream(Streamrepo domain.StreamRepository, lookback time.Duration) *EventStream {
+	return &EventStream{
+		StreamRepo: Streamrepo,
+		lookback:   lookback,
+		clients:    map[*streamClient]bool{},
+		cursor:     time.Now(),
+		seen:       map[primitive.ObjectID]time.Time{},
+	}
+}
+
+// RecordEvent records a domain event in the log, it is pushed to the user it concerns and to admins
+func (es *EventStream) RecordEvent(c context.Context, event domain.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return errors.New("Error encoding event")
+	}
+
+	var concerns struct {
+		UserID primitive.ObjectID `json:"user_id"`
+	}
+	json.Unmarshal(data, &concerns)
+
+	return es.StreamRepo.AddStreamEvent(c, &domain.StreamEvent{
+		SourceID: event.Header().EventID,
+		Name:     event.EventName(),
+		UserID:   concerns.UserID,
+		Data:     string(data),
+	})
+}
+
+// RecordNotification records an in-app notification in the log, it is pushed to its user only
+func (es *EventStream) RecordNotification(c context.Context, notification domain.Notification) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return errors.New("Error encoding notification")
+	}
+
+	return es.StreamRepo.AddStreamEvent(c, &domain.StreamEvent{
+		SourceID: notification.ID,
+		Name:     domain.StreamNotification,
+		UserID:   notification.UserID,
+		Data:     string(data),
+	})
+}
+
+// Subscribe connects a client to the stream of the user, or to the stream of every user.
+// With the ID of the last event the client received, the events recorded after it are replayed first.
+func (es *EventStream) Subscribe(c context.Context, userid string, all bool, lastEventID string) (*domain.StreamSubscription, error) {
+	client := &streamClient{
+		all:    all,
+		events: make(chan domain.StreamEvent, streamBuffer),
+		skip:   map[primitive.ObjectID]bool{},
+	}
+
+	filter := ""
+	if !all {
+		userID, err := primitive.ObjectIDFromHex(userid)
+		if err != nil {
+			return nil, errors.New("Invalid user ID")
+		}
+		client.userID = userID
+		filter = userid
+	}
+
+	// the client is connected before the log is read, events recorded in between are pushed rather than lost
+	es.mu.Lock()
+	es.clients[client] = true
+	es.mu.Unlock()
+
+	replay := []domain.StreamEvent{}
+	truncated := false
+	if lastEventID != "" {
+		// one event more than is replayed tells whether some are left out
+		missed, err := es.StreamRepo.StreamEventsAfter(lastEventID, filter, streamReplayLimit+1)
+		if err != nil {
+			es.disconnect(client)
+			return nil, err
+		}
+		if len(missed) > streamReplayLimit {
+			missed = missed[:streamReplayLimit]
+			truncated = true
+		}
+
+		es.mu.Lock()
+		for _, event := range missed {
+			client.skip[event.ID] = true
+			if client.wants(event) {
+				replay = append(replay, event)
+			}
+		}
+		es.mu.Unlock()
+	}
+
+	return &domain.StreamSubscription{
+		Replay:    replay,
+		Truncated: truncated,
+		Events:    client.events,
+		Close:     func() { es.disconnect(client) },
+	}, nil
+}
+
+// disconnect removes a client from the stream and closes its channel
+func (es *EventStream) disconnect(client *streamClient) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	if es.clients[client] {
+		delete(es.clients, client)
+		close(client.events)
+	}
+}
+
+// Poll reads the events recorded since the last poll and pushes them to the clients they are for.
+// It returns how many new events were read.
+func (es *EventStream) Poll(c context.Context) (int, error) {
+	es.mu.Lock()
+	since := es.cursor.Add(-es.lookback)
+	es.mu.Unlock()
+
+	events, err := es.StreamRepo.RecentStreamEvents(since, streamPollLimit)
+	if err != nil {
+		return 0, err
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	read := 0
+	for _, event := range events {
+		if _, ok := es.seen[event.ID]; ok {
+			continue
+		}
+		es.seen[event.ID] = event.CreatedAt
+		if event.CreatedAt.After(es.cursor) {
+			es.cursor = event.CreatedAt
+		}
+		read++
+
+		for client := range es.clients {
+			es.push(client, event)
+		}
+	}
+
+	// events older than the next poll reads again can't be read twice
+	for id, createdAt := range es.seen {
+		if createdAt.Before(es.cursor.Add(-es.lookback)) {
+			delete(es.seen, id)
+		}
+	}
+
+	return read, nil
+}
+
+// push hands an event to a client it is for, a client too far behind to take it is disconnected
+func (es *EventStream) push(client *streamClient, event domain.StreamEvent) {
+	if client.skip[event.ID] {
+		delete(client.skip, event.ID)
+		return
+	}
+
+	if !client.wants(event) {
+		return
+	}
+
+	select {
+	case client.events <- event:
+	default:
+		delete(es.clients, client)
+		close(client.events)
+	}
+}
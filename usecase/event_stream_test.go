@@ -0,0 +1,169 @@
+package usecase_test
+
+import (
+	"context"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"loan_tracker_api/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type EventStreamTestSuite struct {
+	suite.Suite
+	mockStreamRepository *mocks.StreamRepository
+	Stream               *usecase.EventStream
+}
+
+func (s *EventStreamTestSuite) SetupTest() {
+	s.mockStreamRepository = new(mocks.StreamRepository)
+	s.Stream = usecase.NewEventStream(s.mockStreamRepository, 30*time.Second)
+}
+
+// received drains the events pushed to a subscription so far
+func received(subscription *domain.StreamSubscription) []domain.StreamEvent {
+	events := []domain.StreamEvent{}
+	for {
+		select {
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return events
+			}
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func (s *EventStreamTestSuite) TestRecordEvent() {
+	event := &domain.LoanApproved{LoanID: primitive.NewObjectID(), UserID: primitive.NewObjectID()}
+	event.EventID = primitive.NewObjectID()
+	s.mockStreamRepository.On("AddStreamEvent", mock.Anything, mock.MatchedBy(func(e *domain.StreamEvent) bool {
+		return e.SourceID == event.EventID && e.UserID == event.UserID && e.Name == domain.EventLoanApproved
+	})).Return(nil).Once()
+
+	err := s.Stream.RecordEvent(context.Background(), event)
+
+	s.NoError(err)
+	s.mockStreamRepository.AssertExpectations(s.T())
+}
+
+func (s *EventStreamTestSuite) TestRecordNotification() {
+	notification := domain.Notification{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), Title: "Payment due"}
+	s.mockStreamRepository.On("AddStreamEvent", mock.Anything, mock.MatchedBy(func(e *domain.StreamEvent) bool {
+		return e.SourceID == notification.ID && e.UserID == notification.UserID && e.Name == domain.StreamNotification
+	})).Return(nil).Once()
+
+	err := s.Stream.RecordNotification(context.Background(), notification)
+
+	s.NoError(err)
+	s.mockStreamRepository.AssertExpectations(s.T())
+}
+
+func (s *EventStreamTestSuite) TestPollPushesToClients() {
+	user := primitive.NewObjectID()
+	other := primitive.NewObjectID()
+	now := time.Now()
+	events := []domain.StreamEvent{
+		{ID: primitive.NewObjectID(), Name: domain.EventLoanApproved, UserID: user, CreatedAt: now},
+		{ID: primitive.NewObjectID(), Name: domain.EventLoanApproved, UserID: other, CreatedAt: now},
+		{ID: primitive.NewObjectID(), Name: domain.StreamNotification, UserID: user, CreatedAt: now},
+	}
+	s.mockStreamRepository.On("RecentStreamEvents", mock.Anything, mock.Anything).Return(events, nil)
+
+	mine, err := s.Stream.Subscribe(context.Background(), user.Hex(), false, "")
+	s.Require().NoError(err)
+	admin, err := s.Stream.Subscribe(context.Background(), "", true, "")
+	s.Require().NoError(err)
+
+	read, err := s.Stream.Poll(context.Background())
+	s.NoError(err)
+	s.Equal(3, read)
+
+	// reading the same events again pushes nothing
+	read, err = s.Stream.Poll(context.Background())
+	s.NoError(err)
+	s.Equal(0, read)
+
+	s.Equal([]domain.StreamEvent{events[0], events[2]}, received(mine))
+	// notifications stay with their user
+	s.Equal([]domain.StreamEvent{events[0], events[1]}, received(admin))
+}
+
+func (s *EventStreamTestSuite) TestSubscribeResumes() {
+	user := primitive.NewObjectID()
+	last := primitive.NewObjectID()
+	missed := domain.StreamEvent{ID: primitive.NewObjectID(), Name: domain.EventPaymentReceived, UserID: user, CreatedAt: time.Now()}
+	s.mockStreamRepository.On("StreamEventsAfter", last.Hex(), user.Hex(), mock.Anything).Return([]domain.StreamEvent{missed}, nil).Once()
+	s.mockStreamRepository.On("RecentStreamEvents", mock.Anything, mock.Anything).Return([]domain.StreamEvent{missed}, nil)
+
+	subscription, err := s.Stream.Subscribe(context.Background(), user.Hex(), false, last.Hex())
+	s.Require().NoError(err)
+	_, err = s.Stream.Poll(context.Background())
+	s.NoError(err)
+
+	// the replayed event isn't pushed a second time
+	s.Equal([]domain.StreamEvent{missed}, subscription.Replay)
+	s.Empty(received(subscription))
+}
+
+func (s *EventStreamTestSuite) TestAdminReplayLeavesOutNotifications() {
+	last := primitive.NewObjectID()
+	payment := domain.StreamEvent{ID: primitive.NewObjectID(), Name: domain.EventPaymentReceived, UserID: primitive.NewObjectID()}
+	notification := domain.StreamEvent{ID: primitive.NewObjectID(), Name: domain.StreamNotification, UserID: payment.UserID}
+	s.mockStreamRepository.On("StreamEventsAfter", last.Hex(), "", 1001).Return([]domain.StreamEvent{payment, notification}, nil).Once()
+
+	subscription, err := s.Stream.Subscribe(context.Background(), "", true, last.Hex())
+
+	s.NoError(err)
+	s.Equal([]domain.StreamEvent{payment}, subscription.Replay)
+	s.False(subscription.Truncated)
+}
+
+func (s *EventStreamTestSuite) TestReplayTruncated() {
+	user := primitive.NewObjectID()
+	last := primitive.NewObjectID()
+	missed := make([]domain.StreamEvent, 1001)
+	for i := range missed {
+		missed[i] = domain.StreamEvent{ID: primitive.NewObjectID(), Name: domain.EventPaymentDue, UserID: user}
+	}
+	s.mockStreamRepository.On("StreamEventsAfter", last.Hex(), user.Hex(), 1001).Return(missed, nil).Once()
+
+	subscription, err := s.Stream.Subscribe(context.Background(), user.Hex(), false, last.Hex())
+
+	s.NoError(err)
+	s.Len(subscription.Replay, 1000)
+	s.Equal(missed[999], subscription.Replay[999])
+	s.True(subscription.Truncated)
+}
+
+func (s *EventStreamTestSuite) TestPollDisconnectsSlowClient() {
+	user := primitive.NewObjectID()
+	events := []domain.StreamEvent{}
+	for i := 0; i < 100; i++ {
+		events = append(events, domain.StreamEvent{ID: primitive.NewObjectID(), UserID: user, CreatedAt: time.Now()})
+	}
+	s.mockStreamRepository.On("RecentStreamEvents", mock.Anything, mock.Anything).Return(events, nil)
+
+	subscription, err := s.Stream.Subscribe(context.Background(), user.Hex(), false, "")
+	s.Require().NoError(err)
+	_, err = s.Stream.Poll(context.Background())
+	s.NoError(err)
+
+	pushed := 0
+	for range subscription.Events {
+		pushed++
+	}
+	s.Less(pushed, len(events))
+	// closing after being disconnected is harmless
+	subscription.Close()
+}
+
+func TestEventStreamTestSuite(t *testing.T) {
+	suite.Run(t, new(EventStreamTestSuite))
+}
@@ -0,0 +1,228 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"loan_tracker_api/domain"
+	"sort"
+	"sync"
+	"time"
+)
+
+// how many times a job is attempted unless it was queued with its own limit
+const queueMaxAttempts = 5
+
+// queueType is a type of job the workers of this instance run
+type queueType struct {
+	handler    domain.JobHandler
+	visibility time.Duration
+}
+
+// JobQueue runs background jobs from the persistent queue.
+// Failed jobs are retried with exponential backoff until they run out of attempts.
+type JobQueue struct {
+	QueueRepo      domain.QueueRepository
+	contextTimeout time.Duration
+
+	mu    sync.RWMutex
+	types map[string]queueType
+}
+
+func NewJobQueue(Queuerepo domain.QueueRepository, timeout time.Duration) *JobQueue {
+	return &JobQueue{
+		QueueRepo:      Queuerepo,
+		contextTimeout: timeout,
+		types:          map[string]queueType{},
+	}
+}
+
+// Register sets the handler of a type of job. A job not reported back on within the visibility timeout
+// is handed to another worker, the handler is cancelled by then.
+func (queue *JobQueue) Register(jobType string, visibility time.Duration, handler domain.JobHandler) {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	queue.types[jobType] = queueType{handler: handler, visibility: visibility}
+}
+
+// registered returns the types of jobs the workers of this instance run, sorted
+func (queue *JobQueue) registered() []string {
+	queue.mu.RLock()
+	defer queue.mu.RUnlock()
+
+	types := make([]string, 0, len(queue.types))
+	for jobType := range queue.types {
+		types = append(types, jobType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Enqueue adds a job of a registered type to the queue with its payload encoded as JSON
+func (queue *JobQueue) Enqueue(c context.Context, jobType string, payload interface{}, options domain.JobOptions) (domain.QueuedJob, error) {
+	_, cancel := context.WithTimeout(c, queue.contextTimeout)
+	defer cancel()
+
+	queue.mu.RLock()
+	registered, ok := queue.types[jobType]
+	queue.mu.RUnlock()
+	if !ok {
+		return domain.QueuedJob{}, errors.New("Unknown job type " + jobType)
+	}
+
+	job := domain.QueuedJob{
+		Type:        jobType,
+		Key:         options.Key,
+		Priority:    options.Priority,
+		MaxAttempts: options.MaxAttempts,
+		Visibility:  int(registered.visibility.Seconds()),
+		RunAt:       options.RunAt,
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = queueMaxAttempts
+	}
+
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return domain.QueuedJob{}, errors.New("Error encoding job payload")
+		}
+		job.Payload = string(encoded)
+	}
+
+	queued, err := queue.QueueRepo.EnqueueJob(c, &job)
+	if err != nil {
+		return domain.QueuedJob{}, err
+	}
+	if !queued {
+		return domain.QueuedJob{}, errors.New("A job with key " + options.Key + " is already queued")
+	}
+
+	return job, nil
+}
+
+// Work runs the next due job as the given worker and reports whether there was one.
+// The outcome of the job is recorded on it, only failing to reach the queue is returned.
+func (queue *JobQueue) Work(c context.Context, worker string) (bool, error) {
+	job, claimed, err := queue.QueueRepo.ClaimNextJob(queue.registered(), worker, time.Now())
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	// every attempt it had ended with its worker gone before it reported back
+	if job.Attempts > job.MaxAttempts {
+		queue.fail(&job, worker, errors.New("Worker stopped responding"), time.Now())
+		return true, queue.QueueRepo.FinishJob(&job)
+	}
+
+	err = queue.run(c, job)
+
+	now := time.Now()
+	if err != nil {
+		queue.fail(&job, worker, err, now)
+	} else {
+		job.Status = domain.QueueCompleted
+		job.LastError = ""
+		job.FinishedAt = &now
+	}
+
+	return true, queue.QueueRepo.FinishJob(&job)
+}
+
+// run hands a job to the handler of its type, a panicking handler fails the attempt
+func (queue *JobQueue) run(c context.Context, job domain.QueuedJob) (err error) {
+	queue.mu.RLock()
+	registered := queue.types[job.Type]
+	queue.mu.RUnlock()
+
+	c, cancel := context.WithTimeout(c, time.Duration(job.Visibility)*time.Second)
+	defer cancel()
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("Job panicked: %v", recovered)
+		}
+	}()
+
+	return registered.handler(c, job)
+}
+
+// fail records a failed attempt, the job is retried after a backoff until it runs out of attempts
+func (queue *JobQueue) fail(job *domain.QueuedJob, worker string, err error, now time.Time) {
+	job.LastError = err.Error()
+	job.Failures = append(job.Failures, domain.JobFailure{
+		Attempt:  job.Attempts,
+		Worker:   worker,
+		Error:    err.Error(),
+		FailedAt: now,
+	})
+
+	if job.Attempts >= job.MaxAttempts {
+		job.Status = domain.QueueFailed
+		job.FinishedAt = &now
+		return
+	}
+
+	job.Status = domain.QueuePending
+	job.RunAt = now.Add(backoff(job.Attempts))
+}
+
+func (queue *JobQueue) ListJobs(c context.Context, filter domain.JobFilter) ([]domain.QueuedJob, error) {
+	_, cancel := context.WithTimeout(c, queue.contextTimeout)
+	defer cancel()
+	return queue.QueueRepo.ListJobs(filter)
+}
+
+func (queue *JobQueue) GetJob(c context.Context, id string) (domain.QueuedJob, error) {
+	_, cancel := context.WithTimeout(c, queue.contextTimeout)
+	defer cancel()
+	return queue.QueueRepo.GetJob(id)
+}
+
+// RetryJob queues a failed or cancelled job again with a fresh set of attempts, the failures of its earlier attempts are kept
+func (queue *JobQueue) RetryJob(c context.Context, id string) error {
+	_, cancel := context.WithTimeout(c, queue.contextTimeout)
+	defer cancel()
+
+	job, err := queue.QueueRepo.GetJob(id)
+	if err != nil {
+		return err
+	}
+
+	if job.Status != domain.QueueFailed && job.Status != domain.QueueCancelled {
+		return errors.New("Only failed or cancelled jobs can be retried")
+	}
+
+	from := job.Status
+	job.Status = domain.QueuePending
+	job.Attempts = 0
+	job.LastError = ""
+	job.RunAt = time.Now()
+	job.FinishedAt = nil
+
+	return queue.QueueRepo.UpdateJob(&job, from)
+}
+
+// CancelJob keeps a pending job from running, or dismisses a failed one
+func (queue *JobQueue) CancelJob(c context.Context, id string) error {
+	_, cancel := context.WithTimeout(c, queue.contextTimeout)
+	defer cancel()
+
+	job, err := queue.QueueRepo.GetJob(id)
+	if err != nil {
+		return err
+	}
+
+	if job.Status != domain.QueuePending && job.Status != domain.QueueFailed {
+		return errors.New("Only pending or failed jobs can be cancelled")
+	}
+
+	from := job.Status
+	now := time.Now()
+	job.Status = domain.QueueCancelled
+	job.FinishedAt = &now
+
+	return queue.QueueRepo.UpdateJob(&job, from)
+}
@@ -0,0 +1,219 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"loan_tracker_api/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type JobQueueTestSuite struct {
+	suite.Suite
+	mockQueueRepository *mocks.QueueRepository
+	JobQueue            *usecase.JobQueue
+}
+
+func (s *JobQueueTestSuite) SetupTest() {
+	s.mockQueueRepository = new(mocks.QueueRepository)
+	s.JobQueue = usecase.NewJobQueue(s.mockQueueRepository, time.Second*2)
+}
+
+func (s *JobQueueTestSuite) TestEnqueue() {
+	s.JobQueue.Register("export", 10*time.Minute, func(c context.Context, job domain.QueuedJob) error { return nil })
+	s.mockQueueRepository.On("EnqueueJob", mock.Anything, mock.MatchedBy(func(job *domain.QueuedJob) bool {
+		return job.Type == "export" && job.Payload == `{"user":"u1"}` && job.Priority == 5 &&
+			job.MaxAttempts == 5 && job.Visibility == 600
+	})).Return(true, nil).Once()
+
+	job, err := s.JobQueue.Enqueue(context.Background(), "export", map[string]string{"user": "u1"}, domain.JobOptions{Priority: 5})
+
+	s.NoError(err)
+	s.Equal("export", job.Type)
+
+	var payload map[string]string
+	s.NoError(job.DecodePayload(&payload))
+	s.Equal("u1", payload["user"])
+	s.mockQueueRepository.AssertExpectations(s.T())
+}
+
+func (s *JobQueueTestSuite) TestEnqueueUnknownType() {
+	_, err := s.JobQueue.Enqueue(context.Background(), "export", nil, domain.JobOptions{})
+
+	s.EqualError(err, "Unknown job type export")
+	s.mockQueueRepository.AssertNotCalled(s.T(), "EnqueueJob", mock.Anything, mock.Anything)
+}
+
+func (s *JobQueueTestSuite) TestEnqueueDuplicateKey() {
+	s.JobQueue.Register("loan_purge", time.Hour, func(c context.Context, job domain.QueuedJob) error { return nil })
+	s.mockQueueRepository.On("EnqueueJob", mock.Anything, mock.MatchedBy(func(job *domain.QueuedJob) bool {
+		return job.Key == "loan_purge" && job.MaxAttempts == 2
+	})).Return(false, nil).Once()
+
+	_, err := s.JobQueue.Enqueue(context.Background(), "loan_purge", nil, domain.JobOptions{Key: "loan_purge", MaxAttempts: 2})
+
+	s.EqualError(err, "A job with key loan_purge is already queued")
+}
+
+func (s *JobQueueTestSuite) TestWorkCompletesJob() {
+	var ran domain.QueuedJob
+	s.JobQueue.Register("export", time.Minute, func(c context.Context, job domain.QueuedJob) error {
+		ran = job
+		_, hasDeadline := c.Deadline()
+		s.True(hasDeadline)
+		return nil
+	})
+	s.JobQueue.Register("archive", time.Minute, func(c context.Context, job domain.QueuedJob) error { return nil })
+
+	job := domain.QueuedJob{ID: primitive.NewObjectID(), Type: "export", Status: domain.QueueActive, Attempts: 1, MaxAttempts: 5, Visibility: 60}
+	s.mockQueueRepository.On("ClaimNextJob", []string{"archive", "export"}, "worker-1", mock.Anything).Return(job, true, nil).Once()
+	s.mockQueueRepository.On("FinishJob", mock.MatchedBy(func(job *domain.QueuedJob) bool {
+		return job.Status == domain.QueueCompleted && job.FinishedAt != nil
+	})).Return(nil).Once()
+
+	worked, err := s.JobQueue.Work(context.Background(), "worker-1")
+
+	s.NoError(err)
+	s.True(worked)
+	s.Equal(job.ID, ran.ID)
+	s.mockQueueRepository.AssertExpectations(s.T())
+}
+
+func (s *JobQueueTestSuite) TestWorkNoJobDue() {
+	s.mockQueueRepository.On("ClaimNextJob", mock.Anything, "worker-1", mock.Anything).Return(domain.QueuedJob{}, false, nil).Once()
+
+	worked, err := s.JobQueue.Work(context.Background(), "worker-1")
+
+	s.NoError(err)
+	s.False(worked)
+	s.mockQueueRepository.AssertNotCalled(s.T(), "FinishJob", mock.Anything)
+}
+
+func (s *JobQueueTestSuite) TestWorkRetriesFailedJob() {
+	s.JobQueue.Register("export", time.Minute, func(c context.Context, job domain.QueuedJob) error {
+		return errors.New("Storage unavailable")
+	})
+
+	job := domain.QueuedJob{ID: primitive.NewObjectID(), Type: "export", Status: domain.QueueActive, Attempts: 2, MaxAttempts: 5, Visibility: 60}
+	s.mockQueueRepository.On("ClaimNextJob", mock.Anything, "worker-1", mock.Anything).Return(job, true, nil).Once()
+	s.mockQueueRepository.On("FinishJob", mock.MatchedBy(func(job *domain.QueuedJob) bool {
+		return job.Status == domain.QueuePending && job.LastError == "Storage unavailable" &&
+			len(job.Failures) == 1 && job.Failures[0].Attempt == 2 && job.Failures[0].Worker == "worker-1" &&
+			job.RunAt.After(time.Now().Add(30*time.Second)) && job.FinishedAt == nil
+	})).Return(nil).Once()
+
+	worked, err := s.JobQueue.Work(context.Background(), "worker-1")
+
+	s.NoError(err)
+	s.True(worked)
+	s.mockQueueRepository.AssertExpectations(s.T())
+}
+
+func (s *JobQueueTestSuite) TestWorkFailsJobOutOfAttempts() {
+	s.JobQueue.Register("export", time.Minute, func(c context.Context, job domain.QueuedJob) error {
+		return errors.New("Storage unavailable")
+	})
+
+	job := domain.QueuedJob{ID: primitive.NewObjectID(), Type: "export", Status: domain.QueueActive, Attempts: 5, MaxAttempts: 5, Visibility: 60}
+	s.mockQueueRepository.On("ClaimNextJob", mock.Anything, "worker-1", mock.Anything).Return(job, true, nil).Once()
+	s.mockQueueRepository.On("FinishJob", mock.MatchedBy(func(job *domain.QueuedJob) bool {
+		return job.Status == domain.QueueFailed && job.FinishedAt != nil && len(job.Failures) == 1
+	})).Return(nil).Once()
+
+	_, err := s.JobQueue.Work(context.Background(), "worker-1")
+
+	s.NoError(err)
+	s.mockQueueRepository.AssertExpectations(s.T())
+}
+
+func (s *JobQueueTestSuite) TestWorkRecoversPanic() {
+	s.JobQueue.Register("export", time.Minute, func(c context.Context, job domain.QueuedJob) error {
+		panic("nil map")
+	})
+
+	job := domain.QueuedJob{ID: primitive.NewObjectID(), Type: "export", Status: domain.QueueActive, Attempts: 1, MaxAttempts: 5, Visibility: 60}
+	s.mockQueueRepository.On("ClaimNextJob", mock.Anything, "worker-1", mock.Anything).Return(job, true, nil).Once()
+	s.mockQueueRepository.On("FinishJob", mock.MatchedBy(func(job *domain.QueuedJob) bool {
+		return job.Status == domain.QueuePending && job.LastError == "Job panicked: nil map"
+	})).Return(nil).Once()
+
+	_, err := s.JobQueue.Work(context.Background(), "worker-1")
+
+	s.NoError(err)
+	s.mockQueueRepository.AssertExpectations(s.T())
+}
+
+func (s *JobQueueTestSuite) TestWorkFailsJobWhoseWorkersStoppedResponding() {
+	ran := false
+	s.JobQueue.Register("export", time.Minute, func(c context.Context, job domain.QueuedJob) error {
+		ran = true
+		return nil
+	})
+
+	// claimed again after its last attempt timed out
+	job := domain.QueuedJob{ID: primitive.NewObjectID(), Type: "export", Status: domain.QueueActive, Attempts: 6, MaxAttempts: 5, Visibility: 60}
+	s.mockQueueRepository.On("ClaimNextJob", mock.Anything, "worker-2", mock.Anything).Return(job, true, nil).Once()
+	s.mockQueueRepository.On("FinishJob", mock.MatchedBy(func(job *domain.QueuedJob) bool {
+		return job.Status == domain.QueueFailed && job.LastError == "Worker stopped responding"
+	})).Return(nil).Once()
+
+	worked, err := s.JobQueue.Work(context.Background(), "worker-2")
+
+	s.NoError(err)
+	s.True(worked)
+	s.False(ran)
+	s.mockQueueRepository.AssertExpectations(s.T())
+}
+
+func (s *JobQueueTestSuite) TestRetryJob() {
+	id := primitive.NewObjectID()
+	failures := []domain.JobFailure{{Attempt: 5, Error: "Storage unavailable"}}
+	s.mockQueueRepository.On("GetJob", id.Hex()).Return(domain.QueuedJob{ID: id, Status: domain.QueueFailed, Attempts: 5, Failures: failures}, nil).Once()
+	s.mockQueueRepository.On("UpdateJob", mock.MatchedBy(func(job *domain.QueuedJob) bool {
+		return job.Status == domain.QueuePending && job.Attempts == 0 && len(job.Failures) == 1 && job.FinishedAt == nil
+	}), domain.QueueFailed).Return(nil).Once()
+
+	err := s.JobQueue.RetryJob(context.Background(), id.Hex())
+
+	s.NoError(err)
+	s.mockQueueRepository.AssertExpectations(s.T())
+}
+
+func (s *JobQueueTestSuite) TestRetryJobNotFailed() {
+	s.mockQueueRepository.On("GetJob", "jobid").Return(domain.QueuedJob{Status: domain.QueueActive}, nil).Once()
+
+	err := s.JobQueue.RetryJob(context.Background(), "jobid")
+
+	s.EqualError(err, "Only failed or cancelled jobs can be retried")
+	s.mockQueueRepository.AssertNotCalled(s.T(), "UpdateJob", mock.Anything, mock.Anything)
+}
+
+func (s *JobQueueTestSuite) TestCancelJob() {
+	s.mockQueueRepository.On("GetJob", "jobid").Return(domain.QueuedJob{Status: domain.QueuePending, Key: "loan_purge"}, nil).Once()
+	s.mockQueueRepository.On("UpdateJob", mock.MatchedBy(func(job *domain.QueuedJob) bool {
+		return job.Status == domain.QueueCancelled && job.FinishedAt != nil
+	}), domain.QueuePending).Return(nil).Once()
+
+	err := s.JobQueue.CancelJob(context.Background(), "jobid")
+
+	s.NoError(err)
+	s.mockQueueRepository.AssertExpectations(s.T())
+}
+
+func (s *JobQueueTestSuite) TestCancelJobCompleted() {
+	s.mockQueueRepository.On("GetJob", "jobid").Return(domain.QueuedJob{Status: domain.QueueCompleted}, nil).Once()
+
+	err := s.JobQueue.CancelJob(context.Background(), "jobid")
+
+	s.EqualError(err, "Only pending or failed jobs can be cancelled")
+}
+
+func TestJobQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(JobQueueTestSuite))
+}
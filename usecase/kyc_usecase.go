@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// the youngest age a borrower can be verified at
+const kycMinimumAge = 18
+
+// the status each review decision puts a profile in
+var kycDecisions = map[string]string{
+	domain.KYCDecisionApprove:     domain.KYCApproved,
+	domain.KYCDecisionReject:      domain.KYCRejected,
+	domain.KYCDecisionRequestInfo: domain.KYCMoreInfo,
+}
+
+type KYCUsecase struct {
+	KYCRepo        domain.KYCRepository
+	UserRepo       domain.UserRepository
+	LogRepo        domain.LogRepository
+	UnitOfWork     domain.UnitOfWork
+	Events         domain.EventBus
+	contextTimeout time.Duration
+}
+
+func NewKYCUsecase(Kycrepo domain.KYCRepository, Userrepo domain.UserRepository, Logrepo domain.LogRepository, Unitofwork domain.UnitOfWork, Events domain.EventBus, timeout time.Duration) *KYCUsecase {
+	return &KYCUsecase{
+		KYCRepo:        Kycrepo,
+		UserRepo:       Userrepo,
+		LogRepo:        Logrepo,
+		UnitOfWork:     Unitofwork,
+		Events:         Events,
+		contextTimeout: timeout,
+	}
+}
+
+// kycStatus returns the KYC status of a user, users registered before KYC existed never submitted a profile
+func kycStatus(user domain.User) string {
+	if user.KYCStatus == "" {
+		return domain.KYCNotSubmitted
+	}
+	return user.KYCStatus
+}
+
+// checkKYCProfile trims the fields of a submitted profile and rejects incomplete ones
+func checkKYCProfile(profile *domain.KYCProfile, now time.Time) error {
+	profile.LegalName = strings.TrimSpace(profile.LegalName)
+	profile.NationalID = strings.TrimSpace(profile.NationalID)
+	profile.Address.Line1 = strings.TrimSpace(profile.Address.Line1)
+	profile.Address.City = strings.TrimSpace(profile.Address.City)
+	profile.Address.Country = strings.TrimSpace(profile.Address.Country)
+
+	if profile.LegalName == "" {
+		return errors.New("Legal name is required")
+	}
+	if profile.DateOfBirth.IsZero() {
+		return errors.New("Date of birth is required")
+	}
+	if profile.DateOfBirth.AddDate(kycMinimumAge, 0, 0).After(now) {
+		return errors.New("Borrowers must be at least 18 years old")
+	}
+	if profile.NationalID == "" {
+		return errors.New("National ID number is required")
+	}
+	if profile.Address.Line1 == "" || profile.Address.City == "" || profile.Address.Country == "" {
+		return errors.New("Address must include line1, city and country")
+	}
+	if len(profile.Documents) == 0 {
+		return errors.New("At least one identity document is required")
+	}
+	for i := range profile.Documents {
+		profile.Documents[i].Kind = strings.TrimSpace(profile.Documents[i].Kind)
+		profile.Documents[i].Reference = strings.TrimSpace(profile.Documents[i].Reference)
+		if profile.Documents[i].Kind == "" || profile.Documents[i].Reference == "" {
+			return errors.New("Every document needs a kind and a reference")
+		}
+	}
+	return nil
+}
+
+// SubmitKYC puts the profile of a user in the review queue. A profile can be submitted again
+// while it was never submitted or more information was requested, the new one replaces the old one.
+func (kuse *KYCUsecase) SubmitKYC(c context.Context, profile *domain.KYCProfile, userid string) error {
+	_, cancel := context.WithTimeout(c, kuse.contextTimeout)
+	defer cancel()
+
+	now := time.Now()
+	if err := checkKYCProfile(profile, now); err != nil {
+		return err
+	}
+
+	user, err := kuse.UserRepo.UserProfile(userid)
+	if err != nil {
+		return err
+	}
+
+	status := kycStatus(user)
+	switch status {
+	case domain.KYCPending:
+		return errors.New("KYC is already under review")
+	case domain.KYCApproved:
+		return errors.New("KYC is already approved")
+	case domain.KYCRejected:
+		return errors.New("KYC was rejected and cannot be resubmitted")
+	}
+
+	if existing, err := kuse.KYCRepo.KYCProfile(userid); err == nil {
+		profile.ID = existing.ID
+	}
+	profile.UserID = user.ID
+	profile.Status = domain.KYCPending
+	profile.ReviewNote = ""
+	profile.ReviewedBy = primitive.NilObjectID
+	profile.ReviewedAt = nil
+	profile.SubmittedAt = now
+
+	// the profile itself stays out of the audit log, it is personal data
+	changes := []domain.FieldChange{{Field: "kyc_status", Before: status, After: domain.KYCPending}}
+
+	return kuse.UnitOfWork.Do(c, func(c context.Context) error {
+		if err := kuse.KYCRepo.SaveKYCProfile(c, profile); err != nil {
+			return err
+		}
+		if err := kuse.UserRepo.SetKYCStatus(c, userid, domain.KYCPending); err != nil {
+			return err
+		}
+		return audit(c, kuse.LogRepo, userid, domain.Log{
+			Action:     domain.ActionUserKYCSubmitted,
+			TargetType: domain.TargetUser,
+			TargetID:   user.ID,
+			Changes:    changes,
+		})
+	})
+}
+
+func (kuse *KYCUsecase) KYCProfile(c context.Context, userid string) (domain.KYCProfile, error) {
+	_, cancel := context.WithTimeout(c, kuse.contextTimeout)
+	defer cancel()
+	return kuse.KYCRepo.KYCProfile(userid)
+}
+
+// KYCQueue lists the profiles in a status, oldest submission first. Profiles pending review are listed unless told otherwise.
+func (kuse *KYCUsecase) KYCQueue(c context.Context, status string, page int) ([]domain.KYCProfile, error) {
+	_, cancel := context.WithTimeout(c, kuse.contextTimeout)
+	defer cancel()
+
+	if status == "" {
+		status = domain.KYCPending
+	}
+	switch status {
+	case domain.KYCPending, domain.KYCMoreInfo, domain.KYCApproved, domain.KYCRejected:
+	default:
+		return nil, errors.New("Invalid KYC status")
+	}
+
+	return kuse.KYCRepo.KYCQueue(status, page)
+}
+
+func (kuse *KYCUsecase) GetKYCProfile(c context.Context, id string) (domain.KYCProfile, error) {
+	_, cancel := context.WithTimeout(c, kuse.contextTimeout)
+	defer cancel()
+	return kuse.KYCRepo.GetKYCProfile(id)
+}
+
+// ReviewKYC approves or rejects a profile pending review, or sends it back to the user for more information.
+// Rejecting and requesting information need a note telling the user why.
+func (kuse *KYCUsecase) ReviewKYC(c context.Context, id, decision, note, adminid string) (domain.KYCProfile, error) {
+	_, cancel := context.WithTimeout(c, kuse.contextTimeout)
+	defer cancel()
+
+	status, ok := kycDecisions[decision]
+	if !ok {
+		return domain.KYCProfile{}, errors.New("Invalid KYC decision")
+	}
+
+	note = strings.TrimSpace(note)
+	if note == "" && decision != domain.KYCDecisionApprove {
+		return domain.KYCProfile{}, errors.New("A note is required to reject or request more information")
+	}
+
+	profile, err := kuse.KYCRepo.GetKYCProfile(id)
+	if err != nil {
+		return domain.KYCProfile{}, err
+	}
+	if profile.Status != domain.KYCPending {
+		return domain.KYCProfile{}, errors.New("Only KYC profiles pending review can be reviewed")
+	}
+
+	now := time.Now()
+	reviewer, _ := primitive.ObjectIDFromHex(adminid)
+	profile.Status = status
+	profile.ReviewNote = note
+	profile.ReviewedBy = reviewer
+	profile.ReviewedAt = &now
+
+	changes := []domain.FieldChange{{Field: "kyc_status", Before: domain.KYCPending, After: status}}
+
+	err = kuse.UnitOfWork.Do(c, func(c context.Context) error {
+		if err := kuse.KYCRepo.SaveKYCProfile(c, &profile); err != nil {
+			return err
+		}
+		if err := kuse.UserRepo.SetKYCStatus(c, profile.UserID.Hex(), status); err != nil {
+			return err
+		}
+		if err := audit(c, kuse.LogRepo, adminid, domain.Log{
+			Action:     domain.ActionUserKYCReviewed,
+			TargetType: domain.TargetUser,
+			TargetID:   profile.UserID,
+			Changes:    changes,
+			Note:       note,
+		}); err != nil {
+			return err
+		}
+		return kuse.Events.Publish(c, &domain.KYCReviewed{
+			UserID:     profile.UserID,
+			Status:     status,
+			Note:       note,
+			ReviewedBy: reviewer,
+		})
+	})
+	if err != nil {
+		return domain.KYCProfile{}, err
+	}
+
+	return profile, nil
+}
@@ -0,0 +1,165 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"loan_tracker_api/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type KYCUsecaseTestSuite struct {
+	suite.Suite
+	mockKYCRepository  *mocks.KYCRepository
+	mockUserRepository *mocks.UserRepository
+	mockLogRepository  *mocks.LogRepository
+	mockUnitOfWork     *mocks.UnitOfWork
+	mockEventBus       *mocks.EventBus
+	KYCUsecase         domain.KYCUsecase
+	user               domain.User
+}
+
+func (s *KYCUsecaseTestSuite) SetupTest() {
+	s.mockKYCRepository = new(mocks.KYCRepository)
+	s.mockUserRepository = new(mocks.UserRepository)
+	s.mockLogRepository = new(mocks.LogRepository)
+	s.mockUnitOfWork = new(mocks.UnitOfWork)
+	s.mockUnitOfWork.On("Do", mock.Anything, mock.Anything).Return(func(c context.Context, work func(context.Context) error) error {
+		return work(c)
+	}).Maybe()
+	s.mockEventBus = new(mocks.EventBus)
+	s.KYCUsecase = usecase.NewKYCUsecase(s.mockKYCRepository, s.mockUserRepository, s.mockLogRepository, s.mockUnitOfWork, s.mockEventBus, time.Second*2)
+
+	s.user = domain.User{ID: primitive.NewObjectID(), UserName: "testuser", KYCStatus: domain.KYCNotSubmitted}
+}
+
+func (s *KYCUsecaseTestSuite) profile() *domain.KYCProfile {
+	return &domain.KYCProfile{
+		LegalName:   " Jane Doe ",
+		DateOfBirth: time.Now().AddDate(-30, 0, 0),
+		NationalID:  "ID-123456",
+		Address:     domain.Address{Line1: "1 Main Street", City: "Addis Ababa", Country: "ET"},
+		Documents:   []domain.KYCDocument{{Kind: "passport", Reference: "EP1234567"}},
+	}
+}
+
+func (s *KYCUsecaseTestSuite) TestSubmitKYC() {
+	existing := domain.KYCProfile{ID: primitive.NewObjectID(), UserID: s.user.ID, Status: domain.KYCMoreInfo, ReviewNote: "The passport scan is blurry"}
+	s.user.KYCStatus = domain.KYCMoreInfo
+	s.mockUserRepository.On("UserProfile", s.user.ID.Hex()).Return(s.user, nil).Once()
+	s.mockKYCRepository.On("KYCProfile", s.user.ID.Hex()).Return(existing, nil).Once()
+	s.mockKYCRepository.On("SaveKYCProfile", mock.Anything, mock.MatchedBy(func(p *domain.KYCProfile) bool {
+		return p.ID == existing.ID && p.UserID == s.user.ID && p.Status == domain.KYCPending && p.LegalName == "Jane Doe" && p.ReviewNote == ""
+	})).Return(nil).Once()
+	s.mockUserRepository.On("SetKYCStatus", mock.Anything, s.user.ID.Hex(), domain.KYCPending).Return(nil).Once()
+	s.mockLogRepository.On("AddLog", mock.Anything, mock.MatchedBy(func(entry *domain.Log) bool {
+		return entry.Action == domain.ActionUserKYCSubmitted && entry.TargetID == s.user.ID && entry.Changes[0].Before == domain.KYCMoreInfo
+	})).Return(nil).Once()
+
+	err := s.KYCUsecase.SubmitKYC(context.Background(), s.profile(), s.user.ID.Hex())
+
+	s.NoError(err)
+	s.mockKYCRepository.AssertExpectations(s.T())
+	s.mockUserRepository.AssertExpectations(s.T())
+	s.mockLogRepository.AssertExpectations(s.T())
+}
+
+func (s *KYCUsecaseTestSuite) TestSubmitKYCUnderage() {
+	profile := s.profile()
+	profile.DateOfBirth = time.Now().AddDate(-17, 0, 0)
+
+	err := s.KYCUsecase.SubmitKYC(context.Background(), profile, s.user.ID.Hex())
+
+	s.EqualError(err, "Borrowers must be at least 18 years old")
+	s.mockUserRepository.AssertNotCalled(s.T(), "UserProfile", mock.Anything)
+}
+
+func (s *KYCUsecaseTestSuite) TestSubmitKYCWithoutDocuments() {
+	profile := s.profile()
+	profile.Documents = nil
+
+	err := s.KYCUsecase.SubmitKYC(context.Background(), profile, s.user.ID.Hex())
+
+	s.EqualError(err, "At least one identity document is required")
+}
+
+func (s *KYCUsecaseTestSuite) TestSubmitKYCUnderReview() {
+	s.user.KYCStatus = domain.KYCPending
+	s.mockUserRepository.On("UserProfile", s.user.ID.Hex()).Return(s.user, nil).Once()
+
+	err := s.KYCUsecase.SubmitKYC(context.Background(), s.profile(), s.user.ID.Hex())
+
+	s.EqualError(err, "KYC is already under review")
+	s.mockKYCRepository.AssertNotCalled(s.T(), "SaveKYCProfile", mock.Anything, mock.Anything)
+}
+
+func (s *KYCUsecaseTestSuite) TestKYCQueueDefaultsToPending() {
+	s.mockKYCRepository.On("KYCQueue", domain.KYCPending, 1).Return([]domain.KYCProfile{{Status: domain.KYCPending}}, nil).Once()
+
+	profiles, err := s.KYCUsecase.KYCQueue(context.Background(), "", 1)
+
+	s.NoError(err)
+	s.Len(profiles, 1)
+}
+
+func (s *KYCUsecaseTestSuite) TestReviewKYCApprove() {
+	adminID := primitive.NewObjectID()
+	profile := domain.KYCProfile{ID: primitive.NewObjectID(), UserID: s.user.ID, Status: domain.KYCPending}
+	s.mockKYCRepository.On("GetKYCProfile", profile.ID.Hex()).Return(profile, nil).Once()
+	s.mockKYCRepository.On("SaveKYCProfile", mock.Anything, mock.MatchedBy(func(p *domain.KYCProfile) bool {
+		return p.Status == domain.KYCApproved && p.ReviewedBy == adminID && p.ReviewedAt != nil
+	})).Return(nil).Once()
+	s.mockUserRepository.On("SetKYCStatus", mock.Anything, s.user.ID.Hex(), domain.KYCApproved).Return(nil).Once()
+	s.mockLogRepository.On("AddLog", mock.Anything, mock.MatchedBy(func(entry *domain.Log) bool {
+		return entry.Action == domain.ActionUserKYCReviewed && entry.TargetID == s.user.ID
+	})).Return(nil).Once()
+	s.mockEventBus.On("Publish", mock.Anything, mock.MatchedBy(func(event domain.Event) bool {
+		reviewed, ok := event.(*domain.KYCReviewed)
+		return ok && reviewed.UserID == s.user.ID && reviewed.Status == domain.KYCApproved
+	})).Return(nil).Once()
+
+	reviewed, err := s.KYCUsecase.ReviewKYC(context.Background(), profile.ID.Hex(), domain.KYCDecisionApprove, "", adminID.Hex())
+
+	s.NoError(err)
+	s.Equal(domain.KYCApproved, reviewed.Status)
+	s.mockEventBus.AssertExpectations(s.T())
+	s.mockUserRepository.AssertExpectations(s.T())
+}
+
+func (s *KYCUsecaseTestSuite) TestReviewKYCRejectNeedsNote() {
+	_, err := s.KYCUsecase.ReviewKYC(context.Background(), primitive.NewObjectID().Hex(), domain.KYCDecisionReject, " ", primitive.NewObjectID().Hex())
+
+	s.EqualError(err, "A note is required to reject or request more information")
+	s.mockKYCRepository.AssertNotCalled(s.T(), "GetKYCProfile", mock.Anything)
+}
+
+func (s *KYCUsecaseTestSuite) TestReviewKYCNotPending() {
+	profile := domain.KYCProfile{ID: primitive.NewObjectID(), UserID: s.user.ID, Status: domain.KYCApproved}
+	s.mockKYCRepository.On("GetKYCProfile", profile.ID.Hex()).Return(profile, nil).Once()
+
+	_, err := s.KYCUsecase.ReviewKYC(context.Background(), profile.ID.Hex(), domain.KYCDecisionRequestInfo, "Send a recent utility bill", primitive.NewObjectID().Hex())
+
+	s.EqualError(err, "Only KYC profiles pending review can be reviewed")
+}
+
+func (s *KYCUsecaseTestSuite) TestReviewKYCRollsBackOnFailure() {
+	profile := domain.KYCProfile{ID: primitive.NewObjectID(), UserID: s.user.ID, Status: domain.KYCPending}
+	s.mockKYCRepository.On("GetKYCProfile", profile.ID.Hex()).Return(profile, nil).Once()
+	s.mockKYCRepository.On("SaveKYCProfile", mock.Anything, mock.Anything).Return(nil).Once()
+	s.mockUserRepository.On("SetKYCStatus", mock.Anything, s.user.ID.Hex(), domain.KYCRejected).Return(errors.New("User not found")).Once()
+
+	_, err := s.KYCUsecase.ReviewKYC(context.Background(), profile.ID.Hex(), domain.KYCDecisionReject, "The ID number does not match", primitive.NewObjectID().Hex())
+
+	s.EqualError(err, "User not found")
+	s.mockEventBus.AssertNotCalled(s.T(), "Publish", mock.Anything, mock.Anything)
+}
+
+func TestKYCUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(KYCUsecaseTestSuite))
+}
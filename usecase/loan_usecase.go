@@ -2,55 +2,1132 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"loan_tracker_api/domain"
+	"math"
+	"sort"
+	"strings"
 	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type LoanUsecase struct {
 	UserRepo       domain.LoanRepository
+	Users          domain.UserRepository
+	Collateral     domain.CollateralRepository
+	LogRepo        domain.LogRepository
+	UnitOfWork     domain.UnitOfWork
+	Events         domain.EventBus
+	Products       map[string]domain.LoanProduct
 	contextTimeout time.Duration
 }
 
-func NewLoanUsecase(Userrepo domain.LoanRepository, timeout time.Duration) domain.LoanUsecase {
+func NewLoanUsecase(Userrepo domain.LoanRepository, Users domain.UserRepository, Collateralrepo domain.CollateralRepository, Logrepo domain.LogRepository, Unitofwork domain.UnitOfWork, Events domain.EventBus, products map[string]domain.LoanProduct, timeout time.Duration) domain.LoanUsecase {
 	return &LoanUsecase{
 		UserRepo:       Userrepo,
+		Users:          Users,
+		Collateral:     Collateralrepo,
+		LogRepo:        Logrepo,
+		UnitOfWork:     Unitofwork,
+		Events:         Events,
+		Products:       products,
 		contextTimeout: timeout,
 	}
 
 }
 
+// product returns the product a loan was booked under, loans without one fall back to the default product
+func (luse *LoanUsecase) product(name string) domain.LoanProduct {
+	if product, ok := luse.Products[name]; ok {
+		return product
+	}
+	return luse.Products[domain.DefaultProduct]
+}
+
+// how long a payoff quote for the current day can be referenced by a payment
+const payoffQuoteValidity = 30 * time.Minute
+
+// quoteExpiry is when a payoff quote stops being accepted. A quote for today is accepted for a short while,
+// a quote for a later date through the end of that date, the only day its total holds.
+// Neither outlives the end of its UTC day, as a payment only settles a quote for the day it is made.
+func quoteExpiry(date, now time.Time) time.Time {
+	day := date.UTC().Truncate(24 * time.Hour)
+	end := day.Add(24 * time.Hour)
+	if day.After(now) {
+		return end
+	}
+	if expiry := now.Add(payoffQuoteValidity); expiry.Before(end) {
+		return expiry
+	}
+	return end
+}
+
+// the days-past-due bands of the aging report
+var agingBuckets = []domain.AgingBucket{
+	{Label: "1-30", MinDPD: 1, MaxDPD: 30},
+	{Label: "31-60", MinDPD: 31, MaxDPD: 60},
+	{Label: "61-90", MinDPD: 61, MaxDPD: 90},
+	{Label: "90+", MinDPD: 91, MaxDPD: -1},
+}
+
+// assess applies penalties to an approved loan, refreshes its standing and reports whether anything changed
+func (luse *LoanUsecase) assess(loan *domain.Loan, asOf time.Time) bool {
+	if loan.Status != "approved" {
+		return false
+	}
+	product := luse.product(loan.Product)
+	penalized := assessPenalties(loan, product, asOf)
+	return refreshStanding(loan, product, asOf) || penalized
+}
+
+// auditLoan records the changes made to a loan since the snapshot taken before them
+func (luse *LoanUsecase) auditLoan(c context.Context, action, userid string, loan *domain.Loan, before map[string]interface{}, note string) error {
+	return audit(c, luse.LogRepo, userid, domain.Log{
+		Action:     action,
+		TargetType: domain.TargetLoan,
+		TargetID:   loan.ID,
+		Changes:    diff(before, snapshot(loan)),
+		Note:       note,
+	})
+}
+
+// publish publishes the events of a change in the unit of work it is made in
+func (luse *LoanUsecase) publish(c context.Context, events []domain.Event) error {
+	for _, event := range events {
+		if err := luse.Events.Publish(c, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveLoan stores a loan together with the audit entry of what changed since the snapshot
+// and the events of the change, none of them is kept without the others.
+// The loan is saved over the version it was read at, a replayed unit starts from that version again
+// so a loan changed by someone else in the meantime is refused rather than overwritten.
+func (luse *LoanUsecase) saveLoan(c context.Context, action, userid string, loan *domain.Loan, before map[string]interface{}, note string, events ...domain.Event) error {
+	version, updatedAt := loan.Version, loan.UpdatedAt
+	return luse.UnitOfWork.Do(c, func(c context.Context) error {
+		loan.Version, loan.UpdatedAt = version, updatedAt
+		if err := luse.UserRepo.UpdateLoan(c, loan); err != nil {
+			return err
+		}
+		if err := luse.auditLoan(c, action, userid, loan, before, note); err != nil {
+			return err
+		}
+		return luse.publish(c, events)
+	})
+}
+
+// saveAssessment assesses a loan and stores the result, the system is recorded as the actor
+func (luse *LoanUsecase) saveAssessment(c context.Context, loan *domain.Loan, asOf time.Time) error {
+	before := snapshot(loan)
+	standing := loan.Delinquency
+	if !luse.assess(loan, asOf) {
+		return nil
+	}
+
+	if loan.Delinquency == standing {
+		return luse.saveLoan(c, domain.ActionLoanAssessed, "", loan, before, "")
+	}
+
+	return luse.saveLoan(c, domain.ActionLoanAssessed, "", loan, before, "Loan marked "+loan.Delinquency, &domain.LoanDelinquencyChanged{
+		LoanID:      loan.ID,
+		UserID:      loan.UserID,
+		From:        standing,
+		To:          loan.Delinquency,
+		DaysPastDue: loan.DaysPastDue,
+	})
+}
+
 func (luse *LoanUsecase) ApplyForLoan(c context.Context, loan *domain.Loan, userid string) error {
 	_, cancel := context.WithTimeout(c, luse.contextTimeout)
 	defer cancel()
-	return luse.UserRepo.ApplyForLoan(loan, userid)
+
+	if loan.Product == "" {
+		loan.Product = domain.DefaultProduct
+	}
+	if _, ok := luse.Products[loan.Product]; !ok {
+		return errors.New("Invalid loan product")
+	}
+
+	// loans are only lent to verified identities
+	borrower, err := luse.Users.UserProfile(userid)
+	if err != nil {
+		return err
+	}
+	if borrower.KYCStatus != domain.KYCApproved {
+		return errors.New("KYC must be approved before applying for a loan")
+	}
+
+	if err := luse.nameParties(loan, borrower); err != nil {
+		return err
+	}
+
+	return luse.UnitOfWork.Do(c, func(c context.Context) error {
+		if err := luse.UserRepo.ApplyForLoan(c, loan, userid); err != nil {
+			return err
+		}
+		if err := luse.auditLoan(c, domain.ActionLoanApplied, userid, loan, nil, ""); err != nil {
+			return err
+		}
+		events := []domain.Event{&domain.LoanApplied{
+			LoanID:   loan.ID,
+			UserID:   loan.UserID,
+			Amount:   loan.Amount,
+			Duration: loan.Duration,
+			Product:  loan.Product,
+		}}
+		for _, party := range loan.Parties {
+			events = append(events, &domain.LoanPartyInvited{
+				LoanID:         loan.ID,
+				UserID:         loan.UserID,
+				PartyID:        party.ID,
+				PartyUserID:    party.UserID,
+				Email:          party.Email,
+				Role:           party.Role,
+				LiabilityShare: party.LiabilityShare,
+				Amount:         loan.Amount,
+				Duration:       loan.Duration,
+			})
+		}
+		return luse.publish(c, events)
+	})
+}
+
+// nameParties checks the guarantors and co-borrowers named on an application and invites them.
+// People named by email are linked to their account when they have one. A guarantor stands behind
+// the whole loan unless a share is given, co-borrowers must be given one and leave a share to the borrower.
+func (luse *LoanUsecase) nameParties(loan *domain.Loan, borrower domain.User) error {
+	now := time.Now()
+	named := map[string]bool{}
+	coBorrowed := 0.0
+
+	for i := range loan.Parties {
+		party := &loan.Parties[i]
+		party.Email = strings.ToLower(strings.TrimSpace(party.Email))
+
+		if party.Role != domain.PartyGuarantor && party.Role != domain.PartyCoBorrower {
+			return errors.New("Invalid party role")
+		}
+
+		switch {
+		case !party.UserID.IsZero():
+			if _, err := luse.Users.UserProfile(party.UserID.Hex()); err != nil {
+				return errors.New("Guarantor or co-borrower not found")
+			}
+			party.Email = ""
+		case party.Email != "":
+			// an account only stands for the address once it was verified, until then the invitation stays on the email
+			if user, err := luse.Users.UserByEmail(party.Email); err == nil && user.IsVerified {
+				party.UserID = user.ID
+				party.Email = ""
+			}
+		default:
+			return errors.New("Guarantors and co-borrowers need a user ID or an email")
+		}
+
+		if (!party.UserID.IsZero() && party.UserID == borrower.ID) || (party.Email != "" && strings.EqualFold(party.Email, borrower.Email)) {
+			return errors.New("The borrower can't be named as a guarantor or co-borrower")
+		}
+
+		key := party.Email
+		if !party.UserID.IsZero() {
+			key = party.UserID.Hex()
+		}
+		if named[key] {
+			return errors.New("A person can only be named once on a loan")
+		}
+		named[key] = true
+
+		if party.Role == domain.PartyGuarantor && party.LiabilityShare == 0 {
+			party.LiabilityShare = 1
+		}
+		if party.LiabilityShare <= 0 || party.LiabilityShare > 1 {
+			return errors.New("Liability share must be between 0 and 1")
+		}
+		if party.Role == domain.PartyCoBorrower {
+			coBorrowed += party.LiabilityShare
+		}
+
+		party.ID = primitive.NewObjectID()
+		party.Status = domain.PartyInvited
+		party.InvitedAt = now
+		party.RespondedAt = nil
+	}
+
+	if coBorrowed >= 1 {
+		return errors.New("Co-borrowers can't owe the whole loan, the borrower keeps a share")
+	}
+
+	return nil
 }
 
+// checkLoanToValue makes sure a loan under a secured product is covered by enough collateral to be approved
+func (luse *LoanUsecase) checkLoanToValue(loan domain.Loan) error {
+	maxLTV := luse.product(loan.Product).MaxLTV
+	if maxLTV <= 0 {
+		return nil
+	}
+
+	collateral, err := luse.Collateral.LoanCollateral(loan.ID.Hex())
+	if err != nil {
+		return err
+	}
+	if len(collateral) == 0 {
+		return errors.New("The loan product requires collateral")
+	}
+	// the loan isn't approved yet, an active lien was placed for another loan that already counts on the value
+	for _, item := range collateral {
+		if item.LienStatus == domain.LienActive {
+			return errors.New("Collateral already secures another loan")
+		}
+	}
+
+	if ltv := loanToValue(loan.Amount, collateral); ltv > maxLTV {
+		return fmt.Errorf("Loan-to-value of %.2f exceeds the product maximum of %.2f", ltv, maxLTV)
+	}
+	return nil
+}
+
+// borrowerShare is the part of a loan its borrower owes, what the co-borrowers who didn't decline don't
+func borrowerShare(loan domain.Loan) float64 {
+	share := 1.0
+	for _, party := range loan.Parties {
+		if party.Role == domain.PartyCoBorrower && party.Status != domain.PartyDeclined {
+			share -= party.LiabilityShare
+		}
+	}
+	return math.Round(share*10000) / 10000
+}
+
+// partyOf finds the place of a user among the guarantors and co-borrowers of a loan,
+// by account or by the email they were invited at before they had one, once they verified it
+func partyOf(loan domain.Loan, user domain.User, partyID primitive.ObjectID) int {
+	for i, party := range loan.Parties {
+		if !partyID.IsZero() && party.ID != partyID {
+			continue
+		}
+		invited := party.UserID.IsZero() && party.Email != "" && user.IsVerified && strings.EqualFold(party.Email, user.Email)
+		if party.UserID == user.ID || invited {
+			return i
+		}
+	}
+	return -1
+}
+
+// LoanDetails shows a loan with its charges and delinquency assessed as of now.
+// The assessment is only shown, storing it is left to the loan_assessment job so a read never writes.
 func (luse *LoanUsecase) LoanDetails(c context.Context, loanID string, userid string) (domain.Loan, error) {
 	_, cancel := context.WithTimeout(c, luse.contextTimeout)
 	defer cancel()
-	return luse.UserRepo.LoanDetails(loanID, userid)
+
+	loan, err := luse.UserRepo.LoanDetails(loanID, userid)
+	if err != nil {
+		return loan, err
+	}
+
+	luse.assess(&loan, time.Now())
+
+	collateral, err := luse.Collateral.LoanCollateral(loanID)
+	if err != nil {
+		return loan, err
+	}
+	loan.Collateral = collateral
+	loan.LTV = loanToValue(loan.Amount, collateral)
+
+	return loan, nil
+}
+
+// UserLoans lists the loans a user borrows, co-borrows or guarantees, newest first, with their role and liability share.
+// Invitations the user declined are left out.
+func (luse *LoanUsecase) UserLoans(c context.Context, userid string) ([]domain.UserLoan, error) {
+	_, cancel := context.WithTimeout(c, luse.contextTimeout)
+	defer cancel()
+
+	user, err := luse.Users.UserProfile(userid)
+	if err != nil {
+		return nil, err
+	}
+
+	email := ""
+	if user.IsVerified {
+		email = user.Email
+	}
+
+	loans, err := luse.UserRepo.PartyLoans(userid, email)
+	if err != nil {
+		return nil, err
+	}
+
+	listed := []domain.UserLoan{}
+	for _, loan := range loans {
+		if loan.UserID == user.ID {
+			listed = append(listed, domain.UserLoan{Loan: loan, Role: domain.PartyBorrower, LiabilityShare: borrowerShare(loan)})
+			continue
+		}
+
+		i := partyOf(loan, user, primitive.NilObjectID)
+		if i < 0 || loan.Parties[i].Status == domain.PartyDeclined {
+			continue
+		}
+		listed = append(listed, domain.UserLoan{Loan: loan, Role: loan.Parties[i].Role, LiabilityShare: loan.Parties[i].LiabilityShare})
+	}
+
+	return listed, nil
+}
+
+// RespondToLoan records whether a guarantor or co-borrower accepts the loan they were named on.
+// The application moves to pending review once all of them accepted, a declined one stays waiting until it is rejected.
+func (luse *LoanUsecase) RespondToLoan(c context.Context, loanID, partyID string, accept bool, userid string) (domain.Loan, error) {
+	_, cancel := context.WithTimeout(c, luse.contextTimeout)
+	defer cancel()
+
+	loan, err := luse.UserRepo.GetLoan(loanID)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+
+	user, err := luse.Users.UserProfile(userid)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+
+	partyIDObj, _ := primitive.ObjectIDFromHex(partyID)
+	i := partyOf(loan, user, partyIDObj)
+	if partyIDObj.IsZero() || i < 0 {
+		return domain.Loan{}, errors.New("Invitation not found")
+	}
+
+	if loan.Status != domain.LoanAwaitingAcceptance {
+		return domain.Loan{}, errors.New("The loan is no longer waiting on its guarantors and co-borrowers")
+	}
+	if loan.Parties[i].Status != domain.PartyInvited {
+		return domain.Loan{}, errors.New("Invitation already answered")
+	}
+	if accept && user.KYCStatus != domain.KYCApproved {
+		return domain.Loan{}, errors.New("KYC must be approved before guaranteeing or co-borrowing a loan")
+	}
+
+	before := snapshot(loan)
+	now := time.Now()
+	party := &loan.Parties[i]
+	party.UserID = user.ID
+	party.Email = ""
+	party.Status = domain.PartyDeclined
+	if accept {
+		party.Status = domain.PartyAccepted
+	}
+	party.RespondedAt = &now
+
+	accepted := true
+	for _, other := range loan.Parties {
+		accepted = accepted && other.Status == domain.PartyAccepted
+	}
+	if accepted {
+		loan.Status = "pending"
+	}
+
+	note := strings.ReplaceAll(party.Role, "_", "-") + " " + party.Status
+	err = luse.saveLoan(c, domain.ActionLoanPartyResponded, userid, &loan, before, note, &domain.LoanPartyResponded{
+		LoanID:      loan.ID,
+		UserID:      loan.UserID,
+		PartyID:     party.ID,
+		PartyUserID: user.ID,
+		Role:        party.Role,
+		Status:      party.Status,
+	})
+	if err != nil {
+		return domain.Loan{}, err
+	}
+
+	return loan, nil
+}
+
+func (luse *LoanUsecase) ViewAllLoans(c context.Context, pgnum int, status, order string, dpd domain.DPDRange) ([]domain.Loan, int, error) {
+	_, cancel := context.WithTimeout(c, luse.contextTimeout)
+	defer cancel()
+	return luse.UserRepo.ViewAllLoans(pgnum, status, order, dpd)
+}
+
+// ApproveRejectLoan approves or rejects a loan application. A reason is required to reject one, it is shown to the borrower
+// and recorded in the audit log. The reason given for an approval is only recorded in the audit log.
+func (luse *LoanUsecase) ApproveRejectLoan(c context.Context, loanID string, status, reason, userid string) error {
+	_, cancel := context.WithTimeout(c, luse.contextTimeout)
+	defer cancel()
+
+	reason = strings.TrimSpace(reason)
+	if status != "approved" && reason == "" {
+		return errors.New("A reason is required to reject a loan")
+	}
+
+	loan, err := luse.UserRepo.GetLoan(loanID)
+	if err != nil {
+		return err
+	}
+	if loan.Status != "pending" && loan.Status != domain.LoanAwaitingAcceptance {
+		return errors.New("Loan already processed")
+	}
+	if status == "approved" && loan.Status == domain.LoanAwaitingAcceptance {
+		return errors.New("All guarantors and co-borrowers must accept before the loan can be reviewed")
+	}
+	if status == "approved" {
+		if err := luse.checkLoanToValue(loan); err != nil {
+			return err
+		}
+	}
+	before := snapshot(loan)
+	adminID, _ := primitive.ObjectIDFromHex(userid)
+
+	var rejection *domain.Rejection
+	if status != "approved" {
+		rejection = &domain.Rejection{Reason: reason, RejectedBy: adminID, RejectedAt: time.Now()}
+	}
+
+	version := loan.Version
+	return luse.UnitOfWork.Do(c, func(c context.Context) error {
+		if err := luse.UserRepo.ApproveRejectLoan(c, loanID, status, rejection); err != nil {
+			return err
+		}
+		// the decision is a new version of the loan, the schedule is saved over it
+		loan.Version = version + 1
+		loan.Status = status
+		loan.Rejection = rejection
+
+		if status != "approved" {
+			if err := luse.auditLoan(c, domain.ActionLoanRejected, userid, &loan, before, reason); err != nil {
+				return err
+			}
+			return luse.Events.Publish(c, &domain.LoanRejected{LoanID: loan.ID, UserID: loan.UserID, RejectedBy: adminID, Reason: reason})
+		}
+
+		loan.Schedule = buildSchedule(loan.Amount, loan.Interest, loan.Duration, time.Now())
+		loan.Delinquency = domain.DelinquencyCurrent
+		loan.DaysPastDue = 0
+
+		if err := luse.UserRepo.UpdateLoan(c, &loan); err != nil {
+			return err
+		}
+
+		if err := luse.auditLoan(c, domain.ActionLoanApproved, userid, &loan, before, reason); err != nil {
+			return err
+		}
+		return luse.Events.Publish(c, &domain.LoanApproved{LoanID: loan.ID, UserID: loan.UserID, Amount: loan.Amount, ApprovedBy: adminID})
+	})
+}
+
+func (luse *LoanUsecase) PayoffQuote(c context.Context, loanID string, date time.Time, userid string) (domain.PayoffQuote, error) {
+	_, cancel := context.WithTimeout(c, luse.contextTimeout)
+	defer cancel()
+
+	now := time.Now()
+	if date.Before(now.Truncate(24 * time.Hour)) {
+		return domain.PayoffQuote{}, errors.New("Quote date cannot be in the past")
+	}
+
+	loan, err := luse.UserRepo.LoanDetails(loanID, userid)
+	if err != nil {
+		return domain.PayoffQuote{}, errors.New("Loan not found")
+	}
+
+	if loan.Status != "approved" {
+		return domain.PayoffQuote{}, errors.New("Loan is not being repaid")
+	}
+
+	before := snapshot(loan)
+	luse.assess(&loan, now)
+
+	quote := payoffQuote(&loan, luse.product(loan.Product), date)
+	quote.ID = primitive.NewObjectID()
+	quote.ExpiresAt = quoteExpiry(date, now)
+	loan.PayoffQuote = &quote
+
+	if err := luse.saveLoan(c, domain.ActionLoanPayoffQuoted, userid, &loan, before, ""); err != nil {
+		return domain.PayoffQuote{}, err
+	}
+
+	return quote, nil
+}
+
+func (luse *LoanUsecase) MakePayment(c context.Context, loanID string, amount float64, quoteID, userid string) (domain.Payment, error) {
+	_, cancel := context.WithTimeout(c, luse.contextTimeout)
+	defer cancel()
+
+	loan, err := luse.UserRepo.LoanDetails(loanID, userid)
+	if err != nil {
+		return domain.Payment{}, errors.New("Loan not found")
+	}
+
+	if loan.Status != "approved" {
+		return domain.Payment{}, errors.New("Loan is not being repaid")
+	}
+
+	// bring penalties up to date so the payment settles them before the installments
+	before := snapshot(loan)
+	now := time.Now()
+	product := luse.product(loan.Product)
+	luse.assess(&loan, now)
+
+	if quoteID != "" {
+		if err := settleQuote(&loan, product, quoteID, amount, now); err != nil {
+			return domain.Payment{}, err
+		}
+	}
+
+	if err := allocatePayment(&loan, amount, now); err != nil {
+		return domain.Payment{}, err
+	}
+	refreshStanding(&loan, product, now)
+
+	payment := domain.Payment{
+		ID:     primitive.NewObjectID(),
+		Amount: roundCents(amount),
+		PaidAt: now,
+	}
+	loan.Payments = append(loan.Payments, payment)
+
+	action := domain.ActionLoanPayment
+	events := []domain.Event{&domain.PaymentReceived{
+		LoanID:      loan.ID,
+		UserID:      loan.UserID,
+		PaymentID:   payment.ID,
+		Amount:      payment.Amount,
+		Outstanding: totalDue(&loan),
+	}}
+	if totalDue(&loan) == 0 {
+		loan.Status = "paid_off"
+		loan.PayoffQuote = nil
+		action = domain.ActionLoanPaidOff
+		events = append(events, &domain.LoanPaidOff{LoanID: loan.ID, UserID: loan.UserID})
+	}
+
+	if err := luse.saveLoan(c, action, userid, &loan, before, "", events...); err != nil {
+		return domain.Payment{}, err
+	}
+
+	return payment, nil
+}
+
+// settleQuote checks that a payment honours the loan's payoff quote and settles the loan on the quoted terms
+func settleQuote(loan *domain.Loan, product domain.LoanProduct, quoteID string, amount float64, now time.Time) error {
+	quote := loan.PayoffQuote
+	if quote == nil || quote.ID.Hex() != quoteID {
+		return errors.New("Payoff quote not found")
+	}
+
+	if now.After(quote.ExpiresAt) {
+		return errors.New("Payoff quote has expired")
+	}
+
+	if quote.Date.UTC().Format("2006-01-02") != now.UTC().Format("2006-01-02") {
+		return errors.New("Payoff quote is for a different date")
+	}
+
+	if roundCents(amount) != quote.Total {
+		return errors.New("Payment must match the payoff quote total")
+	}
+
+	settleForPayoff(loan, product, quote.Date)
+	if totalDue(loan) != quote.Total {
+		return errors.New("Payoff quote is no longer valid")
+	}
+
+	return nil
+}
+
+func (luse *LoanUsecase) AssessOverdueLoans(c context.Context) error {
+	_, cancel := context.WithTimeout(c, luse.contextTimeout)
+	defer cancel()
+
+	loans, err := luse.UserRepo.ActiveLoans()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range loans {
+		// a cancelled run stops between loans, so it doesn't keep going beside the worker the job is handed to next
+		if err := c.Err(); err != nil {
+			return err
+		}
+		if err := luse.saveAssessment(c, &loans[i], now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RemindUpcomingPayments reminds borrowers of installments coming due the given numbers of days ahead and again on the day they are due.
+// Each reminder is sent once, the installment keeps the days before its due date it was sent at.
+// A reminder missed while no reminders were sent is replaced by the next one due rather than sent late.
+func (luse *LoanUsecase) RemindUpcomingPayments(c context.Context, days []int) (int, error) {
+	_, cancel := context.WithTimeout(c, luse.contextTimeout)
+	defer cancel()
+
+	loans, err := luse.UserRepo.ActiveLoans()
+	if err != nil {
+		return 0, err
+	}
+
+	// the closest reminder comes first, the one on the due date always exists
+	leads := append([]int{0}, days...)
+	sort.Ints(leads)
+
+	now := time.Now()
+	reminded := 0
+	for i := range loans {
+		if err := c.Err(); err != nil {
+			return reminded, err
+		}
+		loan := &loans[i]
+		before := snapshot(loan)
+
+		events := []domain.Event{}
+		for j := range loan.Schedule {
+			installment := &loan.Schedule[j]
+			due := installmentDue(*installment)
+			if due <= 0 {
+				continue
+			}
+
+			left := daysUntilDue(*installment, now)
+			reminder := -1
+			for _, lead := range leads {
+				if left >= 0 && left <= lead {
+					reminder = lead
+					break
+				}
+			}
+			if reminder < 0 || containsDay(installment.Reminders, reminder) {
+				continue
+			}
+
+			installment.Reminders = append(installment.Reminders, reminder)
+			events = append(events, &domain.PaymentDue{
+				LoanID:       loan.ID,
+				UserID:       loan.UserID,
+				Installment:  installment.Number,
+				DueDate:      installment.DueDate,
+				Amount:       due,
+				DaysUntilDue: left,
+			})
+		}
+
+		if len(events) == 0 {
+			continue
+		}
+
+		if err := luse.saveLoan(c, domain.ActionLoanReminded, "", loan, before, "", events...); err != nil {
+			return reminded, err
+		}
+		reminded += len(events)
+	}
+
+	return reminded, nil
+}
+
+// NoticeOverduePayments sends escalating notices about the oldest overdue installment of each loan once it is the given numbers of days past due.
+// Each notice is sent once, only the latest one reached is sent when several are due at the same time.
+func (luse *LoanUsecase) NoticeOverduePayments(c context.Context, days []int) (int, error) {
+	_, cancel := context.WithTimeout(c, luse.contextTimeout)
+	defer cancel()
+
+	loans, err := luse.UserRepo.ActiveLoans()
+	if err != nil {
+		return 0, err
+	}
+
+	steps := append([]int{}, days...)
+	sort.Ints(steps)
+
+	now := time.Now()
+	noticed := 0
+	for i := range loans {
+		if err := c.Err(); err != nil {
+			return noticed, err
+		}
+		loan := &loans[i]
+		before := snapshot(loan)
+
+		for j := range loan.Schedule {
+			installment := &loan.Schedule[j]
+			due := installmentDue(*installment)
+			if due <= 0 {
+				continue
+			}
+
+			late := -daysUntilDue(*installment, now)
+			notice := 0
+			for k, step := range steps {
+				if late >= step {
+					notice = k + 1
+				}
+			}
+			if notice == 0 || containsDay(installment.Notices, steps[notice-1]) {
+				break
+			}
+
+			installment.Notices = append(installment.Notices, steps[notice-1])
+			err := luse.saveLoan(c, domain.ActionLoanOverdueNotice, "", loan, before, fmt.Sprintf("Overdue notice %d sent", notice), &domain.PaymentOverdue{
+				LoanID:      loan.ID,
+				UserID:      loan.UserID,
+				Installment: installment.Number,
+				DueDate:     installment.DueDate,
+				Amount:      due,
+				DaysPastDue: late,
+				Notice:      notice,
+				Final:       notice == len(steps),
+			})
+			if err != nil {
+				return noticed, err
+			}
+			noticed++
+			break
+		}
+	}
+
+	return noticed, nil
+}
+
+// containsDay reports whether a reminder or notice was already sent at the given number of days
+func containsDay(sent []int, day int) bool {
+	for _, at := range sent {
+		if at == day {
+			return true
+		}
+	}
+	return false
+}
+
+func (luse *LoanUsecase) AgingReport(c context.Context) ([]domain.AgingBucket, error) {
+	_, cancel := context.WithTimeout(c, luse.contextTimeout)
+	defer cancel()
+
+	loans, err := luse.UserRepo.ActiveLoans()
+	if err != nil {
+		return nil, err
+	}
+
+	report := make([]domain.AgingBucket, len(agingBuckets))
+	copy(report, agingBuckets)
+
+	now := time.Now()
+	for i := range loans {
+		dpd := daysPastDue(&loans[i], now)
+		for j := range report {
+			if dpd < report[j].MinDPD || (report[j].MaxDPD >= 0 && dpd > report[j].MaxDPD) {
+				continue
+			}
+			report[j].Count++
+			report[j].Outstanding = roundCents(report[j].Outstanding + totalDue(&loans[i]))
+			break
+		}
+	}
+
+	return report, nil
+}
+
+func (luse *LoanUsecase) WaiveCharge(c context.Context, loanID, chargeID, reason, userid string) error {
+	_, cancel := context.WithTimeout(c, luse.contextTimeout)
+	defer cancel()
+
+	if strings.TrimSpace(reason) == "" {
+		return errors.New("A reason is required to waive a charge")
+	}
+
+	loan, err := luse.UserRepo.GetLoan(loanID)
+	if err != nil {
+		return err
+	}
+
+	chargeIDObj, _ := primitive.ObjectIDFromHex(chargeID)
+	adminID, _ := primitive.ObjectIDFromHex(userid)
+	before := snapshot(loan)
+
+	for i := range loan.Charges {
+		if loan.Charges[i].ID != chargeIDObj {
+			continue
+		}
+
+		if loan.Charges[i].Waived {
+			return errors.New("Charge already waived")
+		}
+
+		loan.Charges[i].Waived = true
+		loan.Charges[i].WaivedBy = adminID
+		loan.Charges[i].WaiveReason = reason
+		loan.Charges[i].WaivedAt = time.Now()
+
+		return luse.saveLoan(c, domain.ActionLoanChargeWaived, userid, &loan, before, "Waived a "+loan.Charges[i].Type+" charge: "+reason)
+	}
+
+	return errors.New("Charge not found")
+}
+
+func (luse *LoanUsecase) RestructureLoan(c context.Context, loanID string, terms domain.Restructure, userid string) (domain.Loan, error) {
+	_, cancel := context.WithTimeout(c, luse.contextTimeout)
+	defer cancel()
+
+	if strings.TrimSpace(terms.Reason) == "" {
+		return domain.Loan{}, errors.New("A reason is required to restructure a loan")
+	}
+
+	if terms.ExtendMonths < 0 || terms.HolidayMonths < 0 || (terms.Interest != nil && *terms.Interest < 0) {
+		return domain.Loan{}, errors.New("Invalid restructuring terms")
+	}
+
+	if terms.ExtendMonths == 0 && terms.HolidayMonths == 0 && terms.Interest == nil && !terms.CapitalizeArrears {
+		return domain.Loan{}, errors.New("No restructuring terms given")
+	}
+
+	loan, err := luse.UserRepo.GetLoan(loanID)
+	if err != nil {
+		return loan, err
+	}
+
+	if loan.Status != "approved" {
+		return domain.Loan{}, errors.New("Loan is not being repaid")
+	}
+
+	// settle penalties up to today so they are carried into the new terms
+	before := snapshot(loan)
+	now := time.Now()
+	luse.assess(&loan, now)
+
+	adminID, _ := primitive.ObjectIDFromHex(userid)
+	restructureSchedule(&loan, terms, adminID, now)
+
+	if totalDue(&loan) == 0 {
+		return domain.Loan{}, errors.New("Loan has nothing left to restructure")
+	}
+	refreshStanding(&loan, luse.product(loan.Product), now)
+
+	restructured := &domain.LoanRestructured{LoanID: loan.ID, UserID: loan.UserID, Reason: terms.Reason}
+	if err := luse.saveLoan(c, domain.ActionLoanRestructured, userid, &loan, before, terms.Reason, restructured); err != nil {
+		return domain.Loan{}, err
+	}
+
+	return loan, nil
+}
+
+// WriteOffLoan requests taking the balance of a defaulted loan off the book.
+// The loan is only written off once another admin approves the request with DecideWriteOff.
+func (luse *LoanUsecase) WriteOffLoan(c context.Context, loanID, reason, userid string) error {
+	_, cancel := context.WithTimeout(c, luse.contextTimeout)
+	defer cancel()
+
+	if strings.TrimSpace(reason) == "" {
+		return errors.New("A reason is required to write off a loan")
+	}
+
+	loan, err := luse.UserRepo.GetLoan(loanID)
+	if err != nil {
+		return err
+	}
+
+	// bring the balance up to date so the approver sees what would be taken off the book
+	before := snapshot(loan)
+	now := time.Now()
+	luse.assess(&loan, now)
+
+	if loan.Status != "approved" || loan.Delinquency != domain.DelinquencyDefaulted {
+		return errors.New("Only defaulted loans can be written off")
+	}
+	if loan.WriteOff != nil {
+		return errors.New("A write-off of this loan is already awaiting approval")
+	}
+
+	requester, _ := primitive.ObjectIDFromHex(userid)
+	loan.WriteOff = &domain.WriteOff{
+		Amount:      totalDue(&loan),
+		Reason:      reason,
+		RequestedBy: requester,
+		RequestedAt: now,
+	}
+
+	return luse.saveLoan(c, domain.ActionLoanWriteOffRequested, userid, &loan, before, reason)
+}
+
+// DecideWriteOff approves or declines the pending write-off of a loan. Only an admin other than the one who requested it
+// can approve it, any admin can decline it. The balance is brought up to date again when it is approved.
+func (luse *LoanUsecase) DecideWriteOff(c context.Context, loanID string, approve bool, userid string) error {
+	_, cancel := context.WithTimeout(c, luse.contextTimeout)
+	defer cancel()
+
+	loan, err := luse.UserRepo.GetLoan(loanID)
+	if err != nil {
+		return err
+	}
+
+	if loan.Status != "approved" || loan.WriteOff == nil {
+		return errors.New("Loan has no write-off awaiting approval")
+	}
+
+	before := snapshot(loan)
+	if !approve {
+		reason := loan.WriteOff.Reason
+		loan.WriteOff = nil
+		return luse.saveLoan(c, domain.ActionLoanWriteOffDeclined, userid, &loan, before, reason)
+	}
+
+	if loan.WriteOff.RequestedBy.Hex() == userid {
+		return errors.New("A write-off must be approved by another admin")
+	}
+
+	now := time.Now()
+	luse.assess(&loan, now)
+	if loan.Delinquency != domain.DelinquencyDefaulted {
+		return errors.New("Only defaulted loans can be written off")
+	}
+
+	approver, _ := primitive.ObjectIDFromHex(userid)
+	loan.Status = "written_off"
+	loan.PayoffQuote = nil
+	loan.WriteOff.Amount = totalDue(&loan)
+	loan.WriteOff.ApprovedBy = approver
+	loan.WriteOff.WrittenOffAt = now
+
+	writtenOff := &domain.LoanWrittenOff{LoanID: loan.ID, UserID: loan.UserID, Amount: loan.WriteOff.Amount}
+	return luse.saveLoan(c, domain.ActionLoanWrittenOff, userid, &loan, before, loan.WriteOff.Reason, writtenOff)
+}
+
+func (luse *LoanUsecase) PostRecovery(c context.Context, loanID string, amount float64, note, userid string) (domain.Recovery, error) {
+	_, cancel := context.WithTimeout(c, luse.contextTimeout)
+	defer cancel()
+
+	if amount <= 0 {
+		return domain.Recovery{}, errors.New("Invalid recovery amount")
+	}
+
+	loan, err := luse.UserRepo.GetLoan(loanID)
+	if err != nil {
+		return domain.Recovery{}, err
+	}
+
+	if loan.Status != "written_off" || loan.WriteOff == nil {
+		return domain.Recovery{}, errors.New("Recoveries can only be posted against written-off loans")
+	}
+
+	if roundCents(recovered(&loan)+amount) > loan.WriteOff.Amount {
+		return domain.Recovery{}, errors.New("Recovery exceeds the written-off balance")
+	}
+
+	before := snapshot(loan)
+	adminID, _ := primitive.ObjectIDFromHex(userid)
+	recovery := domain.Recovery{
+		ID:         primitive.NewObjectID(),
+		Amount:     roundCents(amount),
+		Note:       note,
+		PostedBy:   adminID,
+		ReceivedAt: time.Now(),
+	}
+	loan.Recoveries = append(loan.Recoveries, recovery)
+
+	if err := luse.saveLoan(c, domain.ActionLoanRecovery, userid, &loan, before, note); err != nil {
+		return domain.Recovery{}, err
+	}
+
+	return recovery, nil
 }
 
-func (luse *LoanUsecase) ViewAllLoans(c context.Context, pgnum int, status, order string) ([]domain.Loan, int, error) {
+func (luse *LoanUsecase) WriteOffReport(c context.Context) (domain.WriteOffReport, error) {
 	_, cancel := context.WithTimeout(c, luse.contextTimeout)
 	defer cancel()
-	return luse.UserRepo.ViewAllLoans(pgnum, status, order)
+
+	loans, err := luse.UserRepo.LoansByStatus("written_off")
+	if err != nil {
+		return domain.WriteOffReport{}, err
+	}
+
+	report := domain.WriteOffReport{Entries: []domain.WriteOffEntry{}}
+	for i := range loans {
+		if loans[i].WriteOff == nil {
+			continue
+		}
+
+		entry := domain.WriteOffEntry{
+			LoanID:       loans[i].ID,
+			UserID:       loans[i].UserID,
+			WrittenOff:   loans[i].WriteOff.Amount,
+			Recovered:    recovered(&loans[i]),
+			WrittenOffAt: loans[i].WriteOff.WrittenOffAt,
+		}
+
+		report.Loans++
+		report.WrittenOff = roundCents(report.WrittenOff + entry.WrittenOff)
+		report.Recovered = roundCents(report.Recovered + entry.Recovered)
+		report.Entries = append(report.Entries, entry)
+	}
+	report.Outstanding = roundCents(report.WrittenOff - report.Recovered)
+
+	return report, nil
 }
 
-func (luse *LoanUsecase) ApproveRejectLoan(c context.Context, loanID string, status string, userid string) error {
+func (luse *LoanUsecase) DeleteLoan(c context.Context, loanID, reason, userid string) error {
 	_, cancel := context.WithTimeout(c, luse.contextTimeout)
 	defer cancel()
-	return luse.UserRepo.ApproveRejectLoan(loanID, status, userid)
+
+	loan, err := luse.UserRepo.GetLoan(loanID)
+	if err != nil {
+		return err
+	}
+
+	before := snapshot(loan)
+	adminID, _ := primitive.ObjectIDFromHex(userid)
+
+	return luse.UnitOfWork.Do(c, func(c context.Context) error {
+		if err := luse.UserRepo.DeleteLoan(c, loanID, reason, userid); err != nil {
+			return err
+		}
+
+		loan.Deletion = &domain.Deletion{DeletedAt: time.Now(), DeletedBy: adminID, Reason: reason}
+		return luse.auditLoan(c, domain.ActionLoanDeleted, userid, &loan, before, reason)
+	})
 }
 
-func (luse *LoanUsecase) DeleteLoan(c context.Context, loanID string, userid string) error {
+func (luse *LoanUsecase) RestoreLoan(c context.Context, loanID string, userid string) error {
 	_, cancel := context.WithTimeout(c, luse.contextTimeout)
 	defer cancel()
-	return luse.UserRepo.DeleteLoan(loanID, userid)
+
+	loanIDObj, _ := primitive.ObjectIDFromHex(loanID)
+
+	return luse.UnitOfWork.Do(c, func(c context.Context) error {
+		if err := luse.UserRepo.RestoreLoan(c, loanID); err != nil {
+			return err
+		}
+
+		return audit(c, luse.LogRepo, userid, domain.Log{
+			Action:     domain.ActionLoanRestored,
+			TargetType: domain.TargetLoan,
+			TargetID:   loanIDObj,
+		})
+	})
 }
 
-func (luse *LoanUsecase) ViewLogs(c context.Context) ([]domain.Log, error) {
+func (luse *LoanUsecase) PurgeDeletedLoans(c context.Context, retention time.Duration) (int, error) {
 	_, cancel := context.WithTimeout(c, luse.contextTimeout)
 	defer cancel()
-	return luse.UserRepo.ViewLogs()
+
+	purged := 0
+	err := luse.UnitOfWork.Do(c, func(c context.Context) error {
+		var err error
+		purged, err = luse.UserRepo.PurgeDeletedLoans(c, time.Now().Add(-retention))
+		if err != nil || purged == 0 {
+			return err
+		}
+
+		return audit(c, luse.LogRepo, "", domain.Log{
+			Action:     domain.ActionLoanPurged,
+			TargetType: domain.TargetLoan,
+			Note:       fmt.Sprintf("Purged %d deleted loans", purged),
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
 }
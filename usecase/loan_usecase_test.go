@@ -2,12 +2,14 @@ package usecase_test
 
 import (
 	"context"
+	"errors"
 	"loan_tracker_api/domain"
 	"loan_tracker_api/mocks"
 	"loan_tracker_api/usecase"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -15,12 +17,44 @@ import (
 type LoanUsecaseTestSuite struct {
 	suite.Suite
 	mockLoanRepository *mocks.LoanRepository
+	mockUserRepository *mocks.UserRepository
+	mockCollateral     *mocks.CollateralRepository
+	mockLogRepository  *mocks.LogRepository
+	mockUnitOfWork     *mocks.UnitOfWork
+	mockEventBus       *mocks.EventBus
 	LoanUsecase        domain.LoanUsecase
 }
 
 func (s *LoanUsecaseTestSuite) SetupTest() {
 	s.mockLoanRepository = new(mocks.LoanRepository)
-	s.LoanUsecase = usecase.NewLoanUsecase(s.mockLoanRepository, time.Second*2)
+	s.mockUserRepository = new(mocks.UserRepository)
+	s.mockCollateral = new(mocks.CollateralRepository)
+	s.mockCollateral.On("LoanCollateral", "testloanid").Return(nil, nil).Maybe()
+	s.mockLogRepository = new(mocks.LogRepository)
+	s.mockLogRepository.On("AddLog", mock.Anything, mock.Anything).Return(nil).Maybe()
+	// units of work run their writes directly, the way they do on a standalone server
+	s.mockUnitOfWork = new(mocks.UnitOfWork)
+	s.mockUnitOfWork.On("Do", mock.Anything, mock.Anything).Return(func(c context.Context, work func(context.Context) error) error {
+		return work(c)
+	}).Maybe()
+	s.mockEventBus = new(mocks.EventBus)
+	s.mockEventBus.On("Publish", mock.Anything, mock.Anything).Return(nil).Maybe()
+	products := map[string]domain.LoanProduct{
+		domain.DefaultProduct: {
+			Name:                domain.DefaultProduct,
+			GracePeriodDays:     5,
+			LateFee:             25,
+			PenaltyRate:         0.1,
+			DelinquentAfterDays: 1,
+			DefaultAfterDays:    90,
+			PrepaymentRate:      0.01,
+		},
+		"secured": {
+			Name:   "secured",
+			MaxLTV: 0.8,
+		},
+	}
+	s.LoanUsecase = usecase.NewLoanUsecase(s.mockLoanRepository, s.mockUserRepository, s.mockCollateral, s.mockLogRepository, s.mockUnitOfWork, s.mockEventBus, products, time.Second*2)
 }
 
 func (s *LoanUsecaseTestSuite) TearDownTest() {
@@ -33,11 +67,187 @@ func (s *LoanUsecaseTestSuite) TestApplyForLoan() {
 		Duration: 12,
 	}
 
-	s.mockLoanRepository.On("ApplyForLoan", &expectedLoan, "testuserid").Return(nil).Once()
+	s.mockUserRepository.On("UserProfile", "testuserid").Return(domain.User{KYCStatus: domain.KYCApproved}, nil).Once()
+	s.mockLoanRepository.On("ApplyForLoan", mock.Anything, &expectedLoan, "testuserid").Return(nil).Once()
 
 	err := s.LoanUsecase.ApplyForLoan(context.Background(), &expectedLoan, "testuserid")
 
 	s.NoError(err)
+	s.Equal(domain.DefaultProduct, expectedLoan.Product)
+}
+
+func (s *LoanUsecaseTestSuite) TestApplyForLoanWithoutApprovedKYC() {
+	loan := domain.Loan{
+		Amount:   100000,
+		Duration: 12,
+	}
+	s.mockUserRepository.On("UserProfile", "testuserid").Return(domain.User{KYCStatus: domain.KYCPending}, nil).Once()
+
+	err := s.LoanUsecase.ApplyForLoan(context.Background(), &loan, "testuserid")
+
+	s.EqualError(err, "KYC must be approved before applying for a loan")
+	s.mockLoanRepository.AssertNotCalled(s.T(), "ApplyForLoan", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestApplyForLoanUnknownProduct() {
+	loan := domain.Loan{
+		Amount:   100000,
+		Duration: 12,
+		Product:  "unknown",
+	}
+
+	err := s.LoanUsecase.ApplyForLoan(context.Background(), &loan, "testuserid")
+
+	s.Error(err)
+	s.mockLoanRepository.AssertNotCalled(s.T(), "ApplyForLoan", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestApplyForLoanWithParties() {
+	borrower := domain.User{ID: primitive.NewObjectID(), Email: "borrower@gmail.com", KYCStatus: domain.KYCApproved}
+	coBorrower := domain.User{ID: primitive.NewObjectID(), Email: "coborrower@gmail.com", IsVerified: true}
+	loan := domain.Loan{
+		Amount:   100000,
+		Duration: 12,
+		Parties: []domain.LoanParty{
+			{Role: domain.PartyCoBorrower, Email: " coborrower@gmail.com ", LiabilityShare: 0.4},
+			{Role: domain.PartyGuarantor, Email: "Guarantor@Gmail.com"},
+		},
+	}
+	s.mockUserRepository.On("UserProfile", borrower.ID.Hex()).Return(borrower, nil).Once()
+	s.mockUserRepository.On("UserByEmail", "coborrower@gmail.com").Return(coBorrower, nil).Once()
+	// an account that never verified the address doesn't take the invitation
+	s.mockUserRepository.On("UserByEmail", "guarantor@gmail.com").Return(domain.User{ID: primitive.NewObjectID()}, nil).Once()
+	s.mockLoanRepository.On("ApplyForLoan", mock.Anything, &loan, borrower.ID.Hex()).Return(nil).Once()
+
+	var invited []*domain.LoanPartyInvited
+	events := new(mocks.EventBus)
+	events.On("Publish", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		if event, ok := args.Get(1).(*domain.LoanPartyInvited); ok {
+			invited = append(invited, event)
+		}
+	}).Return(nil)
+	s.LoanUsecase = usecase.NewLoanUsecase(s.mockLoanRepository, s.mockUserRepository, s.mockCollateral, s.mockLogRepository, s.mockUnitOfWork, events, map[string]domain.LoanProduct{domain.DefaultProduct: {}}, time.Second*2)
+
+	err := s.LoanUsecase.ApplyForLoan(context.Background(), &loan, borrower.ID.Hex())
+
+	s.NoError(err)
+	// the co-borrower has an account, the guarantor is invited at their email and stands behind the whole loan
+	s.Equal(coBorrower.ID, loan.Parties[0].UserID)
+	s.Empty(loan.Parties[0].Email)
+	s.Equal(domain.PartyInvited, loan.Parties[0].Status)
+	s.Equal("guarantor@gmail.com", loan.Parties[1].Email)
+	s.Equal(1.0, loan.Parties[1].LiabilityShare)
+	s.Require().Len(invited, 2)
+	s.Equal(coBorrower.ID, invited[0].PartyUserID)
+	s.Equal("guarantor@gmail.com", invited[1].Email)
+}
+
+func (s *LoanUsecaseTestSuite) TestApplyForLoanCoBorrowersOweWholeLoan() {
+	loan := domain.Loan{
+		Amount:   100000,
+		Duration: 12,
+		Parties: []domain.LoanParty{
+			{Role: domain.PartyCoBorrower, Email: "first@gmail.com", LiabilityShare: 0.5},
+			{Role: domain.PartyCoBorrower, Email: "second@gmail.com", LiabilityShare: 0.5},
+		},
+	}
+	s.mockUserRepository.On("UserProfile", "testuserid").Return(domain.User{KYCStatus: domain.KYCApproved}, nil).Once()
+	s.mockUserRepository.On("UserByEmail", mock.Anything).Return(domain.User{}, errors.New("User not found"))
+
+	err := s.LoanUsecase.ApplyForLoan(context.Background(), &loan, "testuserid")
+
+	s.EqualError(err, "Co-borrowers can't owe the whole loan, the borrower keeps a share")
+	s.mockLoanRepository.AssertNotCalled(s.T(), "ApplyForLoan", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestRespondToLoanMovesToReview() {
+	guarantor := domain.User{ID: primitive.NewObjectID(), Email: "Guarantor@gmail.com", IsVerified: true, KYCStatus: domain.KYCApproved}
+	loan := domain.Loan{
+		ID:     primitive.NewObjectID(),
+		UserID: primitive.NewObjectID(),
+		Status: domain.LoanAwaitingAcceptance,
+		Parties: []domain.LoanParty{
+			{ID: primitive.NewObjectID(), Role: domain.PartyCoBorrower, UserID: primitive.NewObjectID(), LiabilityShare: 0.4, Status: domain.PartyAccepted},
+			{ID: primitive.NewObjectID(), Role: domain.PartyGuarantor, Email: "guarantor@gmail.com", LiabilityShare: 1, Status: domain.PartyInvited},
+		},
+	}
+	s.mockLoanRepository.On("GetLoan", loan.ID.Hex()).Return(loan, nil).Once()
+	s.mockUserRepository.On("UserProfile", guarantor.ID.Hex()).Return(guarantor, nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.MatchedBy(func(l *domain.Loan) bool {
+		return l.Status == "pending" && l.Parties[1].UserID == guarantor.ID && l.Parties[1].Status == domain.PartyAccepted
+	})).Return(nil).Once()
+
+	answered, err := s.LoanUsecase.RespondToLoan(context.Background(), loan.ID.Hex(), loan.Parties[1].ID.Hex(), true, guarantor.ID.Hex())
+
+	s.NoError(err)
+	s.Equal("pending", answered.Status)
+	s.NotNil(answered.Parties[1].RespondedAt)
+	s.mockLoanRepository.AssertExpectations(s.T())
+}
+
+func (s *LoanUsecaseTestSuite) TestRespondToLoanWithUnverifiedEmail() {
+	claimant := domain.User{ID: primitive.NewObjectID(), Email: "guarantor@gmail.com", KYCStatus: domain.KYCApproved}
+	loan := domain.Loan{
+		ID:      primitive.NewObjectID(),
+		Status:  domain.LoanAwaitingAcceptance,
+		Parties: []domain.LoanParty{{ID: primitive.NewObjectID(), Role: domain.PartyGuarantor, Email: "guarantor@gmail.com", Status: domain.PartyInvited}},
+	}
+	s.mockLoanRepository.On("GetLoan", loan.ID.Hex()).Return(loan, nil).Once()
+	s.mockUserRepository.On("UserProfile", claimant.ID.Hex()).Return(claimant, nil).Once()
+
+	_, err := s.LoanUsecase.RespondToLoan(context.Background(), loan.ID.Hex(), loan.Parties[0].ID.Hex(), true, claimant.ID.Hex())
+
+	s.EqualError(err, "Invitation not found")
+	s.mockLoanRepository.AssertNotCalled(s.T(), "UpdateLoan", mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestRespondToLoanOfSomeoneElse() {
+	loan := domain.Loan{
+		ID:      primitive.NewObjectID(),
+		Status:  domain.LoanAwaitingAcceptance,
+		Parties: []domain.LoanParty{{ID: primitive.NewObjectID(), Role: domain.PartyGuarantor, UserID: primitive.NewObjectID(), Status: domain.PartyInvited}},
+	}
+	stranger := domain.User{ID: primitive.NewObjectID()}
+	s.mockLoanRepository.On("GetLoan", loan.ID.Hex()).Return(loan, nil).Once()
+	s.mockUserRepository.On("UserProfile", stranger.ID.Hex()).Return(stranger, nil).Once()
+
+	_, err := s.LoanUsecase.RespondToLoan(context.Background(), loan.ID.Hex(), loan.Parties[0].ID.Hex(), true, stranger.ID.Hex())
+
+	s.EqualError(err, "Invitation not found")
+	s.mockLoanRepository.AssertNotCalled(s.T(), "UpdateLoan", mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestUserLoansListsCoBorrowedLoans() {
+	user := domain.User{ID: primitive.NewObjectID(), Email: "user@gmail.com", IsVerified: true}
+	own := domain.Loan{ID: primitive.NewObjectID(), UserID: user.ID, Parties: []domain.LoanParty{
+		{Role: domain.PartyCoBorrower, UserID: primitive.NewObjectID(), LiabilityShare: 0.25, Status: domain.PartyAccepted},
+	}}
+	coBorrowed := domain.Loan{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), Parties: []domain.LoanParty{
+		{Role: domain.PartyCoBorrower, UserID: user.ID, LiabilityShare: 0.3, Status: domain.PartyAccepted},
+	}}
+	declined := domain.Loan{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), Parties: []domain.LoanParty{
+		{Role: domain.PartyGuarantor, UserID: user.ID, LiabilityShare: 1, Status: domain.PartyDeclined},
+	}}
+	s.mockUserRepository.On("UserProfile", user.ID.Hex()).Return(user, nil).Once()
+	s.mockLoanRepository.On("PartyLoans", user.ID.Hex(), "user@gmail.com").Return([]domain.Loan{own, coBorrowed, declined}, nil).Once()
+
+	loans, err := s.LoanUsecase.UserLoans(context.Background(), user.ID.Hex())
+
+	s.NoError(err)
+	s.Require().Len(loans, 2)
+	s.Equal(domain.PartyBorrower, loans[0].Role)
+	s.Equal(0.75, loans[0].LiabilityShare)
+	s.Equal(domain.PartyCoBorrower, loans[1].Role)
+	s.Equal(0.3, loans[1].LiabilityShare)
+}
+
+func (s *LoanUsecaseTestSuite) TestApproveLoanAwaitingAcceptance() {
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(domain.Loan{Status: domain.LoanAwaitingAcceptance}, nil).Once()
+
+	err := s.LoanUsecase.ApproveRejectLoan(context.Background(), "testloanid", "approved", "", "testadminid")
+
+	s.EqualError(err, "All guarantors and co-borrowers must accept before the loan can be reviewed")
+	s.mockLoanRepository.AssertNotCalled(s.T(), "ApproveRejectLoan", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func (s *LoanUsecaseTestSuite) TestLoanDetails() {
@@ -53,6 +263,85 @@ func (s *LoanUsecaseTestSuite) TestLoanDetails() {
 	s.Equal(expectedLoan, loan)
 }
 
+func (s *LoanUsecaseTestSuite) TestLoanDetailsShowsLoanToValue() {
+	loan := domain.Loan{ID: primitive.NewObjectID(), Amount: 60000, Status: "pending"}
+	collateral := []domain.Collateral{{AppraisedValue: 50000}, {AppraisedValue: 30000}}
+
+	s.mockLoanRepository.On("LoanDetails", "securedloanid", "testuserid").Return(loan, nil).Once()
+	s.mockCollateral.On("LoanCollateral", "securedloanid").Return(collateral, nil).Once()
+
+	details, err := s.LoanUsecase.LoanDetails(context.Background(), "securedloanid", "testuserid")
+
+	s.NoError(err)
+	s.Len(details.Collateral, 2)
+	s.Equal(0.75, details.LTV)
+}
+
+func (s *LoanUsecaseTestSuite) TestLoanDetailsAssessesPenalties() {
+	dueDate := time.Now().AddDate(0, 0, -15)
+	overdueLoan := domain.Loan{
+		ID:     primitive.NewObjectID(),
+		Status: "approved",
+		Schedule: []domain.Installment{
+			{Number: 1, DueDate: dueDate, Amount: 1000},
+		},
+	}
+
+	s.mockLoanRepository.On("LoanDetails", "testloanid", "testuserid").Return(overdueLoan, nil).Once()
+
+	loan, err := s.LoanUsecase.LoanDetails(context.Background(), "testloanid", "testuserid")
+
+	s.NoError(err)
+	s.Equal(15, loan.DaysPastDue)
+	s.Equal(domain.DelinquencyDelinquent, loan.Delinquency)
+	s.Len(loan.Charges, 2)
+	s.Equal(domain.ChargeLateFee, loan.Charges[0].Type)
+	s.Equal(25.0, loan.Charges[0].Amount)
+	s.Equal(domain.ChargePenaltyInterest, loan.Charges[1].Type)
+	s.Equal(2.74, loan.Charges[1].Amount)
+	s.mockLoanRepository.AssertNotCalled(s.T(), "UpdateLoan", mock.Anything, mock.Anything)
+	s.mockLogRepository.AssertNotCalled(s.T(), "AddLog", mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestLoanDetailsWithinGracePeriod() {
+	loan := domain.Loan{
+		ID:          primitive.NewObjectID(),
+		Status:      "approved",
+		Delinquency: domain.DelinquencyDelinquent,
+		DaysPastDue: 2,
+		Schedule: []domain.Installment{
+			{Number: 1, DueDate: time.Now().AddDate(0, 0, -2).Add(-time.Hour), Amount: 1000},
+		},
+	}
+
+	s.mockLoanRepository.On("LoanDetails", "testloanid", "testuserid").Return(loan, nil).Once()
+
+	details, err := s.LoanUsecase.LoanDetails(context.Background(), "testloanid", "testuserid")
+
+	s.NoError(err)
+	s.Empty(details.Charges)
+	s.mockLoanRepository.AssertNotCalled(s.T(), "UpdateLoan", mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestLoanDetailsMarksDefaulted() {
+	loan := domain.Loan{
+		ID:          primitive.NewObjectID(),
+		Status:      "approved",
+		Delinquency: domain.DelinquencyDelinquent,
+		Schedule: []domain.Installment{
+			{Number: 1, DueDate: time.Now().AddDate(0, 0, -95), Amount: 1000},
+		},
+	}
+
+	s.mockLoanRepository.On("LoanDetails", "testloanid", "testuserid").Return(loan, nil).Once()
+
+	details, err := s.LoanUsecase.LoanDetails(context.Background(), "testloanid", "testuserid")
+
+	s.NoError(err)
+	s.Equal(domain.DelinquencyDefaulted, details.Delinquency)
+	s.mockLoanRepository.AssertNotCalled(s.T(), "UpdateLoan", mock.Anything, mock.Anything)
+}
+
 func (s *LoanUsecaseTestSuite) TestViewAllLoans() {
 	expectedLoans := []domain.Loan{
 		{
@@ -60,43 +349,1042 @@ func (s *LoanUsecaseTestSuite) TestViewAllLoans() {
 		},
 	}
 
-	s.mockLoanRepository.On("ViewAllLoans", 1, "pending", "asc").Return(expectedLoans, 1, nil).Once()
+	dpd := domain.DPDRange{Min: 0, Max: -1}
+
+	s.mockLoanRepository.On("ViewAllLoans", 1, "pending", "asc", dpd).Return(expectedLoans, 1, nil).Once()
 
-	loans, _, err := s.LoanUsecase.ViewAllLoans(context.Background(), 1, "pending", "asc")
+	loans, _, err := s.LoanUsecase.ViewAllLoans(context.Background(), 1, "pending", "asc", dpd)
 
 	s.NoError(err)
 	s.Equal(expectedLoans, loans)
 }
 
 func (s *LoanUsecaseTestSuite) TestApproveRejectLoan() {
-	s.mockLoanRepository.On("ApproveRejectLoan", "testloanid", "approved", "testuserid").Return(nil).Once()
+	pendingLoan := domain.Loan{
+		ID:       primitive.NewObjectID(),
+		Amount:   1200,
+		Interest: 0,
+		Duration: 12,
+		Status:   "pending",
+		Version:  3,
+	}
 
-	err := s.LoanUsecase.ApproveRejectLoan(context.Background(), "testloanid", "approved", "testuserid")
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(pendingLoan, nil).Once()
+	s.mockLoanRepository.On("ApproveRejectLoan", mock.Anything, "testloanid", "approved", (*domain.Rejection)(nil)).Return(nil).Once()
+	// the schedule is saved over the version the decision made
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+		return len(loan.Schedule) == 12 && loan.Schedule[0].Amount == 100 && loan.Version == 4
+	})).Return(nil).Once()
+
+	err := s.LoanUsecase.ApproveRejectLoan(context.Background(), "testloanid", "approved", "", "testuserid")
 
 	s.NoError(err)
+	s.mockLoanRepository.AssertExpectations(s.T())
 }
 
-func (s *LoanUsecaseTestSuite) TestDeleteLoan() {
-	s.mockLoanRepository.On("DeleteLoan", "testloanid", "testuserid").Return(nil).Once()
+func (s *LoanUsecaseTestSuite) TestApproveProcessedLoan() {
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(domain.Loan{ID: primitive.NewObjectID(), Status: "rejected"}, nil).Once()
+
+	err := s.LoanUsecase.ApproveRejectLoan(context.Background(), "testloanid", "approved", "", "testuserid")
 
-	err := s.LoanUsecase.DeleteLoan(context.Background(), "testloanid", "testuserid")
+	s.EqualError(err, "Loan already processed")
+	s.mockLoanRepository.AssertNotCalled(s.T(), "ApproveRejectLoan", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestApproveLoanAboveMaxLTV() {
+	loan := domain.Loan{ID: primitive.NewObjectID(), Amount: 90000, Duration: 12, Product: "secured", Status: "pending"}
+
+	s.mockLoanRepository.On("GetLoan", "securedloanid").Return(loan, nil).Once()
+	s.mockCollateral.On("LoanCollateral", loan.ID.Hex()).Return([]domain.Collateral{{AppraisedValue: 100000}}, nil).Once()
+
+	err := s.LoanUsecase.ApproveRejectLoan(context.Background(), "securedloanid", "approved", "", "testuserid")
+
+	s.EqualError(err, "Loan-to-value of 0.90 exceeds the product maximum of 0.80")
+	s.mockLoanRepository.AssertNotCalled(s.T(), "ApproveRejectLoan", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestApproveLoanSecuredByPledgedCollateral() {
+	loan := domain.Loan{ID: primitive.NewObjectID(), Amount: 50000, Duration: 12, Product: "secured", Status: "pending"}
+
+	// the item was linked before another loan it secures was approved, its value is already counted on
+	s.mockLoanRepository.On("GetLoan", "securedloanid").Return(loan, nil).Once()
+	s.mockCollateral.On("LoanCollateral", loan.ID.Hex()).Return([]domain.Collateral{{AppraisedValue: 100000, LienStatus: domain.LienActive}}, nil).Once()
+
+	err := s.LoanUsecase.ApproveRejectLoan(context.Background(), "securedloanid", "approved", "", "testuserid")
+
+	s.EqualError(err, "Collateral already secures another loan")
+	s.mockLoanRepository.AssertNotCalled(s.T(), "ApproveRejectLoan", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestApproveLoanWithoutRequiredCollateral() {
+	loan := domain.Loan{ID: primitive.NewObjectID(), Amount: 90000, Duration: 12, Product: "secured", Status: "pending"}
+
+	s.mockLoanRepository.On("GetLoan", "securedloanid").Return(loan, nil).Once()
+	s.mockCollateral.On("LoanCollateral", loan.ID.Hex()).Return([]domain.Collateral{}, nil).Once()
+
+	err := s.LoanUsecase.ApproveRejectLoan(context.Background(), "securedloanid", "approved", "", "testuserid")
+
+	s.EqualError(err, "The loan product requires collateral")
+}
+
+func (s *LoanUsecaseTestSuite) TestRejectLoanIgnoresMaxLTV() {
+	loan := domain.Loan{ID: primitive.NewObjectID(), Amount: 90000, Product: "secured", Status: "pending"}
+
+	s.mockLoanRepository.On("GetLoan", "securedloanid").Return(loan, nil).Once()
+	s.mockLoanRepository.On("ApproveRejectLoan", mock.Anything, "securedloanid", "rejected", mock.Anything).Return(nil).Once()
+
+	err := s.LoanUsecase.ApproveRejectLoan(context.Background(), "securedloanid", "rejected", "Income could not be verified", "testuserid")
+
+	s.NoError(err)
+	s.mockCollateral.AssertNotCalled(s.T(), "LoanCollateral", loan.ID.Hex())
+}
+
+func (s *LoanUsecaseTestSuite) TestRejectLoan() {
+	pendingLoan := domain.Loan{
+		ID:     primitive.NewObjectID(),
+		Amount: 1200,
+		Status: "pending",
+	}
+
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(pendingLoan, nil).Once()
+	s.mockLoanRepository.On("ApproveRejectLoan", mock.Anything, "testloanid", "rejected", mock.MatchedBy(func(rejection *domain.Rejection) bool {
+		return rejection != nil && rejection.Reason == "Debt-to-income ratio is too high" && !rejection.RejectedAt.IsZero()
+	})).Return(nil).Once()
+	s.mockEventBus.On("Publish", mock.Anything, mock.MatchedBy(func(event domain.Event) bool {
+		rejected, ok := event.(*domain.LoanRejected)
+		return ok && rejected.Reason == "Debt-to-income ratio is too high"
+	})).Return(nil).Once()
+
+	err := s.LoanUsecase.ApproveRejectLoan(context.Background(), "testloanid", "rejected", " Debt-to-income ratio is too high ", "testuserid")
+
+	s.NoError(err)
+	s.mockLoanRepository.AssertExpectations(s.T())
+	s.mockLoanRepository.AssertNotCalled(s.T(), "UpdateLoan", mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestRejectLoanNeedsReason() {
+	err := s.LoanUsecase.ApproveRejectLoan(context.Background(), "testloanid", "rejected", "  ", "testuserid")
+
+	s.EqualError(err, "A reason is required to reject a loan")
+	s.mockLoanRepository.AssertNotCalled(s.T(), "GetLoan", mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestApproveRejectLoanAudited() {
+	adminID := primitive.NewObjectID()
+	pendingLoan := domain.Loan{
+		ID:     primitive.NewObjectID(),
+		Amount: 1200,
+		Status: "pending",
+	}
+	ctx := domain.WithRequestMeta(context.Background(), domain.RequestMeta{
+		RequestID: "testrequestid",
+		IP:        "10.0.0.1",
+		UserAgent: "test-agent",
+		IsAdmin:   true,
+	})
+
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(pendingLoan, nil).Once()
+	s.mockLoanRepository.On("ApproveRejectLoan", mock.Anything, "testloanid", "rejected", mock.Anything).Return(nil).Once()
+
+	err := s.LoanUsecase.ApproveRejectLoan(ctx, "testloanid", "rejected", "Income could not be verified", adminID.Hex())
+
+	s.NoError(err)
+	s.mockLogRepository.AssertCalled(s.T(), "AddLog", mock.Anything, mock.MatchedBy(func(entry *domain.Log) bool {
+		return entry.Action == domain.ActionLoanRejected &&
+			entry.ActorID == adminID &&
+			entry.ActorRole == domain.RoleAdmin &&
+			entry.TargetType == domain.TargetLoan &&
+			entry.TargetID == pendingLoan.ID &&
+			entry.RequestID == "testrequestid" &&
+			entry.IP == "10.0.0.1" &&
+			entry.UserAgent == "test-agent" &&
+			entry.Note == "Income could not be verified" &&
+			len(entry.Changes) == 2 &&
+			entry.Changes[0].Field == "rejection" &&
+			entry.Changes[1] == domain.FieldChange{Field: "status", Before: "pending", After: "rejected"}
+	}))
+}
+
+func (s *LoanUsecaseTestSuite) TestApproveLoanSingleUnitOfWork() {
+	pendingLoan := domain.Loan{
+		ID:       primitive.NewObjectID(),
+		Amount:   1200,
+		Duration: 12,
+		Status:   "pending",
+	}
+
+	// the unit hands its own context to the writes made in it
+	type unitKey struct{}
+	inUnit := mock.MatchedBy(func(c context.Context) bool { return c.Value(unitKey{}) != nil })
+	units := new(mocks.UnitOfWork)
+	units.On("Do", mock.Anything, mock.Anything).Return(func(c context.Context, work func(context.Context) error) error {
+		return work(context.WithValue(c, unitKey{}, true))
+	}).Once()
+	logs := new(mocks.LogRepository)
+	s.LoanUsecase = usecase.NewLoanUsecase(s.mockLoanRepository, s.mockUserRepository, s.mockCollateral, logs, units, s.mockEventBus, nil, time.Second*2)
+
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(pendingLoan, nil).Once()
+	s.mockLoanRepository.On("ApproveRejectLoan", inUnit, "testloanid", "approved", (*domain.Rejection)(nil)).Return(nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", inUnit, mock.Anything).Return(nil).Once()
+	logs.On("AddLog", inUnit, mock.Anything).Return(errors.New("Error writing audit log")).Once()
+
+	err := s.LoanUsecase.ApproveRejectLoan(context.Background(), "testloanid", "approved", "", "testuserid")
+
+	// the failed audit entry fails the whole unit, its writes are rolled back with it
+	s.EqualError(err, "Error writing audit log")
+	units.AssertNumberOfCalls(s.T(), "Do", 1)
+	s.mockLoanRepository.AssertExpectations(s.T())
+}
+
+func (s *LoanUsecaseTestSuite) TestMakePayment() {
+	loan := domain.Loan{
+		ID:     primitive.NewObjectID(),
+		Status: "approved",
+		Schedule: []domain.Installment{
+			{Number: 1, DueDate: time.Now().AddDate(0, 0, 10), Amount: 500},
+			{Number: 2, DueDate: time.Now().AddDate(0, 1, 10), Amount: 500},
+		},
+	}
+
+	s.mockLoanRepository.On("LoanDetails", "testloanid", "testuserid").Return(loan, nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+		return loan.Schedule[0].PaidAmount == 500 && !loan.Schedule[0].PaidAt.IsZero() &&
+			loan.Schedule[1].PaidAmount == 200 && len(loan.Payments) == 1
+	})).Return(nil).Once()
+
+	payment, err := s.LoanUsecase.MakePayment(context.Background(), "testloanid", 700, "", "testuserid")
 
 	s.NoError(err)
+	s.Equal(700.0, payment.Amount)
+	s.mockLoanRepository.AssertExpectations(s.T())
 }
 
-func (s *LoanUsecaseTestSuite) TestViewLogs() {
-	expectedLogs := []domain.Log{
+func (s *LoanUsecaseTestSuite) TestReplayedPaymentSavesOverReadVersion() {
+	loan := domain.Loan{
+		ID:       primitive.NewObjectID(),
+		Status:   "approved",
+		Schedule: []domain.Installment{{Number: 1, DueDate: time.Now().AddDate(0, 0, 10), Amount: 500}},
+		Version:  7,
+	}
+
+	// the unit is replayed once, the way a transaction is when its audit entry loses its place in the chain
+	unitOfWork := new(mocks.UnitOfWork)
+	unitOfWork.On("Do", mock.Anything, mock.Anything).Return(func(c context.Context, work func(context.Context) error) error {
+		if err := work(c); err != nil {
+			return err
+		}
+		return work(c)
+	}).Once()
+	loanUsecase := usecase.NewLoanUsecase(s.mockLoanRepository, s.mockUserRepository, s.mockCollateral, s.mockLogRepository, unitOfWork, s.mockEventBus, nil, time.Second*2)
+
+	versions := []int{}
+	s.mockLoanRepository.On("LoanDetails", "testloanid", "testuserid").Return(loan, nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved := args.Get(1).(*domain.Loan)
+		versions = append(versions, saved.Version)
+		saved.Version++
+	}).Return(nil).Twice()
+
+	_, err := loanUsecase.MakePayment(context.Background(), "testloanid", 200, "", "testuserid")
+
+	s.NoError(err)
+	s.Equal([]int{7, 7}, versions)
+}
+
+func (s *LoanUsecaseTestSuite) TestMakePaymentSettlesChargesFirst() {
+	loan := domain.Loan{
+		ID:     primitive.NewObjectID(),
+		Status: "approved",
+		Schedule: []domain.Installment{
+			{Number: 1, DueDate: time.Now().AddDate(0, 0, 10), Amount: 500},
+		},
+		Charges: []domain.Charge{
+			{ID: primitive.NewObjectID(), Installment: 1, Type: domain.ChargeLateFee, Amount: 25},
+		},
+	}
+
+	s.mockLoanRepository.On("LoanDetails", "testloanid", "testuserid").Return(loan, nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+		return loan.Charges[0].PaidAmount == 25 && loan.Schedule[0].PaidAmount == 75
+	})).Return(nil).Once()
+
+	_, err := s.LoanUsecase.MakePayment(context.Background(), "testloanid", 100, "", "testuserid")
+
+	s.NoError(err)
+	s.mockLoanRepository.AssertExpectations(s.T())
+}
+
+func (s *LoanUsecaseTestSuite) TestMakePaymentExceedsBalance() {
+	loan := domain.Loan{
+		ID:     primitive.NewObjectID(),
+		Status: "approved",
+		Schedule: []domain.Installment{
+			{Number: 1, DueDate: time.Now().AddDate(0, 0, 10), Amount: 500},
+		},
+	}
+
+	s.mockLoanRepository.On("LoanDetails", "testloanid", "testuserid").Return(loan, nil).Once()
+
+	_, err := s.LoanUsecase.MakePayment(context.Background(), "testloanid", 501, "", "testuserid")
+
+	s.Error(err)
+	s.mockLoanRepository.AssertNotCalled(s.T(), "UpdateLoan", mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestMakePaymentMarksPaidOff() {
+	loan := domain.Loan{
+		ID:     primitive.NewObjectID(),
+		Status: "approved",
+		Schedule: []domain.Installment{
+			{Number: 1, DueDate: time.Now().AddDate(0, 0, 10), Amount: 500},
+		},
+	}
+
+	s.mockLoanRepository.On("LoanDetails", "testloanid", "testuserid").Return(loan, nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+		return loan.Status == "paid_off"
+	})).Return(nil).Once()
+
+	_, err := s.LoanUsecase.MakePayment(context.Background(), "testloanid", 500, "", "testuserid")
+
+	s.NoError(err)
+	s.mockLoanRepository.AssertExpectations(s.T())
+	s.mockEventBus.AssertCalled(s.T(), "Publish", mock.Anything, mock.MatchedBy(func(event domain.Event) bool {
+		received, ok := event.(*domain.PaymentReceived)
+		return ok && received.LoanID == loan.ID && received.Amount == 500 && received.Outstanding == 0
+	}))
+	s.mockEventBus.AssertCalled(s.T(), "Publish", mock.Anything, mock.MatchedBy(func(event domain.Event) bool {
+		paidOff, ok := event.(*domain.LoanPaidOff)
+		return ok && paidOff.LoanID == loan.ID
+	}))
+}
+
+// payoffLoan has one installment paid and the next one a third of the way into its period
+func payoffLoan(now time.Time) domain.Loan {
+	return domain.Loan{
+		ID:     primitive.NewObjectID(),
+		Status: "approved",
+		Schedule: []domain.Installment{
+			{Number: 1, DueDate: now.AddDate(0, 0, -10), Principal: 490, Interest: 10, Amount: 500, PaidAmount: 500},
+			{Number: 2, DueDate: now.AddDate(0, 0, 20), Principal: 495, Interest: 5, Amount: 500},
+		},
+	}
+}
+
+func (s *LoanUsecaseTestSuite) TestPayoffQuote() {
+	now := time.Now()
+
+	s.mockLoanRepository.On("LoanDetails", "testloanid", "testuserid").Return(payoffLoan(now), nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+		return loan.PayoffQuote != nil && loan.Status == "approved" && loan.Schedule[1].Interest == 5
+	})).Return(nil).Once()
+
+	quote, err := s.LoanUsecase.PayoffQuote(context.Background(), "testloanid", now, "testuserid")
+
+	s.NoError(err)
+	s.Equal(495.0, quote.Principal)
+	s.Equal(1.67, quote.AccruedInterest)
+	s.Equal(0.0, quote.Fees)
+	s.Equal(4.95, quote.PrepaymentPenalty)
+	s.Equal(501.62, quote.Total)
+	s.False(quote.ID.IsZero())
+	s.True(quote.ExpiresAt.After(now))
+	// a quote for today can't be paid after today, even within its 30 minutes
+	s.False(quote.ExpiresAt.After(now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)))
+	s.mockLoanRepository.AssertExpectations(s.T())
+}
+
+func (s *LoanUsecaseTestSuite) TestPayoffQuoteForLaterDate() {
+	now := time.Now()
+	date := now.AddDate(0, 0, 3)
+
+	s.mockLoanRepository.On("LoanDetails", "testloanid", "testuserid").Return(payoffLoan(now), nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.Anything).Return(nil).Once()
+
+	quote, err := s.LoanUsecase.PayoffQuote(context.Background(), "testloanid", date, "testuserid")
+
+	// the quote can still be used on the day it is for, and not after
+	s.NoError(err)
+	s.Equal(date.UTC().Truncate(24*time.Hour).Add(24*time.Hour), quote.ExpiresAt)
+}
+
+func (s *LoanUsecaseTestSuite) TestPayoffQuoteInThePast() {
+	_, err := s.LoanUsecase.PayoffQuote(context.Background(), "testloanid", time.Now().AddDate(0, 0, -2), "testuserid")
+
+	s.Error(err)
+	s.mockLoanRepository.AssertNotCalled(s.T(), "LoanDetails", mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestMakePaymentWithPayoffQuote() {
+	now := time.Now()
+	loan := payoffLoan(now)
+	loan.PayoffQuote = &domain.PayoffQuote{
+		ID:        primitive.NewObjectID(),
+		Date:      now,
+		Total:     501.62,
+		ExpiresAt: now.Add(time.Minute),
+	}
+
+	s.mockLoanRepository.On("LoanDetails", "testloanid", "testuserid").Return(loan, nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+		return loan.Status == "paid_off" && loan.PayoffQuote == nil &&
+			loan.Schedule[1].Interest == 1.67 && loan.Schedule[1].PaidAmount == 496.67 &&
+			len(loan.Charges) == 1 && loan.Charges[0].Type == domain.ChargePrepaymentPenalty
+	})).Return(nil).Once()
+
+	payment, err := s.LoanUsecase.MakePayment(context.Background(), "testloanid", 501.62, loan.PayoffQuote.ID.Hex(), "testuserid")
+
+	s.NoError(err)
+	s.Equal(501.62, payment.Amount)
+	s.mockLoanRepository.AssertExpectations(s.T())
+}
+
+func (s *LoanUsecaseTestSuite) TestMakePaymentWithPayoffQuoteForLaterDate() {
+	now := time.Now()
+	loan := payoffLoan(now)
+	loan.PayoffQuote = &domain.PayoffQuote{
+		ID:        primitive.NewObjectID(),
+		Date:      now.AddDate(0, 0, 1),
+		Total:     501.62,
+		ExpiresAt: now.AddDate(0, 0, 2),
+	}
+
+	s.mockLoanRepository.On("LoanDetails", "testloanid", "testuserid").Return(loan, nil).Once()
+
+	_, err := s.LoanUsecase.MakePayment(context.Background(), "testloanid", 501.62, loan.PayoffQuote.ID.Hex(), "testuserid")
+
+	s.EqualError(err, "Payoff quote is for a different date")
+	s.mockLoanRepository.AssertNotCalled(s.T(), "UpdateLoan", mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestApprovePaidOffLoan() {
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(domain.Loan{ID: primitive.NewObjectID(), Status: "paid_off"}, nil).Once()
+
+	err := s.LoanUsecase.ApproveRejectLoan(context.Background(), "testloanid", "approved", "", "testuserid")
+
+	s.EqualError(err, "Loan already processed")
+	s.mockLoanRepository.AssertNotCalled(s.T(), "ApproveRejectLoan", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestMakePaymentWithExpiredPayoffQuote() {
+	now := time.Now()
+	loan := payoffLoan(now)
+	loan.PayoffQuote = &domain.PayoffQuote{
+		ID:        primitive.NewObjectID(),
+		Date:      now,
+		Total:     501.62,
+		ExpiresAt: now.Add(-time.Minute),
+	}
+
+	s.mockLoanRepository.On("LoanDetails", "testloanid", "testuserid").Return(loan, nil).Once()
+
+	_, err := s.LoanUsecase.MakePayment(context.Background(), "testloanid", 501.62, loan.PayoffQuote.ID.Hex(), "testuserid")
+
+	s.EqualError(err, "Payoff quote has expired")
+	s.mockLoanRepository.AssertNotCalled(s.T(), "UpdateLoan", mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestRestructureLoanExtendsTerm() {
+	now := time.Now()
+	loan := domain.Loan{
+		ID:       primitive.NewObjectID(),
+		Status:   "approved",
+		Duration: 3,
+		Schedule: []domain.Installment{
+			{Number: 1, DueDate: now.AddDate(0, 0, -20), Principal: 400, Amount: 400, PaidAmount: 400},
+			{Number: 2, DueDate: now.AddDate(0, 0, 10), Principal: 400, Amount: 400},
+			{Number: 3, DueDate: now.AddDate(0, 0, 40), Principal: 400, Amount: 400},
+		},
+	}
+
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(loan, nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.Anything).Return(nil).Once()
+
+	restructured, err := s.LoanUsecase.RestructureLoan(context.Background(), "testloanid", domain.Restructure{ExtendMonths: 2, Reason: "job loss"}, "testuserid")
+
+	s.NoError(err)
+	s.Equal(5, restructured.Duration)
+	s.Len(restructured.Schedule, 5)
+	s.Equal(400.0, restructured.Schedule[0].PaidAmount)
+	for _, installment := range restructured.Schedule[1:] {
+		s.Equal(200.0, installment.Amount)
+	}
+	s.Equal(4, restructured.Schedule[1].Number)
+	s.Len(restructured.History, 1)
+	s.Equal(1, restructured.History[0].Version)
+	s.Len(restructured.History[0].Schedule, 3)
+	s.Equal("job loss", restructured.History[0].Restructure.Reason)
+	s.mockLoanRepository.AssertExpectations(s.T())
+}
+
+func (s *LoanUsecaseTestSuite) TestRestructureLoanCapitalizesArrears() {
+	now := time.Now()
+	rate := 0.0
+	loan := domain.Loan{
+		ID:          primitive.NewObjectID(),
+		Status:      "approved",
+		Interest:    0.12,
+		Delinquency: domain.DelinquencyDelinquent,
+		Schedule: []domain.Installment{
+			{Number: 1, DueDate: now.AddDate(0, 0, -40), Principal: 400, Interest: 20, Amount: 420},
+			{Number: 2, DueDate: now.AddDate(0, 0, 10), Principal: 400, Amount: 400},
+		},
+		Charges: []domain.Charge{
+			{ID: primitive.NewObjectID(), Installment: 1, Type: domain.ChargeLateFee, Amount: 25},
+			{ID: primitive.NewObjectID(), Installment: 1, Type: domain.ChargePenaltyInterest, AccruedTo: now.AddDate(0, 0, -35)},
+		},
+	}
+
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(loan, nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.Anything).Return(nil).Once()
+
+	terms := domain.Restructure{Interest: &rate, CapitalizeArrears: true, Reason: "medical leave"}
+	restructured, err := s.LoanUsecase.RestructureLoan(context.Background(), "testloanid", terms, "testuserid")
+
+	s.NoError(err)
+	s.Equal(0.0, restructured.Interest)
+	s.Len(restructured.Schedule, 1)
+	s.Equal(3, restructured.Schedule[0].Number)
+	s.Equal(849.03, restructured.Schedule[0].Amount)
+	s.True(restructured.Charges[0].Capitalized)
+	s.True(restructured.Charges[1].Capitalized)
+	s.Equal(domain.DelinquencyCurrent, restructured.Delinquency)
+	s.mockLoanRepository.AssertExpectations(s.T())
+}
+
+func (s *LoanUsecaseTestSuite) TestRestructureLoanPaymentHoliday() {
+	loan := domain.Loan{
+		ID:       primitive.NewObjectID(),
+		Status:   "approved",
+		Interest: 0.12,
+		Schedule: []domain.Installment{
+			{Number: 1, DueDate: time.Now().AddDate(0, 0, 10), Principal: 1000, Interest: 10, Amount: 1010},
+		},
+	}
+
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(loan, nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.Anything).Return(nil).Once()
+
+	restructured, err := s.LoanUsecase.RestructureLoan(context.Background(), "testloanid", domain.Restructure{HolidayMonths: 2, Reason: "harvest failed"}, "testuserid")
+
+	s.NoError(err)
+	s.Len(restructured.Schedule, 1)
+	s.Equal(1020.1, restructured.Schedule[0].Principal)
+	s.True(restructured.Schedule[0].DueDate.After(loan.Schedule[0].DueDate.AddDate(0, 1, 0)))
+}
+
+func (s *LoanUsecaseTestSuite) TestRestructureLoanRequiresReason() {
+	_, err := s.LoanUsecase.RestructureLoan(context.Background(), "testloanid", domain.Restructure{ExtendMonths: 2}, "testuserid")
+
+	s.Error(err)
+	s.mockLoanRepository.AssertNotCalled(s.T(), "GetLoan", mock.Anything)
+}
+
+// defaultedLoan returns a loan 120 days past due with a late fee charged
+func defaultedLoan() domain.Loan {
+	return domain.Loan{
+		ID:          primitive.NewObjectID(),
+		Status:      "approved",
+		Delinquency: domain.DelinquencyDefaulted,
+		DaysPastDue: 120,
+		Schedule: []domain.Installment{
+			{Number: 1, DueDate: time.Now().AddDate(0, 0, -120), Amount: 1000},
+		},
+		Charges: []domain.Charge{
+			{ID: primitive.NewObjectID(), Installment: 1, Type: domain.ChargeLateFee, Amount: 25},
+			{ID: primitive.NewObjectID(), Installment: 1, Type: domain.ChargePenaltyInterest, AccruedTo: time.Now()},
+		},
+	}
+}
+
+func (s *LoanUsecaseTestSuite) TestWriteOffLoan() {
+	adminID := primitive.NewObjectID().Hex()
+
+	// requesting a write-off leaves the loan on the book until it is approved
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(defaultedLoan(), nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+		return loan.Status == "approved" && loan.WriteOff.Amount == 1025 && loan.WriteOff.RequestedBy.Hex() == adminID &&
+			loan.WriteOff.ApprovedBy.IsZero() && loan.WriteOff.WrittenOffAt.IsZero()
+	})).Return(nil).Once()
+
+	err := s.LoanUsecase.WriteOffLoan(context.Background(), "testloanid", "borrower deceased", adminID)
+
+	s.NoError(err)
+	s.mockLogRepository.AssertCalled(s.T(), "AddLog", mock.Anything, mock.MatchedBy(func(entry *domain.Log) bool {
+		return entry.Action == domain.ActionLoanWriteOffRequested
+	}))
+	s.mockLoanRepository.AssertExpectations(s.T())
+	s.mockEventBus.AssertNotCalled(s.T(), "Publish", mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestWriteOffLoanNotDefaulted() {
+	loan := domain.Loan{
+		ID:     primitive.NewObjectID(),
+		Status: "approved",
+		Schedule: []domain.Installment{
+			{Number: 1, DueDate: time.Now().AddDate(0, 0, 10), Amount: 1000},
+		},
+	}
+
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(loan, nil).Once()
+
+	err := s.LoanUsecase.WriteOffLoan(context.Background(), "testloanid", "uncollectable", "testuserid")
+
+	s.EqualError(err, "Only defaulted loans can be written off")
+	s.mockLoanRepository.AssertNotCalled(s.T(), "UpdateLoan", mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestWriteOffLoanAlreadyRequested() {
+	loan := defaultedLoan()
+	loan.WriteOff = &domain.WriteOff{Amount: 1025, RequestedBy: primitive.NewObjectID()}
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(loan, nil).Once()
+
+	err := s.LoanUsecase.WriteOffLoan(context.Background(), "testloanid", "uncollectable", primitive.NewObjectID().Hex())
+
+	s.EqualError(err, "A write-off of this loan is already awaiting approval")
+	s.mockLoanRepository.AssertNotCalled(s.T(), "UpdateLoan", mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestApproveWriteOff() {
+	requesterID := primitive.NewObjectID()
+	approverID := primitive.NewObjectID().Hex()
+	loan := defaultedLoan()
+	loan.WriteOff = &domain.WriteOff{Amount: 1000, Reason: "borrower deceased", RequestedBy: requesterID}
+
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(loan, nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+		return loan.Status == "written_off" && loan.WriteOff.Amount == 1025 &&
+			loan.WriteOff.RequestedBy == requesterID && loan.WriteOff.ApprovedBy.Hex() == approverID && !loan.WriteOff.WrittenOffAt.IsZero()
+	})).Return(nil).Once()
+
+	err := s.LoanUsecase.DecideWriteOff(context.Background(), "testloanid", true, approverID)
+
+	s.NoError(err)
+	s.mockLogRepository.AssertCalled(s.T(), "AddLog", mock.Anything, mock.MatchedBy(func(entry *domain.Log) bool {
+		return entry.Action == domain.ActionLoanWrittenOff && entry.ActorID.Hex() == approverID
+	}))
+	s.mockLoanRepository.AssertExpectations(s.T())
+}
+
+func (s *LoanUsecaseTestSuite) TestApproveOwnWriteOff() {
+	adminID := primitive.NewObjectID()
+	loan := defaultedLoan()
+	loan.WriteOff = &domain.WriteOff{Amount: 1025, RequestedBy: adminID}
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(loan, nil).Once()
+
+	err := s.LoanUsecase.DecideWriteOff(context.Background(), "testloanid", true, adminID.Hex())
+
+	s.EqualError(err, "A write-off must be approved by another admin")
+	s.mockLoanRepository.AssertNotCalled(s.T(), "UpdateLoan", mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestDeclineWriteOff() {
+	adminID := primitive.NewObjectID()
+	loan := defaultedLoan()
+	loan.WriteOff = &domain.WriteOff{Amount: 1025, RequestedBy: adminID}
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(loan, nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+		return loan.Status == "approved" && loan.WriteOff == nil
+	})).Return(nil).Once()
+
+	// the admin who requested it can withdraw it
+	err := s.LoanUsecase.DecideWriteOff(context.Background(), "testloanid", false, adminID.Hex())
+
+	s.NoError(err)
+	s.mockLogRepository.AssertCalled(s.T(), "AddLog", mock.Anything, mock.MatchedBy(func(entry *domain.Log) bool {
+		return entry.Action == domain.ActionLoanWriteOffDeclined
+	}))
+	s.mockLoanRepository.AssertExpectations(s.T())
+}
+
+func (s *LoanUsecaseTestSuite) TestDecideWithoutWriteOff() {
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(defaultedLoan(), nil).Once()
+
+	err := s.LoanUsecase.DecideWriteOff(context.Background(), "testloanid", true, "testuserid")
+
+	s.EqualError(err, "Loan has no write-off awaiting approval")
+}
+
+func (s *LoanUsecaseTestSuite) TestApproveWrittenOffLoan() {
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(domain.Loan{ID: primitive.NewObjectID(), Status: "written_off"}, nil).Once()
+
+	err := s.LoanUsecase.ApproveRejectLoan(context.Background(), "testloanid", "rejected", "Fraud", "testuserid")
+
+	s.EqualError(err, "Loan already processed")
+	s.mockLoanRepository.AssertNotCalled(s.T(), "ApproveRejectLoan", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestPostRecovery() {
+	loan := domain.Loan{
+		ID:       primitive.NewObjectID(),
+		Status:   "written_off",
+		WriteOff: &domain.WriteOff{Amount: 1000},
+		Recoveries: []domain.Recovery{
+			{ID: primitive.NewObjectID(), Amount: 600},
+		},
+	}
+
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(loan, nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+		return len(loan.Recoveries) == 2 && loan.Status == "written_off"
+	})).Return(nil).Once()
+
+	recovery, err := s.LoanUsecase.PostRecovery(context.Background(), "testloanid", 400, "collections agency", "testuserid")
+
+	s.NoError(err)
+	s.Equal(400.0, recovery.Amount)
+	s.mockLoanRepository.AssertExpectations(s.T())
+}
+
+func (s *LoanUsecaseTestSuite) TestPostRecoveryExceedsWriteOff() {
+	loan := domain.Loan{
+		ID:       primitive.NewObjectID(),
+		Status:   "written_off",
+		WriteOff: &domain.WriteOff{Amount: 1000},
+		Recoveries: []domain.Recovery{
+			{ID: primitive.NewObjectID(), Amount: 600},
+		},
+	}
+
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(loan, nil).Once()
+
+	_, err := s.LoanUsecase.PostRecovery(context.Background(), "testloanid", 400.01, "", "testuserid")
+
+	s.Error(err)
+	s.mockLoanRepository.AssertNotCalled(s.T(), "UpdateLoan", mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestWriteOffReport() {
+	loans := []domain.Loan{
 		{
-			ID: primitive.NewObjectID(),
+			ID:         primitive.NewObjectID(),
+			Status:     "written_off",
+			WriteOff:   &domain.WriteOff{Amount: 1000},
+			Recoveries: []domain.Recovery{{Amount: 250}},
+		},
+		{
+			ID:       primitive.NewObjectID(),
+			Status:   "written_off",
+			WriteOff: &domain.WriteOff{Amount: 500.5},
+		},
+	}
+
+	s.mockLoanRepository.On("LoansByStatus", "written_off").Return(loans, nil).Once()
+
+	report, err := s.LoanUsecase.WriteOffReport(context.Background())
+
+	s.NoError(err)
+	s.Equal(2, report.Loans)
+	s.Equal(1500.5, report.WrittenOff)
+	s.Equal(250.0, report.Recovered)
+	s.Equal(1250.5, report.Outstanding)
+	s.Len(report.Entries, 2)
+}
+
+func (s *LoanUsecaseTestSuite) TestAssessOverdueLoans() {
+	loans := []domain.Loan{
+		{
+			ID:     primitive.NewObjectID(),
+			Status: "approved",
+			Schedule: []domain.Installment{
+				{Number: 1, DueDate: time.Now().AddDate(0, 0, -10), Amount: 1000},
+			},
+		},
+		{
+			ID:          primitive.NewObjectID(),
+			Status:      "approved",
+			Delinquency: domain.DelinquencyCurrent,
+			Schedule: []domain.Installment{
+				{Number: 1, DueDate: time.Now().AddDate(0, 0, 10), Amount: 1000},
+			},
+		},
+	}
+
+	s.mockLoanRepository.On("ActiveLoans").Return(loans, nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.Anything).Return(nil).Once()
+
+	err := s.LoanUsecase.AssessOverdueLoans(context.Background())
+
+	s.NoError(err)
+	s.mockLoanRepository.AssertNumberOfCalls(s.T(), "UpdateLoan", 1)
+}
+
+func (s *LoanUsecaseTestSuite) TestAssessOverdueLoansCancelled() {
+	loans := []domain.Loan{{ID: primitive.NewObjectID(), Status: "approved", Schedule: []domain.Installment{
+		{Number: 1, DueDate: time.Now().AddDate(0, 0, -10), Amount: 1000},
+	}}}
+	s.mockLoanRepository.On("ActiveLoans").Return(loans, nil).Once()
+
+	// the job ran out of time, the worker it is handed to next assesses the loans
+	c, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := s.LoanUsecase.AssessOverdueLoans(c)
+
+	s.ErrorIs(err, context.Canceled)
+	s.mockLoanRepository.AssertNotCalled(s.T(), "UpdateLoan", mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestRemindUpcomingPayments() {
+	now := time.Now()
+	loan := domain.Loan{
+		ID:     primitive.NewObjectID(),
+		UserID: primitive.NewObjectID(),
+		Status: "approved",
+		Schedule: []domain.Installment{
+			{Number: 1, DueDate: now.AddDate(0, 0, -1), Amount: 1000, PaidAmount: 1000},
+			{Number: 2, DueDate: now.AddDate(0, 0, 2), Amount: 1000},
+			{Number: 3, DueDate: now.AddDate(0, 0, 30), Amount: 1000},
 		},
 	}
 
-	s.mockLoanRepository.On("ViewLogs").Return(expectedLogs, nil).Once()
+	published := []domain.Event{}
+	s.mockEventBus.ExpectedCalls = nil
+	s.mockEventBus.On("Publish", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		published = append(published, args.Get(1).(domain.Event))
+	}).Return(nil)
+	s.mockLoanRepository.On("ActiveLoans").Return([]domain.Loan{loan}, nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.MatchedBy(func(l *domain.Loan) bool {
+		return l.Schedule[1].Reminders[0] == 3
+	})).Return(nil).Once()
+
+	reminded, err := s.LoanUsecase.RemindUpcomingPayments(context.Background(), []int{3})
+
+	// only the unpaid installment within three days is reminded of
+	s.NoError(err)
+	s.Equal(1, reminded)
+	s.Require().Len(published, 1)
+	due := published[0].(*domain.PaymentDue)
+	s.Equal(2, due.Installment)
+	s.Equal(2, due.DaysUntilDue)
+	s.Equal(1000.0, due.Amount)
+}
+
+func (s *LoanUsecaseTestSuite) TestRemindUpcomingPaymentsOnce() {
+	now := time.Now()
+	loans := []domain.Loan{{
+		ID:     primitive.NewObjectID(),
+		Status: "approved",
+		Schedule: []domain.Installment{
+			{Number: 1, DueDate: now.AddDate(0, 0, 2), Amount: 1000, Reminders: []int{3}},
+		},
+	}}
+	s.mockLoanRepository.On("ActiveLoans").Return(loans, nil).Once()
+
+	reminded, err := s.LoanUsecase.RemindUpcomingPayments(context.Background(), []int{3})
+
+	s.NoError(err)
+	s.Equal(0, reminded)
+	s.mockLoanRepository.AssertNotCalled(s.T(), "UpdateLoan", mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestRemindUpcomingPaymentsOnDueDate() {
+	loans := []domain.Loan{{
+		ID:     primitive.NewObjectID(),
+		Status: "approved",
+		Schedule: []domain.Installment{
+			{Number: 1, DueDate: time.Now(), Amount: 1000, PaidAmount: 400, Reminders: []int{3}},
+		},
+	}}
+	s.mockLoanRepository.On("ActiveLoans").Return(loans, nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.MatchedBy(func(l *domain.Loan) bool {
+		return len(l.Schedule[0].Reminders) == 2 && l.Schedule[0].Reminders[1] == 0
+	})).Return(nil).Once()
+
+	reminded, err := s.LoanUsecase.RemindUpcomingPayments(context.Background(), []int{3})
+
+	// the earlier reminder doesn't stand in for the one on the due date
+	s.NoError(err)
+	s.Equal(1, reminded)
+	s.mockLoanRepository.AssertExpectations(s.T())
+}
+
+func (s *LoanUsecaseTestSuite) TestRemindUpcomingPaymentsEscalatesLeads() {
+	loans := []domain.Loan{{
+		ID:     primitive.NewObjectID(),
+		Status: "approved",
+		Schedule: []domain.Installment{
+			{Number: 1, DueDate: time.Now().AddDate(0, 0, 2), Amount: 1000, Reminders: []int{7}},
+		},
+	}}
+	s.mockLoanRepository.On("ActiveLoans").Return(loans, nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.MatchedBy(func(l *domain.Loan) bool {
+		return len(l.Schedule[0].Reminders) == 2 && l.Schedule[0].Reminders[1] == 3
+	})).Return(nil).Once()
+
+	reminded, err := s.LoanUsecase.RemindUpcomingPayments(context.Background(), []int{7, 3})
+
+	// the week ahead reminder was sent, the one three days ahead is due now
+	s.NoError(err)
+	s.Equal(1, reminded)
+	s.mockLoanRepository.AssertExpectations(s.T())
+}
+
+func (s *LoanUsecaseTestSuite) TestNoticeOverduePayments() {
+	now := time.Now()
+	loan := domain.Loan{
+		ID:     primitive.NewObjectID(),
+		UserID: primitive.NewObjectID(),
+		Status: "approved",
+		Schedule: []domain.Installment{
+			{Number: 1, DueDate: now.AddDate(0, 0, -40), Amount: 1000, PaidAmount: 1000},
+			{Number: 2, DueDate: now.AddDate(0, 0, -10), Amount: 1000, PaidAmount: 200, Notices: []int{1}},
+			{Number: 3, DueDate: now.AddDate(0, 0, -1), Amount: 1000},
+		},
+	}
+
+	published := []domain.Event{}
+	s.mockEventBus.ExpectedCalls = nil
+	s.mockEventBus.On("Publish", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		published = append(published, args.Get(1).(domain.Event))
+	}).Return(nil)
+	s.mockLoanRepository.On("ActiveLoans").Return([]domain.Loan{loan}, nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.MatchedBy(func(l *domain.Loan) bool {
+		return len(l.Schedule[1].Notices) == 2 && l.Schedule[1].Notices[1] == 7 && len(l.Schedule[2].Notices) == 0
+	})).Return(nil).Once()
+
+	noticed, err := s.LoanUsecase.NoticeOverduePayments(context.Background(), []int{1, 7, 30})
+
+	// only the oldest overdue installment gets a notice, the second one
+	s.NoError(err)
+	s.Equal(1, noticed)
+	s.Require().Len(published, 1)
+	overdue := published[0].(*domain.PaymentOverdue)
+	s.Equal(2, overdue.Installment)
+	s.Equal(2, overdue.Notice)
+	s.Equal(10, overdue.DaysPastDue)
+	s.Equal(800.0, overdue.Amount)
+	s.False(overdue.Final)
+}
+
+func (s *LoanUsecaseTestSuite) TestNoticeOverduePaymentsFinal() {
+	loans := []domain.Loan{{
+		ID:     primitive.NewObjectID(),
+		Status: "approved",
+		Schedule: []domain.Installment{
+			{Number: 1, DueDate: time.Now().AddDate(0, 0, -45), Amount: 1000},
+		},
+	}}
+
+	var overdue *domain.PaymentOverdue
+	s.mockEventBus.ExpectedCalls = nil
+	s.mockEventBus.On("Publish", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		overdue = args.Get(1).(*domain.PaymentOverdue)
+	}).Return(nil)
+	s.mockLoanRepository.On("ActiveLoans").Return(loans, nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.Anything).Return(nil).Once()
+
+	_, err := s.LoanUsecase.NoticeOverduePayments(context.Background(), []int{1, 7, 30})
+
+	// the notices missed before are skipped, the latest one reached is sent
+	s.NoError(err)
+	s.Require().NotNil(overdue)
+	s.Equal(3, overdue.Notice)
+	s.True(overdue.Final)
+}
+
+func (s *LoanUsecaseTestSuite) TestNoticeOverduePaymentsOnce() {
+	loans := []domain.Loan{{
+		ID:     primitive.NewObjectID(),
+		Status: "approved",
+		Schedule: []domain.Installment{
+			{Number: 1, DueDate: time.Now().AddDate(0, 0, -3), Amount: 1000, Notices: []int{1}},
+		},
+	}}
+	s.mockLoanRepository.On("ActiveLoans").Return(loans, nil).Once()
+
+	noticed, err := s.LoanUsecase.NoticeOverduePayments(context.Background(), []int{1, 7, 30})
+
+	s.NoError(err)
+	s.Equal(0, noticed)
+	s.mockLoanRepository.AssertNotCalled(s.T(), "UpdateLoan", mock.Anything, mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestAgingReport() {
+	overdue := func(days int, amount float64) domain.Loan {
+		return domain.Loan{
+			ID:     primitive.NewObjectID(),
+			Status: "approved",
+			Schedule: []domain.Installment{
+				{Number: 1, DueDate: time.Now().AddDate(0, 0, -days), Amount: amount},
+			},
+		}
+	}
+	loans := []domain.Loan{
+		overdue(0, 100),
+		overdue(10, 200),
+		overdue(25, 300),
+		overdue(45, 400),
+		overdue(120, 500),
+	}
+
+	s.mockLoanRepository.On("ActiveLoans").Return(loans, nil).Once()
+
+	buckets, err := s.LoanUsecase.AgingReport(context.Background())
+
+	s.NoError(err)
+	s.Len(buckets, 4)
+	s.Equal(2, buckets[0].Count)
+	s.Equal(500.0, buckets[0].Outstanding)
+	s.Equal(1, buckets[1].Count)
+	s.Equal(0, buckets[2].Count)
+	s.Equal(1, buckets[3].Count)
+	s.Equal(500.0, buckets[3].Outstanding)
+}
+
+func (s *LoanUsecaseTestSuite) TestWaiveCharge() {
+	chargeID := primitive.NewObjectID()
+	loan := domain.Loan{
+		ID:     primitive.NewObjectID(),
+		Status: "approved",
+		Charges: []domain.Charge{
+			{ID: chargeID, Installment: 1, Type: domain.ChargeLateFee, Amount: 25},
+		},
+	}
+
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(loan, nil).Once()
+	s.mockLoanRepository.On("UpdateLoan", mock.Anything, mock.MatchedBy(func(loan *domain.Loan) bool {
+		return loan.Charges[0].Waived && loan.Charges[0].WaiveReason == "hospitalized"
+	})).Return(nil).Once()
+
+	err := s.LoanUsecase.WaiveCharge(context.Background(), "testloanid", chargeID.Hex(), "hospitalized", "testuserid")
+
+	s.NoError(err)
+	s.mockLoanRepository.AssertExpectations(s.T())
+}
+
+func (s *LoanUsecaseTestSuite) TestWaiveChargeRequiresReason() {
+	err := s.LoanUsecase.WaiveCharge(context.Background(), "testloanid", "testchargeid", " ", "testuserid")
+
+	s.Error(err)
+	s.mockLoanRepository.AssertNotCalled(s.T(), "GetLoan", mock.Anything)
+}
+
+func (s *LoanUsecaseTestSuite) TestDeleteLoan() {
+	s.mockLoanRepository.On("GetLoan", "testloanid").Return(domain.Loan{ID: primitive.NewObjectID()}, nil).Once()
+	s.mockLoanRepository.On("DeleteLoan", mock.Anything, "testloanid", "duplicate", "testuserid").Return(nil).Once()
+
+	err := s.LoanUsecase.DeleteLoan(context.Background(), "testloanid", "duplicate", "testuserid")
+
+	s.NoError(err)
+}
+
+func (s *LoanUsecaseTestSuite) TestRestoreLoan() {
+	s.mockLoanRepository.On("RestoreLoan", mock.Anything, "testloanid").Return(nil).Once()
+
+	err := s.LoanUsecase.RestoreLoan(context.Background(), "testloanid", "testuserid")
+
+	s.NoError(err)
+}
+
+func (s *LoanUsecaseTestSuite) TestPurgeDeletedLoans() {
+	s.mockLoanRepository.On("PurgeDeletedLoans", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		return before.Before(time.Now().Add(-6*24*time.Hour)) && before.After(time.Now().Add(-8*24*time.Hour))
+	})).Return(3, nil).Once()
 
-	logs, err := s.LoanUsecase.ViewLogs(context.Background())
+	purged, err := s.LoanUsecase.PurgeDeletedLoans(context.Background(), 7*24*time.Hour)
 
 	s.NoError(err)
-	s.Equal(expectedLogs, logs)
+	s.Equal(3, purged)
 }
 
 func TestLoanUsecaseTestSuite(t *testing.T) {
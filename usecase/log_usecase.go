@@ -0,0 +1,269 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"loan_tracker_api/domain"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type LogUsecase struct {
+	LogRepo        domain.LogRepository
+	Checkpoints    domain.CheckpointStore
+	Archiver       domain.LogArchiver
+	Retention      domain.RetentionPolicy
+	contextTimeout time.Duration
+}
+
+func NewLogUsecase(Logrepo domain.LogRepository, checkpoints domain.CheckpointStore, archiver domain.LogArchiver, retention domain.RetentionPolicy, timeout time.Duration) domain.LogUsecase {
+	return &LogUsecase{
+		LogRepo:        Logrepo,
+		Checkpoints:    checkpoints,
+		Archiver:       archiver,
+		Retention:      retention,
+		contextTimeout: timeout,
+	}
+}
+
+// how many chained entries are read at a time while verifying the chain
+const verifyBatchSize = 500
+
+// how many entries go into a single archive file
+const archiveBatchSize = 1000
+
+// checkTimeRange rejects a filter whose time range ends before it starts
+func checkTimeRange(filter domain.LogFilter) error {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return errors.New("Invalid time range")
+	}
+	return nil
+}
+
+func (luse *LogUsecase) ViewLogs(c context.Context, filter domain.LogFilter) ([]domain.Log, error) {
+	_, cancel := context.WithTimeout(c, luse.contextTimeout)
+	defer cancel()
+
+	if err := checkTimeRange(filter); err != nil {
+		return nil, err
+	}
+
+	return luse.LogRepo.ViewLogs(filter)
+}
+
+// ExportLogs hands every entry matching the filter to each, oldest first, so they can be streamed out
+func (luse *LogUsecase) ExportLogs(c context.Context, filter domain.LogFilter, each func(entry domain.Log) error) error {
+	_, cancel := context.WithTimeout(c, luse.contextTimeout)
+	defer cancel()
+
+	if err := checkTimeRange(filter); err != nil {
+		return err
+	}
+
+	return luse.LogRepo.StreamLogs(filter, each)
+}
+
+// VerifyLogs walks the audit chain from its first entry and reports the first entry that was edited,
+// removed or doesn't match an anchored checkpoint. Archived entries are checked against the content
+// kept in the archive files.
+func (luse *LogUsecase) VerifyLogs(c context.Context) (domain.LogVerification, error) {
+	_, cancel := context.WithTimeout(c, luse.contextTimeout)
+	defer cancel()
+
+	checkpoints, err := luse.Checkpoints.Checkpoints()
+	if err != nil {
+		return domain.LogVerification{}, err
+	}
+
+	anchored := make(map[int64]string, len(checkpoints))
+	var anchoredHead int64
+	for _, checkpoint := range checkpoints {
+		anchored[checkpoint.Sequence] = checkpoint.Hash
+		if checkpoint.Sequence > anchoredHead {
+			anchoredHead = checkpoint.Sequence
+		}
+	}
+
+	verification := domain.LogVerification{Valid: true}
+	broken := func(entry domain.Log, sequence int64, reason string) (domain.LogVerification, error) {
+		verification.Valid = false
+		verification.BrokenLink = &domain.BrokenLink{Sequence: sequence, EntryID: entry.ID, Reason: reason}
+		return verification, nil
+	}
+
+	var previous domain.Log
+	for {
+		entries, err := luse.LogRepo.ChainedLogs(previous.Sequence, verifyBatchSize)
+		if err != nil {
+			return domain.LogVerification{}, err
+		}
+
+		archived, err := luse.archivedContent(entries)
+		if err != nil {
+			return domain.LogVerification{}, err
+		}
+
+		for _, entry := range entries {
+			content, inArchive := archived[entry.Sequence]
+			switch {
+			case entry.Sequence != previous.Sequence+1:
+				return broken(domain.Log{}, previous.Sequence+1, "Entry is missing from the chain")
+			case entry.PrevHash != previous.Hash:
+				return broken(entry, entry.Sequence, "Entry doesn't link to the previous entry")
+			case entry.Archived && !inArchive:
+				return broken(entry, entry.Sequence, "Archived entry is missing from the archive")
+			case entry.Archived && (content.ID != entry.ID || content.ComputeHash() != entry.Hash):
+				return broken(entry, entry.Sequence, "Archived entry content doesn't match its hash")
+			case !entry.Archived && entry.ComputeHash() != entry.Hash:
+				return broken(entry, entry.Sequence, "Entry content doesn't match its hash")
+			}
+
+			if hash, ok := anchored[entry.Sequence]; ok && hash != entry.Hash {
+				return broken(entry, entry.Sequence, "Entry doesn't match its anchored checkpoint")
+			}
+
+			verification.Checked++
+			previous = entry
+		}
+
+		if len(entries) < verifyBatchSize {
+			break
+		}
+	}
+
+	// entries removed from the end of the chain only show up against the checkpoints
+	if anchoredHead > previous.Sequence {
+		return broken(domain.Log{}, previous.Sequence+1, "Entry is missing from the chain")
+	}
+
+	return verification, nil
+}
+
+// archivedContent reads the content of the archived entries among the given ones back from the archive, by sequence
+func (luse *LogUsecase) archivedContent(entries []domain.Log) (map[int64]domain.Log, error) {
+	var from, to int64
+	for _, entry := range entries {
+		if !entry.Archived {
+			continue
+		}
+		if from == 0 {
+			from = entry.Sequence
+		}
+		to = entry.Sequence
+	}
+
+	content := map[int64]domain.Log{}
+	if from == 0 {
+		return content, nil
+	}
+
+	archived, err := luse.Archiver.ArchivedLogs(from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range archived {
+		content[entry.Sequence] = entry
+	}
+	return content, nil
+}
+
+// CheckpointLogs anchors the current head of the audit chain, nothing new is written while the chain hasn't grown
+func (luse *LogUsecase) CheckpointLogs(c context.Context) (domain.LogCheckpoint, error) {
+	_, cancel := context.WithTimeout(c, luse.contextTimeout)
+	defer cancel()
+
+	head, err := luse.LogRepo.LastLog()
+	if err != nil {
+		return domain.LogCheckpoint{}, err
+	}
+
+	if head.Sequence == 0 {
+		return domain.LogCheckpoint{}, errors.New("Audit log is empty")
+	}
+
+	checkpoints, err := luse.Checkpoints.Checkpoints()
+	if err != nil {
+		return domain.LogCheckpoint{}, err
+	}
+
+	if len(checkpoints) > 0 && checkpoints[len(checkpoints)-1].Sequence == head.Sequence {
+		return checkpoints[len(checkpoints)-1], nil
+	}
+
+	checkpoint := domain.LogCheckpoint{
+		Sequence:  head.Sequence,
+		Hash:      head.Hash,
+		CreatedAt: time.Now(),
+	}
+
+	return checkpoint, luse.Checkpoints.AppendCheckpoint(checkpoint)
+}
+
+// ArchiveLogs moves the entries past the retention of their category to the archive, their place in the chain
+// is kept so the chain still verifies
+func (luse *LogUsecase) ArchiveLogs(c context.Context) (int, error) {
+	_, cancel := context.WithTimeout(c, luse.contextTimeout)
+	defer cancel()
+
+	now := time.Now()
+	cutoffs := make(map[string]time.Time, len(luse.Retention))
+	for category, retention := range luse.Retention {
+		cutoffs[category] = now.Add(-retention)
+	}
+
+	archived := 0
+	files := []string{}
+	for {
+		// a cancelled run stops between batches, the batches archived so far are still audited
+		if c.Err() != nil {
+			break
+		}
+
+		entries, err := luse.LogRepo.ArchivableLogs(cutoffs, archiveBatchSize)
+		if err != nil {
+			return archived, err
+		}
+		if len(entries) == 0 {
+			break
+		}
+
+		path, err := luse.Archiver.ArchiveLogs(entries)
+		if err != nil {
+			return archived, err
+		}
+
+		ids := make([]primitive.ObjectID, len(entries))
+		for i := range entries {
+			ids[i] = entries[i].ID
+		}
+
+		if err := luse.LogRepo.MarkArchived(c, ids); err != nil {
+			return archived, err
+		}
+
+		archived += len(entries)
+		files = append(files, path)
+
+		if len(entries) < archiveBatchSize {
+			break
+		}
+	}
+
+	if archived == 0 {
+		return 0, c.Err()
+	}
+
+	err := audit(context.WithoutCancel(c), luse.LogRepo, "", domain.Log{
+		Action:     domain.ActionLogArchived,
+		TargetType: domain.TargetLog,
+		Note:       fmt.Sprintf("Archived %d entries to %s", archived, strings.Join(files, ", ")),
+	})
+	if err != nil {
+		return archived, err
+	}
+
+	return archived, c.Err()
+}
@@ -0,0 +1,312 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"loan_tracker_api/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type LogUsecaseTestSuite struct {
+	suite.Suite
+	mockLogRepository   *mocks.LogRepository
+	mockCheckpointStore *mocks.CheckpointStore
+	mockLogArchiver     *mocks.LogArchiver
+	LogUsecase          domain.LogUsecase
+}
+
+func (s *LogUsecaseTestSuite) SetupTest() {
+	s.mockLogRepository = new(mocks.LogRepository)
+	s.mockCheckpointStore = new(mocks.CheckpointStore)
+	s.mockLogArchiver = new(mocks.LogArchiver)
+	retention := domain.RetentionPolicy{
+		domain.CategoryAuth: 90 * 24 * time.Hour,
+		domain.CategoryLoan: 7 * 365 * 24 * time.Hour,
+	}
+	s.LogUsecase = usecase.NewLogUsecase(s.mockLogRepository, s.mockCheckpointStore, s.mockLogArchiver, retention, time.Second*2)
+}
+
+// chain links audit entries the way the repository stores them
+func chain(count int) []domain.Log {
+	entries := make([]domain.Log, count)
+	prevHash := ""
+	for i := range entries {
+		entries[i] = domain.Log{
+			ID:         primitive.NewObjectID(),
+			Sequence:   int64(i + 1),
+			Action:     domain.ActionLoanPayment,
+			TargetType: domain.TargetLoan,
+			Changes:    []domain.FieldChange{{Field: "status", Before: "approved", After: "paid_off"}},
+			CreatedAt:  time.Now().UTC().Truncate(time.Millisecond),
+			PrevHash:   prevHash,
+		}
+		entries[i].Hash = entries[i].ComputeHash()
+		prevHash = entries[i].Hash
+	}
+	return entries
+}
+
+func (s *LogUsecaseTestSuite) TestViewLogs() {
+	expectedLogs := []domain.Log{
+		{
+			ID:     primitive.NewObjectID(),
+			Action: domain.ActionLoanApproved,
+		},
+	}
+	filter := domain.LogFilter{
+		TargetType: domain.TargetLoan,
+		From:       time.Now().Add(-time.Hour),
+		To:         time.Now(),
+	}
+
+	s.mockLogRepository.On("ViewLogs", filter).Return(expectedLogs, nil).Once()
+
+	logs, err := s.LogUsecase.ViewLogs(context.Background(), filter)
+
+	s.NoError(err)
+	s.Equal(expectedLogs, logs)
+}
+
+func (s *LogUsecaseTestSuite) TestViewLogsInvalidTimeRange() {
+	filter := domain.LogFilter{
+		From: time.Now(),
+		To:   time.Now().Add(-time.Hour),
+	}
+
+	_, err := s.LogUsecase.ViewLogs(context.Background(), filter)
+
+	s.Error(err)
+	s.mockLogRepository.AssertNotCalled(s.T(), "ViewLogs", mock.Anything)
+}
+
+func (s *LogUsecaseTestSuite) TestVerifyLogs() {
+	entries := chain(3)
+
+	s.mockCheckpointStore.On("Checkpoints").Return([]domain.LogCheckpoint{{Sequence: 2, Hash: entries[1].Hash}}, nil).Once()
+	s.mockLogRepository.On("ChainedLogs", int64(0), 500).Return(entries, nil).Once()
+
+	verification, err := s.LogUsecase.VerifyLogs(context.Background())
+
+	s.NoError(err)
+	s.True(verification.Valid)
+	s.Equal(int64(3), verification.Checked)
+	s.Nil(verification.BrokenLink)
+}
+
+func (s *LogUsecaseTestSuite) TestVerifyLogsEditedEntry() {
+	entries := chain(3)
+	entries[1].Note = "edited"
+
+	s.mockCheckpointStore.On("Checkpoints").Return([]domain.LogCheckpoint{}, nil).Once()
+	s.mockLogRepository.On("ChainedLogs", int64(0), 500).Return(entries, nil).Once()
+
+	verification, err := s.LogUsecase.VerifyLogs(context.Background())
+
+	s.NoError(err)
+	s.False(verification.Valid)
+	s.Equal(int64(1), verification.Checked)
+	s.Equal(int64(2), verification.BrokenLink.Sequence)
+	s.Equal(entries[1].ID, verification.BrokenLink.EntryID)
+}
+
+func (s *LogUsecaseTestSuite) TestVerifyLogsRemovedEntry() {
+	entries := chain(3)
+
+	s.mockCheckpointStore.On("Checkpoints").Return([]domain.LogCheckpoint{}, nil).Once()
+	s.mockLogRepository.On("ChainedLogs", int64(0), 500).Return([]domain.Log{entries[0], entries[2]}, nil).Once()
+
+	verification, err := s.LogUsecase.VerifyLogs(context.Background())
+
+	s.NoError(err)
+	s.False(verification.Valid)
+	s.Equal(int64(2), verification.BrokenLink.Sequence)
+}
+
+func (s *LogUsecaseTestSuite) TestVerifyLogsRewrittenChain() {
+	entries := chain(3)
+	anchored := entries[1].Hash
+
+	// rehashing the whole chain after an edit keeps the links intact, only the checkpoint catches it
+	entries[1].Note = "edited"
+	entries[1].Hash = entries[1].ComputeHash()
+	entries[2].PrevHash = entries[1].Hash
+	entries[2].Hash = entries[2].ComputeHash()
+
+	s.mockCheckpointStore.On("Checkpoints").Return([]domain.LogCheckpoint{{Sequence: 2, Hash: anchored}}, nil).Once()
+	s.mockLogRepository.On("ChainedLogs", int64(0), 500).Return(entries, nil).Once()
+
+	verification, err := s.LogUsecase.VerifyLogs(context.Background())
+
+	s.NoError(err)
+	s.False(verification.Valid)
+	s.Equal(int64(2), verification.BrokenLink.Sequence)
+}
+
+func (s *LogUsecaseTestSuite) TestVerifyLogsTruncatedChain() {
+	entries := chain(3)
+
+	s.mockCheckpointStore.On("Checkpoints").Return([]domain.LogCheckpoint{{Sequence: 3, Hash: entries[2].Hash}}, nil).Once()
+	s.mockLogRepository.On("ChainedLogs", int64(0), 500).Return(entries[:2], nil).Once()
+
+	verification, err := s.LogUsecase.VerifyLogs(context.Background())
+
+	s.NoError(err)
+	s.False(verification.Valid)
+	s.Equal(int64(3), verification.BrokenLink.Sequence)
+}
+
+func (s *LogUsecaseTestSuite) TestCheckpointLogs() {
+	entries := chain(2)
+
+	s.mockLogRepository.On("LastLog").Return(entries[1], nil).Once()
+	s.mockCheckpointStore.On("Checkpoints").Return([]domain.LogCheckpoint{{Sequence: 1, Hash: entries[0].Hash}}, nil).Once()
+	s.mockCheckpointStore.On("AppendCheckpoint", mock.MatchedBy(func(checkpoint domain.LogCheckpoint) bool {
+		return checkpoint.Sequence == 2 && checkpoint.Hash == entries[1].Hash
+	})).Return(nil).Once()
+
+	checkpoint, err := s.LogUsecase.CheckpointLogs(context.Background())
+
+	s.NoError(err)
+	s.Equal(int64(2), checkpoint.Sequence)
+	s.mockCheckpointStore.AssertExpectations(s.T())
+}
+
+func (s *LogUsecaseTestSuite) TestCheckpointLogsUnchangedChain() {
+	entries := chain(2)
+
+	s.mockLogRepository.On("LastLog").Return(entries[1], nil).Once()
+	s.mockCheckpointStore.On("Checkpoints").Return([]domain.LogCheckpoint{{Sequence: 2, Hash: entries[1].Hash}}, nil).Once()
+
+	_, err := s.LogUsecase.CheckpointLogs(context.Background())
+
+	s.NoError(err)
+	s.mockCheckpointStore.AssertNotCalled(s.T(), "AppendCheckpoint", mock.Anything)
+}
+
+func (s *LogUsecaseTestSuite) TestVerifyLogsArchivedEntries() {
+	entries := chain(3)
+	content := entries[0]
+
+	// archived entries lose their content but keep their links, the content is checked in the archive
+	entries[0] = domain.Log{ID: entries[0].ID, Sequence: 1, Hash: entries[0].Hash, Archived: true}
+
+	s.mockCheckpointStore.On("Checkpoints").Return([]domain.LogCheckpoint{}, nil).Once()
+	s.mockLogRepository.On("ChainedLogs", int64(0), 500).Return(entries, nil).Once()
+	s.mockLogArchiver.On("ArchivedLogs", int64(1), int64(1)).Return([]domain.Log{content}, nil).Once()
+
+	verification, err := s.LogUsecase.VerifyLogs(context.Background())
+
+	s.NoError(err)
+	s.True(verification.Valid)
+	s.Equal(int64(3), verification.Checked)
+}
+
+func (s *LogUsecaseTestSuite) TestVerifyLogsMissingFromArchive() {
+	entries := chain(2)
+	entries[0] = domain.Log{ID: entries[0].ID, Sequence: 1, Hash: entries[0].Hash, Archived: true}
+
+	s.mockCheckpointStore.On("Checkpoints").Return([]domain.LogCheckpoint{}, nil).Once()
+	s.mockLogRepository.On("ChainedLogs", int64(0), 500).Return(entries, nil).Once()
+	s.mockLogArchiver.On("ArchivedLogs", int64(1), int64(1)).Return([]domain.Log{}, nil).Once()
+
+	verification, err := s.LogUsecase.VerifyLogs(context.Background())
+
+	s.NoError(err)
+	s.False(verification.Valid)
+	s.Equal(&domain.BrokenLink{Sequence: 1, EntryID: entries[0].ID, Reason: "Archived entry is missing from the archive"}, verification.BrokenLink)
+}
+
+func (s *LogUsecaseTestSuite) TestVerifyLogsEditedArchivedContent() {
+	entries := chain(2)
+	content := entries[0]
+	content.Note = "edited after archiving"
+
+	entries[0] = domain.Log{ID: entries[0].ID, Sequence: 1, Hash: entries[0].Hash, Archived: true}
+
+	s.mockCheckpointStore.On("Checkpoints").Return([]domain.LogCheckpoint{}, nil).Once()
+	s.mockLogRepository.On("ChainedLogs", int64(0), 500).Return(entries, nil).Once()
+	s.mockLogArchiver.On("ArchivedLogs", int64(1), int64(1)).Return([]domain.Log{content}, nil).Once()
+
+	verification, err := s.LogUsecase.VerifyLogs(context.Background())
+
+	s.NoError(err)
+	s.False(verification.Valid)
+	s.Equal("Archived entry content doesn't match its hash", verification.BrokenLink.Reason)
+}
+
+func (s *LogUsecaseTestSuite) TestExportLogs() {
+	entries := chain(2)
+	filter := domain.LogFilter{Action: domain.ActionLoanPayment}
+
+	s.mockLogRepository.On("StreamLogs", filter, mock.Anything).Run(func(args mock.Arguments) {
+		each := args.Get(1).(func(entry domain.Log) error)
+		for _, entry := range entries {
+			each(entry)
+		}
+	}).Return(nil).Once()
+
+	exported := []domain.Log{}
+	err := s.LogUsecase.ExportLogs(context.Background(), filter, func(entry domain.Log) error {
+		exported = append(exported, entry)
+		return nil
+	})
+
+	s.NoError(err)
+	s.Equal(entries, exported)
+}
+
+func (s *LogUsecaseTestSuite) TestExportLogsInvalidTimeRange() {
+	filter := domain.LogFilter{
+		From: time.Now(),
+		To:   time.Now().Add(-time.Hour),
+	}
+
+	err := s.LogUsecase.ExportLogs(context.Background(), filter, func(entry domain.Log) error { return nil })
+
+	s.Error(err)
+	s.mockLogRepository.AssertNotCalled(s.T(), "StreamLogs", mock.Anything, mock.Anything)
+}
+
+func (s *LogUsecaseTestSuite) TestArchiveLogs() {
+	entries := chain(2)
+
+	s.mockLogRepository.On("ArchivableLogs", mock.MatchedBy(func(cutoffs map[string]time.Time) bool {
+		auth := cutoffs[domain.CategoryAuth]
+		return len(cutoffs) == 2 && auth.Before(time.Now().AddDate(0, 0, -89)) && auth.After(time.Now().AddDate(0, 0, -91))
+	}), 1000).Return(entries, nil).Once()
+	s.mockLogArchiver.On("ArchiveLogs", entries).Return("audit_archive/audit-1-2.jsonl.gz", nil).Once()
+	s.mockLogRepository.On("MarkArchived", mock.Anything, []primitive.ObjectID{entries[0].ID, entries[1].ID}).Return(nil).Once()
+	s.mockLogRepository.On("AddLog", mock.Anything, mock.MatchedBy(func(entry *domain.Log) bool {
+		return entry.Action == domain.ActionLogArchived && entry.ActorRole == domain.RoleSystem
+	})).Return(nil).Once()
+
+	archived, err := s.LogUsecase.ArchiveLogs(context.Background())
+
+	s.NoError(err)
+	s.Equal(2, archived)
+	s.mockLogRepository.AssertExpectations(s.T())
+}
+
+func (s *LogUsecaseTestSuite) TestArchiveLogsArchiveFailure() {
+	entries := chain(2)
+
+	s.mockLogRepository.On("ArchivableLogs", mock.Anything, 1000).Return(entries, nil).Once()
+	s.mockLogArchiver.On("ArchiveLogs", entries).Return("", errors.New("Error writing archive file")).Once()
+
+	_, err := s.LogUsecase.ArchiveLogs(context.Background())
+
+	// entries stay in the database when they couldn't be written to the archive
+	s.Error(err)
+	s.mockLogRepository.AssertNotCalled(s.T(), "MarkArchived", mock.Anything, mock.Anything)
+}
+
+func TestLogUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(LogUsecaseTestSuite))
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"loan_tracker_api/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationDispatcher queues notifications for the channels chosen for their type and user, and hands them to the notifier of
+// each channel once the change they tell about is committed
+type NotificationDispatcher struct {
+	UserRepo   domain.UserRepository
+	OutboxRepo domain.OutboxRepository
+	Notifiers  map[string]domain.Notifier
+	Channels   map[string][]string
+}
+
+func NewNotificationDispatcher(Userrepo domain.UserRepository, Outboxrepo domain.OutboxRepository, notifiers map[string]domain.Notifier, channels map[string][]string) *NotificationDispatcher {
+	return &NotificationDispatcher{
+		UserRepo:   Userrepo,
+		OutboxRepo: Outboxrepo,
+		Notifiers:  notifiers,
+		Channels:   channels,
+	}
+}
+
+// channelsFor picks the channels a notification of the type reaches the user through, their own choice for the type when they
+// made one and the configured channels otherwise. Required notifications always go through the configured channels.
+// Channels without a notifier are left out.
+func (nd *NotificationDispatcher) channelsFor(user domain.User, kind string) []string {
+	channels := nd.Channels[kind]
+	if chosen, ok := user.NotificationChannels[kind]; ok && !domain.RequiredNotifications[kind] {
+		channels = chosen
+	}
+
+	available := []string{}
+	for _, channel := range channels {
+		if _, ok := nd.Notifiers[channel]; ok {
+			available = append(available, channel)
+		}
+	}
+	return available
+}
+
+// Notify queues a notification for each of its channels in the unit of work of the change it tells about.
+// People without an account, such as guarantors invited by email, can only be reached by email at their address.
+func (nd *NotificationDispatcher) Notify(c context.Context, user domain.User, kind string, data map[string]interface{}) error {
+	if _, ok := nd.Channels[kind]; !ok {
+		return errors.New("Unknown notification " + kind)
+	}
+
+	notification := domain.Notification{
+		ID:        primitive.NewObjectID(),
+		UserID:    user.ID,
+		Type:      kind,
+		Data:      data,
+		CreatedAt: time.Now(),
+	}
+
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return errors.New("Error encoding notification")
+	}
+
+	recipient := user.ID.Hex()
+	if user.ID.IsZero() {
+		recipient = user.Email
+	}
+
+	for _, channel := range nd.channelsFor(user, kind) {
+		if user.ID.IsZero() && channel != domain.ChannelEmail {
+			continue
+		}
+		err := nd.OutboxRepo.AddMessage(c, &domain.OutboxMessage{
+			Kind:      domain.OutboxNotification,
+			Topic:     channel,
+			Recipient: recipient,
+			Payload:   string(payload),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Send hands a queued notification to the notifier of its channel with the user as they are now
+func (nd *NotificationDispatcher) Send(message domain.OutboxMessage) error {
+	notifier, ok := nd.Notifiers[message.Topic]
+	if !ok {
+		return errors.New("No notifier for channel " + message.Topic)
+	}
+
+	var notification domain.Notification
+	if err := json.Unmarshal([]byte(message.Payload), &notification); err != nil {
+		return errors.New("Invalid notification payload")
+	}
+
+	// recipients without an account are queued by email address
+	if !primitive.IsValidObjectID(message.Recipient) {
+		return notifier.Notify(domain.User{Email: message.Recipient}, notification)
+	}
+
+	user, err := nd.UserRepo.UserProfile(message.Recipient)
+	if err != nil {
+		return err
+	}
+
+	return notifier.Notify(user, notification)
+}
@@ -0,0 +1,261 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"loan_tracker_api/usecase"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type NotificationDispatcherTestSuite struct {
+	suite.Suite
+	mockUserRepository   *mocks.UserRepository
+	mockOutboxRepository *mocks.OutboxRepository
+	mockEmailNotifier    *mocks.Notifier
+	mockInAppNotifier    *mocks.Notifier
+	Dispatcher           *usecase.NotificationDispatcher
+}
+
+func (s *NotificationDispatcherTestSuite) SetupTest() {
+	s.mockUserRepository = new(mocks.UserRepository)
+	s.mockOutboxRepository = new(mocks.OutboxRepository)
+	s.mockEmailNotifier = new(mocks.Notifier)
+	s.mockInAppNotifier = new(mocks.Notifier)
+
+	// no SMS gateway is configured
+	notifiers := map[string]domain.Notifier{
+		domain.ChannelEmail: s.mockEmailNotifier,
+		domain.ChannelInApp: s.mockInAppNotifier,
+	}
+	s.Dispatcher = usecase.NewNotificationDispatcher(s.mockUserRepository, s.mockOutboxRepository, notifiers, domain.DefaultChannels)
+}
+
+// queuedChannels records the channels of the notifications queued in the outbox
+func (s *NotificationDispatcherTestSuite) queuedChannels() *[]string {
+	channels := []string{}
+	s.mockOutboxRepository.On("AddMessage", mock.Anything, mock.MatchedBy(func(m *domain.OutboxMessage) bool {
+		return m.Kind == domain.OutboxNotification
+	})).Run(func(args mock.Arguments) {
+		channels = append(channels, args.Get(1).(*domain.OutboxMessage).Topic)
+	}).Return(nil)
+	return &channels
+}
+
+func (s *NotificationDispatcherTestSuite) TestNotifyUsesConfiguredChannels() {
+	channels := s.queuedChannels()
+	user := domain.User{ID: primitive.NewObjectID()}
+
+	err := s.Dispatcher.Notify(context.Background(), user, domain.NotificationPaymentDue, map[string]interface{}{})
+
+	// SMS is left out without a gateway
+	s.NoError(err)
+	s.Equal([]string{domain.ChannelEmail, domain.ChannelInApp}, *channels)
+}
+
+func (s *NotificationDispatcherTestSuite) TestNotifyUsesUserChoice() {
+	channels := s.queuedChannels()
+	user := domain.User{ID: primitive.NewObjectID(), NotificationChannels: map[string][]string{
+		domain.NotificationPaymentReceived: {domain.ChannelInApp},
+		domain.NotificationLoanApproved:    {},
+	}}
+
+	s.NoError(s.Dispatcher.Notify(context.Background(), user, domain.NotificationPaymentReceived, map[string]interface{}{}))
+	s.NoError(s.Dispatcher.Notify(context.Background(), user, domain.NotificationLoanApproved, map[string]interface{}{}))
+
+	// the user opted out of approval notifications entirely
+	s.Equal([]string{domain.ChannelInApp}, *channels)
+}
+
+func (s *NotificationDispatcherTestSuite) TestNotifyIgnoresChoiceForRequiredNotifications() {
+	channels := s.queuedChannels()
+	user := domain.User{ID: primitive.NewObjectID(), NotificationChannels: map[string][]string{
+		domain.NotificationPasswordReset: {},
+	}}
+
+	err := s.Dispatcher.Notify(context.Background(), user, domain.NotificationPasswordReset, map[string]interface{}{})
+
+	s.NoError(err)
+	s.Equal([]string{domain.ChannelEmail}, *channels)
+}
+
+func (s *NotificationDispatcherTestSuite) TestNotifyUnknownType() {
+	err := s.Dispatcher.Notify(context.Background(), domain.User{}, "loan.unknown", nil)
+
+	s.EqualError(err, "Unknown notification loan.unknown")
+	s.mockOutboxRepository.AssertNotCalled(s.T(), "AddMessage", mock.Anything, mock.Anything)
+}
+
+func (s *NotificationDispatcherTestSuite) TestSendHandsNotificationToChannel() {
+	user := domain.User{ID: primitive.NewObjectID(), Email: "test@gmail.com", Language: "fr"}
+	payload, _ := json.Marshal(domain.Notification{ID: primitive.NewObjectID(), Type: domain.NotificationLoanApproved, Data: map[string]interface{}{"Amount": 500.0}})
+	s.mockUserRepository.On("UserProfile", user.ID.Hex()).Return(user, nil).Once()
+	s.mockInAppNotifier.On("Notify", user, mock.MatchedBy(func(n domain.Notification) bool {
+		return n.Type == domain.NotificationLoanApproved && n.Data["Amount"] == 500.0
+	})).Return(nil).Once()
+
+	err := s.Dispatcher.Send(domain.OutboxMessage{Kind: domain.OutboxNotification, Topic: domain.ChannelInApp, Recipient: user.ID.Hex(), Payload: string(payload)})
+
+	s.NoError(err)
+	s.mockInAppNotifier.AssertExpectations(s.T())
+	s.mockEmailNotifier.AssertNotCalled(s.T(), "Notify", mock.Anything, mock.Anything)
+}
+
+func (s *NotificationDispatcherTestSuite) TestSendFailureIsRetried() {
+	user := domain.User{ID: primitive.NewObjectID()}
+	s.mockUserRepository.On("UserProfile", user.ID.Hex()).Return(user, nil).Once()
+	s.mockEmailNotifier.On("Notify", user, mock.Anything).Return(errors.New("failed to send email: connection refused")).Once()
+
+	err := s.Dispatcher.Send(domain.OutboxMessage{Kind: domain.OutboxNotification, Topic: domain.ChannelEmail, Recipient: user.ID.Hex(), Payload: `{"type":"loan_approved"}`})
+
+	s.EqualError(err, "failed to send email: connection refused")
+}
+
+func (s *NotificationDispatcherTestSuite) TestNotifyWithoutAccountOnlyEmails() {
+	var recipients []string
+	s.mockOutboxRepository.On("AddMessage", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		message := args.Get(1).(*domain.OutboxMessage)
+		recipients = append(recipients, message.Topic+":"+message.Recipient)
+	}).Return(nil)
+
+	err := s.Dispatcher.Notify(context.Background(), domain.User{Email: "guarantor@gmail.com"}, domain.NotificationLoanPartyInvite, map[string]interface{}{})
+
+	s.NoError(err)
+	s.Equal([]string{"email:guarantor@gmail.com"}, recipients)
+}
+
+func (s *NotificationDispatcherTestSuite) TestSendToEmailAddress() {
+	s.mockEmailNotifier.On("Notify", domain.User{Email: "guarantor@gmail.com"}, mock.Anything).Return(nil).Once()
+
+	err := s.Dispatcher.Send(domain.OutboxMessage{Kind: domain.OutboxNotification, Topic: domain.ChannelEmail, Recipient: "guarantor@gmail.com", Payload: `{"type":"loan_party_invite"}`})
+
+	s.NoError(err)
+	s.mockEmailNotifier.AssertExpectations(s.T())
+	s.mockUserRepository.AssertNotCalled(s.T(), "UserProfile", mock.Anything)
+}
+
+func (s *NotificationDispatcherTestSuite) TestSendWithoutNotifier() {
+	err := s.Dispatcher.Send(domain.OutboxMessage{Kind: domain.OutboxNotification, Topic: domain.ChannelSMS, Payload: `{}`})
+
+	s.EqualError(err, "No notifier for channel sms")
+}
+
+func (s *NotificationDispatcherTestSuite) TestSubscriberNotifiesVerificationFromEvent() {
+	dispatcher := new(mocks.NotificationDispatcher)
+	subscriber := usecase.NewNotificationSubscriber(dispatcher, s.mockUserRepository)
+	registered := &domain.UserRegistered{UserID: primitive.NewObjectID(), Email: "test@gmail.com", UserName: "testuser", Language: "fr"}
+	dispatcher.On("Notify", mock.Anything, mock.MatchedBy(func(u domain.User) bool {
+		return u.ID == registered.UserID && u.Email == "test@gmail.com" && u.Language == "fr"
+	}), domain.NotificationVerification, map[string]interface{}{"UserName": "testuser"}).Return(nil).Once()
+
+	err := subscriber.Verification(context.Background(), registered)
+
+	// the user isn't read back, they aren't committed yet
+	s.NoError(err)
+	dispatcher.AssertExpectations(s.T())
+	s.mockUserRepository.AssertNotCalled(s.T(), "UserProfile", mock.Anything)
+}
+
+func (s *NotificationDispatcherTestSuite) TestSubscriberNotifiesBorrower() {
+	dispatcher := new(mocks.NotificationDispatcher)
+	subscriber := usecase.NewNotificationSubscriber(dispatcher, s.mockUserRepository)
+	user := domain.User{ID: primitive.NewObjectID(), UserName: "testuser"}
+	loanID := primitive.NewObjectID()
+	s.mockUserRepository.On("UserProfile", user.ID.Hex()).Return(user, nil).Once()
+	dispatcher.On("Notify", mock.Anything, user, domain.NotificationPaymentReceived, map[string]interface{}{
+		"UserName": "testuser", "LoanID": loanID.Hex(), "Amount": 250.0, "Outstanding": 750.0,
+	}).Return(nil).Once()
+
+	err := subscriber.PaymentReceived(context.Background(), &domain.PaymentReceived{LoanID: loanID, UserID: user.ID, Amount: 250, Outstanding: 750})
+
+	s.NoError(err)
+	dispatcher.AssertExpectations(s.T())
+}
+
+func (s *NotificationDispatcherTestSuite) TestSubscriberNotifiesWriteOff() {
+	dispatcher := new(mocks.NotificationDispatcher)
+	subscriber := usecase.NewNotificationSubscriber(dispatcher, s.mockUserRepository)
+	user := domain.User{ID: primitive.NewObjectID(), UserName: "testuser"}
+	loanID := primitive.NewObjectID()
+	s.mockUserRepository.On("UserProfile", user.ID.Hex()).Return(user, nil).Once()
+	dispatcher.On("Notify", mock.Anything, user, domain.NotificationLoanWrittenOff, map[string]interface{}{
+		"UserName": "testuser", "LoanID": loanID.Hex(), "Amount": 800.0,
+	}).Return(nil).Once()
+
+	err := subscriber.LoanWrittenOff(context.Background(), &domain.LoanWrittenOff{LoanID: loanID, UserID: user.ID, Amount: 800})
+
+	s.NoError(err)
+	dispatcher.AssertExpectations(s.T())
+}
+
+func (s *NotificationDispatcherTestSuite) TestSubscriberInvitesPartyByEmail() {
+	dispatcher := new(mocks.NotificationDispatcher)
+	subscriber := usecase.NewNotificationSubscriber(dispatcher, s.mockUserRepository)
+	borrower := domain.User{ID: primitive.NewObjectID(), UserName: "borrower"}
+	invited := &domain.LoanPartyInvited{LoanID: primitive.NewObjectID(), UserID: borrower.ID, PartyID: primitive.NewObjectID(), Email: "guarantor@gmail.com",
+		Role: domain.PartyCoBorrower, LiabilityShare: 0.35, Amount: 1000, Duration: 12}
+	s.mockUserRepository.On("UserProfile", borrower.ID.Hex()).Return(borrower, nil).Once()
+	dispatcher.On("Notify", mock.Anything, domain.User{Email: "guarantor@gmail.com"}, domain.NotificationLoanPartyInvite, mock.MatchedBy(func(data map[string]interface{}) bool {
+		return data["BorrowerName"] == "borrower" && data["LiabilityPercent"] == 35.0 && data["PartyID"] == invited.PartyID.Hex()
+	})).Return(nil).Once()
+
+	err := subscriber.LoanPartyInvited(context.Background(), invited)
+
+	s.NoError(err)
+	dispatcher.AssertExpectations(s.T())
+}
+
+func (s *NotificationDispatcherTestSuite) TestSubscriberTellsRejectionReason() {
+	dispatcher := new(mocks.NotificationDispatcher)
+	subscriber := usecase.NewNotificationSubscriber(dispatcher, s.mockUserRepository)
+	user := domain.User{ID: primitive.NewObjectID(), UserName: "testuser"}
+	loanID := primitive.NewObjectID()
+	s.mockUserRepository.On("UserProfile", user.ID.Hex()).Return(user, nil).Once()
+	dispatcher.On("Notify", mock.Anything, user, domain.NotificationLoanRejected, map[string]interface{}{
+		"UserName": "testuser", "LoanID": loanID.Hex(), "Reason": "Income could not be verified",
+	}).Return(nil).Once()
+
+	err := subscriber.LoanRejected(context.Background(), &domain.LoanRejected{LoanID: loanID, UserID: user.ID, Reason: "Income could not be verified"})
+
+	s.NoError(err)
+	dispatcher.AssertExpectations(s.T())
+}
+
+func (s *NotificationDispatcherTestSuite) TestSubscriberNotifiesMention() {
+	dispatcher := new(mocks.NotificationDispatcher)
+	subscriber := usecase.NewNotificationSubscriber(dispatcher, s.mockUserRepository)
+	author := domain.User{ID: primitive.NewObjectID(), UserName: "reviewer", IsAdmin: true}
+	mentioned := domain.User{ID: primitive.NewObjectID(), UserName: "underwriter", IsAdmin: true}
+	mention := &domain.LoanCommentMention{LoanID: primitive.NewObjectID(), CommentID: primitive.NewObjectID(), AuthorID: author.ID, MentionedID: mentioned.ID}
+	s.mockUserRepository.On("UserProfile", author.ID.Hex()).Return(author, nil).Once()
+	s.mockUserRepository.On("UserProfile", mentioned.ID.Hex()).Return(mentioned, nil).Once()
+	dispatcher.On("Notify", mock.Anything, mentioned, domain.NotificationLoanMention, map[string]interface{}{
+		"UserName": "underwriter", "AuthorName": "reviewer", "LoanID": mention.LoanID.Hex(), "CommentID": mention.CommentID.Hex(),
+	}).Return(nil).Once()
+
+	err := subscriber.LoanCommentMention(context.Background(), mention)
+
+	s.NoError(err)
+	dispatcher.AssertExpectations(s.T())
+}
+
+func (s *NotificationDispatcherTestSuite) TestSubscriberFailsWithoutBorrower() {
+	dispatcher := new(mocks.NotificationDispatcher)
+	subscriber := usecase.NewNotificationSubscriber(dispatcher, s.mockUserRepository)
+	s.mockUserRepository.On("UserProfile", mock.Anything).Return(domain.User{}, errors.New("User not found")).Once()
+
+	err := subscriber.LoanApproved(context.Background(), &domain.LoanApproved{LoanID: primitive.NewObjectID(), UserID: primitive.NewObjectID()})
+
+	s.EqualError(err, "User not found")
+	dispatcher.AssertNotCalled(s.T(), "Notify", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNotificationDispatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(NotificationDispatcherTestSuite))
+}
@@ -0,0 +1,224 @@
+package usecase
+
+import (
+	"context"
+	"loan_tracker_api/domain"
+	"math"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationSubscriber tells users about the domain events that concern them
+type NotificationSubscriber struct {
+	Dispatcher domain.NotificationDispatcher
+	UserRepo   domain.UserRepository
+}
+
+func NewNotificationSubscriber(dispatcher domain.NotificationDispatcher, Userrepo domain.UserRepository) *NotificationSubscriber {
+	return &NotificationSubscriber{
+		Dispatcher: dispatcher,
+		UserRepo:   Userrepo,
+	}
+}
+
+// notifyBorrower notifies the borrower of a loan with their channel preferences
+func (ns *NotificationSubscriber) notifyBorrower(c context.Context, kind string, userID, loanID primitive.ObjectID, data map[string]interface{}) error {
+	user, err := ns.UserRepo.UserProfile(userID.Hex())
+	if err != nil {
+		return err
+	}
+
+	data["UserName"] = user.UserName
+	data["LoanID"] = loanID.Hex()
+	return ns.Dispatcher.Notify(c, user, kind, data)
+}
+
+// Verification sends the verification link to a newly registered user.
+// The user isn't committed yet, so they are taken from the event rather than read back.
+func (ns *NotificationSubscriber) Verification(c context.Context, event domain.Event) error {
+	registered, ok := event.(*domain.UserRegistered)
+	if !ok {
+		return nil
+	}
+	user := domain.User{ID: registered.UserID, Email: registered.Email, UserName: registered.UserName, Language: registered.Language}
+	return ns.Dispatcher.Notify(c, user, domain.NotificationVerification, map[string]interface{}{"UserName": registered.UserName})
+}
+
+// PasswordResetRequested sends the reset link to a user who asked to reset their password
+func (ns *NotificationSubscriber) PasswordResetRequested(c context.Context, event domain.Event) error {
+	requested, ok := event.(*domain.PasswordResetRequested)
+	if !ok {
+		return nil
+	}
+	user := domain.User{ID: requested.UserID, Email: requested.Email, Language: requested.Language}
+	return ns.Dispatcher.Notify(c, user, domain.NotificationPasswordReset, map[string]interface{}{})
+}
+
+// LoanSubmitted acknowledges a loan application
+func (ns *NotificationSubscriber) LoanSubmitted(c context.Context, event domain.Event) error {
+	applied, ok := event.(*domain.LoanApplied)
+	if !ok {
+		return nil
+	}
+	return ns.notifyBorrower(c, domain.NotificationLoanSubmitted, applied.UserID, applied.LoanID, map[string]interface{}{
+		"Amount":   applied.Amount,
+		"Duration": applied.Duration,
+		"Product":  applied.Product,
+	})
+}
+
+// LoanApproved tells the borrower their loan was approved
+func (ns *NotificationSubscriber) LoanApproved(c context.Context, event domain.Event) error {
+	approved, ok := event.(*domain.LoanApproved)
+	if !ok {
+		return nil
+	}
+	return ns.notifyBorrower(c, domain.NotificationLoanApproved, approved.UserID, approved.LoanID, map[string]interface{}{
+		"Amount": approved.Amount,
+	})
+}
+
+// LoanRejected tells the borrower their loan was rejected and why
+func (ns *NotificationSubscriber) LoanRejected(c context.Context, event domain.Event) error {
+	rejected, ok := event.(*domain.LoanRejected)
+	if !ok {
+		return nil
+	}
+	return ns.notifyBorrower(c, domain.NotificationLoanRejected, rejected.UserID, rejected.LoanID, map[string]interface{}{
+		"Reason": rejected.Reason,
+	})
+}
+
+// PaymentReceived sends the receipt of a repayment
+func (ns *NotificationSubscriber) PaymentReceived(c context.Context, event domain.Event) error {
+	received, ok := event.(*domain.PaymentReceived)
+	if !ok {
+		return nil
+	}
+	return ns.notifyBorrower(c, domain.NotificationPaymentReceived, received.UserID, received.LoanID, map[string]interface{}{
+		"Amount":      received.Amount,
+		"Outstanding": received.Outstanding,
+	})
+}
+
+// PaymentDue reminds the borrower of an installment coming due
+func (ns *NotificationSubscriber) PaymentDue(c context.Context, event domain.Event) error {
+	due, ok := event.(*domain.PaymentDue)
+	if !ok {
+		return nil
+	}
+	return ns.notifyBorrower(c, domain.NotificationPaymentDue, due.UserID, due.LoanID, map[string]interface{}{
+		"Installment":  due.Installment,
+		"DueDate":      due.DueDate,
+		"Amount":       due.Amount,
+		"DaysUntilDue": due.DaysUntilDue,
+	})
+}
+
+// LoanPaidOff tells the borrower their loan is fully repaid
+func (ns *NotificationSubscriber) LoanPaidOff(c context.Context, event domain.Event) error {
+	paidOff, ok := event.(*domain.LoanPaidOff)
+	if !ok {
+		return nil
+	}
+	return ns.notifyBorrower(c, domain.NotificationLoanPaidOff, paidOff.UserID, paidOff.LoanID, map[string]interface{}{})
+}
+
+// LoanWrittenOff tells the borrower the outstanding balance of their loan was written off
+func (ns *NotificationSubscriber) LoanWrittenOff(c context.Context, event domain.Event) error {
+	writtenOff, ok := event.(*domain.LoanWrittenOff)
+	if !ok {
+		return nil
+	}
+	return ns.notifyBorrower(c, domain.NotificationLoanWrittenOff, writtenOff.UserID, writtenOff.LoanID, map[string]interface{}{
+		"Amount": writtenOff.Amount,
+	})
+}
+
+// PaymentOverdue sends the borrower a notice about an overdue installment
+func (ns *NotificationSubscriber) PaymentOverdue(c context.Context, event domain.Event) error {
+	overdue, ok := event.(*domain.PaymentOverdue)
+	if !ok {
+		return nil
+	}
+	return ns.notifyBorrower(c, domain.NotificationPaymentOverdue, overdue.UserID, overdue.LoanID, map[string]interface{}{
+		"Installment": overdue.Installment,
+		"DueDate":     overdue.DueDate,
+		"Amount":      overdue.Amount,
+		"DaysPastDue": overdue.DaysPastDue,
+		"Notice":      overdue.Notice,
+		"Final":       overdue.Final,
+	})
+}
+
+// KYCReviewed tells a user the outcome of the review of their KYC profile
+func (ns *NotificationSubscriber) KYCReviewed(c context.Context, event domain.Event) error {
+	reviewed, ok := event.(*domain.KYCReviewed)
+	if !ok {
+		return nil
+	}
+	user, err := ns.UserRepo.UserProfile(reviewed.UserID.Hex())
+	if err != nil {
+		return err
+	}
+	return ns.Dispatcher.Notify(c, user, domain.NotificationKYCReviewed, map[string]interface{}{
+		"UserName": user.UserName,
+		"Status":   reviewed.Status,
+		"Note":     reviewed.Note,
+	})
+}
+
+// LoanPartyInvited asks a guarantor or co-borrower to accept the loan they were named on, by email when they have no account yet
+func (ns *NotificationSubscriber) LoanPartyInvited(c context.Context, event domain.Event) error {
+	invited, ok := event.(*domain.LoanPartyInvited)
+	if !ok {
+		return nil
+	}
+
+	borrower, err := ns.UserRepo.UserProfile(invited.UserID.Hex())
+	if err != nil {
+		return err
+	}
+
+	party := domain.User{Email: invited.Email}
+	if !invited.PartyUserID.IsZero() {
+		if party, err = ns.UserRepo.UserProfile(invited.PartyUserID.Hex()); err != nil {
+			return err
+		}
+	}
+
+	return ns.Dispatcher.Notify(c, party, domain.NotificationLoanPartyInvite, map[string]interface{}{
+		"UserName":         party.UserName,
+		"BorrowerName":     borrower.UserName,
+		"Role":             invited.Role,
+		"LiabilityPercent": math.Round(invited.LiabilityShare * 100),
+		"Amount":           invited.Amount,
+		"Duration":         invited.Duration,
+		"LoanID":           invited.LoanID.Hex(),
+		"PartyID":          invited.PartyID.Hex(),
+	})
+}
+
+// LoanCommentMention tells a staff member they were mentioned in a comment on a loan
+func (ns *NotificationSubscriber) LoanCommentMention(c context.Context, event domain.Event) error {
+	mention, ok := event.(*domain.LoanCommentMention)
+	if !ok {
+		return nil
+	}
+
+	author, err := ns.UserRepo.UserProfile(mention.AuthorID.Hex())
+	if err != nil {
+		return err
+	}
+	mentioned, err := ns.UserRepo.UserProfile(mention.MentionedID.Hex())
+	if err != nil {
+		return err
+	}
+
+	return ns.Dispatcher.Notify(c, mentioned, domain.NotificationLoanMention, map[string]interface{}{
+		"UserName":   mentioned.UserName,
+		"AuthorName": author.UserName,
+		"LoanID":     mention.LoanID.Hex(),
+		"CommentID":  mention.CommentID.Hex(),
+	})
+}
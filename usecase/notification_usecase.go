@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"loan_tracker_api/domain"
+	"time"
+)
+
+type NotificationUsecase struct {
+	NotificationRepo domain.NotificationRepository
+	contextTimeout   time.Duration
+}
+
+func NewNotificationUsecase(Notificationrepo domain.NotificationRepository, timeout time.Duration) domain.NotificationUsecase {
+	return &NotificationUsecase{
+		NotificationRepo: Notificationrepo,
+		contextTimeout:   timeout,
+	}
+}
+
+// Notifications returns a page of the inbox of the user together with how many of their notifications are unread
+func (nuse *NotificationUsecase) Notifications(c context.Context, userid string, unreadOnly bool, page int) ([]domain.Notification, int64, error) {
+	_, cancel := context.WithTimeout(c, nuse.contextTimeout)
+	defer cancel()
+
+	notifications, err := nuse.NotificationRepo.Notifications(userid, unreadOnly, page)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	unread, err := nuse.NotificationRepo.UnreadCount(userid)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, unread, nil
+}
+
+func (nuse *NotificationUsecase) MarkRead(c context.Context, userid, id string) error {
+	_, cancel := context.WithTimeout(c, nuse.contextTimeout)
+	defer cancel()
+	return nuse.NotificationRepo.MarkRead(c, userid, id)
+}
+
+func (nuse *NotificationUsecase) MarkAllRead(c context.Context, userid string) (int64, error) {
+	_, cancel := context.WithTimeout(c, nuse.contextTimeout)
+	defer cancel()
+	return nuse.NotificationRepo.MarkAllRead(c, userid)
+}
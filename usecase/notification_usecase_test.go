@@ -0,0 +1,70 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"loan_tracker_api/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type NotificationUsecaseTestSuite struct {
+	suite.Suite
+	mockNotificationRepository *mocks.NotificationRepository
+	NotificationUsecase        domain.NotificationUsecase
+}
+
+func (s *NotificationUsecaseTestSuite) SetupTest() {
+	s.mockNotificationRepository = new(mocks.NotificationRepository)
+	s.NotificationUsecase = usecase.NewNotificationUsecase(s.mockNotificationRepository, time.Second*2)
+}
+
+func (s *NotificationUsecaseTestSuite) TestNotifications() {
+	notifications := []domain.Notification{
+		{ID: primitive.NewObjectID(), Type: domain.NotificationPaymentDue, Title: "Payment due"},
+	}
+	s.mockNotificationRepository.On("Notifications", "testuserid", true, 2).Return(notifications, nil).Once()
+	s.mockNotificationRepository.On("UnreadCount", "testuserid").Return(int64(11), nil).Once()
+
+	result, unread, err := s.NotificationUsecase.Notifications(context.Background(), "testuserid", true, 2)
+
+	s.NoError(err)
+	s.Equal(notifications, result)
+	s.Equal(int64(11), unread)
+}
+
+func (s *NotificationUsecaseTestSuite) TestNotificationsError() {
+	s.mockNotificationRepository.On("Notifications", "testuserid", false, 1).Return(nil, errors.New("Error fetching notifications")).Once()
+
+	_, _, err := s.NotificationUsecase.Notifications(context.Background(), "testuserid", false, 1)
+
+	s.EqualError(err, "Error fetching notifications")
+	s.mockNotificationRepository.AssertNotCalled(s.T(), "UnreadCount", mock.Anything)
+}
+
+func (s *NotificationUsecaseTestSuite) TestMarkRead() {
+	s.mockNotificationRepository.On("MarkRead", mock.Anything, "testuserid", "notificationid").Return(errors.New("Notification not found")).Once()
+
+	err := s.NotificationUsecase.MarkRead(context.Background(), "testuserid", "notificationid")
+
+	s.EqualError(err, "Notification not found")
+}
+
+func (s *NotificationUsecaseTestSuite) TestMarkAllRead() {
+	s.mockNotificationRepository.On("MarkAllRead", mock.Anything, "testuserid").Return(int64(3), nil).Once()
+
+	marked, err := s.NotificationUsecase.MarkAllRead(context.Background(), "testuserid")
+
+	s.NoError(err)
+	s.Equal(int64(3), marked)
+}
+
+func TestNotificationUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(NotificationUsecaseTestSuite))
+}
@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"time"
+)
+
+type OutboxUsecase struct {
+	OutboxRepo     domain.OutboxRepository
+	Senders        map[string]domain.OutboxSender
+	contextTimeout time.Duration
+}
+
+func NewOutboxUsecase(Outboxrepo domain.OutboxRepository, senders map[string]domain.OutboxSender, timeout time.Duration) domain.OutboxUsecase {
+	return &OutboxUsecase{
+		OutboxRepo:     Outboxrepo,
+		Senders:        senders,
+		contextTimeout: timeout,
+	}
+}
+
+// how many messages are claimed at a time
+const outboxBatchSize = 50
+
+// how long a claimed message is left to its dispatcher before another one may take it
+const outboxLease = 5 * time.Minute
+
+// how many times a message is attempted before it is dead-lettered
+const outboxMaxAttempts = 8
+
+// the wait after the first failed attempt, doubled after every further one up to the cap
+const (
+	outboxBaseBackoff = 30 * time.Second
+	outboxMaxBackoff  = 6 * time.Hour
+)
+
+// backoff returns how long to wait before attempting a message again after its last failed attempt
+func backoff(attempts int) time.Duration {
+	delay := outboxBaseBackoff
+	for i := 1; i < attempts && delay < outboxMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > outboxMaxBackoff {
+		delay = outboxMaxBackoff
+	}
+	return delay
+}
+
+// deliver hands a message to the sender of its kind and records the outcome, reporting whether it was sent
+func (ouse *OutboxUsecase) deliver(message *domain.OutboxMessage, now time.Time) bool {
+	err := errors.New("No sender for " + message.Kind + " messages")
+	if sender, ok := ouse.Senders[message.Kind]; ok {
+		err = sender.Send(*message)
+	}
+
+	message.Attempts++
+	if err == nil {
+		message.Status = domain.OutboxSent
+		message.SentAt = &now
+		message.LastError = ""
+		return true
+	}
+
+	message.LastError = err.Error()
+	if message.Attempts >= outboxMaxAttempts {
+		message.Status = domain.OutboxDead
+		return false
+	}
+
+	message.NextAttemptAt = now.Add(backoff(message.Attempts))
+	return false
+}
+
+// DispatchOutbox delivers the messages that are due and reports how many were sent.
+// Failed messages are retried with exponential backoff until they run out of attempts.
+func (ouse *OutboxUsecase) DispatchOutbox(c context.Context) (int, error) {
+	_, cancel := context.WithTimeout(c, ouse.contextTimeout)
+	defer cancel()
+
+	sent := 0
+	for {
+		messages, err := ouse.OutboxRepo.ClaimMessages(time.Now(), outboxLease, outboxBatchSize)
+		if err != nil {
+			return sent, err
+		}
+
+		for i := range messages {
+			if ouse.deliver(&messages[i], time.Now()) {
+				sent++
+			}
+			if err := ouse.OutboxRepo.UpdateMessage(&messages[i]); err != nil {
+				return sent, err
+			}
+		}
+
+		if len(messages) < outboxBatchSize {
+			return sent, nil
+		}
+	}
+}
+
+func (ouse *OutboxUsecase) DeadMessages(c context.Context, page int) ([]domain.OutboxMessage, error) {
+	_, cancel := context.WithTimeout(c, ouse.contextTimeout)
+	defer cancel()
+	return ouse.OutboxRepo.DeadMessages(page)
+}
+
+// RetryMessage puts a dead message back in the outbox with a fresh set of attempts
+func (ouse *OutboxUsecase) RetryMessage(c context.Context, id string) error {
+	_, cancel := context.WithTimeout(c, ouse.contextTimeout)
+	defer cancel()
+
+	message, err := ouse.OutboxRepo.GetMessage(id)
+	if err != nil {
+		return err
+	}
+
+	if message.Status != domain.OutboxDead {
+		return errors.New("Only dead messages can be retried")
+	}
+
+	message.Status = domain.OutboxPending
+	message.Attempts = 0
+	message.NextAttemptAt = time.Now()
+
+	return ouse.OutboxRepo.UpdateMessage(&message)
+}
@@ -0,0 +1,132 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"loan_tracker_api/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type OutboxUsecaseTestSuite struct {
+	suite.Suite
+	mockOutboxRepository *mocks.OutboxRepository
+	mockEmailSender      *mocks.OutboxSender
+	OutboxUsecase        domain.OutboxUsecase
+}
+
+func (s *OutboxUsecaseTestSuite) SetupTest() {
+	s.mockOutboxRepository = new(mocks.OutboxRepository)
+	s.mockEmailSender = new(mocks.OutboxSender)
+	senders := map[string]domain.OutboxSender{domain.OutboxEmail: s.mockEmailSender}
+	s.OutboxUsecase = usecase.NewOutboxUsecase(s.mockOutboxRepository, senders, time.Second*2)
+}
+
+// pendingEmail returns a verification email as the dispatcher claims it after the given attempts
+func pendingEmail(attempts int) domain.OutboxMessage {
+	return domain.OutboxMessage{
+		ID:        primitive.NewObjectID(),
+		Kind:      domain.OutboxEmail,
+		Topic:     domain.NotificationVerification,
+		Recipient: "test@gmail.com",
+		Status:    domain.OutboxPending,
+		Attempts:  attempts,
+	}
+}
+
+func (s *OutboxUsecaseTestSuite) TestDispatchOutbox() {
+	message := pendingEmail(0)
+	s.mockOutboxRepository.On("ClaimMessages", mock.Anything, 5*time.Minute, 50).Return([]domain.OutboxMessage{message}, nil).Once()
+	s.mockEmailSender.On("Send", message).Return(nil).Once()
+	s.mockOutboxRepository.On("UpdateMessage", mock.MatchedBy(func(m *domain.OutboxMessage) bool {
+		return m.ID == message.ID && m.Status == domain.OutboxSent && m.Attempts == 1 && m.SentAt != nil
+	})).Return(nil).Once()
+
+	sent, err := s.OutboxUsecase.DispatchOutbox(context.Background())
+
+	s.NoError(err)
+	s.Equal(1, sent)
+	s.mockOutboxRepository.AssertExpectations(s.T())
+}
+
+func (s *OutboxUsecaseTestSuite) TestDispatchOutboxBacksOff() {
+	message := pendingEmail(2)
+	s.mockOutboxRepository.On("ClaimMessages", mock.Anything, 5*time.Minute, 50).Return([]domain.OutboxMessage{message}, nil).Once()
+	s.mockEmailSender.On("Send", message).Return(errors.New("failed to send email: connection refused")).Once()
+
+	// the third failed attempt waits twice as long as the second
+	s.mockOutboxRepository.On("UpdateMessage", mock.MatchedBy(func(m *domain.OutboxMessage) bool {
+		wait := time.Until(m.NextAttemptAt)
+		return m.Status == domain.OutboxPending && m.Attempts == 3 &&
+			m.LastError == "failed to send email: connection refused" &&
+			wait > 119*time.Second && wait <= 120*time.Second
+	})).Return(nil).Once()
+
+	sent, err := s.OutboxUsecase.DispatchOutbox(context.Background())
+
+	s.NoError(err)
+	s.Equal(0, sent)
+	s.mockOutboxRepository.AssertExpectations(s.T())
+}
+
+func (s *OutboxUsecaseTestSuite) TestDispatchOutboxDeadLetters() {
+	message := pendingEmail(7)
+	s.mockOutboxRepository.On("ClaimMessages", mock.Anything, 5*time.Minute, 50).Return([]domain.OutboxMessage{message}, nil).Once()
+	s.mockEmailSender.On("Send", message).Return(errors.New("failed to send email: mailbox unavailable")).Once()
+	s.mockOutboxRepository.On("UpdateMessage", mock.MatchedBy(func(m *domain.OutboxMessage) bool {
+		return m.Status == domain.OutboxDead && m.Attempts == 8
+	})).Return(nil).Once()
+
+	_, err := s.OutboxUsecase.DispatchOutbox(context.Background())
+
+	s.NoError(err)
+	s.mockOutboxRepository.AssertExpectations(s.T())
+}
+
+func (s *OutboxUsecaseTestSuite) TestDispatchOutboxUnknownKind() {
+	message := pendingEmail(0)
+	message.Kind = "fax"
+	s.mockOutboxRepository.On("ClaimMessages", mock.Anything, 5*time.Minute, 50).Return([]domain.OutboxMessage{message}, nil).Once()
+	s.mockOutboxRepository.On("UpdateMessage", mock.MatchedBy(func(m *domain.OutboxMessage) bool {
+		return m.Status == domain.OutboxPending && m.LastError == "No sender for fax messages"
+	})).Return(nil).Once()
+
+	_, err := s.OutboxUsecase.DispatchOutbox(context.Background())
+
+	s.NoError(err)
+	s.mockEmailSender.AssertNotCalled(s.T(), "Send", mock.Anything)
+}
+
+func (s *OutboxUsecaseTestSuite) TestRetryMessage() {
+	message := pendingEmail(8)
+	message.Status = domain.OutboxDead
+	s.mockOutboxRepository.On("GetMessage", message.ID.Hex()).Return(message, nil).Once()
+	s.mockOutboxRepository.On("UpdateMessage", mock.MatchedBy(func(m *domain.OutboxMessage) bool {
+		return m.Status == domain.OutboxPending && m.Attempts == 0 && !m.NextAttemptAt.After(time.Now())
+	})).Return(nil).Once()
+
+	err := s.OutboxUsecase.RetryMessage(context.Background(), message.ID.Hex())
+
+	s.NoError(err)
+	s.mockOutboxRepository.AssertExpectations(s.T())
+}
+
+func (s *OutboxUsecaseTestSuite) TestRetryMessageNotDead() {
+	message := pendingEmail(1)
+	s.mockOutboxRepository.On("GetMessage", message.ID.Hex()).Return(message, nil).Once()
+
+	err := s.OutboxUsecase.RetryMessage(context.Background(), message.ID.Hex())
+
+	s.EqualError(err, "Only dead messages can be retried")
+	s.mockOutboxRepository.AssertNotCalled(s.T(), "UpdateMessage", mock.Anything)
+}
+
+func TestOutboxUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(OutboxUsecaseTestSuite))
+}
@@ -0,0 +1,400 @@
+package usecase
+
+import (
+	"errors"
+	"loan_tracker_api/domain"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// roundCents rounds an amount to whole cents
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// buildSchedule builds an amortized monthly repayment schedule, the first installment falls due a month after start
+func buildSchedule(amount, rate float64, months int, start time.Time) []domain.Installment {
+	if months <= 0 {
+		months = 1
+	}
+
+	monthlyRate := rate / 12
+	payment := amount / float64(months)
+	if monthlyRate > 0 {
+		payment = amount * monthlyRate / (1 - math.Pow(1+monthlyRate, -float64(months)))
+	}
+	payment = roundCents(payment)
+
+	schedule := make([]domain.Installment, 0, months)
+	balance := amount
+	for i := 1; i <= months; i++ {
+		interest := roundCents(balance * monthlyRate)
+		principal := roundCents(payment - interest)
+		if i == months {
+			principal = roundCents(balance)
+		}
+		balance = roundCents(balance - principal)
+
+		schedule = append(schedule, domain.Installment{
+			Number:    i,
+			DueDate:   start.AddDate(0, i, 0),
+			Principal: principal,
+			Interest:  interest,
+			Amount:    roundCents(principal + interest),
+		})
+	}
+
+	return schedule
+}
+
+// installmentDue returns what is still owed on an installment
+func installmentDue(installment domain.Installment) float64 {
+	return roundCents(installment.Amount - installment.PaidAmount)
+}
+
+// chargeDue returns what is still owed on a charge, waived and capitalized charges are never owed
+func chargeDue(charge domain.Charge) float64 {
+	if charge.Waived || charge.Capitalized {
+		return 0
+	}
+	return roundCents(charge.Amount - charge.PaidAmount)
+}
+
+// totalDue returns everything still owed on a loan
+func totalDue(loan *domain.Loan) float64 {
+	due := 0.0
+	for _, charge := range loan.Charges {
+		due += chargeDue(charge)
+	}
+	for _, installment := range loan.Schedule {
+		due += installmentDue(installment)
+	}
+	return roundCents(due)
+}
+
+// recovered returns what has been collected on a loan since it was written off
+func recovered(loan *domain.Loan) float64 {
+	total := 0.0
+	for _, recovery := range loan.Recoveries {
+		total += recovery.Amount
+	}
+	return roundCents(total)
+}
+
+// findCharge returns the index of the latest charge of the given type on an installment, or -1
+func findCharge(charges []domain.Charge, installment int, chargeType string) int {
+	for i := len(charges) - 1; i >= 0; i-- {
+		if charges[i].Installment == installment && charges[i].Type == chargeType {
+			return i
+		}
+	}
+	return -1
+}
+
+// assessPenalties applies late fees and accrues penalty interest on installments overdue beyond the grace period.
+// It reports whether any charge was added or changed.
+func assessPenalties(loan *domain.Loan, product domain.LoanProduct, asOf time.Time) bool {
+	changed := false
+
+	for _, installment := range loan.Schedule {
+		outstanding := installmentDue(installment)
+		graceEnd := installment.DueDate.AddDate(0, 0, product.GracePeriodDays)
+		if outstanding <= 0 || !asOf.After(graceEnd) {
+			continue
+		}
+
+		if product.LateFee > 0 && findCharge(loan.Charges, installment.Number, domain.ChargeLateFee) < 0 {
+			loan.Charges = append(loan.Charges, domain.Charge{
+				ID:          primitive.NewObjectID(),
+				Installment: installment.Number,
+				Type:        domain.ChargeLateFee,
+				Amount:      product.LateFee,
+				AccruedTo:   asOf,
+				AssessedAt:  asOf,
+			})
+			changed = true
+		}
+
+		if product.PenaltyRate <= 0 {
+			continue
+		}
+
+		// penalty interest accrues in whole days from where the last penalty charge stopped
+		idx := findCharge(loan.Charges, installment.Number, domain.ChargePenaltyInterest)
+		from := graceEnd
+		if idx >= 0 {
+			from = loan.Charges[idx].AccruedTo
+		}
+
+		days := int(asOf.Sub(from).Hours() / 24)
+		if days <= 0 {
+			continue
+		}
+
+		accrued := roundCents(outstanding * product.PenaltyRate / 365 * float64(days))
+		accruedTo := from.AddDate(0, 0, days)
+
+		if idx < 0 || loan.Charges[idx].Waived {
+			loan.Charges = append(loan.Charges, domain.Charge{
+				ID:          primitive.NewObjectID(),
+				Installment: installment.Number,
+				Type:        domain.ChargePenaltyInterest,
+				Amount:      accrued,
+				AccruedTo:   accruedTo,
+				AssessedAt:  asOf,
+			})
+		} else {
+			loan.Charges[idx].Amount = roundCents(loan.Charges[idx].Amount + accrued)
+			loan.Charges[idx].AccruedTo = accruedTo
+			loan.Charges[idx].AssessedAt = asOf
+		}
+		changed = true
+	}
+
+	return changed
+}
+
+// allocatePayment settles outstanding charges first and then installments, oldest first
+func allocatePayment(loan *domain.Loan, amount float64, paidAt time.Time) error {
+	if amount <= 0 {
+		return errors.New("Invalid payment amount")
+	}
+
+	if roundCents(amount) > totalDue(loan) {
+		return errors.New("Payment exceeds the outstanding balance")
+	}
+
+	remaining := roundCents(amount)
+
+	for i := range loan.Charges {
+		pay := math.Min(remaining, chargeDue(loan.Charges[i]))
+		if pay <= 0 {
+			continue
+		}
+		loan.Charges[i].PaidAmount = roundCents(loan.Charges[i].PaidAmount + pay)
+		remaining = roundCents(remaining - pay)
+	}
+
+	for i := range loan.Schedule {
+		pay := math.Min(remaining, installmentDue(loan.Schedule[i]))
+		if pay <= 0 {
+			continue
+		}
+		loan.Schedule[i].PaidAmount = roundCents(loan.Schedule[i].PaidAmount + pay)
+		if installmentDue(loan.Schedule[i]) == 0 {
+			loan.Schedule[i].PaidAt = paidAt
+		}
+		remaining = roundCents(remaining - pay)
+	}
+
+	return nil
+}
+
+// daysPastDue returns how many days the oldest unpaid installment is overdue
+func daysPastDue(loan *domain.Loan, asOf time.Time) int {
+	for _, installment := range loan.Schedule {
+		if installmentDue(installment) <= 0 {
+			continue
+		}
+		if !asOf.After(installment.DueDate) {
+			return 0
+		}
+		return int(asOf.Sub(installment.DueDate).Hours() / 24)
+	}
+	return 0
+}
+
+// daysUntilDue returns how many calendar days are left until an installment is due, negative once it is overdue
+func daysUntilDue(installment domain.Installment, asOf time.Time) int {
+	day := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return int(day(installment.DueDate.UTC()).Sub(day(asOf.UTC())).Hours() / 24)
+}
+
+// refreshStanding recomputes days past due and moves the loan between current, delinquent and defaulted.
+// It reports whether either value changed.
+func refreshStanding(loan *domain.Loan, product domain.LoanProduct, asOf time.Time) bool {
+	dpd := daysPastDue(loan, asOf)
+
+	standing := domain.DelinquencyCurrent
+	if product.DefaultAfterDays > 0 && dpd >= product.DefaultAfterDays {
+		standing = domain.DelinquencyDefaulted
+	} else if dpd > 0 && dpd >= product.DelinquentAfterDays {
+		standing = domain.DelinquencyDelinquent
+	}
+
+	if loan.DaysPastDue == dpd && loan.Delinquency == standing {
+		return false
+	}
+
+	loan.DaysPastDue = dpd
+	loan.Delinquency = standing
+	return true
+}
+
+// interestPaid returns the part of an installment's payments that went to interest, interest is settled before principal
+func interestPaid(installment domain.Installment) float64 {
+	return math.Min(installment.PaidAmount, installment.Interest)
+}
+
+// settleForPayoff rewrites the installments not yet due so that only interest accrued up to date remains owed
+// and charges the product's prepayment penalty on the principal repaid ahead of schedule
+func settleForPayoff(loan *domain.Loan, product domain.LoanProduct, date time.Time) float64 {
+	prepaid := 0.0
+
+	for i := range loan.Schedule {
+		installment := &loan.Schedule[i]
+		if installmentDue(*installment) <= 0 || !installment.DueDate.After(date) {
+			continue
+		}
+
+		start := installment.DueDate.AddDate(0, -1, 0)
+		if i > 0 {
+			start = loan.Schedule[i-1].DueDate
+		}
+
+		accrued := 0.0
+		if date.After(start) {
+			accrued = installment.Interest * date.Sub(start).Hours() / installment.DueDate.Sub(start).Hours()
+		}
+
+		paidInterest := interestPaid(*installment)
+		prepaid += installment.Principal - (installment.PaidAmount - paidInterest)
+
+		installment.Interest = math.Max(roundCents(accrued), paidInterest)
+		installment.Amount = roundCents(installment.Principal + installment.Interest)
+	}
+
+	penalty := roundCents(prepaid * product.PrepaymentRate)
+	if penalty > 0 {
+		loan.Charges = append(loan.Charges, domain.Charge{
+			ID:         primitive.NewObjectID(),
+			Type:       domain.ChargePrepaymentPenalty,
+			Amount:     penalty,
+			AccruedTo:  date,
+			AssessedAt: date,
+		})
+	}
+
+	return penalty
+}
+
+// payoffQuote works out what closing the loan on date would cost without touching the loan itself
+func payoffQuote(loan *domain.Loan, product domain.LoanProduct, date time.Time) domain.PayoffQuote {
+	settled := *loan
+	settled.Schedule = append([]domain.Installment(nil), loan.Schedule...)
+	settled.Charges = append([]domain.Charge(nil), loan.Charges...)
+
+	quote := domain.PayoffQuote{
+		Date:              date,
+		PrepaymentPenalty: settleForPayoff(&settled, product, date),
+	}
+
+	for _, installment := range settled.Schedule {
+		paidInterest := interestPaid(installment)
+		quote.Principal += installment.Principal - (installment.PaidAmount - paidInterest)
+		quote.AccruedInterest += installment.Interest - paidInterest
+	}
+
+	for _, charge := range loan.Charges {
+		quote.Fees += chargeDue(charge)
+	}
+
+	quote.Principal = roundCents(quote.Principal)
+	quote.AccruedInterest = roundCents(quote.AccruedInterest)
+	quote.Fees = roundCents(quote.Fees)
+	quote.Total = totalDue(&settled)
+
+	return quote
+}
+
+// restructureSchedule archives the current terms of a loan and regenerates its unpaid installments under the new terms.
+// Paid installments are kept, partly paid ones are closed at what was paid and the rest is rescheduled.
+func restructureSchedule(loan *domain.Loan, terms domain.Restructure, adminID primitive.ObjectID, now time.Time) {
+	loan.History = append(loan.History, domain.ScheduleVersion{
+		Version:     len(loan.History) + 1,
+		Interest:    loan.Interest,
+		Duration:    loan.Duration,
+		Schedule:    append([]domain.Installment(nil), loan.Schedule...),
+		Charges:     append([]domain.Charge(nil), loan.Charges...),
+		Restructure: terms,
+		ReplacedBy:  adminID,
+		ReplacedAt:  now,
+	})
+
+	if terms.Interest != nil {
+		loan.Interest = *terms.Interest
+	}
+
+	kept := make([]domain.Installment, 0, len(loan.Schedule))
+	principal := 0.0
+	remaining := 0
+	lastNumber := 0
+	var firstDue time.Time
+
+	for _, installment := range loan.Schedule {
+		if installment.Number > lastNumber {
+			lastNumber = installment.Number
+		}
+
+		overdue := !installment.DueDate.After(now)
+		if installmentDue(installment) <= 0 || (overdue && !terms.CapitalizeArrears) {
+			kept = append(kept, installment)
+			continue
+		}
+
+		paidInterest := interestPaid(installment)
+		paidPrincipal := installment.PaidAmount - paidInterest
+		principal += installment.Principal - paidPrincipal
+
+		if overdue {
+			principal += installment.Interest - paidInterest
+		} else {
+			remaining++
+			if firstDue.IsZero() {
+				firstDue = installment.DueDate
+			}
+		}
+
+		if installment.PaidAmount > 0 {
+			installment.Principal = roundCents(paidPrincipal)
+			installment.Interest = paidInterest
+			installment.Amount = installment.PaidAmount
+			installment.PaidAt = now
+			kept = append(kept, installment)
+		}
+	}
+
+	if terms.CapitalizeArrears {
+		for i := range loan.Charges {
+			principal += chargeDue(loan.Charges[i])
+			if chargeDue(loan.Charges[i]) > 0 {
+				loan.Charges[i].Capitalized = true
+			}
+		}
+	}
+
+	start := now
+	if !firstDue.IsZero() {
+		start = firstDue.AddDate(0, -1, 0)
+	}
+
+	// interest keeps accruing through a payment holiday and is added to the principal
+	if terms.HolidayMonths > 0 {
+		principal *= math.Pow(1+loan.Interest/12, float64(terms.HolidayMonths))
+		start = start.AddDate(0, terms.HolidayMonths, 0)
+	}
+
+	// new installments are numbered after the replaced ones so earlier charges stay attached to their installments
+	rescheduled := buildSchedule(roundCents(principal), loan.Interest, remaining+terms.ExtendMonths, start)
+	for i := range rescheduled {
+		rescheduled[i].Number = lastNumber + i + 1
+	}
+
+	loan.Schedule = append(kept, rescheduled...)
+	loan.Duration = len(loan.Schedule)
+	loan.PayoffQuote = nil
+}
@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"loan_tracker_api/domain"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// scheduledJob is a job registered with the scheduler
+type scheduledJob struct {
+	name     string
+	spec     string
+	schedule *cronSchedule
+	run      domain.JobFunc
+	// queues the run rather than running the job, set for jobs run by the workers of the queue
+	enqueue func(c context.Context, run *domain.JobRun) error
+	// the last minute this instance considered running the job at
+	last time.Time
+}
+
+// Scheduler runs jobs at the minutes their cron expressions match.
+// Every instance runs a scheduler, the lock of a job lets only one of them run it for each minute it is scheduled at.
+type Scheduler struct {
+	SchedulerRepo  domain.SchedulerRepository
+	instance       string
+	location       *time.Location
+	lease          time.Duration
+	contextTimeout time.Duration
+
+	mu   sync.Mutex
+	jobs []*scheduledJob
+}
+
+func NewScheduler(Schedulerrepo domain.SchedulerRepository, instance string, location *time.Location, lease, timeout time.Duration) *Scheduler {
+	return &Scheduler{
+		SchedulerRepo:  Schedulerrepo,
+		instance:       instance,
+		location:       location,
+		lease:          lease,
+		contextTimeout: timeout,
+	}
+}
+
+// queuedRun is the payload of a scheduled job queued for the workers, it names the run the worker records the outcome on
+type queuedRun struct {
+	RunID primitive.ObjectID `json:"run_id"`
+}
+
+// AddJob registers a job run at the minutes the cron expression matches, in the time zone of the scheduler
+func (s *Scheduler) AddJob(name, spec string, run domain.JobFunc) error {
+	return s.addJob(name, spec, run, nil)
+}
+
+// AddQueuedJob registers a job that is queued at the minutes the cron expression matches and run by the workers of the queue.
+// Its run is recorded as queued, the worker that takes it records when it starts and how it ended.
+// A run that is still queued or running keeps the next one from being queued.
+func (s *Scheduler) AddQueuedJob(name, spec string, queue *JobQueue, visibility time.Duration, run domain.JobFunc) error {
+	enqueue := func(c context.Context, record *domain.JobRun) error {
+		_, err := queue.Enqueue(c, name, queuedRun{RunID: record.ID}, domain.JobOptions{Key: name})
+		return err
+	}
+	if err := s.addJob(name, spec, nil, enqueue); err != nil {
+		return err
+	}
+
+	queue.Register(name, visibility, func(c context.Context, job domain.QueuedJob) error {
+		return s.runQueued(c, job, run)
+	})
+	return nil
+}
+
+func (s *Scheduler) addJob(name, spec string, run domain.JobFunc, enqueue func(c context.Context, run *domain.JobRun) error) error {
+	schedule, err := parseSchedule(spec)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, job := range s.jobs {
+		if job.name == name {
+			return errors.New("Job " + name + " is already scheduled")
+		}
+	}
+
+	s.jobs = append(s.jobs, &scheduledJob{name: name, spec: spec, schedule: schedule, run: run, enqueue: enqueue})
+	return nil
+}
+
+// RunDue runs the jobs scheduled at the minute of the given time that no other instance claimed first, and waits for them to end.
+// Checking the same minute again doesn't run a job twice.
+func (s *Scheduler) RunDue(c context.Context, now time.Time) error {
+	minute := now.In(s.location).Truncate(time.Minute)
+
+	s.mu.Lock()
+	due := []*scheduledJob{}
+	for _, job := range s.jobs {
+		if job.last.Equal(minute) || !job.schedule.Matches(minute) {
+			continue
+		}
+		job.last = minute
+		due = append(due, job)
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	failures := make([]error, len(due))
+	for i, job := range due {
+		claimed, err := s.SchedulerRepo.ClaimJob(c, job.name, minute, s.instance, s.lease)
+		if err != nil {
+			failures[i] = err
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, job *scheduledJob) {
+			defer wg.Done()
+			failures[i] = s.runJob(c, job, minute)
+		}(i, job)
+	}
+	wg.Wait()
+
+	return errors.Join(failures...)
+}
+
+// runJob runs or queues a claimed job, records the run in the history and releases the job
+func (s *Scheduler) runJob(c context.Context, job *scheduledJob, minute time.Time) error {
+	defer s.SchedulerRepo.ReleaseJob(c, job.name, s.instance)
+
+	run := &domain.JobRun{
+		ID:          primitive.NewObjectID(),
+		Job:         job.name,
+		Instance:    s.instance,
+		ScheduledAt: minute,
+		Status:      domain.JobRunning,
+		StartedAt:   time.Now(),
+	}
+	if job.enqueue != nil {
+		run.Status = domain.JobQueued
+	}
+	if err := s.SchedulerRepo.AddJobRun(c, run); err != nil {
+		return err
+	}
+
+	if job.enqueue != nil {
+		// the worker that takes the job records how the run ended
+		err := job.enqueue(c, run)
+		if err == nil {
+			return nil
+		}
+		return s.finishRun(c, run, 0, err)
+	}
+
+	processed, err := job.run(c)
+	return s.finishRun(c, run, processed, err)
+}
+
+// runQueued runs a scheduled job taken by a worker of the queue, and records on its run that it started and how it ended.
+// Jobs queued without a run to record on are only run.
+func (s *Scheduler) runQueued(c context.Context, job domain.QueuedJob, run domain.JobFunc) error {
+	var payload queuedRun
+	if job.Payload != "" {
+		if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+			return errors.New("Invalid job payload")
+		}
+	}
+	if payload.RunID.IsZero() {
+		_, err := run(c)
+		return err
+	}
+
+	record := &domain.JobRun{
+		ID:        payload.RunID,
+		Job:       job.Type,
+		Instance:  job.Worker,
+		QueuedJob: job.ID.Hex(),
+		Status:    domain.JobRunning,
+	}
+	if err := s.SchedulerRepo.FinishJobRun(c, record); err != nil {
+		return err
+	}
+
+	processed, err := run(c)
+
+	// the outcome is recorded even when the job ran out of time
+	return s.finishRun(context.WithoutCancel(c), record, processed, err)
+}
+
+// finishRun records how a run ended
+func (s *Scheduler) finishRun(c context.Context, run *domain.JobRun, processed int, err error) error {
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.Processed = processed
+	run.Status = domain.JobSucceeded
+	if err != nil {
+		run.Status = domain.JobFailed
+		run.Error = err.Error()
+	}
+
+	if recordErr := s.SchedulerRepo.FinishJobRun(c, run); recordErr != nil {
+		return recordErr
+	}
+	if err != nil {
+		return errors.New("Job " + run.Job + " failed: " + err.Error())
+	}
+
+	return nil
+}
+
+// Jobs lists the scheduled jobs and when each runs next
+func (s *Scheduler) Jobs(c context.Context) []domain.ScheduledJob {
+	_, cancel := context.WithTimeout(c, s.contextTimeout)
+	defer cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().In(s.location)
+	jobs := make([]domain.ScheduledJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, domain.ScheduledJob{
+			Name:     job.name,
+			Schedule: job.spec,
+			NextRun:  job.schedule.Next(now),
+		})
+	}
+	return jobs
+}
+
+// JobRuns returns a page of the run history of a job, or of every job
+func (s *Scheduler) JobRuns(c context.Context, job string, page int) ([]domain.JobRun, error) {
+	_, cancel := context.WithTimeout(c, s.contextTimeout)
+	defer cancel()
+
+	return s.SchedulerRepo.JobRuns(job, page)
+}
@@ -0,0 +1,191 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/mocks"
+	"loan_tracker_api/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SchedulerTestSuite struct {
+	suite.Suite
+	mockSchedulerRepository *mocks.SchedulerRepository
+	Scheduler               *usecase.Scheduler
+}
+
+func (s *SchedulerTestSuite) SetupTest() {
+	s.mockSchedulerRepository = new(mocks.SchedulerRepository)
+	s.mockSchedulerRepository.On("ReleaseJob", mock.Anything, mock.Anything, "instance-1").Return(nil).Maybe()
+	s.Scheduler = usecase.NewScheduler(s.mockSchedulerRepository, "instance-1", time.UTC, time.Hour, time.Second*2)
+}
+
+// counter returns a job counting its runs
+func counter(runs *int) domain.JobFunc {
+	return func(c context.Context) (int, error) {
+		*runs++
+		return 5, nil
+	}
+}
+
+func (s *SchedulerTestSuite) TestRunDueRunsMatchingJobs() {
+	daily, weekdays, quarterly := 0, 0, 0
+	s.NoError(s.Scheduler.AddJob("daily", "30 8 * * *", counter(&daily)))
+	s.NoError(s.Scheduler.AddJob("weekdays", "*/15 8-17 * * 1-5", counter(&weekdays)))
+	s.NoError(s.Scheduler.AddJob("quarterly", "0 0 1 1,4,7,10 *", counter(&quarterly)))
+
+	// a Saturday
+	now := time.Date(2026, 10, 17, 8, 30, 20, 0, time.UTC)
+	s.mockSchedulerRepository.On("ClaimJob", mock.Anything, "daily", now.Truncate(time.Minute), "instance-1", time.Hour).Return(true, nil).Once()
+	s.mockSchedulerRepository.On("AddJobRun", mock.Anything, mock.MatchedBy(func(r *domain.JobRun) bool {
+		return r.Job == "daily" && r.Status == domain.JobRunning
+	})).Return(nil).Once()
+	s.mockSchedulerRepository.On("FinishJobRun", mock.Anything, mock.MatchedBy(func(r *domain.JobRun) bool {
+		return r.Status == domain.JobSucceeded && r.Processed == 5 && r.FinishedAt != nil
+	})).Return(nil).Once()
+
+	err := s.Scheduler.RunDue(context.Background(), now)
+	s.NoError(err)
+
+	// checking the same minute again runs nothing
+	err = s.Scheduler.RunDue(context.Background(), now.Add(20*time.Second))
+	s.NoError(err)
+
+	s.Equal(1, daily)
+	s.Equal(0, weekdays)
+	s.Equal(0, quarterly)
+	s.mockSchedulerRepository.AssertExpectations(s.T())
+}
+
+func (s *SchedulerTestSuite) TestRunDueSkipsJobClaimedElsewhere() {
+	runs := 0
+	s.NoError(s.Scheduler.AddJob("hourly", "@hourly", counter(&runs)))
+	s.mockSchedulerRepository.On("ClaimJob", mock.Anything, "hourly", mock.Anything, "instance-1", time.Hour).Return(false, nil).Once()
+
+	err := s.Scheduler.RunDue(context.Background(), time.Date(2026, 10, 19, 14, 0, 0, 0, time.UTC))
+
+	s.NoError(err)
+	s.Equal(0, runs)
+	s.mockSchedulerRepository.AssertNotCalled(s.T(), "AddJobRun", mock.Anything, mock.Anything)
+}
+
+func (s *SchedulerTestSuite) TestRunDueRecordsFailure() {
+	s.NoError(s.Scheduler.AddJob("archive", "@every 30m", func(c context.Context) (int, error) {
+		return 2, errors.New("Archive unavailable")
+	}))
+	s.mockSchedulerRepository.On("ClaimJob", mock.Anything, "archive", mock.Anything, "instance-1", time.Hour).Return(true, nil).Once()
+	s.mockSchedulerRepository.On("AddJobRun", mock.Anything, mock.Anything).Return(nil).Once()
+	s.mockSchedulerRepository.On("FinishJobRun", mock.Anything, mock.MatchedBy(func(r *domain.JobRun) bool {
+		return r.Status == domain.JobFailed && r.Error == "Archive unavailable" && r.Processed == 2
+	})).Return(nil).Once()
+
+	err := s.Scheduler.RunDue(context.Background(), time.Date(2026, 10, 19, 14, 30, 0, 0, time.UTC))
+
+	s.EqualError(err, "Job archive failed: Archive unavailable")
+	s.mockSchedulerRepository.AssertExpectations(s.T())
+	s.mockSchedulerRepository.AssertCalled(s.T(), "ReleaseJob", mock.Anything, "archive", "instance-1")
+}
+
+func (s *SchedulerTestSuite) TestQueuedJobRecordsOutcome() {
+	mockQueueRepository := new(mocks.QueueRepository)
+	queue := usecase.NewJobQueue(mockQueueRepository, time.Second*2)
+	s.NoError(s.Scheduler.AddQueuedJob("purge", "0 3 * * *", queue, time.Hour, func(c context.Context) (int, error) {
+		return 4, nil
+	}))
+
+	// the scheduler only queues the run
+	var run *domain.JobRun
+	s.mockSchedulerRepository.On("ClaimJob", mock.Anything, "purge", mock.Anything, "instance-1", time.Hour).Return(true, nil).Once()
+	s.mockSchedulerRepository.On("AddJobRun", mock.Anything, mock.MatchedBy(func(r *domain.JobRun) bool {
+		run = r
+		return r.Job == "purge" && r.Status == domain.JobQueued
+	})).Return(nil).Once()
+	var queued *domain.QueuedJob
+	mockQueueRepository.On("EnqueueJob", mock.Anything, mock.MatchedBy(func(job *domain.QueuedJob) bool {
+		queued = job
+		return job.Type == "purge" && job.Key == "purge"
+	})).Return(true, nil).Once()
+
+	err := s.Scheduler.RunDue(context.Background(), time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC))
+
+	s.NoError(err)
+	s.Equal(`{"run_id":"`+run.ID.Hex()+`"}`, queued.Payload)
+	s.mockSchedulerRepository.AssertNotCalled(s.T(), "FinishJobRun", mock.Anything, mock.Anything)
+
+	// the worker that takes it records the run started and how it ended
+	job := *queued
+	job.ID = primitive.NewObjectID()
+	job.Status = domain.QueueActive
+	job.Worker = "instance-2-1"
+	job.Attempts = 1
+	mockQueueRepository.On("ClaimNextJob", []string{"purge"}, "instance-2-1", mock.Anything).Return(job, true, nil).Once()
+	s.mockSchedulerRepository.On("FinishJobRun", mock.Anything, mock.MatchedBy(func(r *domain.JobRun) bool {
+		return r.ID == run.ID && r.Status == domain.JobRunning && r.Instance == "instance-2-1" && r.QueuedJob == job.ID.Hex()
+	})).Return(nil).Once()
+	s.mockSchedulerRepository.On("FinishJobRun", mock.Anything, mock.MatchedBy(func(r *domain.JobRun) bool {
+		return r.ID == run.ID && r.Status == domain.JobSucceeded && r.Processed == 4 && r.FinishedAt != nil
+	})).Return(nil).Once()
+	mockQueueRepository.On("FinishJob", mock.MatchedBy(func(j *domain.QueuedJob) bool {
+		return j.Status == domain.QueueCompleted
+	})).Return(nil).Once()
+
+	worked, err := queue.Work(context.Background(), "instance-2-1")
+
+	s.NoError(err)
+	s.True(worked)
+	s.mockSchedulerRepository.AssertExpectations(s.T())
+	mockQueueRepository.AssertExpectations(s.T())
+}
+
+func (s *SchedulerTestSuite) TestQueuedJobAlreadyQueued() {
+	mockQueueRepository := new(mocks.QueueRepository)
+	queue := usecase.NewJobQueue(mockQueueRepository, time.Second*2)
+	s.NoError(s.Scheduler.AddQueuedJob("purge", "0 3 * * *", queue, time.Hour, counter(new(int))))
+
+	s.mockSchedulerRepository.On("ClaimJob", mock.Anything, "purge", mock.Anything, "instance-1", time.Hour).Return(true, nil).Once()
+	s.mockSchedulerRepository.On("AddJobRun", mock.Anything, mock.Anything).Return(nil).Once()
+	mockQueueRepository.On("EnqueueJob", mock.Anything, mock.Anything).Return(false, nil).Once()
+	s.mockSchedulerRepository.On("FinishJobRun", mock.Anything, mock.MatchedBy(func(r *domain.JobRun) bool {
+		return r.Status == domain.JobFailed && r.Error == "A job with key purge is already queued"
+	})).Return(nil).Once()
+
+	err := s.Scheduler.RunDue(context.Background(), time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC))
+
+	s.EqualError(err, "Job purge failed: A job with key purge is already queued")
+	s.mockSchedulerRepository.AssertExpectations(s.T())
+}
+
+func (s *SchedulerTestSuite) TestAddJobInvalidSchedule() {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "0 25 * * *", "*/0 * * * *", "5-1 * * * *", "@every 30s", "@yearly"} {
+		s.Error(s.Scheduler.AddJob("job", spec, counter(new(int))), spec)
+	}
+}
+
+func (s *SchedulerTestSuite) TestAddJobTwice() {
+	s.NoError(s.Scheduler.AddJob("job", "@daily", counter(new(int))))
+	s.EqualError(s.Scheduler.AddJob("job", "@hourly", counter(new(int))), "Job job is already scheduled")
+}
+
+func (s *SchedulerTestSuite) TestJobsNextRun() {
+	s.NoError(s.Scheduler.AddJob("sunday", "0 6 * * 7", counter(new(int))))
+	s.NoError(s.Scheduler.AddJob("leap_day", "0 0 29 2 *", counter(new(int))))
+
+	jobs := s.Scheduler.Jobs(context.Background())
+
+	s.Require().Len(jobs, 2)
+	s.Equal(time.Sunday, jobs[0].NextRun.Weekday())
+	s.Equal(6, jobs[0].NextRun.Hour())
+	s.True(jobs[0].NextRun.After(time.Now()))
+	s.Equal(time.February, jobs[1].NextRun.Month())
+	s.Equal(29, jobs[1].NextRun.Day())
+}
+
+func TestSchedulerTestSuite(t *testing.T) {
+	suite.Run(t, new(SchedulerTestSuite))
+}
@@ -2,39 +2,156 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"loan_tracker_api/domain"
+	"regexp"
+	"strings"
 	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type UserUsecase struct {
 	UserRepo       domain.UserRepository
+	LoanRepo       domain.LoanRepository
+	KYCRepo        domain.KYCRepository
+	LogRepo        domain.LogRepository
+	UnitOfWork     domain.UnitOfWork
+	Events         domain.EventBus
 	contextTimeout time.Duration
 }
 
-func NewUserUsecase(Userrepo domain.UserRepository, timeout time.Duration) domain.UserUsecase {
+func NewUserUsecase(Userrepo domain.UserRepository, Loanrepo domain.LoanRepository, KYCrepo domain.KYCRepository, Logrepo domain.LogRepository, Unitofwork domain.UnitOfWork, Events domain.EventBus, timeout time.Duration) domain.UserUsecase {
 	return &UserUsecase{
 		UserRepo:       Userrepo,
+		LoanRepo:       Loanrepo,
+		KYCRepo:        KYCrepo,
+		LogRepo:        Logrepo,
+		UnitOfWork:     Unitofwork,
+		Events:         Events,
 		contextTimeout: timeout,
 	}
 
 }
 
+// auditUser records a change made to a user by the actor
+func (uuse *UserUsecase) auditUser(c context.Context, action, actorid string, targetID primitive.ObjectID, changes []domain.FieldChange, note string) error {
+	return audit(c, uuse.LogRepo, actorid, domain.Log{
+		Action:     action,
+		TargetType: domain.TargetUser,
+		TargetID:   targetID,
+		Changes:    changes,
+		Note:       note,
+	})
+}
+
+// languageTag matches language tags such as "en" or "pt-br", emails are sent in the closest language available
+var languageTag = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// normalizeLanguage lowercases the preferred language of a user and rejects anything that isn't a language tag
+func normalizeLanguage(language string) (string, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language != "" && !languageTag.MatchString(language) {
+		return "", errors.New("Invalid language")
+	}
+	return language, nil
+}
+
+// checkNotificationChannels rejects channel choices for unknown or required notifications and unknown channels
+func checkNotificationChannels(preferences map[string][]string) error {
+	for kind, channels := range preferences {
+		if _, ok := domain.DefaultChannels[kind]; !ok {
+			return errors.New("Unknown notification " + kind)
+		}
+		if domain.RequiredNotifications[kind] {
+			return errors.New("Channels of " + kind + " notifications can't be changed")
+		}
+		for _, channel := range channels {
+			if channel != domain.ChannelEmail && channel != domain.ChannelSMS && channel != domain.ChannelInApp {
+				return errors.New("Unknown channel " + channel)
+			}
+		}
+	}
+	return nil
+}
+
 func (uuse *UserUsecase) RegisterUser(c context.Context, user *domain.User) error {
 	_, cancel := context.WithTimeout(c, uuse.contextTimeout)
 	defer cancel()
-	return uuse.UserRepo.RegisterUser(user)
+
+	language, err := normalizeLanguage(user.Language)
+	if err != nil {
+		return err
+	}
+	user.Language = language
+
+	return uuse.UnitOfWork.Do(c, func(c context.Context) error {
+		if err := uuse.UserRepo.RegisterUser(c, user); err != nil {
+			return err
+		}
+		if err := uuse.auditUser(c, domain.ActionUserRegistered, user.ID.Hex(), user.ID, diff(nil, snapshot(user)), ""); err != nil {
+			return err
+		}
+		return uuse.Events.Publish(c, &domain.UserRegistered{UserID: user.ID, Email: user.Email, UserName: user.UserName, Language: user.Language})
+	})
 }
 
 func (uuse *UserUsecase) VerifyUserEmail(c context.Context, token string) error {
 	_, cancel := context.WithTimeout(c, uuse.contextTimeout)
 	defer cancel()
-	return uuse.UserRepo.VerifyUserEmail(token)
+
+	return uuse.UnitOfWork.Do(c, func(c context.Context) error {
+		user, err := uuse.UserRepo.VerifyUserEmail(c, token)
+		if err != nil {
+			return err
+		}
+
+		before := snapshot(user)
+		user.IsVerified = true
+
+		if err := uuse.auditUser(c, domain.ActionUserVerified, user.ID.Hex(), user.ID, diff(before, snapshot(user)), ""); err != nil {
+			return err
+		}
+		return uuse.Events.Publish(c, &domain.UserVerified{UserID: user.ID})
+	})
 }
 
+// LoginUser logs a user in, failed attempts against a known account are audited as well
 func (uuse *UserUsecase) LoginUser(c context.Context, user domain.User) (string, string, error) {
 	_, cancel := context.WithTimeout(c, uuse.contextTimeout)
 	defer cancel()
-	return uuse.UserRepo.LoginUser(user)
+
+	account, lookupErr := uuse.UserRepo.UserByEmail(user.Email)
+	if lookupErr != nil {
+		return uuse.UserRepo.LoginUser(c, user)
+	}
+
+	// the request isn't authenticated yet, the account tells whether an admin is logging in
+	meta, _ := domain.RequestMetaFrom(c)
+	meta.IsAdmin = account.IsAdmin
+	actorCtx := domain.WithRequestMeta(c, meta)
+
+	var refreshToken, accessToken string
+	var loginErr error
+	err := uuse.UnitOfWork.Do(actorCtx, func(c context.Context) error {
+		refreshToken, accessToken, loginErr = uuse.UserRepo.LoginUser(c, user)
+		if loginErr != nil {
+			return loginErr
+		}
+		return uuse.auditUser(c, domain.ActionUserLogin, account.ID.Hex(), account.ID, nil, "")
+	})
+
+	// the failed attempt is recorded on its own, outside the unit that was rolled back
+	if loginErr != nil {
+		uuse.auditUser(actorCtx, domain.ActionUserLoginFailed, account.ID.Hex(), account.ID, nil, loginErr.Error())
+		return "", "", loginErr
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	return refreshToken, accessToken, nil
 }
 
 func (uuse *UserUsecase) TokenRefresh(c context.Context, refresh_token string) (string, error) {
@@ -52,25 +169,98 @@ func (uuse *UserUsecase) UserProfile(c context.Context, uid string) (domain.User
 func (uuse *UserUsecase) ForgotPassword(c context.Context, email string) error {
 	_, cancel := context.WithTimeout(c, uuse.contextTimeout)
 	defer cancel()
-	return uuse.UserRepo.ForgotPassword(email)
+
+	user, err := uuse.UserRepo.UserByEmail(email)
+	if err != nil {
+		return err
+	}
+
+	return uuse.UnitOfWork.Do(c, func(c context.Context) error {
+		if err := uuse.auditUser(c, domain.ActionUserResetRequest, user.ID.Hex(), user.ID, nil, ""); err != nil {
+			return err
+		}
+		return uuse.Events.Publish(c, &domain.PasswordResetRequested{UserID: user.ID, Email: user.Email, Language: user.Language})
+	})
 }
 
 func (uuse *UserUsecase) ResetPassword(c context.Context, token string, newPassword string) error {
 	_, cancel := context.WithTimeout(c, uuse.contextTimeout)
 	defer cancel()
-	return uuse.UserRepo.ResetPassword(token, newPassword)
+
+	return uuse.UnitOfWork.Do(c, func(c context.Context) error {
+		user, err := uuse.UserRepo.ResetPassword(c, token, newPassword)
+		if err != nil {
+			return err
+		}
+
+		changes := []domain.FieldChange{{Field: "password", Before: "[redacted]", After: "[redacted]"}}
+		if err := uuse.auditUser(c, domain.ActionUserPasswordReset, user.ID.Hex(), user.ID, changes, ""); err != nil {
+			return err
+		}
+		return uuse.Events.Publish(c, &domain.PasswordReset{UserID: user.ID})
+	})
 }
 
 func (uuse *UserUsecase) UpdateUserDetails(c context.Context, user *domain.User) error {
 	_, cancel := context.WithTimeout(c, uuse.contextTimeout)
 	defer cancel()
-	return uuse.UserRepo.UpdateUserDetails(user)
+
+	language, err := normalizeLanguage(user.Language)
+	if err != nil {
+		return err
+	}
+	user.Language = language
+
+	if err := checkNotificationChannels(user.NotificationChannels); err != nil {
+		return err
+	}
+
+	current, err := uuse.UserRepo.UserProfile(user.ID.Hex())
+	if err != nil {
+		return err
+	}
+	before := snapshot(current)
+
+	// only the fields given are updated, the rest keep their current values
+	if user.Bio != "" {
+		current.Bio = user.Bio
+	}
+	if user.UserName != "" {
+		current.UserName = user.UserName
+	}
+	if user.Imageuri != "" {
+		current.Imageuri = user.Imageuri
+	}
+	if user.Contact != "" {
+		current.Contact = user.Contact
+	}
+	if user.Language != "" {
+		current.Language = user.Language
+	}
+	if user.NotificationChannels != nil {
+		current.NotificationChannels = user.NotificationChannels
+	}
+
+	return uuse.UnitOfWork.Do(c, func(c context.Context) error {
+		if err := uuse.UserRepo.UpdateUserDetails(c, user); err != nil {
+			return err
+		}
+		return uuse.auditUser(c, domain.ActionUserUpdated, user.ID.Hex(), user.ID, diff(before, snapshot(current)), "")
+	})
 }
 
 func (uuse *UserUsecase) LogoutUser(c context.Context, uid string) error {
 	_, cancel := context.WithTimeout(c, uuse.contextTimeout)
 	defer cancel()
-	return uuse.UserRepo.LogoutUser(uid)
+
+	uidObj, _ := primitive.ObjectIDFromHex(uid)
+
+	return uuse.UnitOfWork.Do(c, func(c context.Context) error {
+		if err := uuse.UserRepo.LogoutUser(c, uid); err != nil {
+			return err
+		}
+		return uuse.auditUser(c, domain.ActionUserLogout, uid, uidObj, nil, "")
+	})
 }
 
 func (uuse *UserUsecase) ViewAllUsers(c context.Context) ([]domain.User, error) {
@@ -79,8 +269,124 @@ func (uuse *UserUsecase) ViewAllUsers(c context.Context) ([]domain.User, error)
 	return uuse.UserRepo.ViewAllUsers()
 }
 
-func (uuse *UserUsecase) DeleteUser(c context.Context, uid string) error {
+// openLoan reports whether a loan still binds its borrower and parties, written off loans can still be recovered
+func openLoan(status string) bool {
+	switch status {
+	case "pending", domain.LoanAwaitingAcceptance, "approved", "written_off":
+		return true
+	}
+	return false
+}
+
+// tombstone returns a deleted user with their personal fields scrubbed and their tokens revoked,
+// the username and email are made unique from the ID
+func tombstone(user domain.User, deletion domain.Deletion) domain.User {
+	name := "deleted-user-" + user.ID.Hex()
+	user.UserName = name
+	user.Email = name + "@deleted.invalid"
+	user.Imageuri = ""
+	user.Bio = ""
+	user.Contact = ""
+	user.Password = ""
+	user.RefreshToken = ""
+	user.IsVerified = false
+	deletion.Anonymized = true
+	user.Deletion = &deletion
+	return user
+}
+
+// DeleteUser refuses to delete a user with open loans, as a borrower or as a guarantor or co-borrower who didn't decline.
+// Otherwise the account is deactivated and anonymized at once, and their KYC profile removed. It is kept as a tombstone
+// their loans stay linked to, and can't be restored.
+func (uuse *UserUsecase) DeleteUser(c context.Context, uid, reason, adminid string) error {
+	_, cancel := context.WithTimeout(c, uuse.contextTimeout)
+	defer cancel()
+
+	user, err := uuse.UserRepo.UserProfile(uid)
+	if err != nil {
+		return err
+	}
+
+	loans, err := uuse.LoanRepo.PartyLoans(uid, user.Email)
+	if err != nil {
+		return err
+	}
+
+	for _, loan := range loans {
+		if !openLoan(loan.Status) {
+			continue
+		}
+		if loan.UserID == user.ID {
+			return errors.New("User has active loans and cannot be deleted")
+		}
+		if i := partyOf(loan, user, primitive.NilObjectID); i >= 0 && loan.Parties[i].Status != domain.PartyDeclined {
+			return errors.New("User guarantees or co-borrows active loans and cannot be deleted")
+		}
+	}
+
+	adminID, _ := primitive.ObjectIDFromHex(adminid)
+	deleted := tombstone(user, domain.Deletion{DeletedAt: time.Now(), DeletedBy: adminID, Reason: reason})
+	changes := diff(nil, map[string]interface{}{"deletion": snapshot(*deleted.Deletion)})
+
+	return uuse.UnitOfWork.Do(c, func(c context.Context) error {
+		if err := uuse.UserRepo.DeleteUser(c, &deleted); err != nil {
+			return err
+		}
+		// the identity they submitted goes with the rest of their personal data
+		if err := uuse.KYCRepo.DeleteKYCProfiles(c, []primitive.ObjectID{user.ID}); err != nil {
+			return err
+		}
+		if err := uuse.auditUser(c, domain.ActionUserDeleted, adminid, user.ID, changes, reason); err != nil {
+			return err
+		}
+		return uuse.Events.Publish(c, &domain.UserDeleted{UserID: user.ID, DeletedBy: adminID})
+	})
+}
+
+func (uuse *UserUsecase) RestoreUser(c context.Context, uid, adminid string) error {
+	_, cancel := context.WithTimeout(c, uuse.contextTimeout)
+	defer cancel()
+
+	uidObj, _ := primitive.ObjectIDFromHex(uid)
+
+	return uuse.UnitOfWork.Do(c, func(c context.Context) error {
+		if err := uuse.UserRepo.RestoreUser(c, uid); err != nil {
+			return err
+		}
+		return uuse.auditUser(c, domain.ActionUserRestored, adminid, uidObj, nil, "")
+	})
+}
+
+// PurgeDeletedUsers removes the users deleted longer than the retention ago, those loans still reference are anonymized.
+// Their KYC profiles are removed with them, the identity they submitted isn't kept once they are gone.
+func (uuse *UserUsecase) PurgeDeletedUsers(c context.Context, retention time.Duration) (int, error) {
 	_, cancel := context.WithTimeout(c, uuse.contextTimeout)
 	defer cancel()
-	return uuse.UserRepo.DeleteUser(uid)
+
+	purged := 0
+	err := uuse.UnitOfWork.Do(c, func(c context.Context) error {
+		ids, err := uuse.UserRepo.PurgeDeletedUsers(c, time.Now().Add(-retention))
+		if err != nil {
+			return err
+		}
+		purged = len(ids)
+		if purged == 0 {
+			return nil
+		}
+
+		if err := uuse.KYCRepo.DeleteKYCProfiles(c, ids); err != nil {
+			return err
+		}
+
+		return audit(c, uuse.LogRepo, "", domain.Log{
+			Action:     domain.ActionUserPurged,
+			TargetType: domain.TargetUser,
+			Note:       fmt.Sprintf("Purged or anonymized %d deleted users", purged),
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
 }
@@ -2,26 +2,45 @@ package usecase_test
 
 import (
 	"context"
+	"errors"
 	"loan_tracker_api/domain"
 	"loan_tracker_api/mocks"
 	"loan_tracker_api/usecase"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // UserUseCasetestSuite struct to hold any shared resources or setup for the tests
 type UserUseCasetestSuite struct {
 	suite.Suite
 	mockUserRepository *mocks.UserRepository
+	mockLoanRepository *mocks.LoanRepository
+	mockKYCRepository  *mocks.KYCRepository
+	mockLogRepository  *mocks.LogRepository
+	mockUnitOfWork     *mocks.UnitOfWork
+	mockEventBus       *mocks.EventBus
 	UserUsecase        domain.UserUsecase
 }
 
 // SetupTest runs before each test case
 func (s *UserUseCasetestSuite) SetupTest() {
 	s.mockUserRepository = new(mocks.UserRepository)
-	s.UserUsecase = usecase.NewUserUsecase(s.mockUserRepository, time.Second*2)
+	s.mockLoanRepository = new(mocks.LoanRepository)
+	s.mockKYCRepository = new(mocks.KYCRepository)
+	s.mockLogRepository = new(mocks.LogRepository)
+	s.mockLogRepository.On("AddLog", mock.Anything, mock.Anything).Return(nil).Maybe()
+	// units of work run their writes directly, the way they do on a standalone server
+	s.mockUnitOfWork = new(mocks.UnitOfWork)
+	s.mockUnitOfWork.On("Do", mock.Anything, mock.Anything).Return(func(c context.Context, work func(context.Context) error) error {
+		return work(c)
+	}).Maybe()
+	s.mockEventBus = new(mocks.EventBus)
+	s.mockEventBus.On("Publish", mock.Anything, mock.Anything).Return(nil).Maybe()
+	s.UserUsecase = usecase.NewUserUsecase(s.mockUserRepository, s.mockLoanRepository, s.mockKYCRepository, s.mockLogRepository, s.mockUnitOfWork, s.mockEventBus, time.Second*2)
 }
 
 // TearDownTest runs after each test case
@@ -39,13 +58,91 @@ func (s *UserUseCasetestSuite) TestRegisterUser() {
 	}
 
 	// Set up the mock expectation
-	s.mockUserRepository.On("RegisterUser", &expectedUser).Return(nil).Once()
+	s.mockUserRepository.On("RegisterUser", mock.Anything, &expectedUser).Return(nil).Once()
 
 	// Call the method
 	err := s.UserUsecase.RegisterUser(context.Background(), &expectedUser)
 
 	// Check if the method returned an error
 	s.NoError(err)
+
+	// Check that the registration was published for its subscribers
+	s.mockEventBus.AssertCalled(s.T(), "Publish", mock.Anything, mock.MatchedBy(func(event domain.Event) bool {
+		registered, ok := event.(*domain.UserRegistered)
+		return ok && registered.UserID == expectedUser.ID && registered.Email == "test@gmail.com"
+	}))
+
+	// Check that the password and the personal data never reach the audit log
+	s.mockLogRepository.AssertCalled(s.T(), "AddLog", mock.Anything, mock.MatchedBy(func(entry *domain.Log) bool {
+		redacted := 0
+		for _, change := range entry.Changes {
+			switch change.Field {
+			case "password", "email", "username":
+				if change.After != "[redacted]" {
+					return false
+				}
+				redacted++
+			}
+		}
+		return entry.Action == domain.ActionUserRegistered && redacted == 3
+	}))
+}
+
+// TestRegisterUserLanguage test that the preferred language is normalized and reaches the verification email
+func (s *UserUseCasetestSuite) TestRegisterUserLanguage() {
+	// Define the expected user data
+	expectedUser := domain.User{
+		UserName: "testuser",
+		Email:    "test@gmail.com",
+		Password: "passwoRd123!",
+		Language: " FR-CA ",
+	}
+
+	// Set up the mock expectation
+	s.mockUserRepository.On("RegisterUser", mock.Anything, &expectedUser).Return(nil).Once()
+
+	// Call the method
+	err := s.UserUsecase.RegisterUser(context.Background(), &expectedUser)
+
+	// Check the language the user is stored and published with
+	s.NoError(err)
+	s.Equal("fr-ca", expectedUser.Language)
+	s.mockEventBus.AssertCalled(s.T(), "Publish", mock.Anything, mock.MatchedBy(func(event domain.Event) bool {
+		registered, ok := event.(*domain.UserRegistered)
+		return ok && registered.Language == "fr-ca"
+	}))
+}
+
+// TestRegisterUserInvalidLanguage test that a preferred language that isn't a language tag is rejected
+func (s *UserUseCasetestSuite) TestRegisterUserInvalidLanguage() {
+	user := domain.User{UserName: "testuser", Email: "test@gmail.com", Language: "<script>"}
+
+	// Call the method
+	err := s.UserUsecase.RegisterUser(context.Background(), &user)
+
+	// Check that nothing was stored
+	s.EqualError(err, "Invalid language")
+	s.mockUserRepository.AssertNotCalled(s.T(), "RegisterUser", mock.Anything, mock.Anything)
+}
+
+// TestRegisterUserFailed test that nothing is published when the user isn't stored
+func (s *UserUseCasetestSuite) TestRegisterUserFailed() {
+	// Define the expected user data
+	expectedUser := domain.User{
+		UserName: "testuser",
+		Email:    "test@gmail.com",
+		Password: "passwoRd123!",
+	}
+
+	// Set up the mock expectation
+	s.mockUserRepository.On("RegisterUser", mock.Anything, &expectedUser).Return(errors.New("User registration failed")).Once()
+
+	// Call the method
+	err := s.UserUsecase.RegisterUser(context.Background(), &expectedUser)
+
+	// Check that the registration failed without an event
+	s.Error(err)
+	s.mockEventBus.AssertNotCalled(s.T(), "Publish", mock.Anything, mock.Anything)
 }
 
 // TestVerifyUserEmail test the VerifyUserEmail method
@@ -54,7 +151,7 @@ func (s *UserUseCasetestSuite) TestVerifyUserEmail() {
 	token := "testtoken"
 
 	// Set up the mock expectation
-	s.mockUserRepository.On("VerifyUserEmail", token).Return(nil).Once()
+	s.mockUserRepository.On("VerifyUserEmail", mock.Anything, token).Return(domain.User{ID: primitive.NewObjectID()}, nil).Once()
 
 	// Call the method
 	err := s.UserUsecase.VerifyUserEmail(context.Background(), token)
@@ -72,7 +169,8 @@ func (s *UserUseCasetestSuite) TestLoginUser() {
 	}
 
 	// Set up the mock expectation
-	s.mockUserRepository.On("LoginUser", expectedUser).Return("token", "anothertoken", nil).Once()
+	s.mockUserRepository.On("UserByEmail", expectedUser.Email).Return(domain.User{ID: primitive.NewObjectID()}, nil).Once()
+	s.mockUserRepository.On("LoginUser", mock.Anything, expectedUser).Return("token", "anothertoken", nil).Once()
 
 	// Call the method
 	_, _, err := s.UserUsecase.LoginUser(context.Background(), expectedUser)
@@ -81,6 +179,29 @@ func (s *UserUseCasetestSuite) TestLoginUser() {
 	s.NoError(err)
 }
 
+// TestLoginUserFailedAttempt test that failed logins against a known account are audited
+func (s *UserUseCasetestSuite) TestLoginUserFailedAttempt() {
+	// Define the expected user data
+	account := domain.User{ID: primitive.NewObjectID(), Email: "test@gmail.com", IsAdmin: true}
+	attempt := domain.User{Email: "test@gmail.com", Password: "wrong"}
+
+	// Set up the mock expectation
+	s.mockUserRepository.On("UserByEmail", attempt.Email).Return(account, nil).Once()
+	s.mockUserRepository.On("LoginUser", mock.Anything, attempt).Return("", "", errors.New("Invalid password")).Once()
+
+	// Call the method
+	_, _, err := s.UserUsecase.LoginUser(context.Background(), attempt)
+
+	// Check that the attempt failed and was audited against the account
+	s.Error(err)
+	s.mockLogRepository.AssertCalled(s.T(), "AddLog", mock.Anything, mock.MatchedBy(func(entry *domain.Log) bool {
+		return entry.Action == domain.ActionUserLoginFailed &&
+			entry.ActorID == account.ID &&
+			entry.ActorRole == domain.RoleAdmin &&
+			entry.Note == "Invalid password"
+	}))
+}
+
 // TestTokenRefresh test the TokenRefresh method
 func (s *UserUseCasetestSuite) TestTokenRefresh() {
 	// Define the expected token
@@ -117,13 +238,17 @@ func (s *UserUseCasetestSuite) TestForgotPassword() {
 	email := "test@gmail.com"
 
 	// Set up the mock expectation
-	s.mockUserRepository.On("ForgotPassword", email).Return(nil).Once()
+	s.mockUserRepository.On("UserByEmail", email).Return(domain.User{ID: primitive.NewObjectID(), Email: email}, nil).Once()
 
 	// Call the method
 	err := s.UserUsecase.ForgotPassword(context.Background(), email)
 
 	// Check if the method returned an error
 	s.NoError(err)
+	s.mockEventBus.AssertCalled(s.T(), "Publish", mock.Anything, mock.MatchedBy(func(event domain.Event) bool {
+		requested, ok := event.(*domain.PasswordResetRequested)
+		return ok && requested.Email == email
+	}))
 }
 
 // TestResetPassword test the ResetPassword method
@@ -132,7 +257,7 @@ func (s *UserUseCasetestSuite) TestResetPassword() {
 	newpass := "password123"
 
 	//setup mock expectations
-	s.mockUserRepository.On("ResetPassword", token, newpass).Return(nil).Once()
+	s.mockUserRepository.On("ResetPassword", mock.Anything, token, newpass).Return(domain.User{ID: primitive.NewObjectID()}, nil).Once()
 
 	//call the method
 	err := s.UserUsecase.ResetPassword(context.Background(), token, newpass)
@@ -145,12 +270,15 @@ func (s *UserUseCasetestSuite) TestResetPassword() {
 func (s *UserUseCasetestSuite) TestUpdateUserDetails() {
 	// Define the expected user data
 	expectedUser := domain.User{
+		ID:       primitive.NewObjectID(),
 		UserName: "testuser",
 		Email:    "test@gmail.com",
 	}
+	current := domain.User{ID: expectedUser.ID, UserName: "olduser", Email: "test@gmail.com", Bio: "bio"}
 
 	// Set up the mock expectation
-	s.mockUserRepository.On("UpdateUserDetails", &expectedUser).Return(nil).Once()
+	s.mockUserRepository.On("UserProfile", expectedUser.ID.Hex()).Return(current, nil).Once()
+	s.mockUserRepository.On("UpdateUserDetails", mock.Anything, &expectedUser).Return(nil).Once()
 
 	// Call the method
 	err := s.UserUsecase.UpdateUserDetails(context.Background(), &expectedUser)
@@ -158,6 +286,30 @@ func (s *UserUseCasetestSuite) TestUpdateUserDetails() {
 	// Check if the method returned an error
 	s.NoError(err)
 
+	// Check that only the changed field was audited, without the personal data it held
+	s.mockLogRepository.AssertCalled(s.T(), "AddLog", mock.Anything, mock.MatchedBy(func(entry *domain.Log) bool {
+		return entry.Action == domain.ActionUserUpdated && len(entry.Changes) == 1 &&
+			entry.Changes[0] == domain.FieldChange{Field: "username", Before: "[redacted]", After: "[redacted]"}
+	}))
+
+}
+
+// TestUpdateUserNotificationChannels test that channel choices are checked before they are stored
+func (s *UserUseCasetestSuite) TestUpdateUserNotificationChannels() {
+	for message, preferences := range map[string]map[string][]string{
+		"Unknown notification loan_funded":                        {"loan_funded": {domain.ChannelEmail}},
+		"Channels of verification notifications can't be changed": {domain.NotificationVerification: {}},
+		"Unknown channel pigeon":                                  {domain.NotificationPaymentDue: {"pigeon"}},
+	} {
+		user := domain.User{ID: primitive.NewObjectID(), NotificationChannels: preferences}
+
+		// Call the method
+		err := s.UserUsecase.UpdateUserDetails(context.Background(), &user)
+
+		// Check that the choice was rejected
+		s.EqualError(err, message)
+	}
+	s.mockUserRepository.AssertNotCalled(s.T(), "UpdateUserDetails", mock.Anything, mock.Anything)
 }
 
 // TestLogoutUser test the LogoutUser method
@@ -166,7 +318,7 @@ func (s *UserUseCasetestSuite) TestLogoutUser() {
 	uid := "testuid"
 
 	// Set up the mock expectation
-	s.mockUserRepository.On("LogoutUser", uid).Return(nil).Once()
+	s.mockUserRepository.On("LogoutUser", mock.Anything, uid).Return(nil).Once()
 
 	// Call the method
 	err := s.UserUsecase.LogoutUser(context.Background(), uid)
@@ -187,6 +339,113 @@ func (s *UserUseCasetestSuite) TestViewAllUsers() {
 	s.NoError(err)
 }
 
+// TestDeleteUser test the DeleteUser method
+func (s *UserUseCasetestSuite) TestDeleteUser() {
+	// Set up the mock expectation, closed loans and declined invitations don't block the deletion
+	user := domain.User{ID: primitive.NewObjectID(), UserName: "jane", Email: "user@gmail.com", Bio: "Baker", Contact: "+251911000000", Imageuri: "https://example.com/jane.png", Password: "hash", RefreshToken: "token", IsVerified: true, KYCStatus: domain.KYCApproved}
+	loans := []domain.Loan{
+		{UserID: user.ID, Status: "paid_off"},
+		{UserID: user.ID, Status: "rejected"},
+		{UserID: primitive.NewObjectID(), Status: "approved", Parties: []domain.LoanParty{{UserID: user.ID, Status: domain.PartyDeclined}}},
+	}
+	s.mockUserRepository.On("UserProfile", user.ID.Hex()).Return(user, nil).Once()
+	s.mockLoanRepository.On("PartyLoans", user.ID.Hex(), "user@gmail.com").Return(loans, nil).Once()
+	// the personal fields are scrubbed and the tokens revoked when the user is deleted, not when they are purged
+	s.mockUserRepository.On("DeleteUser", mock.Anything, mock.MatchedBy(func(deleted *domain.User) bool {
+		return deleted.ID == user.ID && deleted.UserName == "deleted-user-"+user.ID.Hex() && deleted.Email == "deleted-user-"+user.ID.Hex()+"@deleted.invalid" &&
+			deleted.Bio == "" && deleted.Contact == "" && deleted.Imageuri == "" && deleted.Password == "" && deleted.RefreshToken == "" && !deleted.IsVerified &&
+			deleted.Deletion != nil && deleted.Deletion.Anonymized && deleted.Deletion.Reason == "duplicate account"
+	})).Return(nil).Once()
+	s.mockKYCRepository.On("DeleteKYCProfiles", mock.Anything, []primitive.ObjectID{user.ID}).Return(nil).Once()
+
+	// Call the method
+	err := s.UserUsecase.DeleteUser(context.Background(), user.ID.Hex(), "duplicate account", "testadminid")
+
+	// Check if the method returned an error
+	s.NoError(err)
+	s.mockUserRepository.AssertExpectations(s.T())
+	s.mockKYCRepository.AssertExpectations(s.T())
+}
+
+// TestDeleteUserWithActiveLoans test that DeleteUser refuses borrowers with open loans
+func (s *UserUseCasetestSuite) TestDeleteUserWithActiveLoans() {
+	for _, status := range []string{"pending", domain.LoanAwaitingAcceptance, "approved", "written_off"} {
+		// Set up the mock expectation
+		user := domain.User{ID: primitive.NewObjectID()}
+		loans := []domain.Loan{{UserID: user.ID, Status: "paid_off"}, {UserID: user.ID, Status: status}}
+		s.mockUserRepository.On("UserProfile", user.ID.Hex()).Return(user, nil).Once()
+		s.mockLoanRepository.On("PartyLoans", user.ID.Hex(), "").Return(loans, nil).Once()
+
+		// Call the method
+		err := s.UserUsecase.DeleteUser(context.Background(), user.ID.Hex(), "closing account", "testadminid")
+
+		// Check that the deletion was refused
+		s.EqualError(err, "User has active loans and cannot be deleted", status)
+	}
+	s.mockUserRepository.AssertNotCalled(s.T(), "DeleteUser", mock.Anything, mock.Anything)
+}
+
+// TestDeleteGuarantorOfActiveLoan test that DeleteUser refuses guarantors of open loans, invited by email before they had an account
+func (s *UserUseCasetestSuite) TestDeleteGuarantorOfActiveLoan() {
+	// Set up the mock expectation
+	user := domain.User{ID: primitive.NewObjectID(), Email: "guarantor@gmail.com", IsVerified: true}
+	loans := []domain.Loan{{UserID: primitive.NewObjectID(), Status: "pending", Parties: []domain.LoanParty{{Email: "guarantor@gmail.com", Status: domain.PartyInvited}}}}
+	s.mockUserRepository.On("UserProfile", user.ID.Hex()).Return(user, nil).Once()
+	s.mockLoanRepository.On("PartyLoans", user.ID.Hex(), "guarantor@gmail.com").Return(loans, nil).Once()
+
+	// Call the method
+	err := s.UserUsecase.DeleteUser(context.Background(), user.ID.Hex(), "closing account", "testadminid")
+
+	// Check that the deletion was refused
+	s.EqualError(err, "User guarantees or co-borrows active loans and cannot be deleted")
+	s.mockUserRepository.AssertNotCalled(s.T(), "DeleteUser", mock.Anything, mock.Anything)
+}
+
+// TestRestoreUser test the RestoreUser method
+func (s *UserUseCasetestSuite) TestRestoreUser() {
+	// Set up the mock expectation
+	s.mockUserRepository.On("RestoreUser", mock.Anything, "testuid").Return(nil).Once()
+
+	// Call the method
+	err := s.UserUsecase.RestoreUser(context.Background(), "testuid", "testadminid")
+
+	// Check if the method returned an error
+	s.NoError(err)
+}
+
+// TestPurgeDeletedUsers test the PurgeDeletedUsers method
+func (s *UserUseCasetestSuite) TestPurgeDeletedUsers() {
+	// Set up the mock expectation, only users deleted before the retention period are purged
+	purged := []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID()}
+	s.mockUserRepository.On("PurgeDeletedUsers", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		return before.Before(time.Now().Add(-29*24*time.Hour)) && before.After(time.Now().Add(-31*24*time.Hour))
+	})).Return(purged, nil).Once()
+	// their KYC profiles go with them
+	s.mockKYCRepository.On("DeleteKYCProfiles", mock.Anything, purged).Return(nil).Once()
+
+	// Call the method
+	count, err := s.UserUsecase.PurgeDeletedUsers(context.Background(), 30*24*time.Hour)
+
+	// Check if the method returned an error
+	s.NoError(err)
+	s.Equal(2, count)
+	s.mockKYCRepository.AssertExpectations(s.T())
+}
+
+// TestPurgeWithoutDeletedUsers test that PurgeDeletedUsers leaves KYC profiles alone when nobody was purged
+func (s *UserUseCasetestSuite) TestPurgeWithoutDeletedUsers() {
+	// Set up the mock expectation
+	s.mockUserRepository.On("PurgeDeletedUsers", mock.Anything, mock.Anything).Return([]primitive.ObjectID{}, nil).Once()
+
+	// Call the method
+	count, err := s.UserUsecase.PurgeDeletedUsers(context.Background(), 30*24*time.Hour)
+
+	// Check if the method returned an error
+	s.NoError(err)
+	s.Zero(count)
+	s.mockKYCRepository.AssertNotCalled(s.T(), "DeleteKYCProfiles", mock.Anything, mock.Anything)
+}
+
 // Run the test suite
 func TestUserUsecaseRunSuite(t *testing.T) {
 	suite.Run(t, new(UserUseCasetestSuite))
@@ -0,0 +1,310 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"loan_tracker_api/domain"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type WebhookUsecase struct {
+	WebhookRepo    domain.WebhookRepository
+	OutboxRepo     domain.OutboxRepository
+	UnitOfWork     domain.UnitOfWork
+	Client         domain.WebhookClient
+	contextTimeout time.Duration
+}
+
+func NewWebhookUsecase(Webhookrepo domain.WebhookRepository, Outboxrepo domain.OutboxRepository, Unitofwork domain.UnitOfWork, client domain.WebhookClient, timeout time.Duration) *WebhookUsecase {
+	return &WebhookUsecase{
+		WebhookRepo:    Webhookrepo,
+		OutboxRepo:     Outboxrepo,
+		UnitOfWork:     Unitofwork,
+		Client:         client,
+		contextTimeout: timeout,
+	}
+}
+
+// webhookPayload is the JSON body posted to webhooks
+type webhookPayload struct {
+	ID        primitive.ObjectID `json:"id"`
+	Event     string             `json:"event"`
+	CreatedAt time.Time          `json:"created_at"`
+	Data      interface{}        `json:"data"`
+}
+
+// SignPayload signs the timestamp and body of a webhook request with the webhook secret.
+// Receivers recompute it over the X-Webhook-Timestamp header, a dot and the raw body.
+func SignPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// checkWebhook rejects webhooks that can't be delivered to or subscribe to events that don't exist
+func checkWebhook(webhook *domain.Webhook) error {
+	target, err := url.Parse(webhook.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.New("Invalid webhook URL")
+	}
+
+	if len(webhook.Events) == 0 {
+		return errors.New("A webhook must subscribe to at least one event")
+	}
+
+	for _, name := range webhook.Events {
+		if _, err := domain.NewEvent(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateWebhook stores a new webhook, active or paused as given. A secret is generated when none is given.
+func (wuse *WebhookUsecase) CreateWebhook(c context.Context, webhook *domain.Webhook) error {
+	_, cancel := context.WithTimeout(c, wuse.contextTimeout)
+	defer cancel()
+
+	if err := checkWebhook(webhook); err != nil {
+		return err
+	}
+
+	if webhook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return errors.New("Error generating webhook secret")
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+
+	return wuse.WebhookRepo.AddWebhook(webhook)
+}
+
+// ListWebhooks returns every webhook without its secret
+func (wuse *WebhookUsecase) ListWebhooks(c context.Context) ([]domain.Webhook, error) {
+	_, cancel := context.WithTimeout(c, wuse.contextTimeout)
+	defer cancel()
+
+	webhooks, err := wuse.WebhookRepo.ListWebhooks()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+
+	return webhooks, nil
+}
+
+// UpdateWebhook replaces the URL, events and state of a webhook, its secret is only replaced when a new one is given
+func (wuse *WebhookUsecase) UpdateWebhook(c context.Context, webhook *domain.Webhook) error {
+	_, cancel := context.WithTimeout(c, wuse.contextTimeout)
+	defer cancel()
+
+	if err := checkWebhook(webhook); err != nil {
+		return err
+	}
+
+	current, err := wuse.WebhookRepo.GetWebhook(webhook.ID.Hex())
+	if err != nil {
+		return err
+	}
+
+	if webhook.Secret == "" {
+		webhook.Secret = current.Secret
+	}
+	webhook.CreatedBy = current.CreatedBy
+	webhook.CreatedAt = current.CreatedAt
+
+	if err := wuse.WebhookRepo.UpdateWebhook(webhook); err != nil {
+		return err
+	}
+
+	webhook.Secret = ""
+	return nil
+}
+
+func (wuse *WebhookUsecase) DeleteWebhook(c context.Context, id string) error {
+	_, cancel := context.WithTimeout(c, wuse.contextTimeout)
+	defer cancel()
+	return wuse.WebhookRepo.DeleteWebhook(id)
+}
+
+// EnqueueDeliveries records a delivery of the event for every webhook subscribed to it and queues them in the outbox
+func (wuse *WebhookUsecase) EnqueueDeliveries(c context.Context, event domain.Event) error {
+	webhooks, err := wuse.WebhookRepo.WebhooksFor(event.EventName())
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+
+	header := event.Header()
+	body, err := json.Marshal(webhookPayload{ID: header.EventID, Event: event.EventName(), CreatedAt: header.OccurredAt, Data: event})
+	if err != nil {
+		return errors.New("Error encoding webhook payload")
+	}
+
+	return wuse.UnitOfWork.Do(c, func(c context.Context) error {
+		for _, webhook := range webhooks {
+			delivery := &domain.WebhookDelivery{
+				WebhookID: webhook.ID,
+				EventID:   header.EventID,
+				Event:     event.EventName(),
+				Payload:   string(body),
+			}
+			if err := wuse.WebhookRepo.AddDelivery(c, delivery); err != nil {
+				return err
+			}
+
+			err := wuse.OutboxRepo.AddMessage(c, &domain.OutboxMessage{
+				Kind:      domain.OutboxWebhook,
+				Topic:     delivery.Event,
+				Recipient: delivery.ID.Hex(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// attempt posts a delivery to its webhook and records the attempt, the error tells why the attempt failed
+func (wuse *WebhookUsecase) attempt(webhook domain.Webhook, delivery domain.WebhookDelivery) (domain.DeliveryAttempt, error) {
+	started := time.Now()
+	timestamp := strconv.FormatInt(started.Unix(), 10)
+	headers := map[string]string{
+		"X-Webhook-Id":        delivery.ID.Hex(),
+		"X-Webhook-Event":     delivery.Event,
+		"X-Webhook-Timestamp": timestamp,
+		"X-Webhook-Signature": SignPayload(webhook.Secret, timestamp, []byte(delivery.Payload)),
+	}
+
+	status, err := wuse.Client.Post(webhook.URL, headers, []byte(delivery.Payload))
+	if err == nil && (status < 200 || status > 299) {
+		err = fmt.Errorf("Webhook responded with status %d", status)
+	}
+
+	attempt := domain.DeliveryAttempt{
+		AttemptedAt: started,
+		StatusCode:  status,
+		DurationMS:  time.Since(started).Milliseconds(),
+	}
+	state := domain.DeliveryDelivered
+	if err != nil {
+		attempt.Error = err.Error()
+		state = domain.DeliveryFailed
+	}
+
+	if recordErr := wuse.WebhookRepo.RecordAttempt(delivery.ID, attempt, state); recordErr != nil && err == nil {
+		err = recordErr
+	}
+
+	return attempt, err
+}
+
+// Send posts the delivery an outbox message stands for, failed attempts are retried by the outbox.
+// Deliveries to webhooks that were paused are dropped.
+func (wuse *WebhookUsecase) Send(message domain.OutboxMessage) error {
+	delivery, err := wuse.WebhookRepo.GetDelivery(message.Recipient)
+	if err != nil {
+		return err
+	}
+
+	webhook, err := wuse.WebhookRepo.GetWebhook(delivery.WebhookID.Hex())
+	if err != nil {
+		return err
+	}
+
+	if !webhook.Active {
+		return nil
+	}
+
+	_, err = wuse.attempt(webhook, delivery)
+	return err
+}
+
+// TestWebhook posts a test event to a webhook right away and returns the delivery with its attempt
+func (wuse *WebhookUsecase) TestWebhook(c context.Context, id string) (domain.WebhookDelivery, error) {
+	_, cancel := context.WithTimeout(c, wuse.contextTimeout)
+	defer cancel()
+
+	webhook, err := wuse.WebhookRepo.GetWebhook(id)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	eventID := primitive.NewObjectID()
+	body, err := json.Marshal(webhookPayload{
+		ID:        eventID,
+		Event:     domain.EventWebhookTest,
+		CreatedAt: time.Now(),
+		Data:      map[string]string{"webhook_id": webhook.ID.Hex()},
+	})
+	if err != nil {
+		return domain.WebhookDelivery{}, errors.New("Error encoding webhook payload")
+	}
+
+	delivery := domain.WebhookDelivery{
+		WebhookID: webhook.ID,
+		EventID:   eventID,
+		Event:     domain.EventWebhookTest,
+		Payload:   string(body),
+	}
+	if err := wuse.WebhookRepo.AddDelivery(c, &delivery); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	// a failed test is reported through the delivery, not as an error
+	attempt, err := wuse.attempt(webhook, delivery)
+	delivery.Attempts = append(delivery.Attempts, attempt)
+	delivery.Status = domain.DeliveryDelivered
+	if err != nil {
+		delivery.Status = domain.DeliveryFailed
+	}
+	if delivery.Status == domain.DeliveryDelivered {
+		delivery.DeliveredAt = &attempt.AttemptedAt
+	}
+
+	return delivery, nil
+}
+
+func (wuse *WebhookUsecase) Deliveries(c context.Context, webhookID string, page int) ([]domain.WebhookDelivery, error) {
+	_, cancel := context.WithTimeout(c, wuse.contextTimeout)
+	defer cancel()
+	return wuse.WebhookRepo.Deliveries(webhookID, page)
+}
+
+// Redeliver queues a delivery to be sent again with its original payload, whatever the outcome of earlier attempts
+func (wuse *WebhookUsecase) Redeliver(c context.Context, webhookID, deliveryID string) error {
+	_, cancel := context.WithTimeout(c, wuse.contextTimeout)
+	defer cancel()
+
+	delivery, err := wuse.WebhookRepo.GetDelivery(deliveryID)
+	if err != nil {
+		return err
+	}
+
+	if delivery.WebhookID.Hex() != webhookID {
+		return errors.New("Delivery not found")
+	}
+
+	return wuse.OutboxRepo.AddMessage(c, &domain.OutboxMessage{
+		Kind:      domain.OutboxWebhook,
+		Topic:     delivery.Event,
+		Recipient: delivery.ID.Hex(),
+	})
+}
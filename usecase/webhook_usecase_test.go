@@ -0,0 +1,270 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"loan_tracker_api/domain"
+	"loan_tracker_api/infrastructure"
+	"loan_tracker_api/mocks"
+	"loan_tracker_api/usecase"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type WebhookUsecaseTestSuite struct {
+	suite.Suite
+	mockWebhookRepository *mocks.WebhookRepository
+	mockOutboxRepository  *mocks.OutboxRepository
+	mockUnitOfWork        *mocks.UnitOfWork
+	receiver              *httptest.Server
+	received              []*http.Request
+	bodies                []string
+	status                int
+	WebhookUsecase        *usecase.WebhookUsecase
+}
+
+func (s *WebhookUsecaseTestSuite) SetupTest() {
+	s.mockWebhookRepository = new(mocks.WebhookRepository)
+	s.mockOutboxRepository = new(mocks.OutboxRepository)
+	s.mockUnitOfWork = new(mocks.UnitOfWork)
+	s.mockUnitOfWork.On("Do", mock.Anything, mock.Anything).Return(func(c context.Context, work func(context.Context) error) error {
+		return work(c)
+	}).Maybe()
+
+	// a local receiver standing in for the system behind the webhook
+	s.received, s.bodies, s.status = nil, nil, http.StatusOK
+	s.receiver = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.received = append(s.received, r)
+		s.bodies = append(s.bodies, string(body))
+		w.WriteHeader(s.status)
+	}))
+
+	client := infrastructure.NewHTTPWebhookClient(2 * time.Second)
+	s.WebhookUsecase = usecase.NewWebhookUsecase(s.mockWebhookRepository, s.mockOutboxRepository, s.mockUnitOfWork, client, time.Second*2)
+}
+
+func (s *WebhookUsecaseTestSuite) TearDownTest() {
+	s.receiver.Close()
+}
+
+func (s *WebhookUsecaseTestSuite) webhook() domain.Webhook {
+	return domain.Webhook{
+		ID:     primitive.NewObjectID(),
+		URL:    s.receiver.URL + "/hooks",
+		Events: []string{domain.EventLoanApproved},
+		Secret: "shh",
+		Active: true,
+	}
+}
+
+func (s *WebhookUsecaseTestSuite) TestCreateWebhookGeneratesSecret() {
+	webhook := domain.Webhook{URL: "https://example.com/hooks", Events: []string{domain.EventLoanApproved}, Active: true}
+	s.mockWebhookRepository.On("AddWebhook", &webhook).Return(nil).Once()
+
+	err := s.WebhookUsecase.CreateWebhook(context.Background(), &webhook)
+
+	s.NoError(err)
+	s.Len(webhook.Secret, 64)
+	s.True(webhook.Active)
+	s.mockWebhookRepository.AssertExpectations(s.T())
+}
+
+func (s *WebhookUsecaseTestSuite) TestCreatePausedWebhook() {
+	webhook := domain.Webhook{URL: "https://example.com/hooks", Events: []string{domain.EventLoanApproved}, Secret: "shared"}
+	s.mockWebhookRepository.On("AddWebhook", mock.MatchedBy(func(w *domain.Webhook) bool {
+		return !w.Active && w.Secret == "shared"
+	})).Return(nil).Once()
+
+	err := s.WebhookUsecase.CreateWebhook(context.Background(), &webhook)
+
+	s.NoError(err)
+	s.mockWebhookRepository.AssertExpectations(s.T())
+}
+
+func (s *WebhookUsecaseTestSuite) TestCreateWebhookRejectsInvalidWebhooks() {
+	for _, webhook := range []domain.Webhook{
+		{URL: "ftp://example.com", Events: []string{domain.EventLoanApproved}},
+		{URL: "https://example.com/hooks"},
+		{URL: "https://example.com/hooks", Events: []string{"loan.unknown"}},
+	} {
+		err := s.WebhookUsecase.CreateWebhook(context.Background(), &webhook)
+		s.Error(err)
+	}
+	s.mockWebhookRepository.AssertNotCalled(s.T(), "AddWebhook", mock.Anything)
+}
+
+func (s *WebhookUsecaseTestSuite) TestListWebhooksHidesSecrets() {
+	s.mockWebhookRepository.On("ListWebhooks").Return([]domain.Webhook{s.webhook()}, nil).Once()
+
+	webhooks, err := s.WebhookUsecase.ListWebhooks(context.Background())
+
+	s.NoError(err)
+	s.Len(webhooks, 1)
+	s.Empty(webhooks[0].Secret)
+}
+
+func (s *WebhookUsecaseTestSuite) TestUpdateWebhookKeepsSecret() {
+	current := s.webhook()
+	update := domain.Webhook{ID: current.ID, URL: "https://example.com/new", Events: current.Events}
+	s.mockWebhookRepository.On("GetWebhook", current.ID.Hex()).Return(current, nil).Once()
+	s.mockWebhookRepository.On("UpdateWebhook", mock.MatchedBy(func(w *domain.Webhook) bool {
+		return w.Secret == "shh" && w.URL == "https://example.com/new"
+	})).Return(nil).Once()
+
+	err := s.WebhookUsecase.UpdateWebhook(context.Background(), &update)
+
+	s.NoError(err)
+	s.Empty(update.Secret)
+	s.mockWebhookRepository.AssertExpectations(s.T())
+}
+
+func (s *WebhookUsecaseTestSuite) TestEnqueueDeliveries() {
+	webhook := s.webhook()
+	event := &domain.LoanApproved{LoanID: primitive.NewObjectID()}
+	event.EventID = primitive.NewObjectID()
+	s.mockWebhookRepository.On("WebhooksFor", domain.EventLoanApproved).Return([]domain.Webhook{webhook}, nil).Once()
+
+	deliveryID := primitive.NewObjectID()
+	s.mockWebhookRepository.On("AddDelivery", mock.Anything, mock.MatchedBy(func(d *domain.WebhookDelivery) bool {
+		var payload map[string]interface{}
+		json.Unmarshal([]byte(d.Payload), &payload)
+		return d.WebhookID == webhook.ID && d.EventID == event.EventID && d.Event == domain.EventLoanApproved &&
+			payload["id"] == event.EventID.Hex() && payload["event"] == domain.EventLoanApproved && payload["data"] != nil
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.WebhookDelivery).ID = deliveryID
+	}).Return(nil).Once()
+	s.mockOutboxRepository.On("AddMessage", mock.Anything, mock.MatchedBy(func(m *domain.OutboxMessage) bool {
+		return m.Kind == domain.OutboxWebhook && m.Recipient == deliveryID.Hex()
+	})).Return(nil).Once()
+
+	err := s.WebhookUsecase.EnqueueDeliveries(context.Background(), event)
+
+	s.NoError(err)
+	s.mockWebhookRepository.AssertExpectations(s.T())
+	s.mockOutboxRepository.AssertExpectations(s.T())
+}
+
+func (s *WebhookUsecaseTestSuite) TestEnqueueDeliveriesWithoutWebhooks() {
+	s.mockWebhookRepository.On("WebhooksFor", domain.EventLoanApproved).Return([]domain.Webhook{}, nil).Once()
+
+	err := s.WebhookUsecase.EnqueueDeliveries(context.Background(), &domain.LoanApproved{})
+
+	s.NoError(err)
+	s.mockOutboxRepository.AssertNotCalled(s.T(), "AddMessage", mock.Anything, mock.Anything)
+}
+
+func (s *WebhookUsecaseTestSuite) TestSendSignsPayload() {
+	webhook := s.webhook()
+	delivery := domain.WebhookDelivery{ID: primitive.NewObjectID(), WebhookID: webhook.ID, Event: domain.EventLoanApproved, Payload: `{"event":"loan.approved"}`}
+	s.mockWebhookRepository.On("GetDelivery", delivery.ID.Hex()).Return(delivery, nil).Once()
+	s.mockWebhookRepository.On("GetWebhook", webhook.ID.Hex()).Return(webhook, nil).Once()
+	s.mockWebhookRepository.On("RecordAttempt", delivery.ID, mock.MatchedBy(func(a domain.DeliveryAttempt) bool {
+		return a.StatusCode == http.StatusOK && a.Error == ""
+	}), domain.DeliveryDelivered).Return(nil).Once()
+
+	err := s.WebhookUsecase.Send(domain.OutboxMessage{Kind: domain.OutboxWebhook, Recipient: delivery.ID.Hex()})
+
+	s.NoError(err)
+	s.Len(s.received, 1)
+	request := s.received[0]
+	s.Equal("/hooks", request.URL.Path)
+	s.Equal("application/json", request.Header.Get("Content-Type"))
+	s.Equal(delivery.ID.Hex(), request.Header.Get("X-Webhook-Id"))
+	s.Equal(domain.EventLoanApproved, request.Header.Get("X-Webhook-Event"))
+	s.Equal(delivery.Payload, s.bodies[0])
+
+	// the receiver recomputes the signature with the shared secret
+	timestamp := request.Header.Get("X-Webhook-Timestamp")
+	s.Equal(usecase.SignPayload("shh", timestamp, []byte(s.bodies[0])), request.Header.Get("X-Webhook-Signature"))
+	s.NotEqual(usecase.SignPayload("other", timestamp, []byte(s.bodies[0])), request.Header.Get("X-Webhook-Signature"))
+	s.mockWebhookRepository.AssertExpectations(s.T())
+}
+
+func (s *WebhookUsecaseTestSuite) TestSendRecordsFailedAttempt() {
+	s.status = http.StatusServiceUnavailable
+	webhook := s.webhook()
+	delivery := domain.WebhookDelivery{ID: primitive.NewObjectID(), WebhookID: webhook.ID, Event: domain.EventLoanApproved, Payload: `{}`}
+	s.mockWebhookRepository.On("GetDelivery", delivery.ID.Hex()).Return(delivery, nil).Once()
+	s.mockWebhookRepository.On("GetWebhook", webhook.ID.Hex()).Return(webhook, nil).Once()
+	s.mockWebhookRepository.On("RecordAttempt", delivery.ID, mock.MatchedBy(func(a domain.DeliveryAttempt) bool {
+		return a.StatusCode == http.StatusServiceUnavailable && a.Error == "Webhook responded with status 503"
+	}), domain.DeliveryFailed).Return(nil).Once()
+
+	// the error leaves the message in the outbox to be retried with backoff
+	err := s.WebhookUsecase.Send(domain.OutboxMessage{Kind: domain.OutboxWebhook, Recipient: delivery.ID.Hex()})
+
+	s.EqualError(err, "Webhook responded with status 503")
+	s.mockWebhookRepository.AssertExpectations(s.T())
+}
+
+func (s *WebhookUsecaseTestSuite) TestSendSkipsPausedWebhook() {
+	webhook := s.webhook()
+	webhook.Active = false
+	delivery := domain.WebhookDelivery{ID: primitive.NewObjectID(), WebhookID: webhook.ID, Payload: `{}`}
+	s.mockWebhookRepository.On("GetDelivery", delivery.ID.Hex()).Return(delivery, nil).Once()
+	s.mockWebhookRepository.On("GetWebhook", webhook.ID.Hex()).Return(webhook, nil).Once()
+
+	err := s.WebhookUsecase.Send(domain.OutboxMessage{Kind: domain.OutboxWebhook, Recipient: delivery.ID.Hex()})
+
+	s.NoError(err)
+	s.Empty(s.received)
+	s.mockWebhookRepository.AssertNotCalled(s.T(), "RecordAttempt", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *WebhookUsecaseTestSuite) TestTestWebhook() {
+	s.status = http.StatusInternalServerError
+	webhook := s.webhook()
+	s.mockWebhookRepository.On("GetWebhook", webhook.ID.Hex()).Return(webhook, nil).Once()
+	s.mockWebhookRepository.On("AddDelivery", mock.Anything, mock.MatchedBy(func(d *domain.WebhookDelivery) bool {
+		return d.Event == domain.EventWebhookTest
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.WebhookDelivery).ID = primitive.NewObjectID()
+	}).Return(nil).Once()
+	s.mockWebhookRepository.On("RecordAttempt", mock.Anything, mock.Anything, domain.DeliveryFailed).Return(nil).Once()
+
+	// a failing receiver is reported through the delivery
+	delivery, err := s.WebhookUsecase.TestWebhook(context.Background(), webhook.ID.Hex())
+
+	s.NoError(err)
+	s.Equal(domain.DeliveryFailed, delivery.Status)
+	s.Len(delivery.Attempts, 1)
+	s.Equal(http.StatusInternalServerError, delivery.Attempts[0].StatusCode)
+	s.Len(s.received, 1)
+	s.Equal(domain.EventWebhookTest, s.received[0].Header.Get("X-Webhook-Event"))
+}
+
+func (s *WebhookUsecaseTestSuite) TestRedeliver() {
+	webhook := s.webhook()
+	delivery := domain.WebhookDelivery{ID: primitive.NewObjectID(), WebhookID: webhook.ID, Event: domain.EventLoanApproved}
+	s.mockWebhookRepository.On("GetDelivery", delivery.ID.Hex()).Return(delivery, nil).Once()
+	s.mockOutboxRepository.On("AddMessage", mock.Anything, mock.MatchedBy(func(m *domain.OutboxMessage) bool {
+		return m.Kind == domain.OutboxWebhook && m.Recipient == delivery.ID.Hex()
+	})).Return(nil).Once()
+
+	err := s.WebhookUsecase.Redeliver(context.Background(), webhook.ID.Hex(), delivery.ID.Hex())
+
+	s.NoError(err)
+	s.mockOutboxRepository.AssertExpectations(s.T())
+}
+
+func (s *WebhookUsecaseTestSuite) TestRedeliverOtherWebhook() {
+	delivery := domain.WebhookDelivery{ID: primitive.NewObjectID(), WebhookID: primitive.NewObjectID()}
+	s.mockWebhookRepository.On("GetDelivery", delivery.ID.Hex()).Return(delivery, nil).Once()
+
+	err := s.WebhookUsecase.Redeliver(context.Background(), primitive.NewObjectID().Hex(), delivery.ID.Hex())
+
+	s.EqualError(err, "Delivery not found")
+	s.mockOutboxRepository.AssertNotCalled(s.T(), "AddMessage", mock.Anything, mock.Anything)
+}
+
+func TestWebhookUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookUsecaseTestSuite))
+}